@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rohitashk/golang-rest-api/internal/config"
+)
+
+const configUsage = `usage: api config print [-format yaml|env] [config flags]
+
+Prints the effective configuration after applying the config file,
+environment and flags, with secrets redacted. See api -h for the flags.`
+
+// configCommand runs "api config ..." and returns the exit code.
+func configCommand(args []string) int {
+	if len(args) == 0 || args[0] != "print" {
+		fmt.Fprintln(os.Stderr, configUsage)
+		return 2
+	}
+
+	format, rest, err := takeFlag(args[1:], "format", "yaml")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	cfg, err := config.Load(rest)
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Fprintln(os.Stderr, configUsage)
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid configuration:\n%v\n", err)
+		return 1
+	}
+	if err := cfg.Print(os.Stdout, format); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// takeFlag takes -name out of args, leaving the configuration flags.
+func takeFlag(args []string, name, def string) (string, []string, error) {
+	value := def
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		flagName, v, hasValue := strings.Cut(strings.TrimLeft(args[i], "-"), "=")
+		if !strings.HasPrefix(args[i], "-") || flagName != name {
+			rest = append(rest, args[i])
+			continue
+		}
+		if !hasValue {
+			if i+1 == len(args) {
+				return "", nil, errors.New("-" + name + " needs a value")
+			}
+			i++
+			v = args[i]
+		}
+		value = v
+	}
+	return value, rest, nil
+}
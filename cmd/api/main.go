@@ -2,35 +2,68 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 
+	"github.com/rohitashk/golang-rest-api/internal/adapters/instrumented"
+	"github.com/rohitashk/golang-rest-api/internal/adapters/memory"
 	"github.com/rohitashk/golang-rest-api/internal/adapters/mongodb"
 	"github.com/rohitashk/golang-rest-api/internal/config"
+	"github.com/rohitashk/golang-rest-api/internal/delivery/graphqlapi"
+	"github.com/rohitashk/golang-rest-api/internal/delivery/grpcapi"
 	"github.com/rohitashk/golang-rest-api/internal/delivery/httpapi"
+	"github.com/rohitashk/golang-rest-api/internal/delivery/httpapi/middleware"
+	domainEmployee "github.com/rohitashk/golang-rest-api/internal/domain/employee"
+	"github.com/rohitashk/golang-rest-api/internal/domain/event"
+	"github.com/rohitashk/golang-rest-api/internal/domain/tenant"
+	"github.com/rohitashk/golang-rest-api/internal/health"
 	"github.com/rohitashk/golang-rest-api/internal/observability"
+	customFieldUC "github.com/rohitashk/golang-rest-api/internal/usecase/customfield"
 	employeeUC "github.com/rohitashk/golang-rest-api/internal/usecase/employee"
+	outboxUC "github.com/rohitashk/golang-rest-api/internal/usecase/outbox"
+	webhookUC "github.com/rohitashk/golang-rest-api/internal/usecase/webhook"
 )
 
 func main() {
 	_ = godotenv.Load()
 
-	cfg, err := config.Load()
-	fmt.Println("cfg", cfg)
+	args := os.Args[1:]
+	if len(args) > 0 {
+		switch args[0] {
+		case "config":
+			os.Exit(configCommand(args[1:]))
+		case "migrate":
+			os.Exit(migrateCommand(args[1:]))
+		case "schema":
+			os.Exit(schemaCommand(args[1:]))
+		}
+	}
+
+	cfg, err := config.Load(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
 	if err != nil {
-		panic(err)
+		fmt.Fprintf(os.Stderr, "invalid configuration:\n%v\n", err)
+		os.Exit(2)
 	}
 
-	logger := observability.NewLogger(cfg.AppEnv)
+	runtime := config.NewRuntime(cfg)
+	logger := observability.NewLogger(cfg.AppEnv, runtime.Level())
 	slog.SetDefault(logger)
+	logger.Debug("configuration loaded", "config", cfg)
 
 	if cfg.AppEnv != "prod" {
 		gin.SetMode(gin.DebugMode)
@@ -39,6 +72,20 @@ func main() {
 	}
 
 	ctx := context.Background()
+	metrics := observability.NewMetrics()
+
+	shutdownTracing, err := observability.SetupTracing(ctx, observability.TracingConfig{
+		ServiceName:  cfg.ServiceName,
+		Environment:  cfg.AppEnv,
+		Exporter:     cfg.TracingExporter,
+		OTLPEndpoint: cfg.OTLPEndpoint,
+		OTLPInsecure: cfg.OTLPInsecure,
+		SampleRatio:  cfg.TracingSampleRatio,
+	})
+	if err != nil {
+		logger.Error("tracing setup failed", "err", err)
+		os.Exit(1)
+	}
 
 	mongoClient, err := mongodb.Connect(ctx, cfg.MongoURI, cfg.MongoConnectTimeout)
 	if err != nil {
@@ -49,19 +96,153 @@ func main() {
 		_ = mongoClient.Disconnect(context.Background())
 	}()
 
+	readiness := health.NewRegistry(cfg.HealthCheckTimeout)
+	readiness.Register("mongodb", health.CheckerFunc(mongoClient.Ping))
+
 	db := mongoClient.Database(cfg.MongoDB)
+	if err := migrateOnStart(ctx, mongodb.NewMigrator(db, mongodb.Migrations()), cfg.MongoMigrateOnStart, logger); err != nil {
+		logger.Error("mongo migrations failed", "err", err)
+		os.Exit(1)
+	}
+
 	employeeRepo := mongodb.NewEmployeeRepository(db)
-	if err := employeeRepo.EnsureIndexes(ctx); err != nil {
-		logger.Error("mongo indexes failed", "err", err)
+	if err := ensureSchema(ctx, employeeRepo, mongodb.SchemaMode(cfg.MongoSchemaValidation), logger); err != nil {
+		logger.Error("mongo schema validator failed", "err", err)
 		os.Exit(1)
 	}
+	idempotencyStore := mongodb.NewIdempotencyStore(db)
+	outboxStore := mongodb.NewOutbox(db)
+
+	employees := instrumented.NewEmployeeRepository(employeeRepo, metrics)
+	outbox := instrumented.NewOutbox(outboxStore, metrics)
+	metrics.RegisterHeadcount(func(ctx context.Context) (map[string]map[string]int64, error) {
+		out := map[string]map[string]int64{}
+		for _, id := range runtime.Tenants() {
+			counts, err := employees.Headcount(tenant.WithID(ctx, id))
+			if err != nil {
+				return nil, err
+			}
+			out[id] = counts
+		}
+		return out, nil
+	}, cfg.RequestTimeout)
+
+	// The memory index follows the event feed once the workers start.
+	var searchIndex *memory.EmployeeIndex
+	var searcher domainEmployee.Searcher = employeeRepo
+	if cfg.SearchIndex == "memory" {
+		searchIndex = memory.NewEmployeeIndex(employees, logger)
+		searcher = searchIndex
+	}
+
+	customFields := instrumented.NewCustomFieldRepository(mongodb.NewCustomFieldRepository(db), metrics)
+	employeeDeps := employeeUC.Deps{
+		Repo:         employees,
+		Outbox:       outbox,
+		CustomFields: customFields,
+		Search:       instrumented.NewEmployeeSearcher(searcher, metrics),
+	}
+	if cfg.MongoTransactions {
+		employeeDeps.Tx = mongodb.NewTransactor(mongoClient)
+	}
+	employeeSvc := employeeUC.NewService(employeeDeps)
+	customFieldSvc := customFieldUC.NewService(customFields, employees)
+
+	webhookRepo := mongodb.NewWebhookRepository(db)
+	deliveryRepo := mongodb.NewWebhookDeliveryRepository(db)
+	webhooks := instrumented.NewWebhookRepository(webhookRepo, metrics)
+	deliveries := instrumented.NewWebhookDeliveryRepository(deliveryRepo, metrics)
+	webhookSvc := webhookUC.NewService(webhooks, deliveries)
+
+	publisher := event.Publishers{outboxUC.LogPublisher(logger), webhookSvc}
+
+	var feed event.Feed
+	changeStreams, err := mongoClient.SupportsChangeStreams(ctx)
+	if err != nil {
+		logger.Error("mongo topology check failed", "err", err)
+		os.Exit(1)
+	}
+	if changeStreams {
+		feed = mongodb.NewOutboxFeed(db)
+	} else {
+		logger.Warn("change streams unavailable, employee event stream only sees this instance")
+		broadcaster := memory.NewBroadcaster(1000)
+		feed = broadcaster
+		publisher = append(publisher, broadcaster)
+	}
+	relay := outboxUC.NewRelay(outbox, publisher, logger, outboxUC.RelayConfig{
+		Interval:  cfg.OutboxRelayInterval,
+		BatchSize: cfg.OutboxBatchSize,
+	})
+	dispatcher := webhookUC.NewDispatcher(webhooks, deliveries, webhookUC.NewClient(cfg.WebhookAllowPrivateTargets), logger, webhookUC.DispatcherConfig{
+		Timeout:     cfg.WebhookTimeout,
+		MaxAttempts: cfg.WebhookMaxAttempts,
+	})
+
+	workersCtx, stopWorkers := context.WithCancel(ctx)
+	var workers sync.WaitGroup
+	workers.Add(3)
+	go func() {
+		defer workers.Done()
+		config.NewReloader(args, cfg, runtime, logger).Run(workersCtx)
+	}()
+	go func() {
+		defer workers.Done()
+		relay.Run(workersCtx)
+	}()
+	go func() {
+		defer workers.Done()
+		dispatcher.Run(workersCtx)
+	}()
+	if searchIndex != nil {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			searchIndex.Follow(workersCtx, feed)
+		}()
+	}
 
-	employeeSvc := employeeUC.NewService(employeeRepo)
+	if cfg.SCIMBearerToken == "" {
+		logger.Warn("SCIM_BEARER_TOKEN is not set, SCIM endpoints are unauthenticated")
+	}
+
+	shuttingDown := make(chan struct{})
 
 	router := httpapi.NewRouter(httpapi.RouterDeps{
 		Logger:         logger,
-		RequestTimeout: cfg.RequestTimeout,
+		Health:         readiness,
+		Metrics:        metrics,
+		RequestTimeout: runtime.RequestTimeout,
+		Features:       runtime.Enabled,
 		EmployeeSvc:    employeeSvc,
+		CustomFieldSvc: customFieldSvc,
+		WebhookSvc:     webhookSvc,
+		EventFeed:      feed,
+		Done:           shuttingDown,
+
+		RateLimitStore: memory.NewRateLimitStore(),
+		RateLimit: middleware.RateLimitConfig{
+			Policy: runtime.RateLimits,
+			Logger: logger,
+		},
+		TrustedProxies: cfg.TrustedProxies,
+		Authenticate:   runtime.Authenticate,
+		Tenancy: middleware.TenantConfig{
+			Known:               runtime.KnownTenant,
+			Default:             runtime.DefaultTenant,
+			TrustHeader:         runtime.TrustTenantHeader,
+			CredentialsRequired: runtime.CredentialsRequired,
+		},
+
+		IdempotencyStore: instrumented.NewIdempotencyStore(idempotencyStore, metrics),
+		IdempotencyTTL:   cfg.IdempotencyTTL,
+
+		GraphQLLimits: graphqlapi.Limits{
+			MaxDepth:      cfg.GraphQLMaxDepth,
+			MaxComplexity: cfg.GraphQLMaxComplexity,
+		},
+
+		SCIMToken: cfg.SCIMBearerToken,
 	})
 
 	srv := &http.Server{
@@ -69,6 +250,25 @@ func main() {
 		Handler:           router,
 		ReadHeaderTimeout: 5 * time.Second,
 	}
+	srv.RegisterOnShutdown(func() { close(shuttingDown) })
+
+	grpcSrv := grpcapi.NewServer(grpcapi.ServerDeps{
+		Logger:         logger,
+		RequestTimeout: runtime.RequestTimeout,
+		EmployeeSvc:    employeeSvc,
+		Tenancy: grpcapi.Tenancy{
+			Known:               runtime.KnownTenant,
+			Default:             runtime.DefaultTenant,
+			Authenticate:        runtime.Authenticate,
+			TrustHeader:         runtime.TrustTenantHeader,
+			CredentialsRequired: runtime.CredentialsRequired,
+		},
+	})
+	grpcLis, err := net.Listen("tcp", cfg.GRPCAddr)
+	if err != nil {
+		logger.Error("grpc listen failed", "err", err)
+		os.Exit(1)
+	}
 
 	go func() {
 		logger.Info("http server starting", "addr", cfg.HTTPAddr)
@@ -78,13 +278,58 @@ func main() {
 		}
 	}()
 
+	go func() {
+		logger.Info("grpc server starting", "addr", cfg.GRPCAddr)
+		if err := grpcSrv.Serve(grpcLis); err != nil {
+			logger.Error("grpc server failed", "err", err)
+			os.Exit(1)
+		}
+	}()
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
+	readiness.ShutDown()
+	if cfg.ShutdownDelay > 0 {
+		logger.Info("not ready, waiting before shutdown", "delay", cfg.ShutdownDelay)
+		time.Sleep(cfg.ShutdownDelay)
+	}
+
 	ctxShutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	logger.Info("http server shutting down")
-	_ = srv.Shutdown(ctxShutdown)
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		logger.Info("http server shutting down")
+		_ = srv.Shutdown(ctxShutdown)
+	}()
+	go func() {
+		defer wg.Done()
+		logger.Info("grpc server shutting down")
+		stopped := make(chan struct{})
+		go func() {
+			grpcSrv.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-ctxShutdown.Done():
+			grpcSrv.Stop()
+		}
+	}()
+	wg.Wait()
+
+	// Servers are drained, so nothing new reaches the outbox; whatever the
+	// workers have not sent yet is picked up on the next start.
+	stopWorkers()
+	workers.Wait()
+
+	flushCtx, cancelFlush := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelFlush()
+	if err := shutdownTracing(flushCtx); err != nil {
+		logger.Error("tracing flush failed", "err", err)
+	}
 }
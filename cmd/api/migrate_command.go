@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/rohitashk/golang-rest-api/internal/adapters/mongodb"
+	"github.com/rohitashk/golang-rest-api/internal/config"
+)
+
+const migrateUsage = `usage: api migrate status|up|down [-to VERSION] [config flags]
+
+status  lists every migration and when it was applied
+up      applies pending migrations, up to -to if given
+down    reverts the newest applied migration, or every one above -to
+
+Replicas running migrations at the same time wait for each other.
+See api -h for the flags.`
+
+// migrateCommand runs "api migrate ..." and returns the exit code.
+func migrateCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+	cmd := args[0]
+	if cmd != "status" && cmd != "up" && cmd != "down" {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	toFlag, rest, err := takeFlag(args[1:], "to", "")
+	if err == nil && toFlag != "" && cmd == "status" {
+		err = errors.New("-to only applies to up and down")
+	}
+	to := -1
+	if err == nil && toFlag != "" {
+		if to, err = strconv.Atoi(toFlag); err != nil || to < 0 {
+			err = fmt.Errorf("-to %q: want a version number", toFlag)
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	return withDatabase(rest, migrateUsage, func(ctx context.Context, db *mongo.Database) error {
+		return runMigrate(ctx, mongodb.NewMigrator(db, mongodb.Migrations()), cmd, to)
+	})
+}
+
+// withDatabase loads the configuration from args, connects and runs fn,
+// returning the exit code of a command.
+func withDatabase(args []string, usage string, fn func(ctx context.Context, db *mongo.Database) error) int {
+	cfg, err := config.Load(args)
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Fprintln(os.Stderr, usage)
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid configuration:\n%v\n", err)
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	client, err := mongodb.Connect(ctx, cfg.MongoURI, cfg.MongoConnectTimeout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer func() { _ = client.Disconnect(context.Background()) }()
+
+	if err := fn(ctx, client.Database(cfg.MongoDB)); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+func runMigrate(ctx context.Context, migrator *mongodb.Migrator, cmd string, to int) error {
+	switch cmd {
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "VERSION\tNAME\tAPPLIED")
+		for _, s := range statuses {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.Local().Format(time.RFC3339)
+			}
+			if s.Unknown {
+				applied += " (unknown to this build)"
+			}
+			fmt.Fprintf(tw, "%d\t%s\t%s\n", s.Version, s.Name, applied)
+		}
+		return tw.Flush()
+
+	case "up":
+		done, err := migrator.Up(ctx, max(to, 0))
+		for _, m := range done {
+			fmt.Printf("applied %d %s\n", m.Version, m.Name)
+		}
+		if err == nil && len(done) == 0 {
+			fmt.Println("nothing to apply")
+		}
+		return err
+
+	default:
+		if to < 0 {
+			// One step: down to the applied migration before the newest.
+			statuses, err := migrator.Status(ctx)
+			if err != nil {
+				return err
+			}
+			var applied []int
+			for _, s := range statuses {
+				if s.AppliedAt != nil {
+					applied = append(applied, s.Version)
+				}
+			}
+			if len(applied) == 0 {
+				fmt.Println("nothing to revert")
+				return nil
+			}
+			to = 0
+			if len(applied) > 1 {
+				to = applied[len(applied)-2]
+			}
+		}
+		done, err := migrator.Down(ctx, to)
+		for _, m := range done {
+			fmt.Printf("reverted %d %s\n", m.Version, m.Name)
+		}
+		return err
+	}
+}
+
+// migrateOnStart applies pending migrations, or with apply off refuses to
+// start on an outdated database.
+func migrateOnStart(ctx context.Context, migrator *mongodb.Migrator, apply bool, logger *slog.Logger) error {
+	if !apply {
+		pending, err := migrator.Pending(ctx)
+		if err != nil {
+			return err
+		}
+		if len(pending) > 0 {
+			return fmt.Errorf("%d pending migrations, first %d %s; run api migrate up", len(pending), pending[0].Version, pending[0].Name)
+		}
+		return nil
+	}
+
+	done, err := migrator.Up(ctx, 0)
+	for _, m := range done {
+		logger.Info("migration applied", "version", m.Version, "name", m.Name)
+	}
+	return err
+}
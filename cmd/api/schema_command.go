@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/rohitashk/golang-rest-api/internal/adapters/mongodb"
+)
+
+const schemaUsage = `usage: api schema report [-limit N] [config flags]
+
+Lists stored employees that the collection's schema validator rejects, and
+why, so they can be fixed before MONGO_SCHEMA_VALIDATION=enforce. Exits 1
+when there are any. See api -h for the flags.`
+
+// schemaCommand runs "api schema ..." and returns the exit code.
+func schemaCommand(args []string) int {
+	if len(args) == 0 || args[0] != "report" {
+		fmt.Fprintln(os.Stderr, schemaUsage)
+		return 2
+	}
+	limitFlag, rest, err := takeFlag(args[1:], "limit", "50")
+	limit, convErr := strconv.ParseInt(limitFlag, 10, 64)
+	if err == nil && (convErr != nil || limit < 0) {
+		err = fmt.Errorf("-limit %q: want a number", limitFlag)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	return withDatabase(rest, schemaUsage, func(ctx context.Context, db *mongo.Database) error {
+		docs, total, err := mongodb.NewEmployeeRepository(db).Nonconforming(ctx, limit)
+		if err != nil {
+			return err
+		}
+		for _, d := range docs {
+			fmt.Printf("%s: %s\n", d.ID, strings.Join(d.Reasons, "; "))
+		}
+		if total == 0 {
+			fmt.Println("every employee conforms")
+			return nil
+		}
+		if total > int64(len(docs)) {
+			fmt.Printf("... and %d more\n", total-int64(len(docs)))
+		}
+		return fmt.Errorf("%d employees do not conform", total)
+	})
+}
+
+// ensureSchema keeps the employees validator in line with this build and
+// warns about stored documents it rejects.
+func ensureSchema(ctx context.Context, repo *mongodb.EmployeeRepository, mode mongodb.SchemaMode, logger *slog.Logger) error {
+	changed, err := repo.EnsureSchema(ctx, mode)
+	if err != nil {
+		return err
+	}
+	if changed {
+		logger.Info("employees schema validator installed", "mode", mode)
+	}
+	if mode == mongodb.SchemaOff {
+		return nil
+	}
+	if _, total, err := repo.Nonconforming(ctx, 0); err != nil {
+		return err
+	} else if total > 0 {
+		logger.Warn("employees do not conform to the schema; list them with api schema report", "count", total, "mode", mode)
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+// Command dirsync updates employees from an LDIF export or an LDAP server.
+// It prints the planned changes and only writes them with -apply.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+
+	"github.com/joho/godotenv"
+
+	ldapsrc "github.com/rohitashk/golang-rest-api/internal/adapters/ldap"
+	"github.com/rohitashk/golang-rest-api/internal/adapters/ldif"
+	"github.com/rohitashk/golang-rest-api/internal/adapters/mongodb"
+	"github.com/rohitashk/golang-rest-api/internal/config"
+	"github.com/rohitashk/golang-rest-api/internal/domain/directory"
+	"github.com/rohitashk/golang-rest-api/internal/domain/tenant"
+	"github.com/rohitashk/golang-rest-api/internal/usecase/dirsync"
+	employeeUC "github.com/rohitashk/golang-rest-api/internal/usecase/employee"
+)
+
+func main() {
+	_ = godotenv.Load()
+	os.Exit(cli(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// cli runs dirsync and returns its exit status.
+func cli(args []string, stdout, stderr io.Writer) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := run(ctx, args, stdout, stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "dirsync:", err)
+		return 1
+	}
+	return 0
+}
+
+// openStore connects to the configured database and returns the employee
+// service's dependencies on it and a function closing the connection.
+// Tests replace it.
+var openStore = func(ctx context.Context, cfg config.Config) (employeeUC.Deps, func(), error) {
+	client, err := mongodb.Connect(ctx, cfg.MongoURI, cfg.MongoConnectTimeout)
+	if err != nil {
+		return employeeUC.Deps{}, nil, fmt.Errorf("mongo connect: %w", err)
+	}
+	db := client.Database(cfg.MongoDB)
+	deps := employeeUC.Deps{
+		Repo:         mongodb.NewEmployeeRepository(db),
+		Outbox:       mongodb.NewOutbox(db),
+		CustomFields: mongodb.NewCustomFieldRepository(db),
+	}
+	if cfg.MongoTransactions {
+		deps.Tx = mongodb.NewTransactor(client)
+	}
+	return deps, func() { _ = client.Disconnect(context.Background()) }, nil
+}
+
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("dirsync", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var (
+		ldifPath = fs.String("ldif", "", "LDIF file to read")
+		ldapURL  = fs.String("ldap-url", "", "LDAP server to search, e.g. ldaps://ldap.example.com")
+		bindDN   = fs.String("bind-dn", "", "DN to bind as; the password is read from LDAP_BIND_PASSWORD")
+		baseDN   = fs.String("base-dn", "", "search base")
+		filter   = fs.String("filter", "(objectClass=inetOrgPerson)", "search filter")
+		mapping  = fs.String("mapping", "", "JSON attribute mapping (default: inetOrgPerson)")
+		apply    = fs.Bool("apply", false, "write the changes instead of only reporting them")
+		format   = fs.String("format", "text", "report format: text or json")
+		tenantID = fs.String("tenant", "", "tenant whose employees are synced (default: DEFAULT_TENANT)")
+	)
+	fs.Usage = func() {
+		out := fs.Output()
+		fmt.Fprintln(out, "usage: dirsync (-ldif FILE | -ldap-url URL -base-dn DN) [-mapping FILE] [-apply]")
+		fmt.Fprintln(out)
+		fs.PrintDefaults()
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Employees are matched by email. Entries without an email, or whose mapped")
+		fmt.Fprintln(out, "fields fail validation, are skipped and listed in the report.")
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+
+	if (*ldifPath == "") == (*ldapURL == "") {
+		return errors.New("set exactly one of -ldif and -ldap-url")
+	}
+	if *format != "text" && *format != "json" {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	if *tenantID != "" && !tenant.Valid(*tenantID) {
+		return fmt.Errorf("invalid tenant %q", *tenantID)
+	}
+
+	m := dirsync.DefaultMapping()
+	if *mapping != "" {
+		f, err := os.Open(*mapping)
+		if err != nil {
+			return err
+		}
+		m, err = dirsync.LoadMapping(f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	var source directory.Source = ldif.FileSource{Path: *ldifPath}
+	if *ldapURL != "" {
+		source = ldapsrc.NewSource(ldapsrc.Config{
+			URL:          *ldapURL,
+			BindDN:       *bindDN,
+			BindPassword: os.Getenv("LDAP_BIND_PASSWORD"),
+			BaseDN:       *baseDN,
+			Filter:       *filter,
+		})
+	}
+
+	cfg, err := config.Load(nil)
+	if err != nil {
+		return err
+	}
+
+	if *tenantID == "" {
+		*tenantID = cfg.DefaultTenant
+	}
+	if !slices.Contains(cfg.Tenants, *tenantID) {
+		return fmt.Errorf("tenant %q is not one of TENANTS", *tenantID)
+	}
+
+	ctx = tenant.WithID(ctx, *tenantID)
+
+	deps, closeStore, err := openStore(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+	syncer := dirsync.NewSyncer(deps.Repo, employeeUC.NewService(deps), m)
+
+	entries, err := source.Entries(ctx)
+	if err != nil {
+		return err
+	}
+	report, err := syncer.Plan(ctx, entries)
+	if err != nil {
+		return err
+	}
+
+	out := struct {
+		*dirsync.Report
+		Result *dirsync.Result `json:"result,omitempty"`
+	}{Report: report}
+
+	if *apply {
+		if out.Result, err = syncer.Apply(ctx, report); err != nil {
+			return err
+		}
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(out); err != nil {
+			return err
+		}
+	} else {
+		if err := report.WriteText(stdout); err != nil {
+			return err
+		}
+		if out.Result != nil {
+			fmt.Fprintf(stdout, "applied: %d created, %d updated, %d failed\n",
+				out.Result.Created, out.Result.Updated, len(out.Result.Failures))
+			for _, f := range out.Result.Failures {
+				fmt.Fprintf(stdout, "  %s: %s\n", f.Email, f.Error)
+			}
+		} else {
+			fmt.Fprintln(stdout, "dry run; pass -apply to write these changes")
+		}
+	}
+
+	if out.Result != nil && len(out.Result.Failures) > 0 {
+		return fmt.Errorf("%d changes failed", len(out.Result.Failures))
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rohitashk/golang-rest-api/internal/adapters/memory"
+	"github.com/rohitashk/golang-rest-api/internal/config"
+	domainEmployee "github.com/rohitashk/golang-rest-api/internal/domain/employee"
+	"github.com/rohitashk/golang-rest-api/internal/domain/tenant"
+	employeeUC "github.com/rohitashk/golang-rest-api/internal/usecase/employee"
+)
+
+// export promotes grace and adds ada, who reports to her.
+const export = `version: 1
+
+dn: uid=grace,ou=People,dc=example,dc=com
+givenName: Grace
+sn: Hopper
+mail: grace@example.com
+ou: Navy
+title: Rear Admiral
+
+dn: uid=ada,ou=People,dc=example,dc=com
+givenName: Ada
+sn: Lovelace
+mail: ada@example.com
+ou: R&D
+title: Engineer
+manager: uid=grace,ou=People,dc=example,dc=com
+`
+
+// failingRepository fails every employee it is asked to create.
+type failingRepository struct {
+	*memory.EmployeeRepository
+}
+
+func (failingRepository) Create(context.Context, *domainEmployee.Employee) error {
+	return errors.New("disk full")
+}
+
+// store replaces the database with repo, holding grace as a captain of
+// the acme tenant, and returns the LDIF file to sync from.
+func store(t *testing.T, repo *memory.EmployeeRepository) string {
+	t.Helper()
+	t.Setenv(config.ConfigFileEnv, "")
+	t.Setenv("MONGO_URI", "mongodb://localhost:27017")
+	t.Setenv("TENANTS", "default,acme")
+	t.Setenv("DEFAULT_TENANT", "default")
+
+	if _, err := employeeUC.NewService(employeeUC.Deps{Repo: repo}).Create(tenant.WithID(context.Background(), "acme"), employeeUC.CreateInput{
+		FirstName: "Grace", LastName: "Hopper", Email: "grace@example.com", Department: "Navy", Position: "Captain",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	saved := openStore
+	openStore = func(context.Context, config.Config) (employeeUC.Deps, func(), error) {
+		return employeeUC.Deps{Repo: repo}, func() {}, nil
+	}
+	t.Cleanup(func() { openStore = saved })
+
+	file := filepath.Join(t.TempDir(), "people.ldif")
+	if err := os.WriteFile(file, []byte(export), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+// runDirsync runs the command and returns its exit status and output.
+func runDirsync(args ...string) (code int, stdout, stderr string) {
+	var out, errOut bytes.Buffer
+	code = cli(args, &out, &errOut)
+	return code, out.String(), errOut.String()
+}
+
+func TestFlags(t *testing.T) {
+	file := store(t, memory.NewEmployeeRepository())
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{nil, "set exactly one of -ldif and -ldap-url"},
+		{[]string{"-ldif", file, "-ldap-url", "ldap://localhost"}, "set exactly one"},
+		{[]string{"-ldif", file, "-format", "yaml"}, `unknown format "yaml"`},
+		{[]string{"-ldif", file, "-tenant", "Not A Tenant"}, "invalid tenant"},
+		{[]string{"-ldif", file, "-tenant", "globex"}, `tenant "globex" is not one of TENANTS`},
+		{[]string{"-ldif", file, "-mapping", filepath.Join(t.TempDir(), "missing.json")}, "missing.json"},
+		{[]string{"-ldif", file, "extra"}, `unexpected argument "extra"`},
+		{[]string{"-ldif", file, "-nope"}, "flag provided but not defined: -nope"},
+	}
+	for _, tt := range tests {
+		code, _, stderr := runDirsync(tt.args...)
+		if code != 1 || !strings.Contains(stderr, tt.want) {
+			t.Errorf("%q: exit %d, stderr %q; want 1 with %q", tt.args, code, stderr, tt.want)
+		}
+	}
+
+	if code, _, stderr := runDirsync("-h"); code != 0 || !strings.Contains(stderr, "usage: dirsync") {
+		t.Errorf("-h: exit %d, stderr %q", code, stderr)
+	}
+}
+
+func TestDryRunAndApply(t *testing.T) {
+	repo := memory.NewEmployeeRepository()
+	file := store(t, repo)
+	ctx := tenant.WithID(context.Background(), "acme")
+
+	code, out, stderr := runDirsync("-ldif", file, "-tenant", "acme")
+	if code != 0 {
+		t.Fatalf("dry run exited %d: %s", code, stderr)
+	}
+	for _, want := range []string{"+ create ada@example.com", "~ update grace@example.com", "1 to create, 1 to update", "dry run; pass -apply"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report lacks %q:\n%s", want, out)
+		}
+	}
+	if ada, _ := repo.GetByEmail(ctx, "ada@example.com"); ada != nil {
+		t.Error("the dry run created ada")
+	}
+
+	// The default tenant has no grace to update.
+	if _, out, _ := runDirsync("-ldif", file); !strings.Contains(out, "2 to create, 0 to update") {
+		t.Errorf("default tenant report:\n%s", out)
+	}
+
+	code, out, stderr = runDirsync("-ldif", file, "-tenant", "acme", "-apply", "-format", "json")
+	if code != 0 {
+		t.Fatalf("apply exited %d: %s", code, stderr)
+	}
+	var report struct {
+		Result struct{ Created, Updated int }
+	}
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatalf("%v: %s", err, out)
+	}
+	if report.Result.Created != 1 || report.Result.Updated != 1 {
+		t.Errorf("result = %+v", report.Result)
+	}
+	grace, _ := repo.GetByEmail(ctx, "grace@example.com")
+	ada, _ := repo.GetByEmail(ctx, "ada@example.com")
+	if grace.Position != "Rear Admiral" || ada == nil || ada.ManagerID != grace.ID {
+		t.Errorf("grace = %+v, ada = %+v", grace, ada)
+	}
+}
+
+func TestFailedChangesExitNonZero(t *testing.T) {
+	repo := memory.NewEmployeeRepository()
+	file := store(t, repo)
+	openStore = func(context.Context, config.Config) (employeeUC.Deps, func(), error) {
+		return employeeUC.Deps{Repo: failingRepository{repo}}, func() {}, nil
+	}
+
+	code, out, stderr := runDirsync("-ldif", file, "-tenant", "acme", "-apply")
+	if code != 1 || !strings.Contains(stderr, "dirsync: 1 changes failed") {
+		t.Errorf("exit %d, stderr %q", code, stderr)
+	}
+	if !strings.Contains(out, "applied: 0 created, 1 updated, 1 failed\n  ada@example.com: ") {
+		t.Errorf("report:\n%s", out)
+	}
+
+	if code, _, stderr := runDirsync("-ldif", filepath.Join(t.TempDir(), "missing.ldif")); code != 1 || !strings.Contains(stderr, "missing.ldif") {
+		t.Errorf("missing LDIF: exit %d, stderr %q", code, stderr)
+	}
+}
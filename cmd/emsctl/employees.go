@@ -0,0 +1,231 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	employeeUC "github.com/rohitashk/golang-rest-api/internal/usecase/employee"
+)
+
+func (a *app) employeesCommand(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: emsctl employees list|get|create|update|delete")
+	}
+	switch cmd, rest := args[0], args[1:]; cmd {
+	case "list":
+		return a.listEmployees(ctx, rest)
+	case "get":
+		return a.getEmployee(ctx, rest)
+	case "create":
+		return a.createEmployee(ctx, rest)
+	case "update":
+		return a.updateEmployee(ctx, rest)
+	case "delete":
+		return a.deleteEmployee(ctx, rest)
+	default:
+		return fmt.Errorf("unknown command employees %s", cmd)
+	}
+}
+
+func (a *app) listEmployees(ctx context.Context, args []string) error {
+	var in employeeUC.ListInput
+	var department, status, manager, query string
+	pos, err := subcommand("employees list", args, func(fs *flag.FlagSet) {
+		fs.StringVar(&department, "department", "", "only this department")
+		fs.StringVar(&status, "status", "", "only active or inactive employees")
+		fs.StringVar(&manager, "manager", "", "only direct reports of this employee ID")
+		fs.StringVar(&query, "q", "", "search names, email and position")
+		fs.Func("field", "only employees whose custom field has this value, as key=value; repeatable", func(v string) error {
+			key, value, err := cutField(v)
+			if err != nil {
+				return err
+			}
+			if in.CustomFields == nil {
+				in.CustomFields = map[string]string{}
+			}
+			in.CustomFields[key] = value
+			return nil
+		})
+		fs.Int64Var(&in.Limit, "limit", 20, "page size, at most 200")
+		fs.Int64Var(&in.Offset, "offset", 0, "employees to skip")
+	})
+	if err != nil {
+		return err
+	}
+	if err := wantArgs("employees list", pos, 0, "[flags]"); err != nil {
+		return err
+	}
+	in.Department = optional(department)
+	in.Status = optional(status)
+	in.ManagerID = optional(manager)
+	in.Query = optional(query)
+
+	ctx, err = a.connect(ctx)
+	if err != nil {
+		return err
+	}
+	items, _, err := a.employees.List(ctx, in)
+	if err != nil {
+		return err
+	}
+	return a.out.employees(items)
+}
+
+func (a *app) getEmployee(ctx context.Context, args []string) error {
+	pos, err := subcommand("employees get", args, nil)
+	if err != nil {
+		return err
+	}
+	if err := wantArgs("employees get", pos, 1, "ID"); err != nil {
+		return err
+	}
+	ctx, err = a.connect(ctx)
+	if err != nil {
+		return err
+	}
+	e, err := a.employees.Get(ctx, pos[0])
+	if err != nil {
+		return err
+	}
+	return a.out.employee(e)
+}
+
+func (a *app) createEmployee(ctx context.Context, args []string) error {
+	var in employeeUC.CreateInput
+	pos, err := subcommand("employees create", args, func(fs *flag.FlagSet) {
+		fs.StringVar(&in.FirstName, "first-name", "", "first name")
+		fs.StringVar(&in.LastName, "last-name", "", "last name")
+		fs.StringVar(&in.Email, "email", "", "email, unique among employees")
+		fs.StringVar(&in.Phone, "phone", "", "phone number in E.164 form, e.g. +14155550100")
+		fs.StringVar(&in.DateOfBirth, "date-of-birth", "", "date of birth as YYYY-MM-DD")
+		fs.StringVar(&in.Department, "department", "", "department")
+		fs.StringVar(&in.Position, "position", "", "position")
+		fs.Float64Var(&in.Salary, "salary", 0, "salary")
+		fs.StringVar(&in.Status, "status", "", "active (default) or inactive")
+		fs.StringVar(&in.ManagerID, "manager", "", "manager's employee ID")
+		fs.Func("field", "custom field as key=value; repeatable", setCustomField(&in.CustomFields))
+	})
+	if err != nil {
+		return err
+	}
+	if err := wantArgs("employees create", pos, 0, "[flags]"); err != nil {
+		return err
+	}
+	ctx, err = a.connect(ctx)
+	if err != nil {
+		return err
+	}
+	e, err := a.employees.Create(ctx, in)
+	if err != nil {
+		return err
+	}
+	return a.out.employee(e)
+}
+
+func (a *app) updateEmployee(ctx context.Context, args []string) error {
+	var in employeeUC.UpdateInput
+	pos, err := subcommand("employees update", args, func(fs *flag.FlagSet) {
+		// Only the flags given are changed.
+		fs.Func("first-name", "first name", setString(&in.FirstName))
+		fs.Func("last-name", "last name", setString(&in.LastName))
+		fs.Func("email", "email", setString(&in.Email))
+		fs.Func("phone", `phone number in E.164 form, "" to remove`, setString(&in.Phone))
+		fs.Func("date-of-birth", `date of birth as YYYY-MM-DD, "" to remove`, setString(&in.DateOfBirth))
+		fs.Func("department", "department", setString(&in.Department))
+		fs.Func("position", "position", setString(&in.Position))
+		fs.Func("salary", "salary", func(v string) error {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return fmt.Errorf("invalid number %q", v)
+			}
+			in.Salary = &f
+			return nil
+		})
+		fs.Func("status", "active or inactive", setString(&in.Status))
+		fs.Func("manager", `manager's employee ID, "" to remove`, setString(&in.ManagerID))
+		fs.Func("field", "custom field as key=value, key= to clear it; repeatable", func(v string) error {
+			if err := setCustomField(&in.CustomFields)(v); err != nil {
+				return err
+			}
+			if key, value, _ := strings.Cut(v, "="); value == "" {
+				in.CustomFields[key] = nil
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+	if err := wantArgs("employees update", pos, 1, "ID [flags]"); err != nil {
+		return err
+	}
+	ctx, err = a.connect(ctx)
+	if err != nil {
+		return err
+	}
+	e, err := a.employees.Update(ctx, pos[0], in)
+	if err != nil {
+		return err
+	}
+	return a.out.employee(e)
+}
+
+func (a *app) deleteEmployee(ctx context.Context, args []string) error {
+	pos, err := subcommand("employees delete", args, nil)
+	if err != nil {
+		return err
+	}
+	if err := wantArgs("employees delete", pos, 1, "ID"); err != nil {
+		return err
+	}
+	ctx, err = a.connect(ctx)
+	if err != nil {
+		return err
+	}
+	if err := a.employees.Delete(ctx, pos[0]); err != nil {
+		return err
+	}
+	fmt.Fprintln(a.stdout, "deleted", pos[0])
+	return nil
+}
+
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// setCustomField parses key=value into m. Values stay text; the use case
+// converts them to the field's type.
+func setCustomField(m *map[string]any) func(string) error {
+	return func(v string) error {
+		key, value, err := cutField(v)
+		if err != nil {
+			return err
+		}
+		if *m == nil {
+			*m = map[string]any{}
+		}
+		(*m)[key] = value
+		return nil
+	}
+}
+
+func cutField(v string) (key, value string, err error) {
+	key, value, ok := strings.Cut(v, "=")
+	if !ok || key == "" {
+		return "", "", fmt.Errorf("want key=value, got %q", v)
+	}
+	return key, value, nil
+}
+
+func setString(p **string) func(string) error {
+	return func(v string) error {
+		*p = &v
+		return nil
+	}
+}
@@ -0,0 +1,156 @@
+// Command emsctl manages employees from the command line. It talks to a
+// running API with -api, or straight to MongoDB using the service's own
+// configuration otherwise.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/joho/godotenv"
+
+	"github.com/rohitashk/golang-rest-api/internal/domain"
+)
+
+const usage = `usage: emsctl [flags] COMMAND [ARGS]
+
+Commands:
+  employees list [-department D] [-status S] [-manager ID] [-q TEXT] [-limit N] [-offset N]
+  employees get ID
+  employees create -first-name F -last-name L -email E -department D -position P [-phone P] [-date-of-birth D] [-salary N] [-status S] [-manager ID]
+  employees update ID [-first-name F] [-last-name L] [-email E] [-phone P] [-date-of-birth D] [-department D] [-position P] [-salary N] [-status S] [-manager ID]
+  employees delete ID
+  import [-format json|csv] FILE    create employees from a file, - for stdin
+  export                            write every employee in the -o format
+  indexes ensure                    create MongoDB indexes (direct only)
+  audit show [-limit N] ID          list the recorded events of an employee (direct only)
+
+Every command acts for one tenant and only sees its employees.
+
+Flags:
+`
+
+type app struct {
+	stdout io.Writer
+	out    output
+
+	apiURL     string
+	apiKey     string
+	tenant     string
+	configFile string
+
+	direct    *directConn // nil over HTTP
+	employees employeeStore
+}
+
+func main() {
+	_ = godotenv.Load()
+	os.Exit(cli(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// cli runs emsctl and returns its exit status.
+func cli(args []string, stdout, stderr io.Writer) int {
+	err := run(args, stdout, stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "emsctl:", describe(err))
+		return 1
+	}
+	return 0
+}
+
+func run(args []string, stdout, stderr io.Writer) error {
+	a := &app{stdout: stdout}
+
+	fs := flag.NewFlagSet("emsctl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&a.apiURL, "api", os.Getenv("EMSCTL_API_URL"), "base URL of a running API, e.g. http://localhost:8080; direct MongoDB access when empty")
+	fs.StringVar(&a.apiKey, "api-key", os.Getenv("EMSCTL_API_KEY"), "sent as X-API-Key")
+	fs.StringVar(&a.tenant, "tenant", os.Getenv("EMSCTL_TENANT"), "tenant to act for; the configured default when empty")
+	fs.StringVar(&a.configFile, "config", "", "service config file, for direct access")
+	format := fs.String("o", "table", "output format: table, json or csv")
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), usage)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var err error
+	if a.out, err = newOutput(a.stdout, *format); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	defer a.close()
+
+	cmd, rest := fs.Arg(0), fs.Args()[1:]
+	switch cmd {
+	case "employees":
+		return a.employeesCommand(ctx, rest)
+	case "import":
+		return a.importCommand(ctx, rest)
+	case "export":
+		return a.exportCommand(ctx, rest)
+	case "indexes":
+		return a.indexesCommand(ctx, rest)
+	case "audit":
+		return a.auditCommand(ctx, rest)
+	}
+	return fmt.Errorf("unknown command %q; run emsctl -h", cmd)
+}
+
+// describe adds the rejected fields that domain.Error leaves out of its
+// message.
+func describe(err error) string {
+	msg := err.Error()
+	var derr domain.Error
+	if errors.As(err, &derr) {
+		for _, f := range derr.Fields {
+			msg += "\n  " + f.Field + ": " + f.Message
+		}
+	}
+	return msg
+}
+
+// subcommand parses the flags of a command, which may come before or after
+// its positional arguments.
+func subcommand(name string, args []string, setup func(fs *flag.FlagSet)) ([]string, error) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	if setup != nil {
+		setup(fs)
+	}
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+func wantArgs(cmd string, args []string, n int, names string) error {
+	if len(args) != n {
+		return fmt.Errorf("usage: emsctl %s %s", cmd, names)
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/rohitashk/golang-rest-api/internal/adapters/memory"
+	"github.com/rohitashk/golang-rest-api/internal/delivery/httpapi"
+	"github.com/rohitashk/golang-rest-api/internal/domain/customfield"
+	"github.com/rohitashk/golang-rest-api/internal/domain/tenant"
+	"github.com/rohitashk/golang-rest-api/internal/health"
+	customFieldUC "github.com/rohitashk/golang-rest-api/internal/usecase/customfield"
+	employeeUC "github.com/rohitashk/golang-rest-api/internal/usecase/employee"
+)
+
+func init() { gin.SetMode(gin.TestMode) }
+
+// apiServer serves the API in memory for the default tenant, whose
+// employees may have a badge.
+func apiServer(t *testing.T) string {
+	t.Helper()
+	for _, env := range []string{"EMSCTL_API_URL", "EMSCTL_API_KEY", "EMSCTL_TENANT"} {
+		t.Setenv(env, "")
+	}
+	employees, fields := memory.NewEmployeeRepository(), memory.NewCustomFieldRepository()
+	if err := fields.Create(tenant.WithID(context.Background(), tenant.Default), &customfield.Definition{Key: "badge", Type: customfield.TypeString}); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(httpapi.NewRouter(httpapi.RouterDeps{
+		Health:         health.NewRegistry(time.Second),
+		EmployeeSvc:    employeeUC.NewService(employeeUC.Deps{Repo: employees, CustomFields: fields}),
+		CustomFieldSvc: customFieldUC.NewService(fields, employees),
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+// emsctl runs the command against api and returns its exit status and
+// output.
+func emsctl(t *testing.T, api string, args ...string) (code int, stdout, stderr string) {
+	t.Helper()
+	var out, errOut bytes.Buffer
+	code = cli(append([]string{"-api", api}, args...), &out, &errOut)
+	return code, out.String(), errOut.String()
+}
+
+func TestEmployeesOutput(t *testing.T) {
+	api := apiServer(t)
+	code, out, stderr := emsctl(t, api, "-o", "json", "employees", "create",
+		"-first-name", "Ada", "-last-name", "Lovelace", "-email", "ada@example.com",
+		"-department", "R&D", "-position", "Engineer", "-salary", "100", "-field", "badge=AB1234")
+	if code != 0 {
+		t.Fatalf("create exited %d: %s", code, stderr)
+	}
+	var created employeeRecord
+	if err := json.Unmarshal([]byte(out), &created); err != nil {
+		t.Fatalf("%v: %s", err, out)
+	}
+	if created.ID == "" || created.Email != "ada@example.com" || created.CustomFields["badge"] != "AB1234" {
+		t.Errorf("created %+v", created)
+	}
+
+	_, out, _ = emsctl(t, api, "employees", "list")
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "ID") || !strings.Contains(lines[0], "CUSTOM_FIELDS.BADGE") || !strings.Contains(lines[1], "ada@example.com") {
+		t.Errorf("table =\n%s", out)
+	}
+
+	_, out, _ = emsctl(t, api, "employees", "get", created.ID)
+	if !strings.Contains(out, "email:") || !strings.Contains(out, "custom_fields.badge:") {
+		t.Errorf("table of one employee =\n%s", out)
+	}
+
+	_, out, _ = emsctl(t, api, "-o", "csv", "employees", "list")
+	rows, err := csv.NewReader(strings.NewReader(out)).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[0][0] != "id" || rows[1][0] != created.ID || rows[0][len(rows[0])-1] != "custom_fields.badge" || rows[1][len(rows[1])-1] != "AB1234" {
+		t.Errorf("csv = %q", rows)
+	}
+}
+
+func TestExportImportCSV(t *testing.T) {
+	api := apiServer(t)
+	for _, who := range [][]string{
+		{"-first-name", "Ada", "-last-name", "Lovelace", "-email", "ada@example.com", "-phone", "+14155550100", "-field", "badge=AB1234"},
+		{"-first-name", "Alan", "-last-name", "Turing", "-email", "alan@example.com", "-date-of-birth", "1912-06-23"},
+	} {
+		args := append([]string{"employees", "create", "-department", "R&D", "-position", "Engineer"}, who...)
+		if code, _, stderr := emsctl(t, api, args...); code != 0 {
+			t.Fatalf("create exited %d: %s", code, stderr)
+		}
+	}
+	code, exported, stderr := emsctl(t, api, "-o", "csv", "export")
+	if code != 0 {
+		t.Fatalf("export exited %d: %s", code, stderr)
+	}
+
+	// The export goes into another directory unchanged.
+	file := filepath.Join(t.TempDir(), "employees.csv")
+	if err := os.WriteFile(file, []byte(exported), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	other := apiServer(t)
+	if code, out, stderr := emsctl(t, other, "import", file); code != 0 {
+		t.Fatalf("import exited %d: %s%s", code, out, stderr)
+	}
+	_, reexported, _ := emsctl(t, other, "-o", "csv", "export")
+
+	// IDs and timestamps are new; everything else survives.
+	strip := func(out string) [][]string {
+		rows, err := csv.NewReader(strings.NewReader(out)).ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, row := range rows[1:] {
+			row[0], row[11], row[12] = "", "", ""
+		}
+		return rows
+	}
+	want, got := strip(exported), strip(reexported)
+	if len(got) != 3 || strings.Join(got[0], ",") != strings.Join(want[0], ",") {
+		t.Fatalf("re-exported %q, want %q", got, want)
+	}
+	// The listing is newest first, so the import reversed the order.
+	for i := 1; i < len(want); i++ {
+		if a, b := strings.Join(want[i], ","), strings.Join(got[len(got)-i], ","); a != b {
+			t.Errorf("row %d = %s, want %s", i, b, a)
+		}
+	}
+}
+
+func TestExitStatus(t *testing.T) {
+	api := apiServer(t)
+
+	code, _, stderr := emsctl(t, api, "employees", "get", "6650c0ffee0000000000a001")
+	if code == 0 || !strings.Contains(stderr, "emsctl: ") {
+		t.Errorf("unknown employee: exit %d, stderr %q", code, stderr)
+	}
+
+	// Rejected fields are listed.
+	code, _, stderr = emsctl(t, api, "employees", "create", "-first-name", "Ada", "-email", "not-an-email")
+	if code == 0 || !strings.Contains(stderr, "\n  email: ") || !strings.Contains(stderr, "\n  last_name: ") {
+		t.Errorf("invalid employee: exit %d, stderr %q", code, stderr)
+	}
+
+	// A partly failed import fails, after creating the good rows.
+	file := filepath.Join(t.TempDir(), "employees.csv")
+	content := "first_name,last_name,email,department,position\nAda,Lovelace,ada@example.com,R&D,Engineer\nBob,,bob@example.com,R&D,Engineer\n"
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	code, out, stderr := emsctl(t, api, "-o", "json", "import", file)
+	if code == 0 || !strings.Contains(stderr, "1 of 2 rows failed") {
+		t.Errorf("import: exit %d, stderr %q", code, stderr)
+	}
+	var results []importResult
+	if err := json.Unmarshal([]byte(out), &results); err != nil || len(results) != 2 || results[0].ID == "" || results[1].Error == "" {
+		t.Errorf("results = %s", out)
+	}
+
+	if code, _, _ := emsctl(t, api, "frobnicate"); code == 0 {
+		t.Error("unknown command exited 0")
+	}
+	if code, _, _ := emsctl(t, "http://127.0.0.1:1", "employees", "list"); code == 0 {
+		t.Error("unreachable API exited 0")
+	}
+}
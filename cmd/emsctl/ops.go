@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rohitashk/golang-rest-api/internal/adapters/mongodb"
+	"github.com/rohitashk/golang-rest-api/internal/domain/event"
+)
+
+func (a *app) indexesCommand(ctx context.Context, args []string) error {
+	if len(args) != 1 || args[0] != "ensure" {
+		return fmt.Errorf("usage: emsctl indexes ensure")
+	}
+	if err := a.requireDirect("indexes ensure"); err != nil {
+		return err
+	}
+	// Migrations cover every tenant.
+	if _, err := a.connect(ctx); err != nil {
+		return err
+	}
+
+	// Indexes are created by migrations, so this is "api migrate up".
+	done, err := mongodb.NewMigrator(a.direct.db, mongodb.Migrations()).Up(ctx, 0)
+	for _, m := range done {
+		fmt.Fprintf(a.stdout, "applied migration %d %s\n", m.Version, m.Name)
+	}
+	if err == nil && len(done) == 0 {
+		fmt.Fprintln(a.stdout, "indexes are up to date")
+	}
+	return err
+}
+
+func (a *app) auditCommand(ctx context.Context, args []string) error {
+	if len(args) == 0 || args[0] != "show" {
+		return fmt.Errorf("usage: emsctl audit show [-limit N] ID")
+	}
+	var limit int64
+	pos, err := subcommand("audit show", args[1:], func(fs *flag.FlagSet) {
+		fs.Int64Var(&limit, "limit", 0, "at most this many events, oldest first; 0 for all")
+	})
+	if err != nil {
+		return err
+	}
+	if err := wantArgs("audit show", pos, 1, "[-limit N] ID"); err != nil {
+		return err
+	}
+	if err := a.requireDirect("audit show"); err != nil {
+		return err
+	}
+	ctx, err = a.connect(ctx)
+	if err != nil {
+		return err
+	}
+
+	events, err := a.direct.outbox.History(ctx, pos[0], limit)
+	if err != nil {
+		return err
+	}
+	if len(events) == 0 {
+		return fmt.Errorf("no events recorded for %s", pos[0])
+	}
+
+	bodies := make([]json.RawMessage, 0, len(events))
+	rows := make([][]string, 0, len(events))
+	for _, e := range events {
+		body, err := e.Body()
+		if err != nil {
+			return err
+		}
+		bodies = append(bodies, body)
+		rows = append(rows, []string{e.OccurredAt.UTC().Format(time.RFC3339), string(e.Type), e.ID, summarize(e)})
+	}
+	return a.out.write(bodies, []string{"occurred_at", "type", "id", "changes"}, rows)
+}
+
+// summarize lists the fields an update or merge changed; creates and deletes
+// carry the whole employee, which the JSON output shows.
+func summarize(e event.Envelope) string {
+	if e.Type != event.TypeEmployeeUpdated && e.Type != event.TypeEmployeeMerged {
+		return ""
+	}
+	var ev event.EmployeeMerged // an update has the same changes
+	if err := json.Unmarshal(e.Payload, &ev); err != nil {
+		return "?"
+	}
+	parts := make([]string, 0, len(ev.Changes)+1)
+	if e.Type == event.TypeEmployeeMerged {
+		parts = append(parts, "merged "+ev.Duplicate.ID)
+	}
+	for _, c := range ev.Changes {
+		parts = append(parts, fmt.Sprintf("%s: %v -> %v", c.Field, c.Old, c.New))
+	}
+	return strings.Join(parts, "; ")
+}
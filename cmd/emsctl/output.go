@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	domainEmployee "github.com/rohitashk/golang-rest-api/internal/domain/employee"
+)
+
+type output struct {
+	w      io.Writer
+	format string
+}
+
+func newOutput(w io.Writer, format string) (output, error) {
+	switch format {
+	case "table", "json", "csv":
+		return output{w: w, format: format}, nil
+	}
+	return output{}, fmt.Errorf("unknown output format %q, want table, json or csv", format)
+}
+
+// write prints v as JSON, or header and rows as a table or CSV.
+func (o output) write(v any, header []string, rows [][]string) error {
+	switch o.format {
+	case "json":
+		enc := json.NewEncoder(o.w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case "csv":
+		w := csv.NewWriter(o.w)
+		_ = w.Write(header)
+		_ = w.WriteAll(rows)
+		return w.Error()
+	}
+	tw := tabwriter.NewWriter(o.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.ToUpper(strings.Join(header, "\t")))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// employeeRecord is an employee as emsctl writes it, and as import reads
+// it. The JSON form matches the API's.
+type employeeRecord struct {
+	ID           string         `json:"id,omitempty"`
+	FirstName    string         `json:"first_name"`
+	LastName     string         `json:"last_name"`
+	Email        string         `json:"email"`
+	Phone        string         `json:"phone,omitempty"`
+	DateOfBirth  string         `json:"date_of_birth,omitempty"`
+	Department   string         `json:"department"`
+	Position     string         `json:"position"`
+	Salary       float64        `json:"salary"`
+	Status       string         `json:"status"`
+	ManagerID    string         `json:"manager_id,omitempty"`
+	CustomFields map[string]any `json:"custom_fields,omitempty"`
+	CreatedAt    string         `json:"created_at,omitempty"`
+	UpdatedAt    string         `json:"updated_at,omitempty"`
+}
+
+var employeeColumns = []string{
+	domainEmployee.FieldID, domainEmployee.FieldFirstName, domainEmployee.FieldLastName,
+	domainEmployee.FieldEmail, domainEmployee.FieldPhone, domainEmployee.FieldDateOfBirth,
+	domainEmployee.FieldDepartment, domainEmployee.FieldPosition, domainEmployee.FieldSalary,
+	domainEmployee.FieldStatus, domainEmployee.FieldManagerID, domainEmployee.FieldCreatedAt, domainEmployee.FieldUpdatedAt,
+}
+
+// customFieldColumn prefixes a custom field's key in CSV and table headers,
+// which get a column for every custom field set on any of the employees.
+const customFieldColumn = domainEmployee.FieldCustomFields + "."
+
+func toRecord(e *domainEmployee.Employee) employeeRecord {
+	return employeeRecord{
+		ID:           e.ID,
+		FirstName:    e.FirstName,
+		LastName:     e.LastName,
+		Email:        e.Email,
+		Phone:        e.Phone,
+		DateOfBirth:  e.DateOfBirth,
+		Department:   e.Department,
+		Position:     e.Position,
+		Salary:       e.Salary,
+		Status:       string(e.Status),
+		ManagerID:    e.ManagerID,
+		CustomFields: e.CustomFields,
+		CreatedAt:    e.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:    e.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+func (r employeeRecord) row(customKeys []string) []string {
+	row := []string{
+		r.ID, r.FirstName, r.LastName, r.Email, r.Phone, r.DateOfBirth, r.Department, r.Position,
+		strconv.FormatFloat(r.Salary, 'f', -1, 64), r.Status, r.ManagerID, r.CreatedAt, r.UpdatedAt,
+	}
+	for _, k := range customKeys {
+		switch v := r.CustomFields[k].(type) {
+		case nil:
+			row = append(row, "")
+		case float64:
+			row = append(row, strconv.FormatFloat(v, 'f', -1, 64))
+		default:
+			row = append(row, fmt.Sprint(v))
+		}
+	}
+	return row
+}
+
+// employeeHeader lists the columns of records, and the custom field keys
+// among them.
+func employeeHeader(records []employeeRecord) (header, customKeys []string) {
+	seen := map[string]bool{}
+	for _, r := range records {
+		for k := range r.CustomFields {
+			if !seen[k] {
+				seen[k] = true
+				customKeys = append(customKeys, k)
+			}
+		}
+	}
+	sort.Strings(customKeys)
+
+	header = append([]string{}, employeeColumns...)
+	for _, k := range customKeys {
+		header = append(header, customFieldColumn+k)
+	}
+	return header, customKeys
+}
+
+func (o output) employees(items []domainEmployee.Employee) error {
+	records := make([]employeeRecord, 0, len(items))
+	for i := range items {
+		records = append(records, toRecord(&items[i]))
+	}
+	header, customKeys := employeeHeader(records)
+	rows := make([][]string, 0, len(records))
+	for _, r := range records {
+		rows = append(rows, r.row(customKeys))
+	}
+	return o.write(records, header, rows)
+}
+
+func (o output) employee(e *domainEmployee.Employee) error {
+	r := toRecord(e)
+	header, customKeys := employeeHeader([]employeeRecord{r})
+	if o.format == "table" {
+		// One employee reads better as a list of fields.
+		tw := tabwriter.NewWriter(o.w, 0, 0, 2, ' ', 0)
+		for i, v := range r.row(customKeys) {
+			fmt.Fprintf(tw, "%s:\t%s\n", header[i], v)
+		}
+		return tw.Flush()
+	}
+	return o.write(r, header, [][]string{r.row(customKeys)})
+}
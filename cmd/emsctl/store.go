@@ -0,0 +1,300 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/rohitashk/golang-rest-api/internal/adapters/mongodb"
+	"github.com/rohitashk/golang-rest-api/internal/config"
+	"github.com/rohitashk/golang-rest-api/internal/domain"
+	domainEmployee "github.com/rohitashk/golang-rest-api/internal/domain/employee"
+	"github.com/rohitashk/golang-rest-api/internal/domain/tenant"
+	employeeUC "github.com/rohitashk/golang-rest-api/internal/usecase/employee"
+)
+
+// employeeStore is what the employee commands need; *employeeUC.Service
+// provides it directly and httpStore through the API.
+type employeeStore interface {
+	List(ctx context.Context, in employeeUC.ListInput) ([]domainEmployee.Employee, int64, error)
+	Get(ctx context.Context, id string, fields ...string) (*domainEmployee.Employee, error)
+	Create(ctx context.Context, in employeeUC.CreateInput) (*domainEmployee.Employee, error)
+	Update(ctx context.Context, id string, in employeeUC.UpdateInput) (*domainEmployee.Employee, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type directConn struct {
+	client *mongodb.Client
+	db     *mongo.Database
+	outbox *mongodb.Outbox
+}
+
+// connect opens the store the commands use: the API when -api is set, the
+// database otherwise. The commands use the returned ctx, which acts for the
+// -tenant.
+func (a *app) connect(ctx context.Context) (context.Context, error) {
+	if a.tenant != "" && !tenant.Valid(a.tenant) {
+		return nil, fmt.Errorf("-tenant %q is not a valid tenant ID", a.tenant)
+	}
+	if a.apiURL != "" {
+		u, err := url.Parse(a.apiURL)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("-api %q: want a URL like http://localhost:8080", a.apiURL)
+		}
+		a.employees = &httpStore{
+			base:   strings.TrimRight(a.apiURL, "/"),
+			apiKey: a.apiKey,
+			tenant: a.tenant,
+			client: &http.Client{Timeout: 30 * time.Second},
+		}
+		return ctx, nil
+	}
+
+	var args []string
+	if a.configFile != "" {
+		args = []string{"-config", a.configFile}
+	}
+	cfg, err := config.Load(args)
+	if err != nil {
+		return nil, err
+	}
+	if a.tenant == "" {
+		a.tenant = cfg.DefaultTenant
+	}
+	if !slices.Contains(cfg.Tenants, a.tenant) {
+		return nil, fmt.Errorf("tenant %q is not one of the configured tenants", a.tenant)
+	}
+
+	client, err := mongodb.Connect(ctx, cfg.MongoURI, cfg.MongoConnectTimeout)
+	if err != nil {
+		return nil, err
+	}
+	db := client.Database(cfg.MongoDB)
+	a.direct = &directConn{client: client, db: db, outbox: mongodb.NewOutbox(db)}
+
+	// Writes record their events like the API's do, so webhooks and the
+	// audit trail see them.
+	deps := employeeUC.Deps{
+		Repo:         mongodb.NewEmployeeRepository(db),
+		Outbox:       a.direct.outbox,
+		CustomFields: mongodb.NewCustomFieldRepository(db),
+	}
+	if cfg.MongoTransactions {
+		deps.Tx = mongodb.NewTransactor(client)
+	}
+	a.employees = employeeUC.NewService(deps)
+	return tenant.WithID(ctx, a.tenant), nil
+}
+
+func (a *app) close() {
+	if a.direct != nil {
+		_ = a.direct.client.Disconnect(context.Background())
+	}
+}
+
+func (a *app) requireDirect(cmd string) error {
+	if a.apiURL != "" {
+		return fmt.Errorf("%s needs direct database access; run it without -api", cmd)
+	}
+	return nil
+}
+
+// httpStore calls the REST API. Problem responses become domain.Errors of
+// the same kind, so commands handle both stores alike.
+type httpStore struct {
+	base   string
+	apiKey string
+	tenant string
+	client *http.Client
+}
+
+// apiEmployee is the API's employee representation.
+type apiEmployee struct {
+	ID           string         `json:"id"`
+	FirstName    string         `json:"first_name"`
+	LastName     string         `json:"last_name"`
+	Email        string         `json:"email"`
+	Phone        *string        `json:"phone"`
+	DateOfBirth  *string        `json:"date_of_birth"`
+	Department   string         `json:"department"`
+	Position     string         `json:"position"`
+	Salary       float64        `json:"salary"`
+	Status       string         `json:"status"`
+	ManagerID    *string        `json:"manager_id"`
+	CustomFields map[string]any `json:"custom_fields"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+}
+
+func (e apiEmployee) toDomain() *domainEmployee.Employee {
+	out := &domainEmployee.Employee{
+		ID:         e.ID,
+		FirstName:  e.FirstName,
+		LastName:   e.LastName,
+		Email:      e.Email,
+		Department: e.Department,
+		Position:   e.Position,
+		Salary:     e.Salary,
+		Status:     domainEmployee.Status(e.Status),
+		CreatedAt:  e.CreatedAt,
+		UpdatedAt:  e.UpdatedAt,
+	}
+	if len(e.CustomFields) > 0 {
+		out.CustomFields = e.CustomFields
+	}
+	if e.ManagerID != nil {
+		out.ManagerID = *e.ManagerID
+	}
+	if e.Phone != nil {
+		out.Phone = *e.Phone
+	}
+	if e.DateOfBirth != nil {
+		out.DateOfBirth = *e.DateOfBirth
+	}
+	return out
+}
+
+func (s *httpStore) List(ctx context.Context, in employeeUC.ListInput) ([]domainEmployee.Employee, int64, error) {
+	q := url.Values{}
+	q.Set("limit", strconv.FormatInt(in.Limit, 10))
+	q.Set("offset", strconv.FormatInt(in.Offset, 10))
+	for name, v := range map[string]*string{
+		"department": in.Department,
+		"status":     in.Status,
+		"manager_id": in.ManagerID,
+		"q":          in.Query,
+	} {
+		if v != nil {
+			q.Set(name, *v)
+		}
+	}
+	for key, v := range in.CustomFields {
+		q.Set("custom_fields["+key+"]", v)
+	}
+	if len(in.Fields) > 0 {
+		q.Set("fields", strings.Join(in.Fields, ","))
+	}
+
+	var body struct {
+		Data []apiEmployee `json:"data"`
+		Meta struct {
+			Total int64 `json:"total"`
+		} `json:"meta"`
+	}
+	if err := s.do(ctx, http.MethodGet, "/v1/employees?"+q.Encode(), nil, &body); err != nil {
+		return nil, 0, err
+	}
+	out := make([]domainEmployee.Employee, 0, len(body.Data))
+	for _, e := range body.Data {
+		out = append(out, *e.toDomain())
+	}
+	return out, body.Meta.Total, nil
+}
+
+func (s *httpStore) Get(ctx context.Context, id string, fields ...string) (*domainEmployee.Employee, error) {
+	path := "/v1/employees/" + url.PathEscape(id)
+	if len(fields) > 0 {
+		path += "?fields=" + url.QueryEscape(strings.Join(fields, ","))
+	}
+	return s.employee(ctx, http.MethodGet, path, nil)
+}
+
+func (s *httpStore) Create(ctx context.Context, in employeeUC.CreateInput) (*domainEmployee.Employee, error) {
+	return s.employee(ctx, http.MethodPost, "/v1/employees", in)
+}
+
+// Update sends nil fields as null, which the API leaves unchanged.
+func (s *httpStore) Update(ctx context.Context, id string, in employeeUC.UpdateInput) (*domainEmployee.Employee, error) {
+	return s.employee(ctx, http.MethodPatch, "/v1/employees/"+url.PathEscape(id), in)
+}
+
+func (s *httpStore) Delete(ctx context.Context, id string) error {
+	return s.do(ctx, http.MethodDelete, "/v1/employees/"+url.PathEscape(id), nil, nil)
+}
+
+func (s *httpStore) employee(ctx context.Context, method, path string, in any) (*domainEmployee.Employee, error) {
+	var body struct {
+		Data apiEmployee `json:"data"`
+	}
+	if err := s.do(ctx, method, path, in, &body); err != nil {
+		return nil, err
+	}
+	return body.Data.toDomain(), nil
+}
+
+func (s *httpStore) do(ctx context.Context, method, path string, in, out any) error {
+	var reqBody io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, s.base+path, reqBody)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	if s.apiKey != "" {
+		req.Header.Set("X-API-Key", s.apiKey)
+	}
+	if s.tenant != "" {
+		req.Header.Set("X-Tenant-ID", s.tenant)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return problem(resp)
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("%s %s: decode response: %w", method, path, err)
+	}
+	return nil
+}
+
+func problem(resp *http.Response) error {
+	var body struct {
+		Code   string `json:"code"`
+		Detail string `json:"detail"`
+		Errors []struct {
+			Field   string `json:"field"`
+			Rule    string `json:"rule"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil || body.Code == "" {
+		return errors.New("api: " + resp.Status)
+	}
+
+	derr := domain.Error{Kind: domain.ErrorKind(body.Code), Message: body.Detail}
+	if derr.Message == "" {
+		derr.Message = resp.Status
+	}
+	for _, f := range body.Errors {
+		derr.Fields = append(derr.Fields, domain.FieldError{Field: f.Field, Rule: f.Rule, Message: f.Message})
+	}
+	return derr
+}
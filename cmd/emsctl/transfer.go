@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	domainEmployee "github.com/rohitashk/golang-rest-api/internal/domain/employee"
+	employeeUC "github.com/rohitashk/golang-rest-api/internal/usecase/employee"
+)
+
+// exportPageSize is the largest page List serves.
+const exportPageSize = 200
+
+func (a *app) exportCommand(ctx context.Context, args []string) error {
+	pos, err := subcommand("export", args, nil)
+	if err != nil {
+		return err
+	}
+	if err := wantArgs("export", pos, 0, ""); err != nil {
+		return err
+	}
+	ctx, err = a.connect(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Writes during the export may shift pages; run it when the directory
+	// is quiet for an exact snapshot.
+	var all []domainEmployee.Employee
+	for offset := int64(0); ; offset += exportPageSize {
+		items, total, err := a.employees.List(ctx, employeeUC.ListInput{Limit: exportPageSize, Offset: offset})
+		if err != nil {
+			return err
+		}
+		all = append(all, items...)
+		if len(items) < exportPageSize || offset+exportPageSize >= total {
+			break
+		}
+	}
+	return a.out.employees(all)
+}
+
+type importResult struct {
+	Row   int    `json:"row"`
+	Email string `json:"email"`
+	ID    string `json:"id,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+func (a *app) importCommand(ctx context.Context, args []string) error {
+	var format string
+	pos, err := subcommand("import", args, func(fs *flag.FlagSet) {
+		fs.StringVar(&format, "format", "", "json or csv; taken from the file extension by default")
+	})
+	if err != nil {
+		return err
+	}
+	if err := wantArgs("import", pos, 1, "[-format json|csv] FILE"); err != nil {
+		return err
+	}
+
+	path := pos[0]
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	}
+	var in io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	var records []employeeRecord
+	switch format {
+	case "json":
+		err = json.NewDecoder(in).Decode(&records)
+	case "csv":
+		records, err = readCSV(in)
+	default:
+		return fmt.Errorf("import %s: unknown format %q, set -format json or csv", path, format)
+	}
+	if err != nil {
+		return fmt.Errorf("import %s: %w", path, err)
+	}
+
+	ctx, err = a.connect(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Rows are independent: a bad one is reported and the rest still go in.
+	// Manager IDs must name employees that already exist.
+	results := make([]importResult, 0, len(records))
+	failed := 0
+	for i, r := range records {
+		res := importResult{Row: i + 1, Email: r.Email}
+		e, err := a.employees.Create(ctx, employeeUC.CreateInput{
+			FirstName:    r.FirstName,
+			LastName:     r.LastName,
+			Email:        r.Email,
+			Phone:        r.Phone,
+			DateOfBirth:  r.DateOfBirth,
+			Department:   r.Department,
+			Position:     r.Position,
+			Salary:       r.Salary,
+			Status:       r.Status,
+			ManagerID:    r.ManagerID,
+			CustomFields: r.CustomFields,
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			res.Error = strings.ReplaceAll(describe(err), "\n  ", "; ")
+			failed++
+		} else {
+			res.ID = e.ID
+		}
+		results = append(results, res)
+	}
+
+	rows := make([][]string, 0, len(results))
+	for _, r := range results {
+		rows = append(rows, []string{strconv.Itoa(r.Row), r.Email, r.ID, r.Error})
+	}
+	if err := a.out.write(results, []string{"row", "email", "id", "error"}, rows); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d rows failed", failed, len(records))
+	}
+	return nil
+}
+
+// readCSV reads rows under a header naming employee fields, in any order.
+// id, created_at and updated_at are ignored so an export can be imported.
+// custom_fields.<key> columns set custom fields; empty cells are skipped.
+func readCSV(r io.Reader) ([]employeeRecord, error) {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("empty file")
+		}
+		return nil, err
+	}
+
+	var out []employeeRecord
+	for line := 2; ; line++ {
+		row, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return out, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var rec employeeRecord
+		for i, name := range header {
+			v := strings.TrimSpace(row[i])
+			switch strings.TrimSpace(name) {
+			case domainEmployee.FieldFirstName:
+				rec.FirstName = v
+			case domainEmployee.FieldLastName:
+				rec.LastName = v
+			case domainEmployee.FieldEmail:
+				rec.Email = v
+			case domainEmployee.FieldPhone:
+				rec.Phone = v
+			case domainEmployee.FieldDateOfBirth:
+				rec.DateOfBirth = v
+			case domainEmployee.FieldDepartment:
+				rec.Department = v
+			case domainEmployee.FieldPosition:
+				rec.Position = v
+			case domainEmployee.FieldSalary:
+				if v == "" {
+					continue
+				}
+				if rec.Salary, err = strconv.ParseFloat(v, 64); err != nil {
+					return nil, fmt.Errorf("line %d: salary %q is not a number", line, v)
+				}
+			case domainEmployee.FieldStatus:
+				rec.Status = v
+			case domainEmployee.FieldManagerID:
+				rec.ManagerID = v
+			case domainEmployee.FieldID, domainEmployee.FieldCreatedAt, domainEmployee.FieldUpdatedAt:
+			default:
+				key, ok := strings.CutPrefix(strings.TrimSpace(name), customFieldColumn)
+				if !ok || key == "" {
+					return nil, fmt.Errorf("unknown column %q", name)
+				}
+				if v == "" {
+					continue
+				}
+				if rec.CustomFields == nil {
+					rec.CustomFields = map[string]any{}
+				}
+				rec.CustomFields[key] = v
+			}
+		}
+		out = append(out, rec)
+	}
+}
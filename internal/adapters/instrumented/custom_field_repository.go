@@ -0,0 +1,43 @@
+package instrumented
+
+import (
+	"context"
+	"time"
+
+	"github.com/rohitashk/golang-rest-api/internal/domain/customfield"
+	"github.com/rohitashk/golang-rest-api/internal/observability"
+)
+
+type CustomFieldRepository struct {
+	next customfield.Repository
+	obs  observer
+}
+
+func NewCustomFieldRepository(next customfield.Repository, m *observability.Metrics) *CustomFieldRepository {
+	return &CustomFieldRepository{next: next, obs: observer{metrics: m, repository: "custom_fields"}}
+}
+
+func (r *CustomFieldRepository) Create(ctx context.Context, d *customfield.Definition) (err error) {
+	defer r.obs.done("Create", time.Now(), &err)
+	return r.next.Create(ctx, d)
+}
+
+func (r *CustomFieldRepository) GetByID(ctx context.Context, id string) (_ *customfield.Definition, err error) {
+	defer r.obs.done("GetByID", time.Now(), &err)
+	return r.next.GetByID(ctx, id)
+}
+
+func (r *CustomFieldRepository) List(ctx context.Context) (_ []customfield.Definition, err error) {
+	defer r.obs.done("List", time.Now(), &err)
+	return r.next.List(ctx)
+}
+
+func (r *CustomFieldRepository) Update(ctx context.Context, d *customfield.Definition) (err error) {
+	defer r.obs.done("Update", time.Now(), &err)
+	return r.next.Update(ctx, d)
+}
+
+func (r *CustomFieldRepository) Delete(ctx context.Context, id string) (err error) {
+	defer r.obs.done("Delete", time.Now(), &err)
+	return r.next.Delete(ctx, id)
+}
@@ -0,0 +1,63 @@
+package instrumented
+
+import (
+	"context"
+	"time"
+
+	domainEmployee "github.com/rohitashk/golang-rest-api/internal/domain/employee"
+	"github.com/rohitashk/golang-rest-api/internal/observability"
+)
+
+type EmployeeRepository struct {
+	next domainEmployee.Repository
+	obs  observer
+}
+
+func NewEmployeeRepository(next domainEmployee.Repository, m *observability.Metrics) *EmployeeRepository {
+	return &EmployeeRepository{next: next, obs: observer{metrics: m, repository: "employees"}}
+}
+
+func (r *EmployeeRepository) Create(ctx context.Context, e *domainEmployee.Employee) (err error) {
+	defer r.obs.done("Create", time.Now(), &err)
+	return r.next.Create(ctx, e)
+}
+
+func (r *EmployeeRepository) GetByID(ctx context.Context, id string, fields ...string) (_ *domainEmployee.Employee, err error) {
+	defer r.obs.done("GetByID", time.Now(), &err)
+	return r.next.GetByID(ctx, id, fields...)
+}
+
+func (r *EmployeeRepository) GetByEmail(ctx context.Context, email string) (_ *domainEmployee.Employee, err error) {
+	defer r.obs.done("GetByEmail", time.Now(), &err)
+	return r.next.GetByEmail(ctx, email)
+}
+
+func (r *EmployeeRepository) List(ctx context.Context, filter domainEmployee.ListFilter, page domainEmployee.ListPage, fields ...string) (_ []domainEmployee.Employee, _ int64, err error) {
+	defer r.obs.done("List", time.Now(), &err)
+	return r.next.List(ctx, filter, page, fields...)
+}
+
+func (r *EmployeeRepository) Headcount(ctx context.Context, departments ...string) (_ map[string]int64, err error) {
+	defer r.obs.done("Headcount", time.Now(), &err)
+	return r.next.Headcount(ctx, departments...)
+}
+
+func (r *EmployeeRepository) Update(ctx context.Context, e *domainEmployee.Employee, prevUpdatedAt time.Time) (err error) {
+	defer r.obs.done("Update", time.Now(), &err)
+	return r.next.Update(ctx, e, prevUpdatedAt)
+}
+
+func (r *EmployeeRepository) Delete(ctx context.Context, id string) (err error) {
+	defer r.obs.done("Delete", time.Now(), &err)
+	return r.next.Delete(ctx, id)
+}
+
+func (r *EmployeeRepository) DuplicateBlocks(ctx context.Context, maxBlock int) (_ [][]string, err error) {
+	defer r.obs.done("DuplicateBlocks", time.Now(), &err)
+	return r.next.DuplicateBlocks(ctx, maxBlock)
+}
+
+func (r *EmployeeRepository) RemoveCustomField(ctx context.Context, key string) (err error) {
+	defer r.obs.done("RemoveCustomField", time.Now(), &err)
+	return r.next.RemoveCustomField(ctx, key)
+}
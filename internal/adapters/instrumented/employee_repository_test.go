@@ -0,0 +1,78 @@
+package instrumented
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rohitashk/golang-rest-api/internal/adapters/memory"
+	"github.com/rohitashk/golang-rest-api/internal/domain"
+	domainEmployee "github.com/rohitashk/golang-rest-api/internal/domain/employee"
+	"github.com/rohitashk/golang-rest-api/internal/domain/tenant"
+	"github.com/rohitashk/golang-rest-api/internal/observability"
+)
+
+// brokenRepository fails every listing as a lost connection would, and
+// finds nothing to delete.
+type brokenRepository struct {
+	*memory.EmployeeRepository
+}
+
+func (brokenRepository) Delete(context.Context, string) error {
+	return domain.NotFound("employee not found")
+}
+
+func (brokenRepository) List(context.Context, domainEmployee.ListFilter, domainEmployee.ListPage, ...string) ([]domainEmployee.Employee, int64, error) {
+	return nil, 0, domain.Internal("failed to list employees", errors.New("connection reset"))
+}
+
+func scrape(t *testing.T, m *observability.Metrics) string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, _ := io.ReadAll(w.Body)
+	return string(body)
+}
+
+func TestEmployeeRepositoryRecordsOperations(t *testing.T) {
+	ctx := tenant.WithID(context.Background(), "acme")
+	m := observability.NewMetrics()
+	repo := NewEmployeeRepository(brokenRepository{memory.NewEmployeeRepository()}, m)
+
+	e := &domainEmployee.Employee{FirstName: "Ada", LastName: "Lovelace", Email: "ada@example.com", Department: "R&D", Position: "Engineer", Status: domainEmployee.StatusActive}
+	if err := repo.Create(ctx, e); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.GetByID(ctx, e.ID); err != nil {
+		t.Fatal(err)
+	}
+	// Not found is an answer, not a failure.
+	if err := repo.Delete(ctx, "6650c0ffee0000000000a001"); err == nil {
+		t.Fatal("deleted an unknown employee")
+	}
+	if _, _, err := repo.List(ctx, domainEmployee.ListFilter{}, domainEmployee.ListPage{}); err == nil {
+		t.Fatal("broken List succeeded")
+	}
+
+	out := scrape(t, m)
+	for _, want := range []string{
+		`ems_db_operation_duration_seconds_count{method="Create",repository="employees"} 1`,
+		`ems_db_operation_duration_seconds_count{method="GetByID",repository="employees"} 1`,
+		`ems_db_operation_duration_seconds_count{method="Delete",repository="employees"} 1`,
+		`ems_db_operation_duration_seconds_count{method="List",repository="employees"} 1`,
+		`ems_db_operation_errors_total{method="List",repository="employees"} 1`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("metrics miss %s", want)
+		}
+	}
+	for _, unwanted := range []string{`ems_db_operation_errors_total{method="Delete"`, `ems_db_operation_errors_total{method="Create"`} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("metrics count a failure: %s", unwanted)
+		}
+	}
+}
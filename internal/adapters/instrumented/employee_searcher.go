@@ -0,0 +1,23 @@
+package instrumented
+
+import (
+	"context"
+	"time"
+
+	domainEmployee "github.com/rohitashk/golang-rest-api/internal/domain/employee"
+	"github.com/rohitashk/golang-rest-api/internal/observability"
+)
+
+type EmployeeSearcher struct {
+	next domainEmployee.Searcher
+	obs  observer
+}
+
+func NewEmployeeSearcher(next domainEmployee.Searcher, m *observability.Metrics) *EmployeeSearcher {
+	return &EmployeeSearcher{next: next, obs: observer{metrics: m, repository: "employee_search"}}
+}
+
+func (s *EmployeeSearcher) Search(ctx context.Context, q domainEmployee.SearchQuery) (_ []domainEmployee.Employee, err error) {
+	defer s.obs.done("Search", time.Now(), &err)
+	return s.next.Search(ctx, q)
+}
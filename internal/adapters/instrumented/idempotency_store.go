@@ -0,0 +1,33 @@
+package instrumented
+
+import (
+	"context"
+	"time"
+
+	"github.com/rohitashk/golang-rest-api/internal/domain/idempotency"
+	"github.com/rohitashk/golang-rest-api/internal/observability"
+)
+
+type IdempotencyStore struct {
+	next idempotency.Store
+	obs  observer
+}
+
+func NewIdempotencyStore(next idempotency.Store, m *observability.Metrics) *IdempotencyStore {
+	return &IdempotencyStore{next: next, obs: observer{metrics: m, repository: "idempotency_keys"}}
+}
+
+func (s *IdempotencyStore) Reserve(ctx context.Context, rec *idempotency.Record) (_ *idempotency.Record, err error) {
+	defer s.obs.done("Reserve", time.Now(), &err)
+	return s.next.Reserve(ctx, rec)
+}
+
+func (s *IdempotencyStore) Complete(ctx context.Context, rec *idempotency.Record) (err error) {
+	defer s.obs.done("Complete", time.Now(), &err)
+	return s.next.Complete(ctx, rec)
+}
+
+func (s *IdempotencyStore) Release(ctx context.Context, key string) (err error) {
+	defer s.obs.done("Release", time.Now(), &err)
+	return s.next.Release(ctx, key)
+}
@@ -0,0 +1,19 @@
+// Package instrumented wraps repositories to record the latency and errors of
+// every call in the application metrics.
+package instrumented
+
+import (
+	"time"
+
+	"github.com/rohitashk/golang-rest-api/internal/observability"
+)
+
+type observer struct {
+	metrics    *observability.Metrics
+	repository string
+}
+
+// done is deferred with the named error result of the wrapped method.
+func (o observer) done(method string, start time.Time, err *error) {
+	o.metrics.ObserveDB(o.repository, method, time.Since(start), *err)
+}
@@ -0,0 +1,38 @@
+package instrumented
+
+import (
+	"context"
+	"time"
+
+	"github.com/rohitashk/golang-rest-api/internal/domain/event"
+	"github.com/rohitashk/golang-rest-api/internal/observability"
+)
+
+type Outbox struct {
+	next event.Outbox
+	obs  observer
+}
+
+func NewOutbox(next event.Outbox, m *observability.Metrics) *Outbox {
+	return &Outbox{next: next, obs: observer{metrics: m, repository: "outbox"}}
+}
+
+func (o *Outbox) Append(ctx context.Context, events ...event.Envelope) (err error) {
+	defer o.obs.done("Append", time.Now(), &err)
+	return o.next.Append(ctx, events...)
+}
+
+func (o *Outbox) Claim(ctx context.Context, limit int, lease time.Duration) (_ []event.Envelope, err error) {
+	defer o.obs.done("Claim", time.Now(), &err)
+	return o.next.Claim(ctx, limit, lease)
+}
+
+func (o *Outbox) MarkPublished(ctx context.Context, id string) (err error) {
+	defer o.obs.done("MarkPublished", time.Now(), &err)
+	return o.next.MarkPublished(ctx, id)
+}
+
+func (o *Outbox) MarkFailed(ctx context.Context, id string, reason string, retryAt time.Time) (err error) {
+	defer o.obs.done("MarkFailed", time.Now(), &err)
+	return o.next.MarkFailed(ctx, id, reason, retryAt)
+}
@@ -0,0 +1,82 @@
+package instrumented
+
+import (
+	"context"
+	"time"
+
+	domainWebhook "github.com/rohitashk/golang-rest-api/internal/domain/webhook"
+	"github.com/rohitashk/golang-rest-api/internal/observability"
+)
+
+type WebhookRepository struct {
+	next domainWebhook.SubscriptionRepository
+	obs  observer
+}
+
+func NewWebhookRepository(next domainWebhook.SubscriptionRepository, m *observability.Metrics) *WebhookRepository {
+	return &WebhookRepository{next: next, obs: observer{metrics: m, repository: "webhooks"}}
+}
+
+func (r *WebhookRepository) Create(ctx context.Context, s *domainWebhook.Subscription) (err error) {
+	defer r.obs.done("Create", time.Now(), &err)
+	return r.next.Create(ctx, s)
+}
+
+func (r *WebhookRepository) GetByID(ctx context.Context, id string) (_ *domainWebhook.Subscription, err error) {
+	defer r.obs.done("GetByID", time.Now(), &err)
+	return r.next.GetByID(ctx, id)
+}
+
+func (r *WebhookRepository) List(ctx context.Context) (_ []domainWebhook.Subscription, err error) {
+	defer r.obs.done("List", time.Now(), &err)
+	return r.next.List(ctx)
+}
+
+func (r *WebhookRepository) Update(ctx context.Context, s *domainWebhook.Subscription) (err error) {
+	defer r.obs.done("Update", time.Now(), &err)
+	return r.next.Update(ctx, s)
+}
+
+func (r *WebhookRepository) Delete(ctx context.Context, id string) (err error) {
+	defer r.obs.done("Delete", time.Now(), &err)
+	return r.next.Delete(ctx, id)
+}
+
+type WebhookDeliveryRepository struct {
+	next domainWebhook.DeliveryRepository
+	obs  observer
+}
+
+func NewWebhookDeliveryRepository(next domainWebhook.DeliveryRepository, m *observability.Metrics) *WebhookDeliveryRepository {
+	return &WebhookDeliveryRepository{next: next, obs: observer{metrics: m, repository: "webhook_deliveries"}}
+}
+
+func (r *WebhookDeliveryRepository) Create(ctx context.Context, d *domainWebhook.Delivery) (err error) {
+	defer r.obs.done("Create", time.Now(), &err)
+	return r.next.Create(ctx, d)
+}
+
+func (r *WebhookDeliveryRepository) GetByID(ctx context.Context, id string) (_ *domainWebhook.Delivery, err error) {
+	defer r.obs.done("GetByID", time.Now(), &err)
+	return r.next.GetByID(ctx, id)
+}
+
+func (r *WebhookDeliveryRepository) ListBySubscription(ctx context.Context, subscriptionID string, page domainWebhook.DeliveryPage) (_ []domainWebhook.Delivery, _ int64, err error) {
+	defer r.obs.done("ListBySubscription", time.Now(), &err)
+	return r.next.ListBySubscription(ctx, subscriptionID, page)
+}
+
+func (r *WebhookDeliveryRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) (_ []domainWebhook.Delivery, err error) {
+	defer r.obs.done("ClaimDue", time.Now(), &err)
+	return r.next.ClaimDue(ctx, limit, lease)
+}
+
+func (r *WebhookDeliveryRepository) Update(ctx context.Context, d *domainWebhook.Delivery) (err error) {
+	defer r.obs.done("Update", time.Now(), &err)
+	return r.next.Update(ctx, d)
+}
+
+func (r *WebhookDeliveryRepository) DeleteBySubscription(ctx context.Context, subscriptionID string) (err error) {
+	defer r.obs.done("DeleteBySubscription", time.Now(), &err)
+	return r.next.DeleteBySubscription(ctx, subscriptionID)
+}
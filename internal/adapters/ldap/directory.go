@@ -0,0 +1,186 @@
+package ldap
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	goldap "github.com/go-ldap/ldap/v3"
+
+	"github.com/rohitashk/golang-rest-api/internal/domain/directory"
+)
+
+// Directory is an in-process stand-in for an LDAP server: a Searcher over a
+// fixed set of entries, for tests and for trying mappings without a server.
+// It evaluates the filter, base DN, scope and attribute list of a search the
+// way a server would, comparing values without regard to case. Extensible
+// matches are not supported.
+type Directory struct {
+	entries []*goldap.Entry
+
+	mu       sync.Mutex
+	searches []Search
+}
+
+// Search is a search a Directory has answered.
+type Search struct {
+	BaseDN     string
+	Filter     string
+	Attributes []string
+	PageSize   uint32
+}
+
+func NewDirectory(entries ...*goldap.Entry) *Directory {
+	return &Directory{entries: entries}
+}
+
+// Searches returns the searches answered so far, oldest first.
+func (d *Directory) Searches() []Search {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return slices.Clone(d.searches)
+}
+
+func (d *Directory) SearchWithPaging(req *goldap.SearchRequest, pagingSize uint32) (*goldap.SearchResult, error) {
+	d.mu.Lock()
+	d.searches = append(d.searches, Search{BaseDN: req.BaseDN, Filter: req.Filter, Attributes: slices.Clone(req.Attributes), PageSize: pagingSize})
+	d.mu.Unlock()
+
+	filter, err := goldap.CompileFilter(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	base := directory.NormalizeDN(req.BaseDN)
+	found := base == ""
+	res := &goldap.SearchResult{}
+	for _, e := range d.entries {
+		dn := directory.NormalizeDN(e.DN)
+		if dn == base {
+			found = true
+		}
+		if !inScope(dn, base, req.Scope) {
+			continue
+		}
+		ok, err := matches(e, filter)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			res.Entries = append(res.Entries, selectAttributes(e, req.Attributes))
+		}
+	}
+	if !found {
+		return nil, goldap.NewError(goldap.LDAPResultNoSuchObject, fmt.Errorf("no such object: %s", req.BaseDN))
+	}
+	return res, nil
+}
+
+func inScope(dn, base string, scope int) bool {
+	if base == "" {
+		return scope != goldap.ScopeBaseObject || dn == ""
+	}
+	switch scope {
+	case goldap.ScopeBaseObject:
+		return dn == base
+	case goldap.ScopeSingleLevel:
+		parent, ok := strings.CutSuffix(dn, ","+base)
+		return ok && !strings.Contains(parent, ",")
+	default:
+		return dn == base || strings.HasSuffix(dn, ","+base)
+	}
+}
+
+func matches(e *goldap.Entry, f *ber.Packet) (bool, error) {
+	switch f.Tag {
+	case goldap.FilterAnd:
+		for _, c := range f.Children {
+			if ok, err := matches(e, c); !ok || err != nil {
+				return false, err
+			}
+		}
+		return true, nil
+	case goldap.FilterOr:
+		for _, c := range f.Children {
+			if ok, err := matches(e, c); ok || err != nil {
+				return ok, err
+			}
+		}
+		return false, nil
+	case goldap.FilterNot:
+		ok, err := matches(e, f.Children[0])
+		return !ok, err
+	case goldap.FilterPresent:
+		return len(values(e, str(f))) > 0, nil
+	case goldap.FilterEqualityMatch, goldap.FilterApproxMatch:
+		want := str(f.Children[1])
+		return slices.ContainsFunc(values(e, str(f.Children[0])), func(v string) bool { return strings.EqualFold(v, want) }), nil
+	case goldap.FilterGreaterOrEqual, goldap.FilterLessOrEqual:
+		bound := strings.ToLower(str(f.Children[1]))
+		return slices.ContainsFunc(values(e, str(f.Children[0])), func(v string) bool {
+			c := strings.Compare(strings.ToLower(v), bound)
+			return c == 0 || (c > 0) == (f.Tag == goldap.FilterGreaterOrEqual)
+		}), nil
+	case goldap.FilterSubstrings:
+		parts := f.Children[1].Children
+		return slices.ContainsFunc(values(e, str(f.Children[0])), func(v string) bool {
+			return matchSubstrings(strings.ToLower(v), parts)
+		}), nil
+	default:
+		return false, goldap.NewError(goldap.LDAPResultUnwillingToPerform, errors.New("filter is not supported by the stand-in directory"))
+	}
+}
+
+func matchSubstrings(v string, parts []*ber.Packet) bool {
+	for _, p := range parts {
+		s := strings.ToLower(str(p))
+		switch p.Tag {
+		case goldap.FilterSubstringsInitial:
+			var ok bool
+			if v, ok = strings.CutPrefix(v, s); !ok {
+				return false
+			}
+		case goldap.FilterSubstringsFinal:
+			return strings.HasSuffix(v, s)
+		default:
+			i := strings.Index(v, s)
+			if i < 0 {
+				return false
+			}
+			v = v[i+len(s):]
+		}
+	}
+	return true
+}
+
+// values returns the values of attr, whose name LDAP compares without
+// regard to case.
+func values(e *goldap.Entry, attr string) []string {
+	for _, a := range e.Attributes {
+		if strings.EqualFold(a.Name, attr) {
+			return a.Values
+		}
+	}
+	return nil
+}
+
+func selectAttributes(e *goldap.Entry, attrs []string) *goldap.Entry {
+	if len(attrs) == 0 || slices.Contains(attrs, "*") {
+		return e
+	}
+	out := &goldap.Entry{DN: e.DN}
+	for _, a := range e.Attributes {
+		if slices.ContainsFunc(attrs, func(want string) bool { return strings.EqualFold(want, a.Name) }) {
+			out.Attributes = append(out.Attributes, a)
+		}
+	}
+	return out
+}
+
+func str(p *ber.Packet) string {
+	s, _ := p.Value.(string)
+	return s
+}
@@ -0,0 +1,89 @@
+package ldap
+
+import (
+	"context"
+	"fmt"
+
+	goldap "github.com/go-ldap/ldap/v3"
+
+	"github.com/rohitashk/golang-rest-api/internal/domain/directory"
+)
+
+// Searcher is the part of *ldap.Conn the source uses, so an in-process
+// stand-in such as Directory can replace a live server.
+type Searcher interface {
+	SearchWithPaging(req *goldap.SearchRequest, pagingSize uint32) (*goldap.SearchResult, error)
+}
+
+type Config struct {
+	URL          string
+	BindDN       string
+	BindPassword string
+	BaseDN       string
+	Filter       string // defaults to (objectClass=inetOrgPerson)
+	Attributes   []string
+	PageSize     uint32
+}
+
+type Source struct {
+	cfg      Config
+	searcher Searcher
+}
+
+// NewSource searches a live server, connecting on every Entries call.
+func NewSource(cfg Config) *Source {
+	return &Source{cfg: cfg}
+}
+
+// NewSourceWithSearcher searches through s instead of dialing cfg.URL.
+func NewSourceWithSearcher(s Searcher, cfg Config) *Source {
+	return &Source{cfg: cfg, searcher: s}
+}
+
+func (s *Source) Entries(ctx context.Context) ([]directory.Entry, error) {
+	searcher := s.searcher
+	if searcher == nil {
+		conn, err := goldap.DialURL(s.cfg.URL)
+		if err != nil {
+			return nil, fmt.Errorf("ldap dial: %w", err)
+		}
+		defer conn.Close()
+		if s.cfg.BindDN != "" {
+			if err := conn.Bind(s.cfg.BindDN, s.cfg.BindPassword); err != nil {
+				return nil, fmt.Errorf("ldap bind: %w", err)
+			}
+		}
+		searcher = conn
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	filter := s.cfg.Filter
+	if filter == "" {
+		filter = "(objectClass=inetOrgPerson)"
+	}
+	pageSize := s.cfg.PageSize
+	if pageSize == 0 {
+		pageSize = 500
+	}
+
+	req := goldap.NewSearchRequest(
+		s.cfg.BaseDN, goldap.ScopeWholeSubtree, goldap.NeverDerefAliases,
+		0, 0, false, filter, s.cfg.Attributes, nil,
+	)
+	res, err := searcher.SearchWithPaging(req, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("ldap search: %w", err)
+	}
+
+	out := make([]directory.Entry, 0, len(res.Entries))
+	for _, le := range res.Entries {
+		e := directory.NewEntry(le.DN)
+		for _, a := range le.Attributes {
+			e.Add(a.Name, a.Values...)
+		}
+		out = append(out, e)
+	}
+	return out, nil
+}
@@ -0,0 +1,163 @@
+package ldap
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	goldap "github.com/go-ldap/ldap/v3"
+)
+
+func testDirectory() *Directory {
+	return NewDirectory(
+		goldap.NewEntry("dc=example,dc=com", map[string][]string{"objectClass": {"domain"}}),
+		goldap.NewEntry("ou=People,dc=example,dc=com", map[string][]string{"objectClass": {"organizationalUnit"}}),
+		goldap.NewEntry("uid=ada,ou=People,dc=example,dc=com", map[string][]string{
+			"objectClass": {"top", "inetOrgPerson"},
+			"givenName":   {"Ada"},
+			"sn":          {"Lovelace"},
+			"mail":        {"Ada@Example.com"},
+			"title":       {"Engineer"},
+		}),
+		goldap.NewEntry("uid=grace,ou=People,dc=example,dc=com", map[string][]string{
+			"objectClass":      {"inetOrgPerson"},
+			"givenName":        {"Grace"},
+			"sn":               {"Hopper"},
+			"mail":             {"grace@example.com"},
+			"departmentNumber": {"R&D"},
+			"manager":          {"uid=ada,ou=People,dc=example,dc=com"},
+		}),
+		goldap.NewEntry("uid=svc-backup,ou=Services,dc=example,dc=com", map[string][]string{
+			"objectClass": {"inetOrgPerson"},
+			"mail":        {"backup@example.com"},
+		}),
+	)
+}
+
+func TestSourceEntries(t *testing.T) {
+	dir := testDirectory()
+	src := NewSourceWithSearcher(dir, Config{BaseDN: "ou=People,dc=example,dc=com"})
+
+	entries, err := src.Entries(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("entries = %+v, want ada and grace", entries)
+	}
+	ada := entries[0]
+	if ada.DN != "uid=ada,ou=People,dc=example,dc=com" || ada.Get("GIVENNAME") != "Ada" || ada.Get("mail") != "Ada@Example.com" {
+		t.Errorf("ada = %+v", ada)
+	}
+	if got := ada.Attrs["objectclass"]; !slices.Equal(got, []string{"top", "inetOrgPerson"}) {
+		t.Errorf("objectclass = %q, want every value", got)
+	}
+
+	searches := dir.Searches()
+	if len(searches) != 1 || searches[0].Filter != "(objectClass=inetOrgPerson)" || searches[0].PageSize != 500 {
+		t.Errorf("searches = %+v, want the default filter and page size", searches)
+	}
+}
+
+func TestSourceSendsTheConfiguredSearch(t *testing.T) {
+	dir := testDirectory()
+	src := NewSourceWithSearcher(dir, Config{
+		BaseDN:     "dc=example,dc=com",
+		Filter:     "(&(objectClass=inetOrgPerson)(|(mail=*@example.com)(title=eng*))(!(uid=svc-*)))",
+		Attributes: []string{"mail"},
+		PageSize:   50,
+	})
+
+	entries, err := src.Entries(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mails []string
+	for _, e := range entries {
+		if len(e.Attrs) != 1 {
+			t.Errorf("%s has %v, want only the requested attribute", e.DN, e.Attrs)
+		}
+		mails = append(mails, e.Get("mail"))
+	}
+	// backup@example.com has no uid attribute, so (!(uid=svc-*)) keeps it.
+	if want := []string{"Ada@Example.com", "grace@example.com", "backup@example.com"}; !slices.Equal(mails, want) {
+		t.Errorf("mails = %q, want %q", mails, want)
+	}
+	if s := dir.Searches(); s[0].PageSize != 50 || !slices.Equal(s[0].Attributes, []string{"mail"}) {
+		t.Errorf("search = %+v", s[0])
+	}
+}
+
+func TestDirectoryFilters(t *testing.T) {
+	dir := testDirectory()
+	for filter, want := range map[string]int{
+		"(objectClass=*)":                     5,
+		"(objectclass=INETORGPERSON)":         3,
+		"(manager=*)":                         1,
+		"(sn=*o*e*)":                          2,
+		"(sn=Hop*er)":                         1,
+		"(sn=*lace)":                          1,
+		"(&(givenName>=B)(givenName<=H))":     1,
+		"(!(objectClass=inetOrgPerson))":      2,
+		"(|(departmentNumber=R&D)(sn~=love))": 1,
+	} {
+		res, err := dir.SearchWithPaging(goldap.NewSearchRequest(
+			"dc=example,dc=com", goldap.ScopeWholeSubtree, goldap.NeverDerefAliases, 0, 0, false, filter, nil, nil,
+		), 10)
+		if err != nil {
+			t.Errorf("%s: %v", filter, err)
+		} else if len(res.Entries) != want {
+			t.Errorf("%s: %d entries, want %d", filter, len(res.Entries), want)
+		}
+	}
+}
+
+func TestDirectoryScopes(t *testing.T) {
+	dir := testDirectory()
+	for _, tt := range []struct {
+		base  string
+		scope int
+		want  int
+	}{
+		{"OU=people, DC=example, DC=com", goldap.ScopeWholeSubtree, 3},
+		{"ou=People,dc=example,dc=com", goldap.ScopeSingleLevel, 2},
+		{"ou=People,dc=example,dc=com", goldap.ScopeBaseObject, 1},
+	} {
+		res, err := dir.SearchWithPaging(goldap.NewSearchRequest(
+			tt.base, tt.scope, goldap.NeverDerefAliases, 0, 0, false, "(objectClass=*)", nil, nil,
+		), 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Entries) != tt.want {
+			t.Errorf("%s scope %d: %d entries, want %d", tt.base, tt.scope, len(res.Entries), tt.want)
+		}
+	}
+}
+
+func TestSourceReportsSearchErrors(t *testing.T) {
+	for _, cfg := range []Config{
+		{BaseDN: "ou=Nobody,dc=example,dc=com"},
+		{BaseDN: "dc=example,dc=com", Filter: "(objectClass=inetOrgPerson"},
+		{BaseDN: "dc=example,dc=com", Filter: "(cn:caseExactMatch:=Ada)"},
+	} {
+		_, err := NewSourceWithSearcher(testDirectory(), cfg).Entries(context.Background())
+		var ldapErr *goldap.Error
+		if !errors.As(err, &ldapErr) {
+			t.Errorf("%+v: err = %v, want an LDAP error", cfg, err)
+		}
+	}
+}
+
+func TestSourceStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	dir := testDirectory()
+	if _, err := NewSourceWithSearcher(dir, Config{}).Entries(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v", err)
+	}
+	if len(dir.Searches()) != 0 {
+		t.Error("searched after the context was cancelled")
+	}
+}
@@ -0,0 +1,129 @@
+package ldif
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/rohitashk/golang-rest-api/internal/domain/directory"
+)
+
+// FileSource reads entries from an LDIF export.
+type FileSource struct {
+	Path string
+}
+
+func (s FileSource) Entries(ctx context.Context) ([]directory.Entry, error) {
+	f, err := os.Open(s.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Parse reads LDIF content records (RFC 2849). Change records and URL values
+// are rejected; attribute options such as ;lang-en are dropped.
+func Parse(r io.Reader) ([]directory.Entry, error) {
+	var (
+		out     []directory.Entry
+		cur     *directory.Entry
+		lines   []string // logical lines of the current record
+		lineNum int
+		start   int
+	)
+
+	flushRecord := func() error {
+		defer func() { lines = lines[:0] }()
+		for i, l := range lines {
+			attr, value, err := parseLine(l)
+			if err != nil {
+				return fmt.Errorf("line %d: %w", start+i, err)
+			}
+			if i == 0 {
+				if attr == "version" && len(lines) == 1 {
+					return nil
+				}
+				if attr != "dn" {
+					return fmt.Errorf("line %d: record must start with dn", start)
+				}
+				e := directory.NewEntry(value)
+				cur = &e
+				continue
+			}
+			if attr == "changetype" {
+				return fmt.Errorf("line %d: change records are not supported", start+i)
+			}
+			cur.Add(attr, value)
+		}
+		if cur != nil {
+			out = append(out, *cur)
+			cur = nil
+		}
+		return nil
+	}
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		lineNum++
+		l := strings.TrimRight(sc.Text(), "\r")
+
+		switch {
+		case l == "":
+			if err := flushRecord(); err != nil {
+				return nil, err
+			}
+		case strings.HasPrefix(l, "#"):
+		case strings.HasPrefix(l, " "):
+			// Folded line continues the previous one.
+			if len(lines) == 0 {
+				return nil, fmt.Errorf("line %d: continuation without a preceding line", lineNum)
+			}
+			lines[len(lines)-1] += l[1:]
+		default:
+			if len(lines) == 0 {
+				start = lineNum
+			}
+			// A leading "version: 1" shares the first record when no blank line
+			// follows it.
+			if len(lines) == 1 && strings.HasPrefix(strings.ToLower(lines[0]), "version:") {
+				lines = lines[:0]
+				start = lineNum
+			}
+			lines = append(lines, l)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if err := flushRecord(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func parseLine(l string) (string, string, error) {
+	attr, rest, ok := strings.Cut(l, ":")
+	if !ok {
+		return "", "", fmt.Errorf("missing ':' in %q", l)
+	}
+	attr, _, _ = strings.Cut(strings.ToLower(strings.TrimSpace(attr)), ";")
+
+	switch {
+	case strings.HasPrefix(rest, ":"):
+		b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(rest[1:]))
+		if err != nil {
+			return "", "", fmt.Errorf("invalid base64 value for %s", attr)
+		}
+		return attr, string(b), nil
+	case strings.HasPrefix(rest, "<"):
+		return "", "", fmt.Errorf("URL values are not supported (%s)", attr)
+	default:
+		return attr, strings.TrimLeft(rest, " "), nil
+	}
+}
@@ -0,0 +1,103 @@
+package ldif
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const export = `version: 1
+
+# Ada's record
+dn: uid=ada,ou=People,dc=example,dc=com
+objectClass: top
+objectClass: inetOrgPerson
+givenName: Ada
+sn: Lovelace
+mail: ada@example.com
+title;lang-en: Engineer
+description: Wrote the first
+ program for the Analytical
+  Engine
+
+dn:: dWlkPWrDuHJuLG91PVBlb3BsZSxkYz1leGFtcGxlLGRjPWNvbQ==
+GIVENNAME:: SsO4cm4=
+sn: Hansen
+mail: jorn@example.com
+`
+
+func TestParse(t *testing.T) {
+	entries, err := Parse(strings.NewReader(export))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("%d entries, want 2", len(entries))
+	}
+
+	ada := entries[0]
+	if ada.DN != "uid=ada,ou=People,dc=example,dc=com" {
+		t.Errorf("dn = %q", ada.DN)
+	}
+	if got := ada.Attrs["objectclass"]; !slices.Equal(got, []string{"top", "inetOrgPerson"}) {
+		t.Errorf("objectclass = %q", got)
+	}
+	if got := ada.Get("title"); got != "Engineer" {
+		t.Errorf("title = %q, want the option dropped", got)
+	}
+	if got := ada.Get("description"); got != "Wrote the firstprogram for the Analytical Engine" {
+		t.Errorf("folded description = %q", got)
+	}
+
+	jorn := entries[1]
+	if jorn.DN != "uid=jørn,ou=People,dc=example,dc=com" || jorn.Get("givenName") != "Jørn" {
+		t.Errorf("base64 values: dn = %q, givenName = %q", jorn.DN, jorn.Get("givenName"))
+	}
+}
+
+func TestParseVersionSharingTheFirstRecord(t *testing.T) {
+	entries, err := Parse(strings.NewReader("version: 1\r\ndn: uid=ada,dc=example,dc=com\r\nmail: ada@example.com\r\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Get("mail") != "ada@example.com" || entries[0].Get("version") != "" {
+		t.Errorf("entries = %+v", entries)
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	for _, tt := range []struct {
+		name, ldif, err string
+	}{
+		{"no dn", "mail: ada@example.com\n", "line 1: record must start with dn"},
+		{"change record", "dn: uid=ada,dc=example,dc=com\nchangetype: delete\n", "line 2: change records are not supported"},
+		{"URL value", "dn: uid=ada,dc=example,dc=com\njpegPhoto:< file:///etc/passwd\n", "line 2: URL values are not supported"},
+		{"bad base64", "dn: uid=ada,dc=example,dc=com\nsn:: not base64!\n", "line 2: invalid base64 value for sn"},
+		{"no colon", "dn: uid=ada,dc=example,dc=com\n\ngarbage\n", "line 3: missing ':'"},
+		{"leading continuation", " mail: ada@example.com\n", "line 1: continuation without a preceding line"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.ldif))
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("err = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestFileSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "people.ldif")
+	if err := os.WriteFile(path, []byte(export), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := FileSource{Path: path}.Entries(context.Background())
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("Entries = %d, %v", len(entries), err)
+	}
+	if _, err := (FileSource{Path: filepath.Join(t.TempDir(), "missing.ldif")}).Entries(context.Background()); !os.IsNotExist(err) {
+		t.Errorf("missing file: err = %v", err)
+	}
+}
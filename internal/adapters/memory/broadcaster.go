@@ -0,0 +1,121 @@
+package memory
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/rohitashk/golang-rest-api/internal/domain/event"
+)
+
+// subscriberBuffer is how far a subscriber may fall behind before it is
+// dropped; it then reconnects and catches up from the history.
+const subscriberBuffer = 64
+
+// Broadcaster is an in-process event.Feed fed as an event.Publisher. It only
+// sees events relayed by this process, so it suits single-instance setups.
+type Broadcaster struct {
+	mu      sync.Mutex
+	epoch   string // cursors from another process are expired
+	seq     uint64
+	history []entry
+	size    int
+	subs    map[chan event.Notification]struct{}
+}
+
+type entry struct {
+	seq uint64
+	n   event.Notification
+}
+
+// NewBroadcaster keeps the last history events for resuming subscribers.
+func NewBroadcaster(history int) *Broadcaster {
+	if history <= 0 {
+		history = 1000
+	}
+	var b [4]byte
+	_, _ = rand.Read(b[:])
+	return &Broadcaster{
+		epoch: hex.EncodeToString(b[:]),
+		size:  history,
+		subs:  map[chan event.Notification]struct{}{},
+	}
+}
+
+func (b *Broadcaster) Publish(ctx context.Context, e event.Envelope) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.seq++
+	n := event.Notification{Cursor: b.epoch + "-" + strconv.FormatUint(b.seq, 10), Event: e}
+	b.history = append(b.history, entry{seq: b.seq, n: n})
+	if len(b.history) > b.size {
+		b.history = b.history[len(b.history)-b.size:]
+	}
+
+	for ch := range b.subs {
+		select {
+		case ch <- n:
+		default:
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}
+	return nil
+}
+
+func (b *Broadcaster) Subscribe(ctx context.Context, cursor string) (<-chan event.Notification, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var backlog []event.Notification
+	if cursor != "" {
+		after, ok := b.parse(cursor)
+		if !ok {
+			return nil, event.ErrCursorExpired
+		}
+		for _, e := range b.history {
+			if e.seq > after {
+				backlog = append(backlog, e.n)
+			}
+		}
+	}
+
+	ch := make(chan event.Notification, len(backlog)+subscriberBuffer)
+	for _, n := range backlog {
+		ch <- n
+	}
+	b.subs[ch] = struct{}{}
+
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subs[ch]; ok {
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}()
+	return ch, nil
+}
+
+// parse returns the sequence number of cursor if the history still holds
+// every event after it.
+func (b *Broadcaster) parse(cursor string) (uint64, bool) {
+	epoch, s, ok := strings.Cut(cursor, "-")
+	if !ok || epoch != b.epoch {
+		return 0, false
+	}
+	seq, err := strconv.ParseUint(s, 10, 64)
+	if err != nil || seq > b.seq {
+		return 0, false
+	}
+	oldest := b.seq + 1
+	if len(b.history) > 0 {
+		oldest = b.history[0].seq
+	}
+	return seq, seq+1 >= oldest
+}
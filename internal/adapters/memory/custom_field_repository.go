@@ -0,0 +1,125 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/rohitashk/golang-rest-api/internal/domain"
+	"github.com/rohitashk/golang-rest-api/internal/domain/customfield"
+	"github.com/rohitashk/golang-rest-api/internal/domain/tenant"
+)
+
+// CustomFieldRepository keeps custom field definitions in this process, for
+// tests and local tools.
+type CustomFieldRepository struct {
+	mu      sync.Mutex
+	tenants map[string]map[string]customfield.Definition
+	lastID  int
+}
+
+func NewCustomFieldRepository() *CustomFieldRepository {
+	return &CustomFieldRepository{tenants: map[string]map[string]customfield.Definition{}}
+}
+
+// definitions returns the definitions of the tenant in ctx; r.mu must be
+// held.
+func (r *CustomFieldRepository) definitions(ctx context.Context) (map[string]customfield.Definition, error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	m := r.tenants[tenantID]
+	if m == nil {
+		m = map[string]customfield.Definition{}
+		r.tenants[tenantID] = m
+	}
+	return m, nil
+}
+
+func (r *CustomFieldRepository) Create(ctx context.Context, d *customfield.Definition) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m, err := r.definitions(ctx)
+	if err != nil {
+		return err
+	}
+	for _, other := range m {
+		if other.Key == d.Key {
+			return domain.Conflict("custom field with this key already exists")
+		}
+	}
+	r.lastID++
+	d.ID = fmt.Sprintf("%024x", r.lastID)
+	m[d.ID] = cloneDefinition(*d)
+	return nil
+}
+
+func (r *CustomFieldRepository) GetByID(ctx context.Context, id string) (*customfield.Definition, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m, err := r.definitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	d, ok := m[id]
+	if !ok {
+		return nil, nil
+	}
+	d = cloneDefinition(d)
+	return &d, nil
+}
+
+func (r *CustomFieldRepository) List(ctx context.Context) ([]customfield.Definition, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m, err := r.definitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var out []customfield.Definition
+	for _, d := range m {
+		out = append(out, cloneDefinition(d))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out, nil
+}
+
+// Update saves everything but the key and type, which never change.
+func (r *CustomFieldRepository) Update(ctx context.Context, d *customfield.Definition) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m, err := r.definitions(ctx)
+	if err != nil {
+		return err
+	}
+	cur, ok := m[d.ID]
+	if !ok {
+		return domain.NotFound("custom field not found")
+	}
+	next := cloneDefinition(*d)
+	next.Key, next.Type, next.CreatedAt = cur.Key, cur.Type, cur.CreatedAt
+	m[d.ID] = next
+	return nil
+}
+
+func (r *CustomFieldRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m, err := r.definitions(ctx)
+	if err != nil {
+		return err
+	}
+	if _, ok := m[id]; !ok {
+		return domain.NotFound("custom field not found")
+	}
+	delete(m, id)
+	return nil
+}
+
+func cloneDefinition(d customfield.Definition) customfield.Definition {
+	d.Enum = slices.Clone(d.Enum)
+	return d
+}
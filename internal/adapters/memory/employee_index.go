@@ -0,0 +1,346 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rohitashk/golang-rest-api/internal/domain"
+	domainEmployee "github.com/rohitashk/golang-rest-api/internal/domain/employee"
+	"github.com/rohitashk/golang-rest-api/internal/domain/event"
+	"github.com/rohitashk/golang-rest-api/internal/domain/tenant"
+	"github.com/rohitashk/golang-rest-api/internal/search"
+)
+
+const (
+	// indexLoadTimeout bounds reading a tenant's employees into the index.
+	indexLoadTimeout = time.Minute
+	// indexResubscribe is how long Follow waits before subscribing again.
+	indexResubscribe = time.Second
+)
+
+// EmployeeIndex is an in-process inverted index of employees, an
+// employee.Searcher for stores without text search. A tenant's employees
+// are loaded on its first search and then kept current by Follow, so
+// results lag writes by as long as events take to reach the feed.
+type EmployeeIndex struct {
+	repo   domainEmployee.Repository
+	logger *slog.Logger
+
+	mu        sync.RWMutex
+	following bool
+	tenants   map[string]*tenantIndex
+}
+
+type tenantIndex struct {
+	loaded    chan struct{} // closed once loading succeeded or failed
+	err       error
+	pending   []event.Envelope // changes seen while loading
+	employees map[string]domainEmployee.Employee
+	postings  map[string]map[string]struct{} // word to employee IDs
+	words     []string                       // sorted, for prefix lookups
+	grams     map[string]map[string]struct{} // search.Grams to words, for fuzzy lookups
+}
+
+func NewEmployeeIndex(repo domainEmployee.Repository, logger *slog.Logger) *EmployeeIndex {
+	return &EmployeeIndex{repo: repo, logger: logger, tenants: map[string]*tenantIndex{}}
+}
+
+func (x *EmployeeIndex) Search(ctx context.Context, q domainEmployee.SearchQuery) ([]domainEmployee.Employee, error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	t, err := x.tenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	var scores map[string]float64
+	for i, term := range q.Terms {
+		found := t.lookup(term, q.Prefix && i == len(q.Terms)-1, q.Fuzzy)
+		if scores == nil {
+			scores = found
+			continue
+		}
+		for id := range scores {
+			if score, ok := found[id]; ok {
+				scores[id] += score
+			} else {
+				delete(scores, id)
+			}
+		}
+		if len(scores) == 0 {
+			return nil, nil
+		}
+	}
+
+	out := make([]domainEmployee.Employee, 0, len(scores))
+	for id := range scores {
+		e := t.employees[id]
+		if q.Department != nil && strings.TrimSpace(*q.Department) != "" && e.Department != strings.TrimSpace(*q.Department) {
+			continue
+		}
+		if q.Status != nil && *q.Status != "" && e.Status != *q.Status {
+			continue
+		}
+		out = append(out, e)
+	}
+	// Best matches first, so those cut at the limit are the ones the ranking
+	// would drop anyway.
+	sort.Slice(out, func(i, j int) bool {
+		a, b := scores[out[i].ID], scores[out[j].ID]
+		if a != b {
+			return a > b
+		}
+		return out[i].ID < out[j].ID
+	})
+	if q.Limit > 0 && int64(len(out)) > q.Limit {
+		out = out[:q.Limit]
+	}
+	return out, nil
+}
+
+// tenant returns the index of tenantID, loading it on first use.
+func (x *EmployeeIndex) tenant(ctx context.Context, tenantID string) (*tenantIndex, error) {
+	x.mu.Lock()
+	if !x.following {
+		x.mu.Unlock()
+		return nil, domain.Internal("search index is not following changes yet", nil)
+	}
+	t, ok := x.tenants[tenantID]
+	if !ok {
+		t = &tenantIndex{loaded: make(chan struct{})}
+		x.tenants[tenantID] = t
+		go x.load(tenantID, t)
+	}
+	x.mu.Unlock()
+
+	select {
+	case <-t.loaded:
+	case <-ctx.Done():
+		return nil, domain.Internal("search index is still loading", ctx.Err())
+	}
+	if t.err != nil {
+		return nil, t.err
+	}
+	return t, nil
+}
+
+// load reads every employee of tenantID, then applies the changes that
+// arrived meanwhile. They may predate the read, but they come in order, so
+// each employee ends at its latest state.
+func (x *EmployeeIndex) load(tenantID string, t *tenantIndex) {
+	ctx, cancel := context.WithTimeout(tenant.WithID(context.Background(), tenantID), indexLoadTimeout)
+	defer cancel()
+	all, _, err := x.repo.List(ctx, domainEmployee.ListFilter{}, domainEmployee.ListPage{})
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	defer close(t.loaded)
+	if err != nil {
+		// The next search tries again.
+		t.err = err
+		if x.tenants[tenantID] == t {
+			delete(x.tenants, tenantID)
+		}
+		return
+	}
+
+	t.employees = make(map[string]domainEmployee.Employee, len(all))
+	t.postings = map[string]map[string]struct{}{}
+	t.grams = map[string]map[string]struct{}{}
+	for _, e := range all {
+		t.put(e)
+	}
+	for _, e := range t.pending {
+		x.applyTo(t, e)
+	}
+	t.pending = nil
+	x.logger.Info("search index loaded", "tenant", tenantID, "employees", len(all))
+}
+
+// Follow applies the changes in feed to the loaded tenants until ctx ends.
+// Searches fail until it has first subscribed. When the feed cannot resume
+// where it stopped, changes were missed, so tenants are loaded again.
+func (x *EmployeeIndex) Follow(ctx context.Context, feed event.Feed) {
+	var cursor string
+	for {
+		notifications, err := feed.Subscribe(ctx, cursor)
+		if errors.Is(err, event.ErrCursorExpired) {
+			x.reset()
+			cursor = ""
+			notifications, err = feed.Subscribe(ctx, "")
+		}
+		if err != nil {
+			x.logger.Error("search index subscribe failed", "err", err)
+		} else {
+			x.mu.Lock()
+			x.following = true
+			x.mu.Unlock()
+			for n := range notifications {
+				x.apply(n.Event)
+				cursor = n.Cursor
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(indexResubscribe):
+		}
+	}
+}
+
+func (x *EmployeeIndex) reset() {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.tenants = map[string]*tenantIndex{}
+}
+
+func (x *EmployeeIndex) apply(e event.Envelope) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	t, ok := x.tenants[e.Tenant]
+	if !ok {
+		// Not loaded; its first search reads the change from the store.
+		return
+	}
+	select {
+	case <-t.loaded:
+		x.applyTo(t, e)
+	default:
+		t.pending = append(t.pending, e)
+	}
+}
+
+func (x *EmployeeIndex) applyTo(t *tenantIndex, e event.Envelope) {
+	var body struct {
+		Employee event.EmployeeSnapshot `json:"employee"`
+	}
+	if err := json.Unmarshal(e.Payload, &body); err != nil {
+		x.logger.Error("search index: undecodable event", "event_id", e.ID, "type", e.Type, "err", err)
+		return
+	}
+	switch e.Type {
+	case event.TypeEmployeeCreated, event.TypeEmployeeUpdated, event.TypeEmployeeMerged:
+		t.put(fromSnapshot(body.Employee))
+	case event.TypeEmployeeDeleted:
+		t.remove(body.Employee.ID)
+	}
+}
+
+func (t *tenantIndex) put(e domainEmployee.Employee) {
+	t.remove(e.ID)
+	t.employees[e.ID] = e
+	for _, w := range indexWords(e) {
+		ids, ok := t.postings[w]
+		if !ok {
+			ids = map[string]struct{}{}
+			t.postings[w] = ids
+			i := sort.SearchStrings(t.words, w)
+			t.words = append(t.words, "")
+			copy(t.words[i+1:], t.words[i:])
+			t.words[i] = w
+			for _, g := range search.Grams(w) {
+				if t.grams[g] == nil {
+					t.grams[g] = map[string]struct{}{}
+				}
+				t.grams[g][w] = struct{}{}
+			}
+		}
+		ids[e.ID] = struct{}{}
+	}
+}
+
+func (t *tenantIndex) remove(id string) {
+	e, ok := t.employees[id]
+	if !ok {
+		return
+	}
+	delete(t.employees, id)
+	for _, w := range indexWords(e) {
+		delete(t.postings[w], id)
+		if len(t.postings[w]) == 0 {
+			delete(t.postings, w)
+			i := sort.SearchStrings(t.words, w)
+			t.words = append(t.words[:i], t.words[i+1:]...)
+			for _, g := range search.Grams(w) {
+				delete(t.grams[g], w)
+				if len(t.grams[g]) == 0 {
+					delete(t.grams, g)
+				}
+			}
+		}
+	}
+}
+
+// lookup scores the employees with a word term matches by their best
+// match. Words it may match by prefix are a range of t.words; words it may
+// match with typos share one of its grams.
+func (t *tenantIndex) lookup(term string, prefix, fuzzy bool) map[string]float64 {
+	out := map[string]float64{}
+	seen := map[string]bool{}
+	try := func(w string) {
+		if seen[w] {
+			return
+		}
+		seen[w] = true
+		kind, edits := search.Match(term, w, prefix, fuzzy)
+		if kind == search.NoMatch {
+			return
+		}
+		score := search.Factor(kind, edits)
+		for id := range t.postings[w] {
+			out[id] = max(out[id], score)
+		}
+	}
+
+	try(term)
+	if prefix {
+		for i := sort.SearchStrings(t.words, term); i < len(t.words) && strings.HasPrefix(t.words[i], term); i++ {
+			try(t.words[i])
+		}
+	}
+	if fuzzy && search.MaxEdits(term) > 0 {
+		for _, g := range search.Grams(term) {
+			for w := range t.grams[g] {
+				try(w)
+			}
+		}
+	}
+	return out
+}
+
+// indexWords are the words an employee is found by, the same ones the
+// MongoDB store keeps in search_words.
+func indexWords(e domainEmployee.Employee) []string {
+	return search.Words(e.FirstName, e.LastName, e.Email, e.Department, e.Position)
+}
+
+func fromSnapshot(s event.EmployeeSnapshot) domainEmployee.Employee {
+	return domainEmployee.Employee{
+		ID:           s.ID,
+		FirstName:    s.FirstName,
+		LastName:     s.LastName,
+		Email:        s.Email,
+		Phone:        s.Phone,
+		DateOfBirth:  s.DateOfBirth,
+		Department:   s.Department,
+		Position:     s.Position,
+		Salary:       s.Salary,
+		Status:       domainEmployee.Status(s.Status),
+		ManagerID:    s.ManagerID,
+		CustomFields: s.CustomFields,
+		CreatedAt:    s.CreatedAt,
+		UpdatedAt:    s.UpdatedAt,
+	}
+}
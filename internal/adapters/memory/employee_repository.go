@@ -0,0 +1,286 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rohitashk/golang-rest-api/internal/domain"
+	domainEmployee "github.com/rohitashk/golang-rest-api/internal/domain/employee"
+	"github.com/rohitashk/golang-rest-api/internal/domain/tenant"
+)
+
+// EmployeeRepository keeps employees in this process, for tests and local
+// tools. It answers like the MongoDB repository, except that it always
+// loads every field.
+type EmployeeRepository struct {
+	mu      sync.Mutex
+	tenants map[string]map[string]domainEmployee.Employee
+	lastID  int
+}
+
+func NewEmployeeRepository() *EmployeeRepository {
+	return &EmployeeRepository{tenants: map[string]map[string]domainEmployee.Employee{}}
+}
+
+// employees returns the employees of the tenant in ctx; r.mu must be held.
+func (r *EmployeeRepository) employees(ctx context.Context) (map[string]domainEmployee.Employee, error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	m := r.tenants[tenantID]
+	if m == nil {
+		m = map[string]domainEmployee.Employee{}
+		r.tenants[tenantID] = m
+	}
+	return m, nil
+}
+
+func (r *EmployeeRepository) Create(ctx context.Context, e *domainEmployee.Employee) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m, err := r.employees(ctx)
+	if err != nil {
+		return err
+	}
+	e.Email = strings.ToLower(strings.TrimSpace(e.Email))
+	if emailTaken(m, e) {
+		return domain.Conflict("employee with this email already exists")
+	}
+	r.lastID++
+	e.ID = fmt.Sprintf("%024x", r.lastID)
+	m[e.ID] = clone(*e)
+	return nil
+}
+
+func (r *EmployeeRepository) GetByID(ctx context.Context, id string, _ ...string) (*domainEmployee.Employee, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m, err := r.employees(ctx)
+	if err != nil {
+		return nil, err
+	}
+	e, ok := m[id]
+	if !ok {
+		return nil, nil
+	}
+	e = clone(e)
+	return &e, nil
+}
+
+func (r *EmployeeRepository) GetByEmail(ctx context.Context, email string) (*domainEmployee.Employee, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m, err := r.employees(ctx)
+	if err != nil {
+		return nil, err
+	}
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return nil, domain.Validation("email is required")
+	}
+	for _, e := range m {
+		if e.Email == email {
+			e = clone(e)
+			return &e, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *EmployeeRepository) List(ctx context.Context, filter domainEmployee.ListFilter, page domainEmployee.ListPage, _ ...string) ([]domainEmployee.Employee, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m, err := r.employees(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	out := make([]domainEmployee.Employee, 0)
+	for _, e := range m {
+		if matches(&e, filter) {
+			out = append(out, clone(e))
+		}
+	}
+	// Newest first, like the MongoDB repository.
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].CreatedAt.After(out[j].CreatedAt)
+		}
+		return out[i].ID > out[j].ID
+	})
+
+	total := int64(len(out))
+	out = out[min(page.Offset, total):]
+	if page.Limit > 0 && int64(len(out)) > page.Limit {
+		out = out[:page.Limit]
+	}
+	return out, total, nil
+}
+
+func matches(e *domainEmployee.Employee, f domainEmployee.ListFilter) bool {
+	if f.IDs != nil {
+		found := false
+		for _, id := range f.IDs {
+			found = found || id == e.ID
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.Department != nil && strings.TrimSpace(*f.Department) != "" && e.Department != strings.TrimSpace(*f.Department) {
+		return false
+	}
+	if f.Status != nil && *f.Status != "" && e.Status != *f.Status {
+		return false
+	}
+	if f.ManagerID != nil && e.ManagerID != *f.ManagerID {
+		return false
+	}
+	if f.ManagerIDs != nil && !slices.Contains(f.ManagerIDs, e.ManagerID) {
+		return false
+	}
+	if f.Departments != nil && !slices.Contains(f.Departments, e.Department) {
+		return false
+	}
+	if f.Email != nil && e.Email != strings.ToLower(strings.TrimSpace(*f.Email)) {
+		return false
+	}
+	if f.Query != nil && strings.TrimSpace(*f.Query) != "" {
+		q := strings.ToLower(strings.TrimSpace(*f.Query))
+		if !strings.Contains(strings.ToLower(e.FirstName), q) &&
+			!strings.Contains(strings.ToLower(e.LastName), q) &&
+			!strings.Contains(e.Email, q) {
+			return false
+		}
+	}
+	for key, v := range f.CustomFields {
+		got, ok := e.CustomFields[key]
+		if !ok || got != v {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *EmployeeRepository) Headcount(ctx context.Context, departments ...string) (map[string]int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m, err := r.employees(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]int64, len(departments))
+	for _, d := range departments {
+		out[d] = 0
+	}
+	for _, e := range m {
+		if e.Status != domainEmployee.StatusActive {
+			continue
+		}
+		if _, ok := out[e.Department]; ok || len(departments) == 0 {
+			out[e.Department]++
+		}
+	}
+	return out, nil
+}
+
+func (r *EmployeeRepository) Update(ctx context.Context, e *domainEmployee.Employee, prevUpdatedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m, err := r.employees(ctx)
+	if err != nil {
+		return err
+	}
+	cur, ok := m[e.ID]
+	if !ok {
+		return domain.NotFound("employee not found")
+	}
+	if !prevUpdatedAt.IsZero() && !cur.UpdatedAt.Equal(prevUpdatedAt) {
+		return domainEmployee.ErrModified
+	}
+	e.Email = strings.ToLower(strings.TrimSpace(e.Email))
+	if emailTaken(m, e) {
+		return domain.Conflict("employee with this email already exists")
+	}
+	next := clone(*e)
+	next.CreatedAt = cur.CreatedAt
+	m[e.ID] = next
+	return nil
+}
+
+func (r *EmployeeRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m, err := r.employees(ctx)
+	if err != nil {
+		return err
+	}
+	if _, ok := m[id]; !ok {
+		return domain.NotFound("employee not found")
+	}
+	delete(m, id)
+	return nil
+}
+
+func (r *EmployeeRepository) DuplicateBlocks(ctx context.Context, maxBlock int) ([][]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m, err := r.employees(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byKey := map[string][]string{}
+	for id, e := range m {
+		for _, key := range domainEmployee.DuplicateKeys(&e) {
+			byKey[key] = append(byKey[key], id)
+		}
+	}
+	var out [][]string
+	for _, ids := range byKey {
+		if len(ids) >= 2 && len(ids) <= maxBlock {
+			sort.Strings(ids)
+			out = append(out, ids)
+		}
+	}
+	return out, nil
+}
+
+func (r *EmployeeRepository) RemoveCustomField(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m, err := r.employees(ctx)
+	if err != nil {
+		return err
+	}
+	for id, e := range m {
+		if _, ok := e.CustomFields[key]; ok {
+			e.CustomFields = maps.Clone(e.CustomFields)
+			delete(e.CustomFields, key)
+			m[id] = e
+		}
+	}
+	return nil
+}
+
+func emailTaken(m map[string]domainEmployee.Employee, e *domainEmployee.Employee) bool {
+	for id, other := range m {
+		if id != e.ID && other.Email == e.Email {
+			return true
+		}
+	}
+	return false
+}
+
+// clone copies e so that callers never share its custom fields with the
+// stored employee.
+func clone(e domainEmployee.Employee) domainEmployee.Employee {
+	e.CustomFields = maps.Clone(e.CustomFields)
+	return e
+}
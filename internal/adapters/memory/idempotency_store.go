@@ -0,0 +1,60 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/rohitashk/golang-rest-api/internal/domain/idempotency"
+)
+
+// IdempotencyStore keeps idempotency keys in this process, for tests and
+// local tools. Expired keys are taken over like in the MongoDB store.
+type IdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]idempotency.Record
+	now     func() time.Time
+}
+
+func NewIdempotencyStore() *IdempotencyStore {
+	return &IdempotencyStore{records: map[string]idempotency.Record{}, now: time.Now}
+}
+
+func (s *IdempotencyStore) Reserve(_ context.Context, rec *idempotency.Record) (*idempotency.Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if existing, ok := s.records[rec.Key]; ok && existing.ExpiresAt.After(s.now()) {
+		existing.Body = slices.Clone(existing.Body)
+		return &existing, nil
+	}
+	s.records[rec.Key] = idempotency.Record{
+		Key:         rec.Key,
+		Fingerprint: rec.Fingerprint,
+		CreatedAt:   rec.CreatedAt,
+		ExpiresAt:   rec.ExpiresAt,
+	}
+	return nil, nil
+}
+
+func (s *IdempotencyStore) Complete(_ context.Context, rec *idempotency.Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cur, ok := s.records[rec.Key]; ok {
+		cur.Completed = true
+		cur.StatusCode = rec.StatusCode
+		cur.ContentType = rec.ContentType
+		cur.Body = slices.Clone(rec.Body)
+		s.records[rec.Key] = cur
+	}
+	return nil
+}
+
+func (s *IdempotencyStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cur, ok := s.records[key]; ok && !cur.Completed {
+		delete(s.records, key)
+	}
+	return nil
+}
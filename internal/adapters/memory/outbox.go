@@ -0,0 +1,121 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rohitashk/golang-rest-api/internal/domain"
+	"github.com/rohitashk/golang-rest-api/internal/domain/event"
+	"github.com/rohitashk/golang-rest-api/internal/domain/tenant"
+)
+
+// Outbox keeps events in this process, for tests and local tools. It claims
+// and retries events like the MongoDB outbox.
+type Outbox struct {
+	mu     sync.Mutex
+	events []outboxEntry // in the order appended
+}
+
+type outboxEntry struct {
+	event.Envelope
+	availableAt time.Time
+	published   bool
+	lastError   string
+}
+
+func NewOutbox() *Outbox {
+	return &Outbox{}
+}
+
+func (o *Outbox) Append(_ context.Context, events ...event.Envelope) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for _, e := range events {
+		if o.find(e.ID) != nil {
+			return domain.Conflict("event " + e.ID + " already exists")
+		}
+	}
+	for _, e := range events {
+		e.Payload = slices.Clone(e.Payload)
+		o.events = append(o.events, outboxEntry{Envelope: e, availableAt: e.OccurredAt})
+	}
+	return nil
+}
+
+func (o *Outbox) Claim(_ context.Context, limit int, lease time.Duration) ([]event.Envelope, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	now := time.Now().UTC()
+	var due []*outboxEntry
+	for i := range o.events {
+		if e := &o.events[i]; !e.published && !e.availableAt.After(now) {
+			due = append(due, e)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool { return due[i].OccurredAt.Before(due[j].OccurredAt) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	out := make([]event.Envelope, len(due))
+	for i, e := range due {
+		e.availableAt = now.Add(lease)
+		e.Attempts++
+		out[i] = e.Envelope
+		out[i].Payload = slices.Clone(e.Payload)
+	}
+	return out, nil
+}
+
+func (o *Outbox) MarkPublished(_ context.Context, id string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if e := o.find(id); e != nil {
+		e.published, e.lastError = true, ""
+	}
+	return nil
+}
+
+func (o *Outbox) MarkFailed(_ context.Context, id string, reason string, retryAt time.Time) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if e := o.find(id); e != nil {
+		e.availableAt, e.lastError = retryAt.UTC(), reason
+	}
+	return nil
+}
+
+// History returns the events of one aggregate of the tenant in ctx, oldest
+// first, whether or not they have been published. Up to limit are returned;
+// 0 means all.
+func (o *Outbox) History(ctx context.Context, aggregateID string, limit int64) ([]event.Envelope, error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	out := make([]event.Envelope, 0)
+	for _, e := range o.events {
+		if e.Tenant == tenantID && e.AggregateID == aggregateID {
+			out = append(out, e.Envelope)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].OccurredAt.Before(out[j].OccurredAt) })
+	if limit > 0 && int64(len(out)) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+// find returns the event with id; o.mu must be held.
+func (o *Outbox) find(id string) *outboxEntry {
+	for i := range o.events {
+		if o.events[i].ID == id {
+			return &o.events[i]
+		}
+	}
+	return nil
+}
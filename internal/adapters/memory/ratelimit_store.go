@@ -0,0 +1,49 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rohitashk/golang-rest-api/internal/domain/ratelimit"
+)
+
+// RateLimitStore keeps token buckets in this process, so every instance
+// enforces its own limits.
+type RateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]rateBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type rateBucket struct {
+	ratelimit.Bucket
+	limit ratelimit.Limit
+}
+
+// Buckets that have refilled are dropped at most once per sweepInterval.
+const sweepInterval = time.Minute
+
+func NewRateLimitStore() *RateLimitStore {
+	return &RateLimitStore{buckets: map[string]rateBucket{}, now: time.Now}
+}
+
+func (s *RateLimitStore) Take(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Decision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		for k, b := range s.buckets {
+			if b.limit.Full(b.Bucket, now) {
+				delete(s.buckets, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	b, d := limit.Take(s.buckets[key].Bucket, now)
+	s.buckets[key] = rateBucket{Bucket: b, limit: limit}
+	return d, nil
+}
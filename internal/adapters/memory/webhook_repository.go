@@ -0,0 +1,246 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rohitashk/golang-rest-api/internal/domain"
+	"github.com/rohitashk/golang-rest-api/internal/domain/tenant"
+	domainWebhook "github.com/rohitashk/golang-rest-api/internal/domain/webhook"
+)
+
+// WebhookRepository keeps webhook subscriptions in this process, for tests
+// and local tools.
+type WebhookRepository struct {
+	mu      sync.Mutex
+	tenants map[string]map[string]domainWebhook.Subscription
+	lastID  int
+}
+
+func NewWebhookRepository() *WebhookRepository {
+	return &WebhookRepository{tenants: map[string]map[string]domainWebhook.Subscription{}}
+}
+
+// subscriptions returns the subscriptions of the tenant in ctx; r.mu must be
+// held.
+func (r *WebhookRepository) subscriptions(ctx context.Context) (map[string]domainWebhook.Subscription, error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	m := r.tenants[tenantID]
+	if m == nil {
+		m = map[string]domainWebhook.Subscription{}
+		r.tenants[tenantID] = m
+	}
+	return m, nil
+}
+
+func (r *WebhookRepository) Create(ctx context.Context, s *domainWebhook.Subscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m, err := r.subscriptions(ctx)
+	if err != nil {
+		return err
+	}
+	r.lastID++
+	s.ID = fmt.Sprintf("%024x", r.lastID)
+	m[s.ID] = cloneSubscription(*s)
+	return nil
+}
+
+func (r *WebhookRepository) GetByID(ctx context.Context, id string) (*domainWebhook.Subscription, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m, err := r.subscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := m[id]
+	if !ok {
+		return nil, nil
+	}
+	s = cloneSubscription(s)
+	return &s, nil
+}
+
+func (r *WebhookRepository) List(ctx context.Context) ([]domainWebhook.Subscription, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m, err := r.subscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var out []domainWebhook.Subscription
+	for _, s := range m {
+		out = append(out, cloneSubscription(s))
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].CreatedAt.Before(out[j].CreatedAt)
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out, nil
+}
+
+func (r *WebhookRepository) Update(ctx context.Context, s *domainWebhook.Subscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m, err := r.subscriptions(ctx)
+	if err != nil {
+		return err
+	}
+	cur, ok := m[s.ID]
+	if !ok {
+		return domain.NotFound("webhook not found")
+	}
+	next := cloneSubscription(*s)
+	next.Secret, next.CreatedAt = cur.Secret, cur.CreatedAt
+	m[s.ID] = next
+	return nil
+}
+
+func (r *WebhookRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m, err := r.subscriptions(ctx)
+	if err != nil {
+		return err
+	}
+	if _, ok := m[id]; !ok {
+		return domain.NotFound("webhook not found")
+	}
+	delete(m, id)
+	return nil
+}
+
+func cloneSubscription(s domainWebhook.Subscription) domainWebhook.Subscription {
+	s.EventTypes = slices.Clone(s.EventTypes)
+	return s
+}
+
+// WebhookDeliveryRepository keeps webhook deliveries in this process, for
+// tests and local tools. Like the MongoDB repository it is not scoped to a
+// tenant: deliveries are found through their subscription.
+type WebhookDeliveryRepository struct {
+	mu         sync.Mutex
+	deliveries map[string]domainWebhook.Delivery
+	lastID     int
+}
+
+func NewWebhookDeliveryRepository() *WebhookDeliveryRepository {
+	return &WebhookDeliveryRepository{deliveries: map[string]domainWebhook.Delivery{}}
+}
+
+func (r *WebhookDeliveryRepository) Create(_ context.Context, d *domainWebhook.Delivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, other := range r.deliveries {
+		if other.SubscriptionID == d.SubscriptionID && other.EventID == d.EventID {
+			return nil
+		}
+	}
+	r.lastID++
+	d.ID = fmt.Sprintf("%024x", r.lastID)
+	r.deliveries[d.ID] = cloneDelivery(*d)
+	return nil
+}
+
+func (r *WebhookDeliveryRepository) GetByID(_ context.Context, id string) (*domainWebhook.Delivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	d, ok := r.deliveries[id]
+	if !ok {
+		return nil, nil
+	}
+	d = cloneDelivery(d)
+	return &d, nil
+}
+
+func (r *WebhookDeliveryRepository) ListBySubscription(_ context.Context, subscriptionID string, page domainWebhook.DeliveryPage) ([]domainWebhook.Delivery, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]domainWebhook.Delivery, 0)
+	for _, d := range r.deliveries {
+		if d.SubscriptionID == subscriptionID {
+			out = append(out, cloneDelivery(d))
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].CreatedAt.After(out[j].CreatedAt)
+		}
+		return out[i].ID > out[j].ID
+	})
+	total := int64(len(out))
+	out = out[min(page.Offset, total):]
+	if page.Limit > 0 && int64(len(out)) > page.Limit {
+		out = out[:page.Limit]
+	}
+	return out, total, nil
+}
+
+func (r *WebhookDeliveryRepository) ClaimDue(_ context.Context, limit int, lease time.Duration) ([]domainWebhook.Delivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now().UTC()
+	var due []domainWebhook.Delivery
+	for _, d := range r.deliveries {
+		if d.Status == domainWebhook.DeliveryPending && !d.NextAttemptAt.After(now) {
+			due = append(due, d)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].NextAttemptAt.Equal(due[j].NextAttemptAt) {
+			return due[i].NextAttemptAt.Before(due[j].NextAttemptAt)
+		}
+		return due[i].ID < due[j].ID
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	for i := range due {
+		due[i].NextAttemptAt = now.Add(lease)
+		r.deliveries[due[i].ID] = due[i]
+		due[i] = cloneDelivery(due[i])
+	}
+	return due, nil
+}
+
+func (r *WebhookDeliveryRepository) Update(_ context.Context, d *domainWebhook.Delivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cur, ok := r.deliveries[d.ID]
+	if !ok {
+		return domain.NotFound("delivery not found")
+	}
+	cur.Status = d.Status
+	cur.Tries = d.Tries
+	cur.Attempts = slices.Clone(d.Attempts)
+	cur.NextAttemptAt = d.NextAttemptAt
+	cur.UpdatedAt = d.UpdatedAt
+	r.deliveries[d.ID] = cur
+	return nil
+}
+
+func (r *WebhookDeliveryRepository) DeleteBySubscription(_ context.Context, subscriptionID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, d := range r.deliveries {
+		if d.SubscriptionID == subscriptionID {
+			delete(r.deliveries, id)
+		}
+	}
+	return nil
+}
+
+func cloneDelivery(d domainWebhook.Delivery) domainWebhook.Delivery {
+	d.Payload = slices.Clone(d.Payload)
+	d.Attempts = slices.Clone(d.Attempts)
+	return d
+}
@@ -5,19 +5,23 @@ import (
 	"fmt"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.opentelemetry.io/contrib/instrumentation/go.mongodb.org/mongo-driver/mongo/otelmongo"
 )
 
 type Client struct {
 	client *mongo.Client
 }
 
+// Connect traces every command as a span of the caller's trace.
 func Connect(ctx context.Context, uri string, timeout time.Duration) (*Client, error) {
 	cctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	cl, err := mongo.Connect(cctx, options.Client().ApplyURI(uri))
+	cl, err := mongo.Connect(cctx, options.Client().ApplyURI(uri).SetMonitor(otelmongo.NewMonitor()))
 	if err != nil {
 		return nil, fmt.Errorf("mongo connect: %w", err)
 	}
@@ -33,6 +37,24 @@ func (c *Client) Database(name string) *mongo.Database {
 	return c.client.Database(name)
 }
 
+// Ping checks the primary is reachable; it serves as the readiness check.
+func (c *Client) Ping(ctx context.Context) error {
+	return c.client.Ping(ctx, readpref.Primary())
+}
+
 func (c *Client) Disconnect(ctx context.Context) error {
 	return c.client.Disconnect(ctx)
 }
+
+// SupportsChangeStreams reports whether the server is a replica set member
+// or mongos; standalone servers cannot open change streams.
+func (c *Client) SupportsChangeStreams(ctx context.Context) (bool, error) {
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	if err := c.client.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+		return false, fmt.Errorf("mongo hello: %w", err)
+	}
+	return hello.SetName != "" || hello.Msg == "isdbgrid", nil
+}
@@ -0,0 +1,177 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rohitashk/golang-rest-api/internal/domain"
+	"github.com/rohitashk/golang-rest-api/internal/domain/customfield"
+	"github.com/rohitashk/golang-rest-api/internal/domain/tenant"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type CustomFieldRepository struct {
+	coll *mongo.Collection
+}
+
+func NewCustomFieldRepository(db *mongo.Database) *CustomFieldRepository {
+	return &CustomFieldRepository{coll: db.Collection("custom_fields")}
+}
+
+type customFieldDoc struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty"`
+	Tenant     string             `bson:"tenant"`
+	Key        string             `bson:"key"`
+	Label      string             `bson:"label,omitempty"`
+	Type       string             `bson:"type"`
+	Required   bool               `bson:"required"`
+	Enum       []string           `bson:"enum,omitempty"`
+	Pattern    string             `bson:"pattern,omitempty"`
+	Department string             `bson:"department,omitempty"`
+	CreatedAt  time.Time          `bson:"created_at"`
+	UpdatedAt  time.Time          `bson:"updated_at"`
+}
+
+func (r *CustomFieldRepository) Create(ctx context.Context, d *customfield.Definition) error {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return err
+	}
+	res, err := r.coll.InsertOne(ctx, customFieldDoc{
+		Tenant:     tenantID,
+		Key:        d.Key,
+		Label:      d.Label,
+		Type:       string(d.Type),
+		Required:   d.Required,
+		Enum:       d.Enum,
+		Pattern:    d.Pattern,
+		Department: d.Department,
+		CreatedAt:  d.CreatedAt,
+		UpdatedAt:  d.UpdatedAt,
+	})
+	if err != nil {
+		if isDuplicateKey(err) {
+			return domain.Conflict("custom field with this key already exists")
+		}
+		return domain.Internal("failed to create custom field", err)
+	}
+
+	oid, ok := res.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return domain.Internal("failed to parse inserted id", errors.New("unexpected inserted id type"))
+	}
+	d.ID = oid.Hex()
+	return nil
+}
+
+func (r *CustomFieldRepository) GetByID(ctx context.Context, id string) (*customfield.Definition, error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	oid, err := parseObjectID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc customFieldDoc
+	if err := r.coll.FindOne(ctx, bson.M{"_id": oid, "tenant": tenantID}).Decode(&doc); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, domain.Internal("failed to fetch custom field", err)
+	}
+	return toDefinition(doc), nil
+}
+
+func (r *CustomFieldRepository) List(ctx context.Context) ([]customfield.Definition, error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cur, err := r.coll.Find(ctx, bson.M{"tenant": tenantID}, options.Find().SetSort(bson.D{{Key: "key", Value: 1}}))
+	if err != nil {
+		return nil, domain.Internal("failed to list custom fields", err)
+	}
+	defer cur.Close(ctx)
+
+	var out []customfield.Definition
+	for cur.Next(ctx) {
+		var doc customFieldDoc
+		if err := cur.Decode(&doc); err != nil {
+			return nil, domain.Internal("failed to decode custom field", err)
+		}
+		out = append(out, *toDefinition(doc))
+	}
+	if err := cur.Err(); err != nil {
+		return nil, domain.Internal("failed to iterate custom fields", err)
+	}
+	return out, nil
+}
+
+// Update saves everything but the key and type, which never change.
+func (r *CustomFieldRepository) Update(ctx context.Context, d *customfield.Definition) error {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return err
+	}
+	oid, err := parseObjectID(d.ID)
+	if err != nil {
+		return err
+	}
+
+	res, err := r.coll.UpdateOne(ctx, bson.M{"_id": oid, "tenant": tenantID}, bson.M{"$set": bson.M{
+		"label":      d.Label,
+		"required":   d.Required,
+		"enum":       d.Enum,
+		"pattern":    d.Pattern,
+		"department": d.Department,
+		"updated_at": d.UpdatedAt,
+	}})
+	if err != nil {
+		return domain.Internal("failed to update custom field", err)
+	}
+	if res.MatchedCount == 0 {
+		return domain.NotFound("custom field not found")
+	}
+	return nil
+}
+
+func (r *CustomFieldRepository) Delete(ctx context.Context, id string) error {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return err
+	}
+	oid, err := parseObjectID(id)
+	if err != nil {
+		return err
+	}
+
+	res, err := r.coll.DeleteOne(ctx, bson.M{"_id": oid, "tenant": tenantID})
+	if err != nil {
+		return domain.Internal("failed to delete custom field", err)
+	}
+	if res.DeletedCount == 0 {
+		return domain.NotFound("custom field not found")
+	}
+	return nil
+}
+
+func toDefinition(doc customFieldDoc) *customfield.Definition {
+	return &customfield.Definition{
+		ID:         doc.ID.Hex(),
+		Key:        doc.Key,
+		Label:      doc.Label,
+		Type:       customfield.Type(doc.Type),
+		Required:   doc.Required,
+		Enum:       doc.Enum,
+		Pattern:    doc.Pattern,
+		Department: doc.Department,
+		CreatedAt:  doc.CreatedAt,
+		UpdatedAt:  doc.UpdatedAt,
+	}
+}
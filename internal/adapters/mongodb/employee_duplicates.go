@@ -0,0 +1,94 @@
+package mongodb
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/rohitashk/golang-rest-api/internal/domain"
+	"github.com/rohitashk/golang-rest-api/internal/domain/tenant"
+)
+
+// duplicateKeyBatch is how many shared keys one query looks up.
+const duplicateKeyBatch = 1000
+
+// DuplicateBlocks counts employees per stored duplicate key on the server,
+// then looks up the employees of the keys shared by two to maxBlock through
+// the tenant_duplicate_keys index. Only IDs and keys leave the server.
+func (r *EmployeeRepository) DuplicateBlocks(ctx context.Context, maxBlock int) ([][]string, error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cur, err := r.coll.Aggregate(ctx, bson.A{
+		bson.M{"$match": bson.M{"tenant": tenantID, "duplicate_keys": bson.M{"$exists": true}}},
+		bson.M{"$project": bson.M{"duplicate_keys": 1}},
+		bson.M{"$unwind": "$duplicate_keys"},
+		bson.M{"$group": bson.M{"_id": "$duplicate_keys", "n": bson.M{"$sum": 1}}},
+		bson.M{"$match": bson.M{"n": bson.M{"$gte": 2, "$lte": maxBlock}}},
+	}, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return nil, domain.Internal("failed to count duplicate keys", err)
+	}
+	var shared []string
+	for cur.Next(ctx) {
+		var group struct {
+			Key string `bson:"_id"`
+		}
+		if err := cur.Decode(&group); err != nil {
+			cur.Close(ctx)
+			return nil, domain.Internal("failed to decode duplicate key", err)
+		}
+		shared = append(shared, group.Key)
+	}
+	err = cur.Err()
+	cur.Close(ctx)
+	if err != nil {
+		return nil, domain.Internal("failed to iterate duplicate keys", err)
+	}
+
+	var out [][]string
+	for start := 0; start < len(shared); start += duplicateKeyBatch {
+		keys := shared[start:min(start+duplicateKeyBatch, len(shared))]
+		blocks, err := r.duplicateBlocks(ctx, tenantID, keys)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, blocks...)
+	}
+	return out, nil
+}
+
+// duplicateBlocks returns the IDs of the employees with each of keys.
+func (r *EmployeeRepository) duplicateBlocks(ctx context.Context, tenantID string, keys []string) ([][]string, error) {
+	cur, err := r.coll.Find(ctx,
+		bson.M{"tenant": tenantID, "duplicate_keys": bson.M{"$in": keys}},
+		options.Find().SetProjection(bson.M{"_id": 1, "duplicate_keys": 1}))
+	if err != nil {
+		return nil, domain.Internal("failed to find duplicate employees", err)
+	}
+	defer cur.Close(ctx)
+
+	wanted := make(map[string]int, len(keys))
+	for i, k := range keys {
+		wanted[k] = i
+	}
+	blocks := make([][]string, len(keys))
+	for cur.Next(ctx) {
+		var doc employeeDoc
+		if err := cur.Decode(&doc); err != nil {
+			return nil, domain.Internal("failed to decode employee", err)
+		}
+		for _, k := range doc.DuplicateKeys {
+			if i, ok := wanted[k]; ok {
+				blocks[i] = append(blocks[i], doc.ID.Hex())
+			}
+		}
+	}
+	if err := cur.Err(); err != nil {
+		return nil, domain.Internal("failed to iterate employees", err)
+	}
+	return blocks, nil
+}
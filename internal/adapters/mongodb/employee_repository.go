@@ -3,13 +3,13 @@ package mongodb
 import (
 	"context"
 	"errors"
-	"fmt"
 	"regexp"
 	"strings"
 	"time"
 
 	"github.com/rohitashk/golang-rest-api/internal/domain"
 	domainEmployee "github.com/rohitashk/golang-rest-api/internal/domain/employee"
+	"github.com/rohitashk/golang-rest-api/internal/domain/tenant"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -24,47 +24,74 @@ func NewEmployeeRepository(db *mongo.Database) *EmployeeRepository {
 	return &EmployeeRepository{coll: db.Collection("employees")}
 }
 
+// employeeDoc is also the source of the collection's validator; schema tags
+// mirror the use case's validation so direct writes are held to it too.
 type employeeDoc struct {
-	ID         primitive.ObjectID `bson:"_id,omitempty"`
-	FirstName  string             `bson:"first_name"`
-	LastName   string             `bson:"last_name"`
-	Email      string             `bson:"email"`
-	Department string             `bson:"department"`
-	Position   string             `bson:"position"`
-	Salary     float64            `bson:"salary"`
-	Status     string             `bson:"status"`
-	CreatedAt  time.Time          `bson:"created_at"`
-	UpdatedAt  time.Time          `bson:"updated_at"`
-}
-
-func (r *EmployeeRepository) EnsureIndexes(ctx context.Context) error {
-	_, err := r.coll.Indexes().CreateMany(ctx, []mongo.IndexModel{
-		{
-			Keys:    bson.D{{Key: "email", Value: 1}},
-			Options: options.Index().SetUnique(true).SetName("uniq_email"),
-		},
-		{
-			Keys:    bson.D{{Key: "department", Value: 1}, {Key: "status", Value: 1}},
-			Options: options.Index().SetName("dept_status"),
-		},
-	})
-	if err != nil {
-		return fmt.Errorf("create indexes: %w", err)
-	}
-	return nil
+	ID            primitive.ObjectID `bson:"_id,omitempty"`
+	Tenant        string             `bson:"tenant" schema:"minLength=1,maxLength=63"`
+	FirstName     string             `bson:"first_name" schema:"minLength=1,maxLength=100"`
+	LastName      string             `bson:"last_name" schema:"minLength=1,maxLength=100"`
+	Email         string             `bson:"email" schema:"minLength=3,maxLength=320"`
+	Phone         string             `bson:"phone,omitempty" schema:"maxLength=16"`
+	DateOfBirth   string             `bson:"date_of_birth,omitempty" schema:"minLength=10,maxLength=10"`
+	Department    string             `bson:"department" schema:"minLength=1,maxLength=120"`
+	Position      string             `bson:"position" schema:"minLength=1,maxLength=120"`
+	Salary        float64            `bson:"salary" schema:"minimum=0,maximum=1000000000"`
+	Status        string             `bson:"status" schema:"enum=active|inactive"`
+	ManagerID     primitive.ObjectID `bson:"manager_id,omitempty"`
+	CustomFields  map[string]any     `bson:"custom_fields,omitempty" schema:"values=string|number|bool"`
+	SearchWords   []string           `bson:"search_words,omitempty"`   // see searchWords
+	SearchGrams   []string           `bson:"search_grams,omitempty"`   // see searchGrams
+	DuplicateKeys []string           `bson:"duplicate_keys,omitempty"` // see DuplicateBlocks
+	CreatedAt     time.Time          `bson:"created_at"`
+	UpdatedAt     time.Time          `bson:"updated_at"`
+}
+
+var (
+	employeeSchema    = schemaOf(employeeDoc{})
+	employeeValidator = jsonSchema(employeeSchema)
+)
+
+// EnsureSchema installs or updates the collection's validator for mode and
+// reports whether it changed.
+func (r *EmployeeRepository) EnsureSchema(ctx context.Context, mode SchemaMode) (bool, error) {
+	return ensureValidator(ctx, r.coll, employeeValidator, mode)
+}
+
+// Nonconforming lists up to limit stored employees the validator would
+// reject, and counts all of them, so they can be fixed before enforcing.
+func (r *EmployeeRepository) Nonconforming(ctx context.Context, limit int64) ([]Nonconforming, int64, error) {
+	return nonconforming(ctx, r.coll, employeeValidator, employeeSchema, limit)
 }
 
 func (r *EmployeeRepository) Create(ctx context.Context, e *domainEmployee.Employee) error {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return err
+	}
+	managerID, err := parseOptionalObjectID(e.ManagerID)
+	if err != nil {
+		return err
+	}
+
 	doc := employeeDoc{
-		FirstName:  e.FirstName,
-		LastName:   e.LastName,
-		Email:      strings.ToLower(strings.TrimSpace(e.Email)),
-		Department: e.Department,
-		Position:   e.Position,
-		Salary:     e.Salary,
-		Status:     string(e.Status),
-		CreatedAt:  e.CreatedAt,
-		UpdatedAt:  e.UpdatedAt,
+		Tenant:        tenantID,
+		FirstName:     e.FirstName,
+		LastName:      e.LastName,
+		Email:         strings.ToLower(strings.TrimSpace(e.Email)),
+		Phone:         e.Phone,
+		DateOfBirth:   e.DateOfBirth,
+		Department:    e.Department,
+		Position:      e.Position,
+		Salary:        e.Salary,
+		Status:        string(e.Status),
+		ManagerID:     managerID,
+		CustomFields:  e.CustomFields,
+		SearchWords:   searchWords(e),
+		SearchGrams:   searchGrams(e),
+		DuplicateKeys: domainEmployee.DuplicateKeys(e),
+		CreatedAt:     e.CreatedAt,
+		UpdatedAt:     e.UpdatedAt,
 	}
 
 	res, err := r.coll.InsertOne(ctx, doc)
@@ -83,14 +110,23 @@ func (r *EmployeeRepository) Create(ctx context.Context, e *domainEmployee.Emplo
 	return nil
 }
 
-func (r *EmployeeRepository) GetByID(ctx context.Context, id string) (*domainEmployee.Employee, error) {
+func (r *EmployeeRepository) GetByID(ctx context.Context, id string, fields ...string) (*domainEmployee.Employee, error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
 	oid, err := parseObjectID(id)
 	if err != nil {
 		return nil, err
 	}
 
+	opts := options.FindOne()
+	if len(fields) > 0 {
+		opts.SetProjection(projection(fields))
+	}
+
 	var doc employeeDoc
-	err = r.coll.FindOne(ctx, bson.M{"_id": oid}).Decode(&doc)
+	err = r.coll.FindOne(ctx, bson.M{"_id": oid, "tenant": tenantID}, opts).Decode(&doc)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return nil, nil
@@ -101,13 +137,17 @@ func (r *EmployeeRepository) GetByID(ctx context.Context, id string) (*domainEmp
 }
 
 func (r *EmployeeRepository) GetByEmail(ctx context.Context, email string) (*domainEmployee.Employee, error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
 	email = strings.ToLower(strings.TrimSpace(email))
 	if email == "" {
 		return nil, domain.Validation("email is required")
 	}
 
 	var doc employeeDoc
-	err := r.coll.FindOne(ctx, bson.M{"email": email}).Decode(&doc)
+	err = r.coll.FindOne(ctx, bson.M{"tenant": tenantID, "email": email}).Decode(&doc)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return nil, nil
@@ -117,14 +157,49 @@ func (r *EmployeeRepository) GetByEmail(ctx context.Context, email string) (*dom
 	return toDomain(doc), nil
 }
 
-func (r *EmployeeRepository) List(ctx context.Context, filter domainEmployee.ListFilter, page domainEmployee.ListPage) ([]domainEmployee.Employee, int64, error) {
-	q := bson.M{}
+func (r *EmployeeRepository) List(ctx context.Context, filter domainEmployee.ListFilter, page domainEmployee.ListPage, fields ...string) ([]domainEmployee.Employee, int64, error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	q := bson.M{"tenant": tenantID}
+	if filter.IDs != nil {
+		oids := make([]primitive.ObjectID, 0, len(filter.IDs))
+		for _, id := range filter.IDs {
+			if oid, err := parseObjectID(id); err == nil {
+				oids = append(oids, oid)
+			}
+		}
+		q["_id"] = bson.M{"$in": oids}
+	}
 	if filter.Department != nil && strings.TrimSpace(*filter.Department) != "" {
 		q["department"] = strings.TrimSpace(*filter.Department)
 	}
 	if filter.Status != nil && *filter.Status != "" {
 		q["status"] = string(*filter.Status)
 	}
+	if filter.ManagerID != nil {
+		managerID, err := parseObjectID(*filter.ManagerID)
+		if err != nil {
+			return nil, 0, err
+		}
+		q["manager_id"] = managerID
+	}
+	if filter.ManagerIDs != nil {
+		oids := make([]primitive.ObjectID, 0, len(filter.ManagerIDs))
+		for _, id := range filter.ManagerIDs {
+			if oid, err := parseObjectID(id); err == nil {
+				oids = append(oids, oid)
+			}
+		}
+		q["manager_id"] = bson.M{"$in": oids}
+	}
+	if filter.Departments != nil {
+		q["department"] = bson.M{"$in": filter.Departments}
+	}
+	if filter.Email != nil {
+		q["email"] = strings.ToLower(strings.TrimSpace(*filter.Email))
+	}
 	if filter.Query != nil && strings.TrimSpace(*filter.Query) != "" {
 		escaped := regexp.QuoteMeta(strings.TrimSpace(*filter.Query))
 		re := primitive.Regex{Pattern: escaped, Options: "i"}
@@ -134,6 +209,9 @@ func (r *EmployeeRepository) List(ctx context.Context, filter domainEmployee.Lis
 			{"email": re},
 		}
 	}
+	for key, v := range filter.CustomFields {
+		q["custom_fields."+key] = v
+	}
 
 	total, err := r.coll.CountDocuments(ctx, q)
 	if err != nil {
@@ -144,6 +222,9 @@ func (r *EmployeeRepository) List(ctx context.Context, filter domainEmployee.Lis
 		SetSort(bson.D{{Key: "created_at", Value: -1}}).
 		SetLimit(page.Limit).
 		SetSkip(page.Offset)
+	if len(fields) > 0 {
+		opts.SetProjection(projection(fields))
+	}
 
 	cur, err := r.coll.Find(ctx, q, opts)
 	if err != nil {
@@ -166,24 +247,101 @@ func (r *EmployeeRepository) List(ctx context.Context, filter domainEmployee.Lis
 	return out, total, nil
 }
 
-func (r *EmployeeRepository) Update(ctx context.Context, e *domainEmployee.Employee) error {
+func (r *EmployeeRepository) Headcount(ctx context.Context, departments ...string) (map[string]int64, error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	match := bson.M{"tenant": tenantID, "status": string(domainEmployee.StatusActive)}
+	if len(departments) > 0 {
+		match["department"] = bson.M{"$in": departments}
+	}
+
+	cur, err := r.coll.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.M{"_id": "$department", "count": bson.M{"$sum": 1}}}},
+	})
+	if err != nil {
+		return nil, domain.Internal("failed to count employees", err)
+	}
+	defer cur.Close(ctx)
+
+	out := make(map[string]int64, len(departments))
+	for _, d := range departments {
+		out[d] = 0
+	}
+	for cur.Next(ctx) {
+		var row struct {
+			Department string `bson:"_id"`
+			Count      int64  `bson:"count"`
+		}
+		if err := cur.Decode(&row); err != nil {
+			return nil, domain.Internal("failed to decode headcount", err)
+		}
+		out[row.Department] = row.Count
+	}
+	if err := cur.Err(); err != nil {
+		return nil, domain.Internal("failed to iterate headcount", err)
+	}
+	return out, nil
+}
+
+func (r *EmployeeRepository) Update(ctx context.Context, e *domainEmployee.Employee, prevUpdatedAt time.Time) error {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return err
+	}
 	oid, err := parseObjectID(e.ID)
 	if err != nil {
 		return err
 	}
+	managerID, err := parseOptionalObjectID(e.ManagerID)
+	if err != nil {
+		return err
+	}
 
 	set := bson.M{
-		"first_name": e.FirstName,
-		"last_name":  e.LastName,
-		"email":      strings.ToLower(strings.TrimSpace(e.Email)),
-		"department": e.Department,
-		"position":   e.Position,
-		"salary":     e.Salary,
-		"status":     string(e.Status),
-		"updated_at": e.UpdatedAt,
+		"first_name":     e.FirstName,
+		"last_name":      e.LastName,
+		"email":          strings.ToLower(strings.TrimSpace(e.Email)),
+		"department":     e.Department,
+		"position":       e.Position,
+		"salary":         e.Salary,
+		"status":         string(e.Status),
+		"search_words":   searchWords(e),
+		"search_grams":   searchGrams(e),
+		"duplicate_keys": domainEmployee.DuplicateKeys(e),
+		"updated_at":     e.UpdatedAt,
 	}
 
-	res, err := r.coll.UpdateOne(ctx, bson.M{"_id": oid}, bson.M{"$set": set})
+	unset := bson.M{}
+	if managerID.IsZero() {
+		unset["manager_id"] = ""
+	} else {
+		set["manager_id"] = managerID
+	}
+	for field, v := range map[string]string{"phone": e.Phone, "date_of_birth": e.DateOfBirth} {
+		if v == "" {
+			unset[field] = ""
+		} else {
+			set[field] = v
+		}
+	}
+	if len(e.CustomFields) == 0 {
+		unset["custom_fields"] = ""
+	} else {
+		set["custom_fields"] = e.CustomFields
+	}
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	filter := bson.M{"_id": oid, "tenant": tenantID}
+	if !prevUpdatedAt.IsZero() {
+		filter["updated_at"] = prevUpdatedAt
+	}
+	res, err := r.coll.UpdateOne(ctx, filter, update)
 	if err != nil {
 		if isDuplicateKey(err) {
 			return domain.Conflict("employee with this email already exists")
@@ -191,18 +349,33 @@ func (r *EmployeeRepository) Update(ctx context.Context, e *domainEmployee.Emplo
 		return domain.Internal("failed to update employee", err)
 	}
 	if res.MatchedCount == 0 {
-		return domain.NotFound("employee not found")
+		if prevUpdatedAt.IsZero() {
+			return domain.NotFound("employee not found")
+		}
+		// Gone, or changed since it was read.
+		n, err := r.coll.CountDocuments(ctx, bson.M{"_id": oid, "tenant": tenantID})
+		if err != nil {
+			return domain.Internal("failed to update employee", err)
+		}
+		if n == 0 {
+			return domain.NotFound("employee not found")
+		}
+		return domainEmployee.ErrModified
 	}
 	return nil
 }
 
 func (r *EmployeeRepository) Delete(ctx context.Context, id string) error {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return err
+	}
 	oid, err := parseObjectID(id)
 	if err != nil {
 		return err
 	}
 
-	res, err := r.coll.DeleteOne(ctx, bson.M{"_id": oid})
+	res, err := r.coll.DeleteOne(ctx, bson.M{"_id": oid, "tenant": tenantID})
 	if err != nil {
 		return domain.Internal("failed to delete employee", err)
 	}
@@ -212,19 +385,65 @@ func (r *EmployeeRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+func (r *EmployeeRepository) RemoveCustomField(ctx context.Context, key string) error {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return err
+	}
+	field := "custom_fields." + key
+	_, err = r.coll.UpdateMany(ctx,
+		bson.M{"tenant": tenantID, field: bson.M{"$exists": true}},
+		bson.M{"$unset": bson.M{field: ""}})
+	if err != nil {
+		return domain.Internal("failed to remove custom field", err)
+	}
+	return nil
+}
+
 func toDomain(doc employeeDoc) *domainEmployee.Employee {
+	var managerID string
+	if !doc.ManagerID.IsZero() {
+		managerID = doc.ManagerID.Hex()
+	}
 	return &domainEmployee.Employee{
-		ID:         doc.ID.Hex(),
-		FirstName:  doc.FirstName,
-		LastName:   doc.LastName,
-		Email:      doc.Email,
-		Department: doc.Department,
-		Position:   doc.Position,
-		Salary:     doc.Salary,
-		Status:     domainEmployee.Status(doc.Status),
-		CreatedAt:  doc.CreatedAt,
-		UpdatedAt:  doc.UpdatedAt,
+		ID:           doc.ID.Hex(),
+		FirstName:    doc.FirstName,
+		LastName:     doc.LastName,
+		Email:        doc.Email,
+		Phone:        doc.Phone,
+		DateOfBirth:  doc.DateOfBirth,
+		Department:   doc.Department,
+		Position:     doc.Position,
+		Salary:       doc.Salary,
+		Status:       domainEmployee.Status(doc.Status),
+		ManagerID:    managerID,
+		CustomFields: doc.CustomFields,
+		CreatedAt:    doc.CreatedAt,
+		UpdatedAt:    doc.UpdatedAt,
+	}
+}
+
+// projection maps domain field names to employeeDoc keys; _id is always
+// returned by Mongo.
+func projection(fields []string) bson.M {
+	p := bson.M{}
+	for _, f := range fields {
+		if f == domainEmployee.FieldID {
+			continue
+		}
+		p[f] = 1
+	}
+	if len(p) == 0 {
+		p["_id"] = 1
+	}
+	return p
+}
+
+func parseOptionalObjectID(id string) (primitive.ObjectID, error) {
+	if strings.TrimSpace(id) == "" {
+		return primitive.NilObjectID, nil
 	}
+	return parseObjectID(id)
 }
 
 func parseObjectID(id string) (primitive.ObjectID, error) {
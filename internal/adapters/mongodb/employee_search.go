@@ -0,0 +1,128 @@
+package mongodb
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/rohitashk/golang-rest-api/internal/domain"
+	domainEmployee "github.com/rohitashk/golang-rest-api/internal/domain/employee"
+	"github.com/rohitashk/golang-rest-api/internal/domain/tenant"
+	"github.com/rohitashk/golang-rest-api/internal/search"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// searchWords are the words of an employee's searchable attributes. The
+// text index cannot match the start of a word or a misspelled one, so they
+// are stored for an index on anchored regexes to do so.
+func searchWords(e *domainEmployee.Employee) []string {
+	return search.Words(e.FirstName, e.LastName, e.Email, e.Department, e.Position)
+}
+
+// searchGrams are the search.Grams of the words of an employee's names, the
+// only attributes typos are forgiven in.
+func searchGrams(e *domainEmployee.Employee) []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, w := range search.Words(e.FirstName, e.LastName) {
+		for _, g := range search.Grams(w) {
+			if !seen[g] {
+				seen[g] = true
+				out = append(out, g)
+			}
+		}
+	}
+	return out
+}
+
+// Search finds terms spelled out in full with the text index. Prefix terms
+// are looked up in search_words by their start, and fuzzy terms long enough
+// to allow typos also in search_grams. Candidates are sorted by relevance
+// before the limit, so it cuts the worst ones: the text score, plus for
+// every other term one if it is a whole word of the employee and the share
+// of its grams the employee's names have.
+func (r *EmployeeRepository) Search(ctx context.Context, q domainEmployee.SearchQuery) ([]domainEmployee.Employee, error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	match := bson.M{"tenant": tenantID}
+	if q.Department != nil && strings.TrimSpace(*q.Department) != "" {
+		match["department"] = strings.TrimSpace(*q.Department)
+	}
+	if q.Status != nil && *q.Status != "" {
+		match["status"] = string(*q.Status)
+	}
+
+	var phrases []string
+	var conds, relevance bson.A
+	for i, term := range q.Terms {
+		prefix := q.Prefix && i == len(q.Terms)-1
+		fuzzy := q.Fuzzy && search.MaxEdits(term) > 0
+		if !prefix && !fuzzy {
+			// Quoted, every term must be present rather than any.
+			phrases = append(phrases, `"`+term+`"`)
+			continue
+		}
+
+		cond := bson.M{"search_words": term}
+		if prefix {
+			cond = bson.M{"search_words": startsWith(term)}
+		}
+		relevance = append(relevance, bson.M{"$cond": bson.A{
+			bson.M{"$in": bson.A{term, bson.M{"$ifNull": bson.A{"$search_words", bson.A{}}}}}, 1, 0,
+		}})
+		if fuzzy {
+			grams := search.Grams(term)
+			cond = bson.M{"$or": bson.A{cond, bson.M{"search_grams": bson.M{"$in": grams}}}}
+			relevance = append(relevance, bson.M{"$divide": bson.A{
+				bson.M{"$size": bson.M{"$setIntersection": bson.A{bson.M{"$ifNull": bson.A{"$search_grams", bson.A{}}}, grams}}},
+				len(grams),
+			}})
+		}
+		conds = append(conds, cond)
+	}
+	if len(conds) > 0 {
+		match["$and"] = conds
+	}
+	if len(phrases) > 0 {
+		match["$text"] = bson.M{"$search": strings.Join(phrases, " ")}
+		relevance = append(relevance, bson.M{"$meta": "textScore"})
+	}
+
+	pipeline := bson.A{
+		bson.M{"$match": match},
+		bson.M{"$addFields": bson.M{"relevance": bson.M{"$add": relevance}}},
+		bson.M{"$sort": bson.D{{Key: "relevance", Value: -1}, {Key: "_id", Value: 1}}},
+	}
+	if q.Limit > 0 {
+		pipeline = append(pipeline, bson.M{"$limit": q.Limit})
+	}
+	pipeline = append(pipeline, bson.M{"$project": bson.M{"relevance": 0}})
+
+	cur, err := r.coll.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, domain.Internal("failed to search employees", err)
+	}
+	defer cur.Close(ctx)
+
+	var out []domainEmployee.Employee
+	for cur.Next(ctx) {
+		var doc employeeDoc
+		if err := cur.Decode(&doc); err != nil {
+			return nil, domain.Internal("failed to decode employee", err)
+		}
+		out = append(out, *toDomain(doc))
+	}
+	if err := cur.Err(); err != nil {
+		return nil, domain.Internal("failed to iterate employees", err)
+	}
+	return out, nil
+}
+
+// startsWith is anchored and case-sensitive, as words are stored lowercase,
+// so the server can bound it to a range of the index.
+func startsWith(prefix string) primitive.Regex {
+	return primitive.Regex{Pattern: "^" + regexp.QuoteMeta(prefix)}
+}
@@ -0,0 +1,102 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rohitashk/golang-rest-api/internal/domain"
+	"github.com/rohitashk/golang-rest-api/internal/domain/idempotency"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type IdempotencyStore struct {
+	coll *mongo.Collection
+	now  func() time.Time
+}
+
+func NewIdempotencyStore(db *mongo.Database) *IdempotencyStore {
+	return &IdempotencyStore{coll: db.Collection("idempotency_keys"), now: time.Now}
+}
+
+type idempotencyDoc struct {
+	Key         string    `bson:"_id"`
+	Fingerprint string    `bson:"fingerprint"`
+	Completed   bool      `bson:"completed"`
+	StatusCode  int       `bson:"status_code,omitempty"`
+	ContentType string    `bson:"content_type,omitempty"`
+	Body        []byte    `bson:"body,omitempty"`
+	CreatedAt   time.Time `bson:"created_at"`
+	ExpiresAt   time.Time `bson:"expires_at"`
+}
+
+func (s *IdempotencyStore) Reserve(ctx context.Context, rec *idempotency.Record) (*idempotency.Record, error) {
+	doc := idempotencyDoc{
+		Key:         rec.Key,
+		Fingerprint: rec.Fingerprint,
+		CreatedAt:   rec.CreatedAt,
+		ExpiresAt:   rec.ExpiresAt,
+	}
+
+	_, err := s.coll.InsertOne(ctx, doc)
+	if err == nil {
+		return nil, nil
+	}
+	if !isDuplicateKey(err) {
+		return nil, domain.Internal("failed to reserve idempotency key", err)
+	}
+
+	// The TTL monitor only runs periodically, so an expired record may still
+	// be around. Take it over instead of replaying a stale response.
+	res, err := s.coll.ReplaceOne(ctx, bson.M{"_id": rec.Key, "expires_at": bson.M{"$lte": s.now().UTC()}}, doc)
+	if err != nil {
+		return nil, domain.Internal("failed to reserve idempotency key", err)
+	}
+	if res.MatchedCount == 1 {
+		return nil, nil
+	}
+
+	var existing idempotencyDoc
+	if err := s.coll.FindOne(ctx, bson.M{"_id": rec.Key}).Decode(&existing); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.Conflict("idempotency key was released concurrently, retry the request")
+		}
+		return nil, domain.Internal("failed to fetch idempotency key", err)
+	}
+	return toIdempotencyRecord(existing), nil
+}
+
+func (s *IdempotencyStore) Complete(ctx context.Context, rec *idempotency.Record) error {
+	_, err := s.coll.UpdateOne(ctx, bson.M{"_id": rec.Key}, bson.M{"$set": bson.M{
+		"completed":    true,
+		"status_code":  rec.StatusCode,
+		"content_type": rec.ContentType,
+		"body":         rec.Body,
+	}})
+	if err != nil {
+		return domain.Internal("failed to store idempotent response", err)
+	}
+	return nil
+}
+
+func (s *IdempotencyStore) Release(ctx context.Context, key string) error {
+	_, err := s.coll.DeleteOne(ctx, bson.M{"_id": key, "completed": false})
+	if err != nil {
+		return domain.Internal("failed to release idempotency key", err)
+	}
+	return nil
+}
+
+func toIdempotencyRecord(doc idempotencyDoc) *idempotency.Record {
+	return &idempotency.Record{
+		Key:         doc.Key,
+		Fingerprint: doc.Fingerprint,
+		Completed:   doc.Completed,
+		StatusCode:  doc.StatusCode,
+		ContentType: doc.ContentType,
+		Body:        doc.Body,
+		CreatedAt:   doc.CreatedAt,
+		ExpiresAt:   doc.ExpiresAt,
+	}
+}
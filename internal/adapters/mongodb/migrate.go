@@ -0,0 +1,285 @@
+package mongodb
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Migration is one versioned change to the database: indexes, renamed or
+// backfilled fields and the like. Up and Down must be safe to run again
+// after failing halfway, since index builds and multi-document updates
+// cannot share a transaction with the migration record. Down may be nil for
+// a change that cannot be undone.
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(ctx context.Context, db *mongo.Database) error
+	Down    func(ctx context.Context, db *mongo.Database) error
+}
+
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time // nil while pending
+	// Unknown marks a version applied by a build that had migrations this
+	// one does not.
+	Unknown bool
+}
+
+// Migrator applies migrations in version order, recording each in the
+// migrations collection. A lease in migrations_lock keeps replicas that
+// start together from running them concurrently; the others wait for it.
+type Migrator struct {
+	db         *mongo.Database
+	applied    *mongo.Collection
+	lock       *mongo.Collection
+	migrations []Migration
+	owner      string
+	lease      time.Duration
+	retry      time.Duration
+	now        func() time.Time
+}
+
+type migrationDoc struct {
+	Version   int       `bson:"_id"`
+	Name      string    `bson:"name"`
+	AppliedAt time.Time `bson:"applied_at"`
+}
+
+const lockID = "migrations"
+
+// NewMigrator panics if versions are not positive and unique, which is a
+// programming error.
+func NewMigrator(db *mongo.Database, migrations []Migration) *Migrator {
+	sorted := append([]Migration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+	for i, m := range sorted {
+		if m.Version <= 0 || (i > 0 && sorted[i-1].Version == m.Version) || m.Up == nil {
+			panic(fmt.Sprintf("migration %d %q: versions must be positive and unique, and Up set", m.Version, m.Name))
+		}
+	}
+
+	return &Migrator{
+		db:         db,
+		applied:    db.Collection("migrations"),
+		lock:       db.Collection("migrations_lock"),
+		migrations: sorted,
+		owner:      lockOwner(),
+		lease:      time.Minute,
+		retry:      time.Second,
+		now:        time.Now,
+	}
+}
+
+func lockOwner() string {
+	host, _ := os.Hostname()
+	var b [4]byte
+	_, _ = rand.Read(b[:])
+	return fmt.Sprintf("%s/%d/%s", host, os.Getpid(), hex.EncodeToString(b[:]))
+}
+
+// Status lists every known migration and any applied version this build
+// does not know, by version.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := m.appliedDocs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []MigrationStatus
+	for _, mig := range m.migrations {
+		s := MigrationStatus{Version: mig.Version, Name: mig.Name}
+		if doc, ok := applied[mig.Version]; ok {
+			at := doc.AppliedAt
+			s.AppliedAt = &at
+			delete(applied, mig.Version)
+		}
+		out = append(out, s)
+	}
+	for _, doc := range applied {
+		at := doc.AppliedAt
+		out = append(out, MigrationStatus{Version: doc.Version, Name: doc.Name, AppliedAt: &at, Unknown: true})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+	return out, nil
+}
+
+// Pending lists the migrations not applied yet.
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	applied, err := m.appliedDocs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var out []Migration
+	for _, mig := range m.migrations {
+		if _, ok := applied[mig.Version]; !ok {
+			out = append(out, mig)
+		}
+	}
+	return out, nil
+}
+
+// Up applies the pending migrations up to version to, or all of them when
+// to is 0, and returns those applied. It stops at the first failure.
+func (m *Migrator) Up(ctx context.Context, to int) (done []Migration, err error) {
+	err = m.locked(ctx, func(ctx context.Context) error {
+		pending, err := m.Pending(ctx)
+		if err != nil {
+			return err
+		}
+		for _, mig := range pending {
+			if to > 0 && mig.Version > to {
+				break
+			}
+			if err := mig.Up(ctx, m.db); err != nil {
+				return fmt.Errorf("migration %d %s: %w", mig.Version, mig.Name, err)
+			}
+			doc := migrationDoc{Version: mig.Version, Name: mig.Name, AppliedAt: m.now().UTC()}
+			if _, err := m.applied.InsertOne(ctx, doc); err != nil {
+				return fmt.Errorf("migration %d %s: record: %w", mig.Version, mig.Name, err)
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down reverts the applied migrations above version to, newest first, and
+// returns those reverted. It stops at the first failure or at a migration
+// without Down.
+func (m *Migrator) Down(ctx context.Context, to int) (done []Migration, err error) {
+	err = m.locked(ctx, func(ctx context.Context) error {
+		applied, err := m.appliedDocs(ctx)
+		if err != nil {
+			return err
+		}
+		for _, doc := range applied {
+			if doc.Version > to && !m.known(doc.Version) {
+				return fmt.Errorf("migration %d %s was applied by a newer build; revert it with that build", doc.Version, doc.Name)
+			}
+		}
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			mig := m.migrations[i]
+			if mig.Version <= to {
+				break
+			}
+			if _, ok := applied[mig.Version]; !ok {
+				continue
+			}
+			if mig.Down == nil {
+				return fmt.Errorf("migration %d %s cannot be reverted", mig.Version, mig.Name)
+			}
+			if err := mig.Down(ctx, m.db); err != nil {
+				return fmt.Errorf("revert migration %d %s: %w", mig.Version, mig.Name, err)
+			}
+			if _, err := m.applied.DeleteOne(ctx, bson.M{"_id": mig.Version}); err != nil {
+				return fmt.Errorf("revert migration %d %s: record: %w", mig.Version, mig.Name, err)
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+func (m *Migrator) known(version int) bool {
+	for _, mig := range m.migrations {
+		if mig.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Migrator) appliedDocs(ctx context.Context) (map[int]migrationDoc, error) {
+	cur, err := m.applied.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("read migrations: %w", err)
+	}
+	var docs []migrationDoc
+	if err := cur.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("read migrations: %w", err)
+	}
+	out := make(map[int]migrationDoc, len(docs))
+	for _, d := range docs {
+		out[d.Version] = d
+	}
+	return out, nil
+}
+
+// locked runs fn holding the lock, waiting for another runner to finish
+// first. The lease is renewed while fn runs, and expires on its own if this
+// process dies, so a crash never leaves the database locked for good.
+func (m *Migrator) locked(ctx context.Context, fn func(ctx context.Context) error) error {
+	for {
+		ok, err := m.acquire(ctx)
+		if err != nil {
+			return err
+		}
+		if ok {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for the migrations lock: %w", ctx.Err())
+		case <-time.After(m.retry):
+		}
+	}
+
+	fnCtx, cancel := context.WithCancel(ctx)
+	renewed := make(chan struct{})
+	go func() {
+		defer close(renewed)
+		ticker := time.NewTicker(m.lease / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-fnCtx.Done():
+				return
+			case <-ticker.C:
+				// A failed renewal is retried on the next tick; the lease
+				// has two more ticks to run.
+				_, _ = m.acquire(fnCtx)
+			}
+		}
+	}()
+
+	err := fn(fnCtx)
+	cancel()
+	<-renewed
+
+	if _, relErr := m.lock.DeleteOne(context.WithoutCancel(ctx), bson.M{"_id": lockID, "owner": m.owner}); relErr != nil && err == nil {
+		err = fmt.Errorf("release migrations lock: %w", relErr)
+	}
+	return err
+}
+
+// acquire takes or extends the lease. It fails to match, and the upsert
+// hits the unique _id, while another owner's lease is live.
+func (m *Migrator) acquire(ctx context.Context) (bool, error) {
+	now := m.now().UTC()
+	filter := bson.M{"_id": lockID, "$or": bson.A{
+		bson.M{"owner": m.owner},
+		bson.M{"expires_at": bson.M{"$lte": now}},
+	}}
+	update := bson.M{"$set": bson.M{"owner": m.owner, "expires_at": now.Add(m.lease)}}
+
+	_, err := m.lock.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err == nil {
+		return true, nil
+	}
+	if isDuplicateKey(err) {
+		return false, nil
+	}
+	return false, fmt.Errorf("migrations lock: %w", err)
+}
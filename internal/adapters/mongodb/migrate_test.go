@@ -0,0 +1,229 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+// The lock tests run against the driver's mock deployment: each command gets
+// the next queued response, and the commands sent are recorded.
+
+var (
+	lockTaken = mtest.CreateSuccessResponse()
+	// lockHeld is the upsert hitting the _id of another owner's live lease.
+	lockHeld = mtest.CreateWriteErrorsResponse(mtest.WriteError{Code: 11000, Message: "E11000 duplicate key error"})
+)
+
+type lockUpdate struct {
+	Q struct {
+		ID string `bson:"_id"`
+		Or []struct {
+			Owner     string `bson:"owner"`
+			ExpiresAt struct {
+				Lte time.Time `bson:"$lte"`
+			} `bson:"expires_at"`
+		} `bson:"$or"`
+	} `bson:"q"`
+	U struct {
+		Set struct {
+			Owner     string    `bson:"owner"`
+			ExpiresAt time.Time `bson:"expires_at"`
+		} `bson:"$set"`
+	} `bson:"u"`
+	Upsert bool `bson:"upsert"`
+}
+
+type lockDelete struct {
+	Q struct {
+		ID    string `bson:"_id"`
+		Owner string `bson:"owner"`
+	} `bson:"q"`
+}
+
+func newTestMigrator(mt *mtest.T) *Migrator {
+	m := NewMigrator(mt.DB, nil)
+	m.retry = time.Millisecond
+	return m
+}
+
+// lockCommands returns the names of the commands sent, and the updates and
+// deletes among them.
+func lockCommands(mt *mtest.T) (names []string, updates []lockUpdate, deletes []lockDelete) {
+	mt.Helper()
+	for _, ev := range mt.GetAllStartedEvents() {
+		names = append(names, ev.CommandName)
+		switch ev.CommandName {
+		case "update":
+			var u lockUpdate
+			if err := bson.Unmarshal(ev.Command.Lookup("updates").Array().Index(0).Value().Document(), &u); err != nil {
+				mt.Fatal(err)
+			}
+			updates = append(updates, u)
+		case "delete":
+			var d lockDelete
+			if err := bson.Unmarshal(ev.Command.Lookup("deletes").Array().Index(0).Value().Document(), &d); err != nil {
+				mt.Fatal(err)
+			}
+			deletes = append(deletes, d)
+		}
+	}
+	return names, updates, deletes
+}
+
+func TestMigrationLockAcquire(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	mt.Run("free", func(mt *mtest.T) {
+		m := newTestMigrator(mt)
+		m.now = func() time.Time { return now }
+		mt.AddMockResponses(lockTaken)
+
+		if ok, err := m.acquire(context.Background()); !ok || err != nil {
+			mt.Fatalf("acquire = %v, %v", ok, err)
+		}
+		_, updates, _ := lockCommands(mt)
+		if len(updates) != 1 {
+			mt.Fatalf("%d updates", len(updates))
+		}
+		u := updates[0]
+		// The lease is taken when it is this owner's or has expired.
+		if u.Q.ID != lockID || len(u.Q.Or) != 2 || u.Q.Or[0].Owner != m.owner || !u.Q.Or[1].ExpiresAt.Lte.Equal(now) {
+			mt.Errorf("filter = %+v", u.Q)
+		}
+		if !u.Upsert || u.U.Set.Owner != m.owner || !u.U.Set.ExpiresAt.Equal(now.Add(m.lease)) {
+			mt.Errorf("update = %+v, upsert %v", u.U, u.Upsert)
+		}
+	})
+
+	mt.Run("held", func(mt *mtest.T) {
+		mt.AddMockResponses(lockHeld)
+		if ok, err := newTestMigrator(mt).acquire(context.Background()); ok || err != nil {
+			mt.Errorf("acquire = %v, %v, want false while held", ok, err)
+		}
+	})
+
+	mt.Run("error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 13, Name: "Unauthorized", Message: "not authorized"}))
+		if ok, err := newTestMigrator(mt).acquire(context.Background()); ok || err == nil {
+			mt.Errorf("acquire = %v, %v, want an error", ok, err)
+		}
+	})
+}
+
+func TestMigrationLockWaitsForHolder(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	mt.Run("wait", func(mt *mtest.T) {
+		m := newTestMigrator(mt)
+		mt.AddMockResponses(lockHeld, lockHeld, lockTaken, mtest.CreateSuccessResponse())
+
+		ran := false
+		if err := m.locked(context.Background(), func(context.Context) error {
+			ran = true
+			return nil
+		}); err != nil {
+			mt.Fatal(err)
+		}
+		if !ran {
+			mt.Fatal("fn did not run")
+		}
+		names, _, deletes := lockCommands(mt)
+		if want := []string{"update", "update", "update", "delete"}; strings.Join(names, " ") != strings.Join(want, " ") {
+			mt.Errorf("commands = %q, want %q", names, want)
+		}
+		// Only this owner's lease is released.
+		if len(deletes) != 1 || deletes[0].Q.ID != lockID || deletes[0].Q.Owner != m.owner {
+			mt.Errorf("release = %+v", deletes)
+		}
+	})
+}
+
+func TestMigrationLockGivesUpWaiting(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	mt.Run("cancel", func(mt *mtest.T) {
+		m := newTestMigrator(mt)
+		for i := 0; i < 1000; i++ {
+			mt.AddMockResponses(lockHeld)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		err := m.locked(ctx, func(context.Context) error {
+			mt.Error("fn ran without the lock")
+			return nil
+		})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			mt.Errorf("err = %v", err)
+		}
+		// A lease never held is not released.
+		if _, _, deletes := lockCommands(mt); len(deletes) != 0 {
+			mt.Errorf("released %+v", deletes)
+		}
+	})
+}
+
+func TestMigrationLockRenewsWhileRunning(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	mt.Run("renew", func(mt *mtest.T) {
+		m := newTestMigrator(mt)
+		m.lease = 30 * time.Millisecond
+		for i := 0; i < 100; i++ {
+			mt.AddMockResponses(lockTaken)
+		}
+
+		if err := m.locked(context.Background(), func(context.Context) error {
+			time.Sleep(4 * m.lease)
+			return nil
+		}); err != nil {
+			mt.Fatal(err)
+		}
+
+		names, updates, _ := lockCommands(mt)
+		// The lease is renewed every third of it, so it never lapses while
+		// the migrations run.
+		if len(updates) < 4 {
+			mt.Errorf("%d updates in four lease lengths, want renewals", len(updates))
+		}
+		for _, u := range updates {
+			if u.U.Set.Owner != m.owner || u.Q.Or[0].Owner != m.owner {
+				mt.Errorf("renewal of another owner: %+v", u)
+			}
+		}
+		if names[len(names)-1] != "delete" {
+			mt.Errorf("last command = %s, want the release after the renewals stop", names[len(names)-1])
+		}
+	})
+}
+
+func TestMigrationLockReleasedAfterFailure(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("fn fails", func(mt *mtest.T) {
+		m := newTestMigrator(mt)
+		mt.AddMockResponses(lockTaken, mtest.CreateSuccessResponse())
+		failed := errors.New("migration failed")
+
+		if err := m.locked(context.Background(), func(context.Context) error { return failed }); !errors.Is(err, failed) {
+			mt.Errorf("err = %v, want the migration's", err)
+		}
+		if _, _, deletes := lockCommands(mt); len(deletes) != 1 {
+			mt.Errorf("%d releases, want 1", len(deletes))
+		}
+	})
+
+	mt.Run("release fails", func(mt *mtest.T) {
+		m := newTestMigrator(mt)
+		mt.AddMockResponses(lockTaken, mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 91, Name: "ShutdownInProgress", Message: "shutting down"}))
+
+		err := m.locked(context.Background(), func(context.Context) error { return nil })
+		if err == nil || !strings.Contains(err.Error(), "release migrations lock") {
+			mt.Errorf("err = %v", err)
+		}
+	})
+}
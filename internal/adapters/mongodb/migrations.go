@@ -0,0 +1,351 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	domainEmployee "github.com/rohitashk/golang-rest-api/internal/domain/employee"
+	"github.com/rohitashk/golang-rest-api/internal/domain/tenant"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Migrations is every schema change, oldest first. An applied migration is
+// never edited; a new index, field rename or backfill is a new entry with
+// the next version.
+func Migrations() []Migration {
+	return []Migration{
+		{
+			Version: 1,
+			Name:    "baseline indexes",
+			Up:      createIndexes(baselineIndexes),
+			Down:    dropIndexes(baselineIndexes),
+		},
+		{
+			Version: 2,
+			Name:    "tenant scoped records",
+			Up:      tenantScopeUp,
+			Down:    tenantScopeDown,
+		},
+		{
+			Version: 3,
+			Name:    "custom fields",
+			Up:      createIndexes(customFieldIndexes),
+			Down:    dropIndexes(customFieldIndexes),
+		},
+		{
+			Version: 4,
+			Name:    "employee search",
+			Up:      searchUp,
+			Down:    searchDown,
+		},
+		{
+			Version: 5,
+			Name:    "duplicate keys",
+			Up:      duplicateKeysUp,
+			Down:    duplicateKeysDown,
+		},
+		{
+			Version: 6,
+			Name:    "employee search grams",
+			Up:      searchGramsUp,
+			Down:    searchGramsDown,
+		},
+	}
+}
+
+// baselineIndexes are the indexes the service created at boot before it had
+// migrations. Creating an index that exists with the same options is a
+// no-op, so databases from that time migrate cleanly.
+var baselineIndexes = map[string][]mongo.IndexModel{
+	"employees": {
+		{
+			Keys:    bson.D{{Key: "email", Value: 1}},
+			Options: options.Index().SetUnique(true).SetName("uniq_email"),
+		},
+		{
+			Keys:    bson.D{{Key: "department", Value: 1}, {Key: "status", Value: 1}},
+			Options: options.Index().SetName("dept_status"),
+		},
+		{
+			Keys:    bson.D{{Key: "manager_id", Value: 1}},
+			Options: options.Index().SetSparse(true).SetName("manager_id"),
+		},
+	},
+	"idempotency_keys": {
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0).SetName("ttl_expires_at"),
+		},
+	},
+	"outbox": {
+		{
+			Keys:    bson.D{{Key: "published_at", Value: 1}, {Key: "available_at", Value: 1}, {Key: "occurred_at", Value: 1}},
+			Options: options.Index().SetName("pending"),
+		},
+		{
+			Keys:    bson.D{{Key: "aggregate_id", Value: 1}, {Key: "occurred_at", Value: 1}},
+			Options: options.Index().SetName("aggregate_occurred_at"),
+		},
+	},
+	"webhook_deliveries": {
+		{
+			Keys:    bson.D{{Key: "subscription_id", Value: 1}, {Key: "event_id", Value: 1}},
+			Options: options.Index().SetUnique(true).SetName("uniq_subscription_event"),
+		},
+		{
+			Keys:    bson.D{{Key: "status", Value: 1}, {Key: "next_attempt_at", Value: 1}},
+			Options: options.Index().SetName("status_next_attempt_at"),
+		},
+		{
+			Keys:    bson.D{{Key: "subscription_id", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetName("subscription_created_at"),
+		},
+	},
+}
+
+// tenantCollections hold records that belong to a tenant. Records from
+// before tenancy belong to tenant.Default.
+var tenantCollections = []string{"employees", "webhooks", "webhook_deliveries", "outbox"}
+
+// tenantIndexes lead with the tenant, which every query now filters on.
+// Emails are unique per tenant rather than globally.
+var tenantIndexes = map[string][]mongo.IndexModel{
+	"employees": {
+		{
+			Keys:    bson.D{{Key: "tenant", Value: 1}, {Key: "email", Value: 1}},
+			Options: options.Index().SetUnique(true).SetName("uniq_tenant_email"),
+		},
+		{
+			Keys:    bson.D{{Key: "tenant", Value: 1}, {Key: "department", Value: 1}, {Key: "status", Value: 1}},
+			Options: options.Index().SetName("tenant_dept_status"),
+		},
+	},
+	"webhooks": {
+		{
+			Keys:    bson.D{{Key: "tenant", Value: 1}, {Key: "created_at", Value: 1}},
+			Options: options.Index().SetName("tenant_created_at"),
+		},
+	},
+}
+
+// replacedByTenantIndexes are the baseline indexes tenantIndexes supersede.
+var replacedByTenantIndexes = map[string][]mongo.IndexModel{
+	"employees": {baselineIndexes["employees"][0], baselineIndexes["employees"][1]},
+}
+
+func tenantScopeUp(ctx context.Context, db *mongo.Database) error {
+	for _, coll := range tenantCollections {
+		_, err := db.Collection(coll).UpdateMany(ctx,
+			bson.M{"tenant": bson.M{"$exists": false}},
+			bson.M{"$set": bson.M{"tenant": tenant.Default}})
+		if err != nil {
+			return fmt.Errorf("assign %s to tenant %s: %w", coll, tenant.Default, err)
+		}
+	}
+	// The new unique index is built before the global one goes, so emails
+	// stay unique throughout.
+	if err := createIndexes(tenantIndexes)(ctx, db); err != nil {
+		return err
+	}
+	return dropIndexes(replacedByTenantIndexes)(ctx, db)
+}
+
+// tenantScopeDown restores the global indexes but keeps the tenant fields.
+// It fails while two tenants share an email.
+func tenantScopeDown(ctx context.Context, db *mongo.Database) error {
+	if err := createIndexes(replacedByTenantIndexes)(ctx, db); err != nil {
+		return err
+	}
+	return dropIndexes(tenantIndexes)(ctx, db)
+}
+
+// customFieldIndexes keep definition keys unique per tenant and let
+// employees be filtered on any custom field.
+var customFieldIndexes = map[string][]mongo.IndexModel{
+	"custom_fields": {
+		{
+			Keys:    bson.D{{Key: "tenant", Value: 1}, {Key: "key", Value: 1}},
+			Options: options.Index().SetUnique(true).SetName("uniq_tenant_key"),
+		},
+	},
+	"employees": {
+		{
+			Keys:    bson.D{{Key: "custom_fields.$**", Value: 1}},
+			Options: options.Index().SetName("custom_fields"),
+		},
+	},
+}
+
+// searchIndexes serve GET /v1/employees/search: the text index finds whole
+// words, search_words the starts of words. Both lead with the tenant, so a
+// search only reads its tenant's entries. The text index does not stem or
+// drop stop words, as names are not prose.
+var searchIndexes = map[string][]mongo.IndexModel{
+	"employees": {
+		{
+			Keys: bson.D{
+				{Key: "tenant", Value: 1},
+				{Key: "first_name", Value: "text"},
+				{Key: "last_name", Value: "text"},
+				{Key: "email", Value: "text"},
+				{Key: "department", Value: "text"},
+				{Key: "position", Value: "text"},
+			},
+			Options: options.Index().SetName("search_text").SetDefaultLanguage("none").SetWeights(bson.D{
+				{Key: "first_name", Value: 10},
+				{Key: "last_name", Value: 10},
+				{Key: "email", Value: 5},
+				{Key: "department", Value: 2},
+				{Key: "position", Value: 2},
+			}),
+		},
+		{
+			Keys:    bson.D{{Key: "tenant", Value: 1}, {Key: "search_words", Value: 1}},
+			Options: options.Index().SetName("tenant_search_words"),
+		},
+	},
+}
+
+// backfillBatch is how many employees get a derived field per bulk write.
+const backfillBatch = 500
+
+func searchUp(ctx context.Context, db *mongo.Database) error {
+	if err := backfillEmployees(ctx, db, "search_words", func(e *domainEmployee.Employee) any { return searchWords(e) }); err != nil {
+		return err
+	}
+	return createIndexes(searchIndexes)(ctx, db)
+}
+
+func searchDown(ctx context.Context, db *mongo.Database) error {
+	if err := dropIndexes(searchIndexes)(ctx, db); err != nil {
+		return err
+	}
+	return unsetEmployeeField(ctx, db, "search_words")
+}
+
+// duplicateKeyIndexes let DuplicateBlocks find the employees sharing a key.
+var duplicateKeyIndexes = map[string][]mongo.IndexModel{
+	"employees": {
+		{
+			Keys:    bson.D{{Key: "tenant", Value: 1}, {Key: "duplicate_keys", Value: 1}},
+			Options: options.Index().SetName("tenant_duplicate_keys"),
+		},
+	},
+}
+
+func duplicateKeysUp(ctx context.Context, db *mongo.Database) error {
+	err := backfillEmployees(ctx, db, "duplicate_keys", func(e *domainEmployee.Employee) any { return domainEmployee.DuplicateKeys(e) })
+	if err != nil {
+		return err
+	}
+	return createIndexes(duplicateKeyIndexes)(ctx, db)
+}
+
+func duplicateKeysDown(ctx context.Context, db *mongo.Database) error {
+	if err := dropIndexes(duplicateKeyIndexes)(ctx, db); err != nil {
+		return err
+	}
+	return unsetEmployeeField(ctx, db, "duplicate_keys")
+}
+
+// searchGramIndexes let Search find names with typos, wherever they are.
+var searchGramIndexes = map[string][]mongo.IndexModel{
+	"employees": {
+		{
+			Keys:    bson.D{{Key: "tenant", Value: 1}, {Key: "search_grams", Value: 1}},
+			Options: options.Index().SetName("tenant_search_grams"),
+		},
+	},
+}
+
+func searchGramsUp(ctx context.Context, db *mongo.Database) error {
+	if err := backfillEmployees(ctx, db, "search_grams", func(e *domainEmployee.Employee) any { return searchGrams(e) }); err != nil {
+		return err
+	}
+	return createIndexes(searchGramIndexes)(ctx, db)
+}
+
+func searchGramsDown(ctx context.Context, db *mongo.Database) error {
+	if err := dropIndexes(searchGramIndexes)(ctx, db); err != nil {
+		return err
+	}
+	return unsetEmployeeField(ctx, db, "search_grams")
+}
+
+// backfillEmployees sets field to value(e) on every employee without it.
+func backfillEmployees(ctx context.Context, db *mongo.Database, field string, value func(*domainEmployee.Employee) any) error {
+	coll := db.Collection("employees")
+	cur, err := coll.Find(ctx, bson.M{field: bson.M{"$exists": false}})
+	if err != nil {
+		return fmt.Errorf("find employees without %s: %w", field, err)
+	}
+	defer cur.Close(ctx)
+
+	var batch []mongo.WriteModel
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if _, err := coll.BulkWrite(ctx, batch, options.BulkWrite().SetOrdered(false)); err != nil {
+			return fmt.Errorf("set employee %s: %w", field, err)
+		}
+		batch = batch[:0]
+		return nil
+	}
+	for cur.Next(ctx) {
+		var doc employeeDoc
+		if err := cur.Decode(&doc); err != nil {
+			return fmt.Errorf("decode employee: %w", err)
+		}
+		batch = append(batch, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": doc.ID}).
+			SetUpdate(bson.M{"$set": bson.M{field: value(toDomain(doc))}}))
+		if len(batch) == backfillBatch {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := cur.Err(); err != nil {
+		return fmt.Errorf("iterate employees: %w", err)
+	}
+	return flush()
+}
+
+func unsetEmployeeField(ctx context.Context, db *mongo.Database, field string) error {
+	_, err := db.Collection("employees").UpdateMany(ctx,
+		bson.M{field: bson.M{"$exists": true}},
+		bson.M{"$unset": bson.M{field: ""}})
+	if err != nil {
+		return fmt.Errorf("remove employee %s: %w", field, err)
+	}
+	return nil
+}
+
+func createIndexes(indexes map[string][]mongo.IndexModel) func(context.Context, *mongo.Database) error {
+	return func(ctx context.Context, db *mongo.Database) error {
+		for coll, models := range indexes {
+			if _, err := db.Collection(coll).Indexes().CreateMany(ctx, models); err != nil {
+				return fmt.Errorf("create indexes on %s: %w", coll, err)
+			}
+		}
+		return nil
+	}
+}
+
+func dropIndexes(indexes map[string][]mongo.IndexModel) func(context.Context, *mongo.Database) error {
+	return func(ctx context.Context, db *mongo.Database) error {
+		for coll, models := range indexes {
+			for _, model := range models {
+				name := *model.Options.Name
+				_, err := db.Collection(coll).Indexes().DropOne(ctx, name)
+				if err != nil && !isIndexNotFound(err) {
+					return fmt.Errorf("drop index %s on %s: %w", name, coll, err)
+				}
+			}
+		}
+		return nil
+	}
+}
@@ -26,3 +26,10 @@ func isDuplicateKey(err error) bool {
 
 	return false
 }
+
+// isIndexNotFound lets a Down that failed halfway run again.
+func isIndexNotFound(err error) bool {
+	var cmdErr mongo.CommandError
+	// IndexNotFound, or NamespaceNotFound when the collection is gone.
+	return errors.As(err, &cmdErr) && (cmdErr.Code == 27 || cmdErr.Code == 26)
+}
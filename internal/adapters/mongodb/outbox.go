@@ -0,0 +1,145 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rohitashk/golang-rest-api/internal/domain"
+	"github.com/rohitashk/golang-rest-api/internal/domain/event"
+	"github.com/rohitashk/golang-rest-api/internal/domain/tenant"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Outbox struct {
+	coll *mongo.Collection
+	now  func() time.Time
+}
+
+func NewOutbox(db *mongo.Database) *Outbox {
+	return &Outbox{coll: db.Collection("outbox"), now: time.Now}
+}
+
+type outboxDoc struct {
+	ID          string     `bson:"_id"`
+	Tenant      string     `bson:"tenant"`
+	Type        string     `bson:"type"`
+	AggregateID string     `bson:"aggregate_id"`
+	OccurredAt  time.Time  `bson:"occurred_at"`
+	Payload     []byte     `bson:"payload"`
+	Attempts    int        `bson:"attempts"`
+	AvailableAt time.Time  `bson:"available_at"` // when the event may next be claimed
+	PublishedAt *time.Time `bson:"published_at"`
+	LastError   string     `bson:"last_error,omitempty"`
+}
+
+func (o *Outbox) Append(ctx context.Context, events ...event.Envelope) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	docs := make([]any, 0, len(events))
+	for _, e := range events {
+		docs = append(docs, outboxDoc{
+			ID:          e.ID,
+			Tenant:      e.Tenant,
+			Type:        string(e.Type),
+			AggregateID: e.AggregateID,
+			OccurredAt:  e.OccurredAt,
+			Payload:     e.Payload,
+			AvailableAt: e.OccurredAt,
+		})
+	}
+
+	if _, err := o.coll.InsertMany(ctx, docs); err != nil {
+		return domain.Internal("failed to append events", err)
+	}
+	return nil
+}
+
+func (o *Outbox) Claim(ctx context.Context, limit int, lease time.Duration) ([]event.Envelope, error) {
+	now := o.now().UTC()
+	filter := bson.M{"published_at": nil, "available_at": bson.M{"$lte": now}}
+	update := bson.M{
+		"$set": bson.M{"available_at": now.Add(lease)},
+		"$inc": bson.M{"attempts": 1},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "occurred_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var out []event.Envelope
+	for len(out) < limit {
+		var doc outboxDoc
+		err := o.coll.FindOneAndUpdate(ctx, filter, update, opts).Decode(&doc)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			break
+		}
+		if err != nil {
+			return out, domain.Internal("failed to claim events", err)
+		}
+		out = append(out, toEnvelope(doc))
+	}
+	return out, nil
+}
+
+func (o *Outbox) MarkPublished(ctx context.Context, id string) error {
+	_, err := o.coll.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$set":   bson.M{"published_at": o.now().UTC()},
+		"$unset": bson.M{"last_error": ""},
+	})
+	if err != nil {
+		return domain.Internal("failed to mark event published", err)
+	}
+	return nil
+}
+
+func (o *Outbox) MarkFailed(ctx context.Context, id string, reason string, retryAt time.Time) error {
+	_, err := o.coll.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{
+		"available_at": retryAt.UTC(),
+		"last_error":   reason,
+	}})
+	if err != nil {
+		return domain.Internal("failed to mark event failed", err)
+	}
+	return nil
+}
+
+// History returns the events of one aggregate of the tenant in ctx, oldest
+// first, whether or not they have been published. Up to limit are returned;
+// 0 means all.
+func (o *Outbox) History(ctx context.Context, aggregateID string, limit int64) ([]event.Envelope, error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "occurred_at", Value: 1}}).SetLimit(limit)
+	cur, err := o.coll.Find(ctx, bson.M{"tenant": tenantID, "aggregate_id": aggregateID}, opts)
+	if err != nil {
+		return nil, domain.Internal("failed to read events", err)
+	}
+	var docs []outboxDoc
+	if err := cur.All(ctx, &docs); err != nil {
+		return nil, domain.Internal("failed to read events", err)
+	}
+
+	out := make([]event.Envelope, 0, len(docs))
+	for _, doc := range docs {
+		out = append(out, toEnvelope(doc))
+	}
+	return out, nil
+}
+
+func toEnvelope(doc outboxDoc) event.Envelope {
+	return event.Envelope{
+		ID:          doc.ID,
+		Tenant:      doc.Tenant,
+		Type:        event.Type(doc.Type),
+		AggregateID: doc.AggregateID,
+		OccurredAt:  doc.OccurredAt,
+		Payload:     doc.Payload,
+		Attempts:    doc.Attempts,
+	}
+}
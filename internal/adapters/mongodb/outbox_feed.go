@@ -0,0 +1,62 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+
+	"github.com/rohitashk/golang-rest-api/internal/domain"
+	"github.com/rohitashk/golang-rest-api/internal/domain/event"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// OutboxFeed streams events from a change stream on the outbox collection,
+// so it sees events written by every instance. Cursors are resume tokens.
+type OutboxFeed struct {
+	coll *mongo.Collection
+}
+
+func NewOutboxFeed(db *mongo.Database) *OutboxFeed {
+	return &OutboxFeed{coll: db.Collection("outbox")}
+}
+
+func (f *OutboxFeed) Subscribe(ctx context.Context, cursor string) (<-chan event.Notification, error) {
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: bson.M{"operationType": "insert"}}}}
+	opts := options.ChangeStream()
+	if cursor != "" {
+		opts.SetResumeAfter(bson.M{"_data": cursor})
+	}
+
+	cs, err := f.coll.Watch(ctx, pipeline, opts)
+	if err != nil {
+		var cmdErr mongo.CommandError
+		if cursor != "" && errors.As(err, &cmdErr) {
+			return nil, event.ErrCursorExpired
+		}
+		return nil, domain.Internal("failed to watch outbox", err)
+	}
+
+	ch := make(chan event.Notification)
+	go func() {
+		defer close(ch)
+		defer cs.Close(context.Background())
+
+		for cs.Next(ctx) {
+			var change struct {
+				FullDocument outboxDoc `bson:"fullDocument"`
+			}
+			if err := cs.Decode(&change); err != nil {
+				return
+			}
+			token, _ := cs.ResumeToken().Lookup("_data").StringValueOK()
+
+			select {
+			case ch <- event.Notification{Cursor: token, Event: toEnvelope(change.FullDocument)}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
@@ -0,0 +1,383 @@
+package mongodb
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SchemaMode is how strictly a collection's $jsonSchema validator is
+// applied.
+type SchemaMode string
+
+const (
+	// SchemaOff leaves the collection's validator alone.
+	SchemaOff SchemaMode = "off"
+	// SchemaWarn accepts non-conforming writes; the server logs them.
+	SchemaWarn SchemaMode = "warn"
+	// SchemaEnforce rejects them, including updates to documents that
+	// already do not conform.
+	SchemaEnforce SchemaMode = "enforce"
+)
+
+// fieldSchema is one document field as described by its struct tags: the
+// bson name, and constraints in a schema tag such as
+// `schema:"minLength=1,maxLength=100"` or `schema:"enum=active|inactive"`;
+// `schema:"values=string|number"` limits the values of a map. Fields without
+// omitempty are required.
+type fieldSchema struct {
+	name      string
+	bsonType  string
+	required  bool
+	minLength *int
+	maxLength *int
+	minimum   *float64
+	maximum   *float64
+	enum      []string
+	values    []string // bson types of a map's values
+}
+
+var (
+	objectIDType = reflect.TypeOf(primitive.ObjectID{})
+	timeType     = reflect.TypeOf(time.Time{})
+)
+
+// schemaOf derives the fields of a document struct. A tag it cannot read is
+// a programming error and panics.
+func schemaOf(doc any) []fieldSchema {
+	t := reflect.TypeOf(doc)
+	var out []fieldSchema
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, opts, _ := strings.Cut(f.Tag.Get("bson"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		fs := fieldSchema{name: name, required: !strings.Contains(opts, "omitempty") && name != "_id"}
+
+		switch {
+		case f.Type == objectIDType:
+			fs.bsonType = "objectId"
+		case f.Type == timeType:
+			fs.bsonType = "date"
+		case f.Type.Kind() == reflect.String:
+			fs.bsonType = "string"
+		case f.Type.Kind() == reflect.Map:
+			fs.bsonType = "object"
+		case f.Type.Kind() == reflect.Slice:
+			fs.bsonType = "array"
+		case f.Type.Kind() == reflect.Float64, f.Type.Kind() == reflect.Int, f.Type.Kind() == reflect.Int64:
+			// Any numeric type, so documents written by other clients
+			// (e.g. an int salary from the shell) still conform.
+			fs.bsonType = "number"
+		default:
+			panic(fmt.Sprintf("schema: field %s: no bson type for %s", f.Name, f.Type))
+		}
+
+		for _, c := range strings.Split(f.Tag.Get("schema"), ",") {
+			if c == "" {
+				continue
+			}
+			key, val, _ := strings.Cut(c, "=")
+			switch key {
+			case "minLength", "maxLength":
+				n, err := strconv.Atoi(val)
+				if err != nil {
+					panic(fmt.Sprintf("schema: field %s: %s=%q", f.Name, key, val))
+				}
+				if key == "minLength" {
+					fs.minLength = &n
+				} else {
+					fs.maxLength = &n
+				}
+			case "minimum", "maximum":
+				v, err := strconv.ParseFloat(val, 64)
+				if err != nil {
+					panic(fmt.Sprintf("schema: field %s: %s=%q", f.Name, key, val))
+				}
+				if key == "minimum" {
+					fs.minimum = &v
+				} else {
+					fs.maximum = &v
+				}
+			case "enum":
+				fs.enum = strings.Split(val, "|")
+			case "values":
+				fs.values = strings.Split(val, "|")
+				for _, v := range fs.values {
+					if _, ok := valueTypes[v]; !ok || fs.bsonType != "object" {
+						panic(fmt.Sprintf("schema: field %s: values=%q", f.Name, val))
+					}
+				}
+			default:
+				panic(fmt.Sprintf("schema: field %s: unknown constraint %q", f.Name, key))
+			}
+		}
+		out = append(out, fs)
+	}
+	return out
+}
+
+// jsonSchema renders fields as a $jsonSchema. bson.D keeps the key order
+// stable, so the result can be compared with the installed validator.
+func jsonSchema(fields []fieldSchema) bson.D {
+	required := bson.A{}
+	props := bson.D{}
+	for _, f := range fields {
+		if f.required {
+			required = append(required, f.name)
+		}
+		p := bson.D{{Key: "bsonType", Value: f.bsonType}}
+		if f.minLength != nil {
+			p = append(p, bson.E{Key: "minLength", Value: int32(*f.minLength)})
+		}
+		if f.maxLength != nil {
+			p = append(p, bson.E{Key: "maxLength", Value: int32(*f.maxLength)})
+		}
+		if f.minimum != nil {
+			p = append(p, bson.E{Key: "minimum", Value: *f.minimum})
+		}
+		if f.maximum != nil {
+			p = append(p, bson.E{Key: "maximum", Value: *f.maximum})
+		}
+		if f.enum != nil {
+			enum := bson.A{}
+			for _, v := range f.enum {
+				enum = append(enum, v)
+			}
+			p = append(p, bson.E{Key: "enum", Value: enum})
+		}
+		if f.values != nil {
+			types := bson.A{}
+			for _, v := range f.values {
+				types = append(types, v)
+			}
+			p = append(p, bson.E{Key: "additionalProperties", Value: bson.D{{Key: "bsonType", Value: types}}})
+		}
+		props = append(props, bson.E{Key: f.name, Value: p})
+	}
+	// Other fields are allowed, so a new field can be written before the
+	// validator that describes it is installed.
+	return bson.D{{Key: "$jsonSchema", Value: bson.D{
+		{Key: "bsonType", Value: "object"},
+		{Key: "required", Value: required},
+		{Key: "properties", Value: props},
+	}}}
+}
+
+// violations explains why doc does not match fields, in the terms of the
+// validator; the server only says that it does not.
+func violations(fields []fieldSchema, doc bson.Raw) []string {
+	var out []string
+	for _, f := range fields {
+		v, err := doc.LookupErr(f.name)
+		if err != nil {
+			if f.required {
+				out = append(out, f.name+" is missing")
+			}
+			continue
+		}
+
+		switch f.bsonType {
+		case "objectId":
+			if v.Type != bson.TypeObjectID {
+				out = append(out, fmt.Sprintf("%s is a %s, want objectId", f.name, v.Type))
+			}
+		case "date":
+			if v.Type != bson.TypeDateTime {
+				out = append(out, fmt.Sprintf("%s is a %s, want date", f.name, v.Type))
+			}
+		case "object":
+			if v.Type != bson.TypeEmbeddedDocument {
+				out = append(out, fmt.Sprintf("%s is a %s, want object", f.name, v.Type))
+				continue
+			}
+			if f.values == nil {
+				continue
+			}
+			elems, _ := v.Document().Elements()
+			for _, e := range elems {
+				if !oneOfTypes(f.values, e.Value()) {
+					out = append(out, fmt.Sprintf("%s.%s is a %s, want %s", f.name, e.Key(), e.Value().Type, strings.Join(f.values, " or ")))
+				}
+			}
+		case "array":
+			if v.Type != bson.TypeArray {
+				out = append(out, fmt.Sprintf("%s is a %s, want array", f.name, v.Type))
+			}
+		case "number":
+			n, ok := number(v)
+			if !ok {
+				out = append(out, fmt.Sprintf("%s is a %s, want number", f.name, v.Type))
+				continue
+			}
+			if f.minimum != nil && n < *f.minimum {
+				out = append(out, fmt.Sprintf("%s is %v, below %v", f.name, n, *f.minimum))
+			}
+			if f.maximum != nil && n > *f.maximum {
+				out = append(out, fmt.Sprintf("%s is %v, above %v", f.name, n, *f.maximum))
+			}
+		case "string":
+			s, ok := v.StringValueOK()
+			if !ok {
+				out = append(out, fmt.Sprintf("%s is a %s, want string", f.name, v.Type))
+				continue
+			}
+			n := utf8.RuneCountInString(s)
+			if f.minLength != nil && n < *f.minLength {
+				out = append(out, fmt.Sprintf("%s is shorter than %d", f.name, *f.minLength))
+			}
+			if f.maxLength != nil && n > *f.maxLength {
+				out = append(out, fmt.Sprintf("%s is longer than %d", f.name, *f.maxLength))
+			}
+			if f.enum != nil && !contains(f.enum, s) {
+				out = append(out, fmt.Sprintf("%s is %q, want one of %s", f.name, s, strings.Join(f.enum, ", ")))
+			}
+		}
+	}
+	return out
+}
+
+// valueTypes are the bson types a map's values may be limited to.
+var valueTypes = map[string]func(bson.RawValue) bool{
+	"string": func(v bson.RawValue) bool { return v.Type == bson.TypeString },
+	"number": func(v bson.RawValue) bool { _, ok := number(v); return ok },
+	"bool":   func(v bson.RawValue) bool { return v.Type == bson.TypeBoolean },
+}
+
+func oneOfTypes(types []string, v bson.RawValue) bool {
+	for _, t := range types {
+		if valueTypes[t](v) {
+			return true
+		}
+	}
+	return false
+}
+
+func number(v bson.RawValue) (float64, bool) {
+	switch v.Type {
+	case bson.TypeDouble:
+		return v.Double(), true
+	case bson.TypeInt32:
+		return float64(v.Int32()), true
+	case bson.TypeInt64:
+		return float64(v.Int64()), true
+	case bson.TypeDecimal128:
+		f, err := strconv.ParseFloat(v.Decimal128().String(), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ensureValidator installs validator on coll at mode, creating the
+// collection if needed, and reports whether anything changed.
+func ensureValidator(ctx context.Context, coll *mongo.Collection, validator bson.D, mode SchemaMode) (bool, error) {
+	if mode == SchemaOff {
+		return false, nil
+	}
+	action := "error"
+	if mode == SchemaWarn {
+		action = "warn"
+	}
+
+	specs, err := coll.Database().ListCollectionSpecifications(ctx, bson.M{"name": coll.Name()})
+	if err != nil {
+		return false, fmt.Errorf("read %s validator: %w", coll.Name(), err)
+	}
+	if len(specs) == 0 {
+		err := coll.Database().RunCommand(ctx, bson.D{
+			{Key: "create", Value: coll.Name()},
+			{Key: "validator", Value: validator},
+			{Key: "validationLevel", Value: "strict"},
+			{Key: "validationAction", Value: action},
+		}).Err()
+		if err != nil {
+			return false, fmt.Errorf("create %s: %w", coll.Name(), err)
+		}
+		return true, nil
+	}
+
+	var current struct {
+		Validator        bson.Raw `bson:"validator"`
+		ValidationLevel  string   `bson:"validationLevel"`
+		ValidationAction string   `bson:"validationAction"`
+	}
+	if specs[0].Options != nil {
+		if err := bson.Unmarshal(specs[0].Options, &current); err != nil {
+			return false, fmt.Errorf("read %s validator: %w", coll.Name(), err)
+		}
+	}
+	want, err := bson.Marshal(validator)
+	if err != nil {
+		return false, err
+	}
+	if bytes.Equal(current.Validator, want) && current.ValidationLevel == "strict" && current.ValidationAction == action {
+		return false, nil
+	}
+
+	err = coll.Database().RunCommand(ctx, bson.D{
+		{Key: "collMod", Value: coll.Name()},
+		{Key: "validator", Value: validator},
+		{Key: "validationLevel", Value: "strict"},
+		{Key: "validationAction", Value: action},
+	}).Err()
+	if err != nil {
+		return false, fmt.Errorf("update %s validator: %w", coll.Name(), err)
+	}
+	return true, nil
+}
+
+// Nonconforming is a stored document that fails the collection's schema.
+type Nonconforming struct {
+	ID      string
+	Reasons []string
+}
+
+// nonconforming finds up to limit documents of coll that fail validator,
+// with the reasons fields gives for each, and counts all of them.
+func nonconforming(ctx context.Context, coll *mongo.Collection, validator bson.D, fields []fieldSchema, limit int64) ([]Nonconforming, int64, error) {
+	filter := bson.M{"$nor": bson.A{validator}}
+	total, err := coll.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("count nonconforming %s: %w", coll.Name(), err)
+	}
+	if total == 0 || limit == 0 {
+		return nil, total, nil
+	}
+
+	cur, err := coll.Find(ctx, filter, options.Find().SetLimit(limit))
+	if err != nil {
+		return nil, 0, fmt.Errorf("find nonconforming %s: %w", coll.Name(), err)
+	}
+	defer cur.Close(ctx)
+
+	var out []Nonconforming
+	for cur.Next(ctx) {
+		n := Nonconforming{ID: fmt.Sprint(cur.Current.Lookup("_id"))}
+		if oid, ok := cur.Current.Lookup("_id").ObjectIDOK(); ok {
+			n.ID = oid.Hex()
+		}
+		n.Reasons = violations(fields, cur.Current)
+		out = append(out, n)
+	}
+	return out, total, cur.Err()
+}
@@ -0,0 +1,234 @@
+package mongodb
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func intp(n int) *int           { return &n }
+func floatp(n float64) *float64 { return &n }
+
+func TestSchemaOf(t *testing.T) {
+	fields := map[string]fieldSchema{}
+	for _, f := range employeeSchema {
+		fields[f.name] = f
+	}
+	tests := []fieldSchema{
+		{name: "_id", bsonType: "objectId"},
+		{name: "tenant", bsonType: "string", required: true, minLength: intp(1), maxLength: intp(63)},
+		{name: "phone", bsonType: "string", maxLength: intp(16)},
+		{name: "salary", bsonType: "number", required: true, minimum: floatp(0), maximum: floatp(1000000000)},
+		{name: "status", bsonType: "string", required: true, enum: []string{"active", "inactive"}},
+		{name: "manager_id", bsonType: "objectId"},
+		{name: "custom_fields", bsonType: "object", values: []string{"string", "number", "bool"}},
+		{name: "search_words", bsonType: "array"},
+		{name: "created_at", bsonType: "date", required: true},
+	}
+	for _, want := range tests {
+		if got := fields[want.name]; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s = %+v, want %+v", want.name, got, want)
+		}
+	}
+}
+
+func TestSchemaOfRejectsBadTags(t *testing.T) {
+	tests := map[string]any{
+		"type": struct {
+			C chan int `bson:"c"`
+		}{},
+		"bound": struct {
+			N string `bson:"n" schema:"maxLength=ten"`
+		}{},
+		"constraint": struct {
+			N string `bson:"n" schema:"pattern=x"`
+		}{},
+		"values": struct {
+			M map[string]any `bson:"m" schema:"values=date"`
+		}{},
+		"not a map": struct {
+			N string `bson:"n" schema:"values=string"`
+		}{},
+	}
+	for name, doc := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: schemaOf did not panic", name)
+				}
+			}()
+			schemaOf(doc)
+		}()
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	type doc struct {
+		ID     primitive.ObjectID `bson:"_id,omitempty"`
+		Name   string             `bson:"name" schema:"minLength=1,maxLength=10"`
+		Score  float64            `bson:"score,omitempty" schema:"minimum=0,maximum=5"`
+		Kind   string             `bson:"kind" schema:"enum=a|b"`
+		Extras map[string]any     `bson:"extras,omitempty" schema:"values=string|bool"`
+		Skip   string             `bson:"-"`
+	}
+	want := bson.D{{Key: "$jsonSchema", Value: bson.D{
+		{Key: "bsonType", Value: "object"},
+		{Key: "required", Value: bson.A{"name", "kind"}},
+		{Key: "properties", Value: bson.D{
+			{Key: "_id", Value: bson.D{{Key: "bsonType", Value: "objectId"}}},
+			{Key: "name", Value: bson.D{{Key: "bsonType", Value: "string"}, {Key: "minLength", Value: int32(1)}, {Key: "maxLength", Value: int32(10)}}},
+			{Key: "score", Value: bson.D{{Key: "bsonType", Value: "number"}, {Key: "minimum", Value: 0.0}, {Key: "maximum", Value: 5.0}}},
+			{Key: "kind", Value: bson.D{{Key: "bsonType", Value: "string"}, {Key: "enum", Value: bson.A{"a", "b"}}}},
+			{Key: "extras", Value: bson.D{{Key: "bsonType", Value: "object"}, {Key: "additionalProperties", Value: bson.D{{Key: "bsonType", Value: bson.A{"string", "bool"}}}}}},
+		}},
+	}}}
+	if got := jsonSchema(schemaOf(doc{})); !reflect.DeepEqual(got, want) {
+		t.Errorf("jsonSchema =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestViolations(t *testing.T) {
+	now := time.Now()
+	valid := bson.D{
+		{Key: "_id", Value: primitive.NewObjectID()},
+		{Key: "tenant", Value: "acme"},
+		{Key: "first_name", Value: "Ada"},
+		{Key: "last_name", Value: "Lovelace"},
+		{Key: "email", Value: "ada@example.com"},
+		{Key: "department", Value: "R&D"},
+		{Key: "position", Value: "Engineer"},
+		{Key: "salary", Value: int32(100)},
+		{Key: "status", Value: "active"},
+		{Key: "custom_fields", Value: bson.D{{Key: "badge", Value: "AB1234"}, {Key: "floor", Value: 3.0}, {Key: "remote", Value: true}}},
+		{Key: "created_at", Value: now},
+		{Key: "updated_at", Value: now},
+	}
+	// with returns valid with key set to v, or removed when v is nil.
+	with := func(key string, v any) bson.D {
+		out := bson.D{}
+		for _, e := range valid {
+			if e.Key != key {
+				out = append(out, e)
+			}
+		}
+		if v != nil {
+			out = append(out, bson.E{Key: key, Value: v})
+		}
+		return out
+	}
+
+	tests := []struct {
+		doc  bson.D
+		want []string
+	}{
+		{valid, nil},
+		{with("tenant", nil), []string{"tenant is missing"}},
+		{with("phone", nil), nil},
+		{with("first_name", ""), []string{"first_name is shorter than 1"}},
+		{with("salary", -1.5), []string{"salary is -1.5, below 0"}},
+		{with("salary", "100"), []string{"salary is a string, want number"}},
+		{with("status", "retired"), []string{`status is "retired", want one of active, inactive`}},
+		{with("manager_id", "6650c0ffee"), []string{"manager_id is a string, want objectId"}},
+		{with("created_at", "yesterday"), []string{"created_at is a string, want date"}},
+		{with("custom_fields", bson.D{{Key: "badge", Value: bson.A{"x"}}, {Key: "floor", Value: int64(3)}}), []string{"custom_fields.badge is a array, want string or number or bool"}},
+		{with("custom_fields", "none"), []string{"custom_fields is a string, want object"}},
+	}
+	for _, tt := range tests {
+		raw, err := bson.Marshal(tt.doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := violations(employeeSchema, raw); !slices.Equal(got, tt.want) {
+			t.Errorf("violations = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+// collectionSpec is the listCollections answer for the employees collection
+// with validator installed at action.
+func collectionSpec(mt *mtest.T, validator bson.D, action string) bson.D {
+	return mtest.CreateCursorResponse(0, mt.DB.Name()+".$cmd.listCollections", mtest.FirstBatch, bson.D{
+		{Key: "name", Value: "employees"},
+		{Key: "type", Value: "collection"},
+		{Key: "options", Value: bson.D{
+			{Key: "validator", Value: validator},
+			{Key: "validationLevel", Value: "strict"},
+			{Key: "validationAction", Value: action},
+		}},
+		{Key: "info", Value: bson.D{{Key: "readOnly", Value: false}}},
+	})
+}
+
+func commandNames(mt *mtest.T) []string {
+	var out []string
+	for _, ev := range mt.GetAllStartedEvents() {
+		out = append(out, ev.CommandName)
+	}
+	return out
+}
+
+func TestEnsureValidator(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("unchanged", func(mt *mtest.T) {
+		mt.AddMockResponses(collectionSpec(mt, employeeValidator, "error"))
+		changed, err := ensureValidator(context.Background(), mt.DB.Collection("employees"), employeeValidator, SchemaEnforce)
+		if err != nil || changed {
+			mt.Errorf("changed = %v, %v, want false", changed, err)
+		}
+		if got := commandNames(mt); !slices.Equal(got, []string{"listCollections"}) {
+			mt.Errorf("commands = %q, want no collMod", got)
+		}
+	})
+
+	mt.Run("action changed", func(mt *mtest.T) {
+		mt.AddMockResponses(collectionSpec(mt, employeeValidator, "error"), mtest.CreateSuccessResponse())
+		changed, err := ensureValidator(context.Background(), mt.DB.Collection("employees"), employeeValidator, SchemaWarn)
+		if err != nil || !changed {
+			mt.Errorf("changed = %v, %v, want true", changed, err)
+		}
+		if got := commandNames(mt); !slices.Equal(got, []string{"listCollections", "collMod"}) {
+			mt.Errorf("commands = %q", got)
+		}
+	})
+
+	mt.Run("schema changed", func(mt *mtest.T) {
+		old := jsonSchema(employeeSchema[:len(employeeSchema)-1])
+		mt.AddMockResponses(collectionSpec(mt, old, "error"), mtest.CreateSuccessResponse())
+		changed, err := ensureValidator(context.Background(), mt.DB.Collection("employees"), employeeValidator, SchemaEnforce)
+		if err != nil || !changed {
+			mt.Errorf("changed = %v, %v, want true", changed, err)
+		}
+		evs := mt.GetAllStartedEvents()
+		if len(evs) != 2 || evs[1].CommandName != "collMod" {
+			mt.Fatalf("commands = %q", commandNames(mt))
+		}
+		if got := evs[1].Command.Lookup("validationAction").StringValue(); got != "error" {
+			mt.Errorf("validationAction = %q", got)
+		}
+	})
+
+	mt.Run("missing", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, mt.DB.Name()+".$cmd.listCollections", mtest.FirstBatch), mtest.CreateSuccessResponse())
+		changed, err := ensureValidator(context.Background(), mt.DB.Collection("employees"), employeeValidator, SchemaEnforce)
+		if err != nil || !changed {
+			mt.Errorf("changed = %v, %v, want true", changed, err)
+		}
+		if got := commandNames(mt); !slices.Equal(got, []string{"listCollections", "create"}) {
+			mt.Errorf("commands = %q", got)
+		}
+	})
+
+	mt.Run("off", func(mt *mtest.T) {
+		changed, err := ensureValidator(context.Background(), mt.DB.Collection("employees"), employeeValidator, SchemaOff)
+		if err != nil || changed || len(mt.GetAllStartedEvents()) != 0 {
+			mt.Errorf("changed = %v, %v, commands %q", changed, err, commandNames(mt))
+		}
+	})
+}
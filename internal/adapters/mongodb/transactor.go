@@ -0,0 +1,44 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+
+	"github.com/rohitashk/golang-rest-api/internal/domain"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Transactor runs functions in a multi-document transaction. Transactions
+// need a replica set or sharded cluster.
+type Transactor struct {
+	client *mongo.Client
+}
+
+func NewTransactor(c *Client) *Transactor {
+	return &Transactor{client: c.client}
+}
+
+func (t *Transactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	// Join the caller's transaction instead of nesting.
+	if mongo.SessionFromContext(ctx) != nil {
+		return fn(ctx)
+	}
+
+	sess, err := t.client.StartSession()
+	if err != nil {
+		return domain.Internal("failed to start session", err)
+	}
+	defer sess.EndSession(context.Background())
+
+	_, err = sess.WithTransaction(ctx, func(sc mongo.SessionContext) (any, error) {
+		return nil, fn(sc)
+	})
+	if err != nil {
+		var derr domain.Error
+		if errors.As(err, &derr) {
+			return err
+		}
+		return domain.Internal("transaction failed", err)
+	}
+	return nil
+}
@@ -0,0 +1,378 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rohitashk/golang-rest-api/internal/domain"
+	"github.com/rohitashk/golang-rest-api/internal/domain/tenant"
+	domainWebhook "github.com/rohitashk/golang-rest-api/internal/domain/webhook"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type WebhookRepository struct {
+	coll *mongo.Collection
+}
+
+func NewWebhookRepository(db *mongo.Database) *WebhookRepository {
+	return &WebhookRepository{coll: db.Collection("webhooks")}
+}
+
+type webhookDoc struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty"`
+	Tenant      string             `bson:"tenant"`
+	URL         string             `bson:"url"`
+	Secret      string             `bson:"secret"`
+	EventTypes  []string           `bson:"event_types"`
+	Description string             `bson:"description,omitempty"`
+	Active      bool               `bson:"active"`
+	CreatedAt   time.Time          `bson:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at"`
+}
+
+func (r *WebhookRepository) Create(ctx context.Context, s *domainWebhook.Subscription) error {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return err
+	}
+	res, err := r.coll.InsertOne(ctx, webhookDoc{
+		Tenant:      tenantID,
+		URL:         s.URL,
+		Secret:      s.Secret,
+		EventTypes:  s.EventTypes,
+		Description: s.Description,
+		Active:      s.Active,
+		CreatedAt:   s.CreatedAt,
+		UpdatedAt:   s.UpdatedAt,
+	})
+	if err != nil {
+		return domain.Internal("failed to create webhook", err)
+	}
+
+	oid, ok := res.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return domain.Internal("failed to parse inserted id", errors.New("unexpected inserted id type"))
+	}
+	s.ID = oid.Hex()
+	return nil
+}
+
+func (r *WebhookRepository) GetByID(ctx context.Context, id string) (*domainWebhook.Subscription, error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	oid, err := parseObjectID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc webhookDoc
+	if err := r.coll.FindOne(ctx, bson.M{"_id": oid, "tenant": tenantID}).Decode(&doc); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, domain.Internal("failed to fetch webhook", err)
+	}
+	return toSubscription(doc), nil
+}
+
+func (r *WebhookRepository) List(ctx context.Context) ([]domainWebhook.Subscription, error) {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cur, err := r.coll.Find(ctx, bson.M{"tenant": tenantID}, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, domain.Internal("failed to list webhooks", err)
+	}
+	defer cur.Close(ctx)
+
+	var out []domainWebhook.Subscription
+	for cur.Next(ctx) {
+		var doc webhookDoc
+		if err := cur.Decode(&doc); err != nil {
+			return nil, domain.Internal("failed to decode webhook", err)
+		}
+		out = append(out, *toSubscription(doc))
+	}
+	if err := cur.Err(); err != nil {
+		return nil, domain.Internal("failed to iterate webhooks", err)
+	}
+	return out, nil
+}
+
+func (r *WebhookRepository) Update(ctx context.Context, s *domainWebhook.Subscription) error {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return err
+	}
+	oid, err := parseObjectID(s.ID)
+	if err != nil {
+		return err
+	}
+
+	res, err := r.coll.UpdateOne(ctx, bson.M{"_id": oid, "tenant": tenantID}, bson.M{"$set": bson.M{
+		"url":         s.URL,
+		"event_types": s.EventTypes,
+		"description": s.Description,
+		"active":      s.Active,
+		"updated_at":  s.UpdatedAt,
+	}})
+	if err != nil {
+		return domain.Internal("failed to update webhook", err)
+	}
+	if res.MatchedCount == 0 {
+		return domain.NotFound("webhook not found")
+	}
+	return nil
+}
+
+func (r *WebhookRepository) Delete(ctx context.Context, id string) error {
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		return err
+	}
+	oid, err := parseObjectID(id)
+	if err != nil {
+		return err
+	}
+
+	res, err := r.coll.DeleteOne(ctx, bson.M{"_id": oid, "tenant": tenantID})
+	if err != nil {
+		return domain.Internal("failed to delete webhook", err)
+	}
+	if res.DeletedCount == 0 {
+		return domain.NotFound("webhook not found")
+	}
+	return nil
+}
+
+func toSubscription(doc webhookDoc) *domainWebhook.Subscription {
+	return &domainWebhook.Subscription{
+		ID:          doc.ID.Hex(),
+		URL:         doc.URL,
+		Secret:      doc.Secret,
+		EventTypes:  doc.EventTypes,
+		Description: doc.Description,
+		Active:      doc.Active,
+		CreatedAt:   doc.CreatedAt,
+		UpdatedAt:   doc.UpdatedAt,
+	}
+}
+
+type WebhookDeliveryRepository struct {
+	coll *mongo.Collection
+	now  func() time.Time
+}
+
+func NewWebhookDeliveryRepository(db *mongo.Database) *WebhookDeliveryRepository {
+	return &WebhookDeliveryRepository{coll: db.Collection("webhook_deliveries"), now: time.Now}
+}
+
+type deliveryDoc struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty"`
+	Tenant         string             `bson:"tenant"`
+	SubscriptionID primitive.ObjectID `bson:"subscription_id"`
+	EventID        string             `bson:"event_id"`
+	EventType      string             `bson:"event_type"`
+	Payload        []byte             `bson:"payload"`
+	Status         string             `bson:"status"`
+	Tries          int                `bson:"tries"`
+	Attempts       []attemptDoc       `bson:"attempts"`
+	NextAttemptAt  time.Time          `bson:"next_attempt_at"`
+	CreatedAt      time.Time          `bson:"created_at"`
+	UpdatedAt      time.Time          `bson:"updated_at"`
+}
+
+type attemptDoc struct {
+	At         time.Time `bson:"at"`
+	StatusCode int       `bson:"status_code,omitempty"`
+	Error      string    `bson:"error,omitempty"`
+	DurationMS int64     `bson:"duration_ms"`
+}
+
+func (r *WebhookDeliveryRepository) Create(ctx context.Context, d *domainWebhook.Delivery) error {
+	subID, err := parseObjectID(d.SubscriptionID)
+	if err != nil {
+		return err
+	}
+
+	doc := toDeliveryDoc(d)
+	doc.SubscriptionID = subID
+	res, err := r.coll.InsertOne(ctx, doc)
+	if err != nil {
+		if isDuplicateKey(err) {
+			return nil
+		}
+		return domain.Internal("failed to create webhook delivery", err)
+	}
+
+	if oid, ok := res.InsertedID.(primitive.ObjectID); ok {
+		d.ID = oid.Hex()
+	}
+	return nil
+}
+
+func (r *WebhookDeliveryRepository) GetByID(ctx context.Context, id string) (*domainWebhook.Delivery, error) {
+	oid, err := parseObjectID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc deliveryDoc
+	if err := r.coll.FindOne(ctx, bson.M{"_id": oid}).Decode(&doc); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, domain.Internal("failed to fetch webhook delivery", err)
+	}
+	return toDelivery(doc), nil
+}
+
+func (r *WebhookDeliveryRepository) ListBySubscription(ctx context.Context, subscriptionID string, page domainWebhook.DeliveryPage) ([]domainWebhook.Delivery, int64, error) {
+	subID, err := parseObjectID(subscriptionID)
+	if err != nil {
+		return nil, 0, err
+	}
+	q := bson.M{"subscription_id": subID}
+
+	total, err := r.coll.CountDocuments(ctx, q)
+	if err != nil {
+		return nil, 0, domain.Internal("failed to count webhook deliveries", err)
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(page.Offset).
+		SetLimit(page.Limit)
+	cur, err := r.coll.Find(ctx, q, opts)
+	if err != nil {
+		return nil, 0, domain.Internal("failed to list webhook deliveries", err)
+	}
+	defer cur.Close(ctx)
+
+	out := make([]domainWebhook.Delivery, 0, page.Limit)
+	for cur.Next(ctx) {
+		var doc deliveryDoc
+		if err := cur.Decode(&doc); err != nil {
+			return nil, 0, domain.Internal("failed to decode webhook delivery", err)
+		}
+		out = append(out, *toDelivery(doc))
+	}
+	if err := cur.Err(); err != nil {
+		return nil, 0, domain.Internal("failed to iterate webhook deliveries", err)
+	}
+	return out, total, nil
+}
+
+func (r *WebhookDeliveryRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]domainWebhook.Delivery, error) {
+	now := r.now().UTC()
+	filter := bson.M{"status": string(domainWebhook.DeliveryPending), "next_attempt_at": bson.M{"$lte": now}}
+	update := bson.M{"$set": bson.M{"next_attempt_at": now.Add(lease)}}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var out []domainWebhook.Delivery
+	for len(out) < limit {
+		var doc deliveryDoc
+		err := r.coll.FindOneAndUpdate(ctx, filter, update, opts).Decode(&doc)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			break
+		}
+		if err != nil {
+			return out, domain.Internal("failed to claim webhook deliveries", err)
+		}
+		out = append(out, *toDelivery(doc))
+	}
+	return out, nil
+}
+
+func (r *WebhookDeliveryRepository) Update(ctx context.Context, d *domainWebhook.Delivery) error {
+	oid, err := parseObjectID(d.ID)
+	if err != nil {
+		return err
+	}
+
+	doc := toDeliveryDoc(d)
+	res, err := r.coll.UpdateOne(ctx, bson.M{"_id": oid}, bson.M{"$set": bson.M{
+		"status":          doc.Status,
+		"tries":           doc.Tries,
+		"attempts":        doc.Attempts,
+		"next_attempt_at": doc.NextAttemptAt,
+		"updated_at":      doc.UpdatedAt,
+	}})
+	if err != nil {
+		return domain.Internal("failed to update webhook delivery", err)
+	}
+	if res.MatchedCount == 0 {
+		return domain.NotFound("delivery not found")
+	}
+	return nil
+}
+
+func (r *WebhookDeliveryRepository) DeleteBySubscription(ctx context.Context, subscriptionID string) error {
+	subID, err := parseObjectID(subscriptionID)
+	if err != nil {
+		return err
+	}
+	if _, err := r.coll.DeleteMany(ctx, bson.M{"subscription_id": subID}); err != nil {
+		return domain.Internal("failed to delete webhook deliveries", err)
+	}
+	return nil
+}
+
+func toDeliveryDoc(d *domainWebhook.Delivery) deliveryDoc {
+	attempts := make([]attemptDoc, 0, len(d.Attempts))
+	for _, a := range d.Attempts {
+		attempts = append(attempts, attemptDoc{
+			At:         a.At,
+			StatusCode: a.StatusCode,
+			Error:      a.Error,
+			DurationMS: a.Duration.Milliseconds(),
+		})
+	}
+	return deliveryDoc{
+		Tenant:        d.Tenant,
+		EventID:       d.EventID,
+		EventType:     d.EventType,
+		Payload:       d.Payload,
+		Status:        string(d.Status),
+		Tries:         d.Tries,
+		Attempts:      attempts,
+		NextAttemptAt: d.NextAttemptAt,
+		CreatedAt:     d.CreatedAt,
+		UpdatedAt:     d.UpdatedAt,
+	}
+}
+
+func toDelivery(doc deliveryDoc) *domainWebhook.Delivery {
+	attempts := make([]domainWebhook.Attempt, 0, len(doc.Attempts))
+	for _, a := range doc.Attempts {
+		attempts = append(attempts, domainWebhook.Attempt{
+			At:         a.At,
+			StatusCode: a.StatusCode,
+			Error:      a.Error,
+			Duration:   time.Duration(a.DurationMS) * time.Millisecond,
+		})
+	}
+	return &domainWebhook.Delivery{
+		ID:             doc.ID.Hex(),
+		Tenant:         doc.Tenant,
+		SubscriptionID: doc.SubscriptionID.Hex(),
+		EventID:        doc.EventID,
+		EventType:      doc.EventType,
+		Payload:        doc.Payload,
+		Status:         domainWebhook.DeliveryStatus(doc.Status),
+		Tries:          doc.Tries,
+		Attempts:       attempts,
+		NextAttemptAt:  doc.NextAttemptAt,
+		CreatedAt:      doc.CreatedAt,
+		UpdatedAt:      doc.UpdatedAt,
+	}
+}
@@ -0,0 +1,46 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+
+	"github.com/rohitashk/golang-rest-api/internal/domain"
+	domainWebhook "github.com/rohitashk/golang-rest-api/internal/domain/webhook"
+)
+
+func TestCreateDeliveryIgnoresRepublishedEvents(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	delivery := func() *domainWebhook.Delivery {
+		return &domainWebhook.Delivery{SubscriptionID: primitive.NewObjectID().Hex(), EventID: "evt-1", Status: domainWebhook.DeliveryPending}
+	}
+
+	mt.Run("new", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		d := delivery()
+		if err := NewWebhookDeliveryRepository(mt.DB).Create(context.Background(), d); err != nil || d.ID == "" {
+			mt.Errorf("Create = %v, ID %q", err, d.ID)
+		}
+	})
+
+	// uniq_subscription_event already holds a delivery of the event.
+	mt.Run("queued before", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{Code: 11000, Message: "E11000 duplicate key error"}))
+		d := delivery()
+		if err := NewWebhookDeliveryRepository(mt.DB).Create(context.Background(), d); err != nil || d.ID != "" {
+			mt.Errorf("Create = %v, ID %q; want the duplicate ignored", err, d.ID)
+		}
+	})
+
+	mt.Run("failed", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{Code: 2, Message: "bad value"}))
+		err := NewWebhookDeliveryRepository(mt.DB).Create(context.Background(), delivery())
+		var derr domain.Error
+		if !errors.As(err, &derr) || derr.Kind != domain.ErrKindInternal {
+			mt.Errorf("Create = %v, want an internal error", err)
+		}
+	})
+}
@@ -0,0 +1,86 @@
+// Package auth identifies API callers by the keys they send. Each key
+// belongs to one tenant, which the caller then acts for.
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/rohitashk/golang-rest-api/internal/domain/tenant"
+)
+
+// MinKeyLength keeps keys long enough not to be guessed.
+const MinKeyLength = 16
+
+// Principal is an authenticated caller.
+type Principal struct {
+	// ID names the caller in logs and rate limits without revealing its key.
+	ID string
+	// Tenant is the tenant the caller acts for; empty acts for the default
+	// tenant.
+	Tenant string
+}
+
+// Keys maps API keys to the callers they identify. Only digests of the keys
+// are kept.
+type Keys struct {
+	byDigest map[[sha256.Size]byte]Principal
+}
+
+// ParseKeys reads entries of the form "tenant:key".
+func ParseKeys(entries []string) (*Keys, error) {
+	k := &Keys{byDigest: make(map[[sha256.Size]byte]Principal, len(entries))}
+	for i, entry := range entries {
+		tenantID, key, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		switch {
+		case !ok:
+			return nil, fmt.Errorf("key %d is not tenant:key", i+1)
+		case !tenant.Valid(tenantID):
+			return nil, fmt.Errorf("key %d: %q is not a valid tenant ID", i+1, tenantID)
+		case len(key) < MinKeyLength:
+			return nil, fmt.Errorf("key %d of tenant %s is shorter than %d characters", i+1, tenantID, MinKeyLength)
+		}
+		digest := sha256.Sum256([]byte(key))
+		if _, dup := k.byDigest[digest]; dup {
+			return nil, fmt.Errorf("key %d of tenant %s is given twice", i+1, tenantID)
+		}
+		k.byDigest[digest] = Principal{ID: tenantID + ":" + hex.EncodeToString(digest[:4]), Tenant: tenantID}
+	}
+	return k, nil
+}
+
+// Lookup returns the caller key identifies. Keys are compared by digest, so
+// the time taken says nothing about how much of a key was right.
+func (k *Keys) Lookup(key string) (Principal, bool) {
+	if k == nil || key == "" {
+		return Principal{}, false
+	}
+	p, ok := k.byDigest[sha256.Sum256([]byte(key))]
+	return p, ok
+}
+
+// Len is the number of keys.
+func (k *Keys) Len() int {
+	if k == nil {
+		return 0
+	}
+	return len(k.byDigest)
+}
+
+// Tenants lists the tenants of the keys, for checking they are known.
+func (k *Keys) Tenants() []string {
+	if k == nil {
+		return nil
+	}
+	seen := map[string]bool{}
+	var out []string
+	for _, p := range k.byDigest {
+		if !seen[p.Tenant] {
+			seen[p.Tenant] = true
+			out = append(out, p.Tenant)
+		}
+	}
+	return out
+}
@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseKeys(t *testing.T) {
+	keys, err := ParseKeys([]string{"acme:0123456789abcdef", " globex:fedcba9876543210 "})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys.Len() != 2 {
+		t.Fatalf("Len = %d, want 2", keys.Len())
+	}
+
+	p, ok := keys.Lookup("fedcba9876543210")
+	if !ok || p.Tenant != "globex" {
+		t.Fatalf("Lookup = %+v, %v", p, ok)
+	}
+	if !strings.HasPrefix(p.ID, "globex:") || strings.Contains(p.ID, "fedcba") {
+		t.Errorf("ID %q must name the tenant but not the key", p.ID)
+	}
+	for _, key := range []string{"", "0123456789abcde", "acme:0123456789abcdef", "0123456789ABCDEF"} {
+		if _, ok := keys.Lookup(key); ok {
+			t.Errorf("Lookup(%q) found a caller", key)
+		}
+	}
+}
+
+func TestParseKeysRejects(t *testing.T) {
+	for _, entries := range [][]string{
+		{"0123456789abcdef"},           // no tenant
+		{"Acme Corp:0123456789abcdef"}, // invalid tenant
+		{"acme:short"},                 // too short
+		{"acme:0123456789abcdef", "globex:0123456789abcdef"}, // shared
+	} {
+		if _, err := ParseKeys(entries); err == nil {
+			t.Errorf("ParseKeys(%q) succeeded", entries)
+		} else if strings.Contains(err.Error(), "0123456789abcdef") {
+			t.Errorf("error reveals the key: %v", err)
+		}
+	}
+}
+
+func TestNilKeysKnowNone(t *testing.T) {
+	var keys *Keys
+	if _, ok := keys.Lookup("0123456789abcdef"); ok || keys.Len() != 0 {
+		t.Error("nil Keys knows a key")
+	}
+}
@@ -2,65 +2,322 @@ package config
 
 import (
 	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"log/slog"
+	"net/netip"
 	"os"
+	"slices"
+	"strings"
 	"time"
+
+	"github.com/rohitashk/golang-rest-api/internal/auth"
+	"github.com/rohitashk/golang-rest-api/internal/domain/ratelimit"
+	"github.com/rohitashk/golang-rest-api/internal/domain/tenant"
 )
 
 type Config struct {
 	AppEnv string
+	// LogLevel is debug, info, warn or error. Unset, it is debug for local
+	// and dev and info otherwise.
+	LogLevel string
+	// Features switches optional APIs, named by the Feature* constants.
+	Features map[string]bool
+
+	// File is the config file the settings were read from, if any.
+	File string
 
 	HTTPAddr string
+	GRPCAddr string
+
+	// Tenants are the tenants requests may act for. DefaultTenant is used
+	// by requests that name none, unless TenantRequired.
+	Tenants        []string
+	DefaultTenant  string
+	TenantRequired bool
+	// TenantTrustHeader takes the tenant of requests without credentials
+	// from X-Tenant-ID; only for a gateway in front that sets or strips it.
+	TenantTrustHeader bool
+
+	// APIKeys are "tenant:key" entries; a caller sending a key acts for its
+	// tenant. When any are set every API request needs one.
+	APIKeys []string
 
 	MongoURI            string
 	MongoDB             string
 	MongoConnectTimeout time.Duration
+	// MongoTransactions needs a replica set; turn it off for a standalone
+	// server at the cost of events possibly diverging from their writes.
+	MongoTransactions bool
+	// MongoMigrateOnStart applies pending migrations at boot. Off, the
+	// service refuses to start until "api migrate up" has run.
+	MongoMigrateOnStart bool
+	// MongoSchemaValidation is off, warn or enforce; see
+	// mongodb.SchemaMode.
+	MongoSchemaValidation string
+
+	// SearchIndex backs employee search: mongo uses the collection's text
+	// index, memory an index kept in each instance.
+	SearchIndex string
 
 	RequestTimeout time.Duration
+
+	HealthCheckTimeout time.Duration
+	// ShutdownDelay keeps serving after readiness turns off, giving load
+	// balancers time to notice before connections are closed.
+	ShutdownDelay time.Duration
+
+	IdempotencyTTL time.Duration
+
+	GraphQLMaxDepth      int
+	GraphQLMaxComplexity int
+
+	OutboxRelayInterval time.Duration
+	OutboxBatchSize     int
+
+	WebhookTimeout     time.Duration
+	WebhookMaxAttempts int
+	// WebhookAllowPrivateTargets lets webhooks reach loopback and private
+	// addresses, for local testing only.
+	WebhookAllowPrivateTargets bool
+
+	SCIMBearerToken string
+
+	// RateLimit applies per client to every route without its own entry in
+	// RateLimitRoutes, which is keyed by "METHOD /route/template".
+	RateLimit       ratelimit.Limit
+	RateLimitRoutes map[string]ratelimit.Limit
+	// TrustedProxies lists the proxy CIDRs whose X-Forwarded-For is believed
+	// when finding the client IP. Empty trusts none.
+	TrustedProxies []string
+
+	// TracingExporter is none, stdout or otlp.
+	TracingExporter    string
+	ServiceName        string
+	OTLPEndpoint       string
+	OTLPInsecure       bool
+	TracingSampleRatio float64
 }
 
-func Load() (Config, error) {
-	cfg := Config{
+func defaults() Config {
+	return Config{
 		AppEnv: "local",
+		Features: map[string]bool{
+			FeatureGraphQL:        true,
+			FeatureSCIM:           true,
+			FeatureEmployeeEvents: true,
+		},
 
 		HTTPAddr: ":8080",
+		GRPCAddr: ":9090",
+
+		Tenants:       []string{tenant.Default},
+		DefaultTenant: tenant.Default,
+
+		MongoDB:               "employee_mgmt",
+		MongoConnectTimeout:   10 * time.Second,
+		MongoTransactions:     true,
+		MongoMigrateOnStart:   true,
+		MongoSchemaValidation: "warn",
+		SearchIndex:           "mongo",
+		RequestTimeout:        5 * time.Second,
+		HealthCheckTimeout:    2 * time.Second,
+		IdempotencyTTL:        24 * time.Hour,
 
-		MongoDB:             "employee_mgmt",
-		MongoConnectTimeout: 10 * time.Second,
-		RequestTimeout:      5 * time.Second,
+		GraphQLMaxDepth:      8,
+		GraphQLMaxComplexity: 2000,
+
+		OutboxRelayInterval: time.Second,
+		OutboxBatchSize:     100,
+
+		WebhookTimeout:     10 * time.Second,
+		WebhookMaxAttempts: 8,
+
+		RateLimit: ratelimit.Limit{Rate: 50, Burst: 100},
+
+		TracingExporter:    "none",
+		ServiceName:        "employee-api",
+		OTLPEndpoint:       "localhost:4317",
+		TracingSampleRatio: 1,
 	}
+}
+
+const (
+	FeatureGraphQL        = "graphql"
+	FeatureSCIM           = "scim"
+	FeatureEmployeeEvents = "employee_events"
+)
 
-	if v := os.Getenv("APP_ENV"); v != "" {
-		cfg.AppEnv = v
+// ConfigFileEnv names the config file when -config is not given.
+const ConfigFileEnv = "CONFIG_FILE"
+
+// Load layers, from lowest to highest precedence: defaults, the YAML or TOML
+// file named by -config or CONFIG_FILE, environment variables (NAME or
+// NAME_FILE holding a path to read the value from), and the flags in args.
+// Every problem is reported, joined into one error. -h returns flag.ErrHelp
+// after printing usage to stderr.
+func Load(args []string) (Config, error) {
+	return load(args, os.Stderr)
+}
+
+func load(args []string, usage io.Writer) (Config, error) {
+	cfg := defaults()
+	settings := settingsFor(&cfg)
+
+	fs := flag.NewFlagSet("api", flag.ContinueOnError)
+	fs.SetOutput(usage)
+	configFile := fs.String("config", os.Getenv(ConfigFileEnv), "YAML (.yaml, .yml) or TOML (.toml) config file")
+	flagValues := map[string]string{}
+	for _, s := range settings {
+		name := s.flagName()
+		fs.Func(name, s.usage, func(v string) error {
+			flagValues[name] = v
+			return nil
+		})
 	}
-	if v := os.Getenv("HTTP_ADDR"); v != "" {
-		cfg.HTTPAddr = v
+	if err := fs.Parse(args); err != nil {
+		return Config{}, err
+	}
+	if fs.NArg() > 0 {
+		return Config{}, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
 	}
 
-	if v := os.Getenv("MONGO_URI"); v != "" {
-		cfg.MongoURI = v
+	var errs []error
+	if *configFile != "" {
+		errs = append(errs, applyFile(*configFile, settings)...)
 	}
-	if v := os.Getenv("MONGO_DB"); v != "" {
-		cfg.MongoDB = v
+	for _, s := range settings {
+		v, ok, err := lookupEnv(s.env)
+		switch {
+		case err != nil:
+			errs = append(errs, err)
+		case ok:
+			if err := s.value.Set(v); err != nil {
+				errs = append(errs, fmt.Errorf("env %s: %w", s.env, err))
+			}
+		}
 	}
-	if v := os.Getenv("MONGO_CONNECT_TIMEOUT"); v != "" {
-		d, err := time.ParseDuration(v)
-		if err != nil {
-			return Config{}, fmt.Errorf("parse MONGO_CONNECT_TIMEOUT: %w", err)
+	for _, s := range settings {
+		if v, ok := flagValues[s.flagName()]; ok {
+			if err := s.value.Set(v); err != nil {
+				errs = append(errs, fmt.Errorf("flag -%s: %w", s.flagName(), err))
+			}
 		}
-		cfg.MongoConnectTimeout = d
 	}
-	if v := os.Getenv("REQUEST_TIMEOUT"); v != "" {
-		d, err := time.ParseDuration(v)
+	if cfg.LogLevel == "" {
+		cfg.LogLevel = "info"
+		if cfg.AppEnv == "local" || cfg.AppEnv == "dev" {
+			cfg.LogLevel = "debug"
+		}
+	}
+	cfg.File = *configFile
+	errs = append(errs, cfg.validate()...)
+
+	if err := errors.Join(errs...); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// lookupEnv reads name, or the file named by name_FILE. Empty values count as
+// unset, like a blank line in an env file.
+func lookupEnv(name string) (string, bool, error) {
+	v := os.Getenv(name)
+	path := os.Getenv(name + "_FILE")
+	switch {
+	case v != "" && path != "":
+		return "", false, fmt.Errorf("env %s and %s_FILE are both set", name, name)
+	case path != "":
+		b, err := os.ReadFile(path)
 		if err != nil {
-			return Config{}, fmt.Errorf("parse REQUEST_TIMEOUT: %w", err)
+			return "", false, fmt.Errorf("env %s_FILE: %w", name, err)
 		}
-		cfg.RequestTimeout = d
+		return strings.TrimRight(string(b), "\r\n"), true, nil
 	}
+	return v, v != "", nil
+}
 
-	if cfg.MongoURI == "" {
-		return Config{}, errors.New("MONGO_URI is required")
+func (c Config) validate() []error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
 	}
 
-	return cfg, nil
+	check(c.MongoURI != "", "mongo.uri (MONGO_URI) is required")
+	check(c.MongoDB != "", "mongo.db (MONGO_DB) is required")
+	check(c.HTTPAddr != "", "http.addr (HTTP_ADDR) is required")
+	check(c.GRPCAddr != "", "grpc.addr (GRPC_ADDR) is required")
+
+	for name, d := range map[string]time.Duration{
+		"mongo.connect_timeout": c.MongoConnectTimeout,
+		"http.request_timeout":  c.RequestTimeout,
+		"health.check_timeout":  c.HealthCheckTimeout,
+		"idempotency.ttl":       c.IdempotencyTTL,
+		"outbox.relay_interval": c.OutboxRelayInterval,
+		"webhook.timeout":       c.WebhookTimeout,
+	} {
+		check(d > 0, "%s must be positive", name)
+	}
+	check(c.ShutdownDelay >= 0, "http.shutdown_delay must not be negative")
+
+	for name, n := range map[string]int{
+		"graphql.max_depth":      c.GraphQLMaxDepth,
+		"graphql.max_complexity": c.GraphQLMaxComplexity,
+		"outbox.batch_size":      c.OutboxBatchSize,
+		"webhook.max_attempts":   c.WebhookMaxAttempts,
+	} {
+		check(n > 0, "%s must be positive", name)
+	}
+
+	for _, p := range c.TrustedProxies {
+		_, errPrefix := netip.ParsePrefix(p)
+		_, errAddr := netip.ParseAddr(p)
+		check(errPrefix == nil || errAddr == nil, "http.trusted_proxies: %q is not an IP or CIDR", p)
+	}
+
+	check(len(c.Tenants) > 0, "tenancy.tenants must name at least one tenant")
+	for _, id := range c.Tenants {
+		check(tenant.Valid(id), "tenancy.tenants: %q is not a valid tenant ID", id)
+	}
+	check(slices.Contains(c.Tenants, c.DefaultTenant), "tenancy.default %q is not one of tenancy.tenants", c.DefaultTenant)
+	if keys, err := auth.ParseKeys(c.APIKeys); err != nil {
+		errs = append(errs, fmt.Errorf("auth.api_keys: %w", err))
+	} else {
+		for _, id := range keys.Tenants() {
+			check(slices.Contains(c.Tenants, id), "auth.api_keys: tenant %q is not one of tenancy.tenants", id)
+		}
+	}
+
+	var level slog.Level
+	check(level.UnmarshalText([]byte(c.LogLevel)) == nil, "log.level must be debug, info, warn or error, got %q", c.LogLevel)
+	for name := range c.Features {
+		_, known := defaults().Features[name]
+		check(known, "features: unknown feature %q", name)
+	}
+
+	switch c.MongoSchemaValidation {
+	case "off", "warn", "enforce":
+	default:
+		errs = append(errs, fmt.Errorf("mongo.schema_validation must be off, warn or enforce, got %q", c.MongoSchemaValidation))
+	}
+
+	switch c.SearchIndex {
+	case "mongo", "memory":
+	default:
+		errs = append(errs, fmt.Errorf("search.index must be mongo or memory, got %q", c.SearchIndex))
+	}
+
+	switch c.TracingExporter {
+	case "none", "stdout", "otlp":
+	default:
+		errs = append(errs, fmt.Errorf("tracing.exporter must be none, stdout or otlp, got %q", c.TracingExporter))
+	}
+	check(c.TracingSampleRatio >= 0 && c.TracingSampleRatio <= 1, "tracing.sample_ratio must be between 0 and 1")
+
+	// Map iteration above makes the order random; keep reports stable.
+	sortErrors(errs)
+	return errs
 }
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// applyFile sets the values in a YAML or TOML file. Keys nest by their dotted
+// setting names, e.g. mongo: {uri: ...}; unknown keys are errors so typos do
+// not go unnoticed.
+func applyFile(path string, settings []setting) []error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return []error{fmt.Errorf("config file: %w", err)}
+	}
+
+	tree := map[string]any{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(b, &tree)
+	case ".toml":
+		err = toml.Unmarshal(b, &tree)
+	default:
+		return []error{fmt.Errorf("config file %s: unknown format %q, want .yaml, .yml or .toml", path, ext)}
+	}
+	if err != nil {
+		return []error{fmt.Errorf("config file %s: %w", path, err)}
+	}
+
+	byKey := make(map[string]setting, len(settings))
+	for _, s := range settings {
+		byKey[s.key] = s
+	}
+
+	var errs []error
+	var walk func(prefix string, m map[string]any)
+	walk = func(prefix string, m map[string]any) {
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			key := prefix + k
+			raw := m[k]
+			if s, ok := byKey[key]; ok {
+				if err := setFromFile(s.value, raw); err != nil {
+					errs = append(errs, fmt.Errorf("config file %s: %s: %w", path, key, err))
+				}
+				continue
+			}
+			if sub, ok := raw.(map[string]any); ok {
+				walk(key+".", sub)
+				continue
+			}
+			errs = append(errs, fmt.Errorf("config file %s: unknown key %s", path, key))
+		}
+	}
+	walk("", tree)
+	return errs
+}
+
+func setFromFile(v value, raw any) error {
+	if fv, ok := v.(fileValue); ok {
+		return fv.SetFile(raw)
+	}
+	switch raw.(type) {
+	case map[string]any, []any:
+		return fmt.Errorf("want a single value, got %v", raw)
+	case nil:
+		return nil
+	}
+	return v.Set(fmt.Sprint(raw))
+}
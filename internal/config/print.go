@@ -0,0 +1,138 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const redacted = "[redacted]"
+
+// String lists every setting with secrets redacted, so a Config can be
+// printed or logged as a whole.
+func (c Config) String() string {
+	var b strings.Builder
+	for i, s := range settingsFor(&c) {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(s.key + "=" + display(s))
+	}
+	return b.String()
+}
+
+func (c Config) GoString() string { return "config.Config{" + c.String() + "}" }
+
+func (c Config) LogValue() slog.Value {
+	settings := settingsFor(&c)
+	attrs := make([]slog.Attr, 0, len(settings))
+	for _, s := range settings {
+		attrs = append(attrs, slog.String(s.key, display(s)))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// Print writes the configuration as YAML, loadable with -config, or as
+// env (NAME=value lines). Secrets are redacted.
+func (c Config) Print(w io.Writer, format string) error {
+	settings := settingsFor(&c)
+	switch format {
+	case "yaml":
+		tree := map[string]any{}
+		for _, s := range settings {
+			node := tree
+			parts := strings.Split(s.key, ".")
+			for _, p := range parts[:len(parts)-1] {
+				child, ok := node[p].(map[string]any)
+				if !ok {
+					child = map[string]any{}
+					node[p] = child
+				}
+				node = child
+			}
+			node[parts[len(parts)-1]] = export(s)
+		}
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(tree); err != nil {
+			return err
+		}
+		return enc.Close()
+	case "env":
+		for _, s := range settings {
+			v := display(s)
+			if strings.ContainsAny(v, " \"'#$\\") {
+				v = strconv.Quote(v)
+			}
+			if _, err := fmt.Fprintf(w, "%s=%s\n", s.env, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown format %q, want yaml or env", format)
+}
+
+func display(s setting) string {
+	v := s.value.String()
+	if s.secret && v != "" {
+		return redact(v)
+	}
+	return v
+}
+
+// export is the value as it would be written in a config file. Secrets are
+// written redacted, whatever their type.
+func export(s setting) any {
+	if s.secret && s.value.String() != "" {
+		return display(s)
+	}
+	switch v := s.value.(type) {
+	case *listValue:
+		return []string(*v)
+	case *routeLimitsValue:
+		out := map[string]string{}
+		for route, l := range *v {
+			out[route] = l.String()
+		}
+		return out
+	case *featuresValue:
+		return map[string]bool(*v)
+	case *boolValue:
+		return bool(*v)
+	case *intValue:
+		return int(*v)
+	case *floatValue:
+		return float64(*v)
+	}
+	return display(s)
+}
+
+// redact keeps the non-secret parts of a connection string so the output
+// still shows where the service connects.
+func redact(v string) string {
+	u, err := url.Parse(v)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return redacted
+	}
+	if u.User != nil {
+		if _, hasPassword := u.User.Password(); hasPassword {
+			u.User = url.UserPassword(u.User.Username(), "xxxxx")
+		}
+	}
+	if u.RawQuery != "" {
+		q := u.Query()
+		for k := range q {
+			if strings.Contains(strings.ToLower(k), "password") || strings.Contains(strings.ToLower(k), "secret") {
+				q.Set(k, "xxxxx")
+			}
+		}
+		u.RawQuery = q.Encode()
+	}
+	return u.String()
+}
@@ -0,0 +1,121 @@
+package config
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Reloader loads the configuration again, from the same args, on SIGHUP or
+// when the config file changes, and applies the reloadable settings to a
+// Runtime. Changes to other settings are logged as needing a restart. An
+// invalid configuration is logged and the running one kept.
+type Reloader struct {
+	args    []string
+	runtime *Runtime
+	logger  *slog.Logger
+
+	// PollInterval is how often the config file is checked for changes.
+	PollInterval time.Duration
+
+	mu      sync.Mutex
+	current Config
+}
+
+func NewReloader(args []string, current Config, runtime *Runtime, logger *slog.Logger) *Reloader {
+	return &Reloader{
+		args:         args,
+		runtime:      runtime,
+		logger:       logger,
+		PollInterval: 2 * time.Second,
+		current:      current,
+	}
+}
+
+// Run reloads until ctx is done.
+func (r *Reloader) Run(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	// Polling rather than watching keeps working when the file is replaced,
+	// as editors and mounted ConfigMaps do.
+	var tick <-chan time.Time
+	last, _ := stat(r.current.File)
+	if r.current.File != "" {
+		ticker := time.NewTicker(r.PollInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			r.Reload("SIGHUP")
+		case <-tick:
+			info, err := stat(r.current.File)
+			if err != nil || info == last {
+				continue
+			}
+			last = info
+			r.Reload("file change")
+		}
+	}
+}
+
+type fileInfo struct {
+	size    int64
+	modTime time.Time
+}
+
+func stat(path string) (fileInfo, error) {
+	if path == "" {
+		return fileInfo{}, nil
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return fileInfo{}, err
+	}
+	return fileInfo{size: fi.Size(), modTime: fi.ModTime()}, nil
+}
+
+// Reload loads and applies the configuration once; trigger says why, for
+// the log.
+func (r *Reloader) Reload(trigger string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	next, err := Load(r.args)
+	if err != nil {
+		r.logger.Error("config reload failed, keeping the running configuration", "trigger", trigger, "err", err)
+		return
+	}
+
+	// Apply before logging, so a lowered log level already shows the lines
+	// reporting it.
+	running := r.current
+	r.current = r.current.reloadable(next)
+	r.runtime.Apply(r.current)
+
+	old, updated := settingsFor(&running), settingsFor(&next)
+	applied := 0
+	for i, s := range old {
+		before, after := display(s), display(updated[i])
+		if before == after {
+			continue
+		}
+		if s.reload {
+			applied++
+			r.logger.Info("config setting changed", "key", s.key, "old", before, "new", after)
+		} else {
+			r.logger.Warn("config setting changed but needs a restart", "key", s.key, "old", before, "new", after)
+		}
+	}
+	r.logger.Info("config reloaded", "trigger", trigger, "applied", applied)
+}
@@ -0,0 +1,191 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const reloadBase = `
+mongo:
+  uri: mongodb://localhost:27017
+grpc:
+  addr: ":9090"
+log:
+  level: info
+http:
+  request_timeout: 5s
+`
+
+// newReloader loads the configuration from a file holding content and
+// returns a Reloader of it, the Runtime it applies to and its log.
+func newReloader(t *testing.T, content string) (*Reloader, *Runtime, string, *bytes.Buffer) {
+	t.Helper()
+	file := writeFile(t, "config.yaml", content)
+	cfg, err := loadEnv(t, nil, "-config", file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rt := NewRuntime(cfg)
+	var log bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&log, &slog.HandlerOptions{Level: rt.Level()}))
+	return NewReloader([]string{"-config", file}, cfg, rt, logger), rt, file, &log
+}
+
+func rewrite(t *testing.T, file, content string) {
+	t.Helper()
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReloadAppliesReloadableSettings(t *testing.T) {
+	r, rt, file, log := newReloader(t, reloadBase)
+
+	rewrite(t, file, strings.NewReplacer("level: info", "level: debug", "5s", "2s").Replace(reloadBase))
+	r.Reload("test")
+
+	if got := rt.Level().Level(); got != slog.LevelDebug {
+		t.Errorf("log level = %v, want debug", got)
+	}
+	if got := rt.RequestTimeout(); got != 2*time.Second {
+		t.Errorf("RequestTimeout = %v, want 2s", got)
+	}
+	for _, want := range []string{"key=log.level old=info new=debug", "key=http.request_timeout", "applied=2"} {
+		if !strings.Contains(log.String(), want) {
+			t.Errorf("log misses %q:\n%s", want, log)
+		}
+	}
+}
+
+func TestReloadKeepsRestartOnlySettings(t *testing.T) {
+	r, rt, file, log := newReloader(t, reloadBase)
+
+	rewrite(t, file, strings.NewReplacer(`":9090"`, `":9191"`, "5s", "2s").Replace(reloadBase))
+	r.Reload("test")
+
+	if r.current.GRPCAddr != ":9090" {
+		t.Errorf("GRPCAddr = %q, want it kept until a restart", r.current.GRPCAddr)
+	}
+	if !strings.Contains(log.String(), `level=WARN msg="config setting changed but needs a restart" key=grpc.addr old=:9090 new=:9191`) {
+		t.Errorf("no warning about grpc.addr:\n%s", log)
+	}
+	// The reloadable settings of the same file still apply.
+	if got := rt.RequestTimeout(); got != 2*time.Second {
+		t.Errorf("RequestTimeout = %v, want 2s", got)
+	}
+	if !strings.Contains(log.String(), "applied=1") {
+		t.Errorf("log:\n%s", log)
+	}
+}
+
+func TestReloadKeepsRunningConfigWhenInvalid(t *testing.T) {
+	r, rt, file, log := newReloader(t, reloadBase)
+
+	rewrite(t, file, strings.NewReplacer("level: info", "level: loud", "5s", "2s").Replace(reloadBase))
+	r.Reload("test")
+
+	if got := rt.RequestTimeout(); got != 5*time.Second {
+		t.Errorf("RequestTimeout = %v, want the running 5s", got)
+	}
+	if r.current.LogLevel != "info" {
+		t.Errorf("LogLevel = %q", r.current.LogLevel)
+	}
+	if !strings.Contains(log.String(), "config reload failed, keeping the running configuration") || !strings.Contains(log.String(), "log.level") {
+		t.Errorf("log:\n%s", log)
+	}
+
+	// A fixed file is picked up again.
+	rewrite(t, file, strings.Replace(reloadBase, "5s", "2s", 1))
+	r.Reload("test")
+	if got := rt.RequestTimeout(); got != 2*time.Second {
+		t.Errorf("RequestTimeout = %v after the fix, want 2s", got)
+	}
+}
+
+func TestRunReloadsOnFileChange(t *testing.T) {
+	r, rt, file, _ := newReloader(t, reloadBase)
+	r.PollInterval = 5 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.Run(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// Let Run take note of the file before it changes.
+	time.Sleep(20 * time.Millisecond)
+	rewrite(t, file, strings.Replace(reloadBase, "5s", "2s", 1))
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(file, later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for rt.RequestTimeout() != 2*time.Second {
+		if time.Now().After(deadline) {
+			t.Fatal("file change not reloaded")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// TestRuntimeConcurrentUse is meant for -race: requests read the settings
+// while a reload replaces them.
+func TestRuntimeConcurrentUse(t *testing.T) {
+	cfg, err := loadEnv(t, map[string]string{
+		"MONGO_URI": "mongodb://localhost:27017",
+		"TENANTS":   "default,acme",
+		"API_KEYS":  "acme:0123456789abcdef",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rt := NewRuntime(cfg)
+	other := cfg
+	other.LogLevel, other.RequestTimeout, other.APIKeys = "debug", time.Second, nil
+	other.Features = map[string]bool{FeatureSCIM: true}
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				_ = rt.Level().Level()
+				_ = rt.RequestTimeout()
+				_ = rt.RateLimits()
+				_ = rt.Enabled(FeatureSCIM)
+				_ = rt.KnownTenant("acme")
+				_ = rt.DefaultTenant()
+				_ = rt.TrustTenantHeader()
+				_, _ = rt.Authenticate("0123456789abcdef")
+				_ = rt.CredentialsRequired()
+			}
+		}()
+	}
+	for i := 0; i < 200; i++ {
+		if i%2 == 0 {
+			rt.Apply(other)
+		} else {
+			rt.Apply(cfg)
+		}
+	}
+	close(stop)
+	wg.Wait()
+}
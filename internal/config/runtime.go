@@ -0,0 +1,112 @@
+package config
+
+import (
+	"log/slog"
+	"maps"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"github.com/rohitashk/golang-rest-api/internal/auth"
+	"github.com/rohitashk/golang-rest-api/internal/domain/ratelimit"
+)
+
+// Runtime holds the settings that can change while the service runs. The
+// parts of the service using them read them through Runtime on every use
+// rather than copying them at startup.
+type Runtime struct {
+	level          slog.LevelVar
+	requestTimeout atomic.Int64
+	rateLimits     atomic.Pointer[ratelimit.Policy]
+	features       atomic.Pointer[map[string]bool]
+	tenants        atomic.Pointer[tenancy]
+}
+
+type tenancy struct {
+	ids         []string
+	def         string
+	required    bool
+	trustHeader bool
+	keys        *auth.Keys
+}
+
+func NewRuntime(c Config) *Runtime {
+	rt := &Runtime{}
+	rt.Apply(c)
+	return rt
+}
+
+// Apply switches to the reloadable settings of c, which must be valid.
+func (rt *Runtime) Apply(c Config) {
+	var level slog.Level
+	_ = level.UnmarshalText([]byte(c.LogLevel))
+	rt.level.Set(level)
+	rt.requestTimeout.Store(int64(c.RequestTimeout))
+	rt.rateLimits.Store(&ratelimit.Policy{Default: c.RateLimit, Routes: maps.Clone(c.RateLimitRoutes)})
+	features := maps.Clone(c.Features)
+	rt.features.Store(&features)
+	keys, _ := auth.ParseKeys(c.APIKeys)
+	rt.tenants.Store(&tenancy{
+		ids:         slices.Clone(c.Tenants),
+		def:         c.DefaultTenant,
+		required:    c.TenantRequired,
+		trustHeader: c.TenantTrustHeader,
+		keys:        keys,
+	})
+}
+
+// Level is for slog.HandlerOptions.Level.
+func (rt *Runtime) Level() slog.Leveler { return &rt.level }
+
+func (rt *Runtime) RequestTimeout() time.Duration {
+	return time.Duration(rt.requestTimeout.Load())
+}
+
+func (rt *Runtime) RateLimits() ratelimit.Policy { return *rt.rateLimits.Load() }
+
+// Enabled reports whether the feature is on; unknown features are off.
+func (rt *Runtime) Enabled(feature string) bool { return (*rt.features.Load())[feature] }
+
+// Tenants lists the tenants requests may act for.
+func (rt *Runtime) Tenants() []string { return rt.tenants.Load().ids }
+
+// DefaultTenant is the tenant of requests that name none; empty when they
+// must name one.
+func (rt *Runtime) DefaultTenant() string {
+	t := rt.tenants.Load()
+	if t.required {
+		return ""
+	}
+	return t.def
+}
+
+// KnownTenant reports whether requests may act for id.
+func (rt *Runtime) KnownTenant(id string) bool { return slices.Contains(rt.Tenants(), id) }
+
+// TrustTenantHeader reports whether requests without credentials may name
+// their tenant.
+func (rt *Runtime) TrustTenantHeader() bool { return rt.tenants.Load().trustHeader }
+
+// Authenticate returns the caller an API key identifies.
+func (rt *Runtime) Authenticate(key string) (auth.Principal, bool) {
+	return rt.tenants.Load().keys.Lookup(key)
+}
+
+// CredentialsRequired reports whether every API request needs a key.
+func (rt *Runtime) CredentialsRequired() bool { return rt.tenants.Load().keys.Len() > 0 }
+
+// reloadable copies the settings Runtime applies from next, leaving the
+// ones that need a restart as they were.
+func (c Config) reloadable(next Config) Config {
+	c.LogLevel = next.LogLevel
+	c.Features = next.Features
+	c.RequestTimeout = next.RequestTimeout
+	c.RateLimit = next.RateLimit
+	c.RateLimitRoutes = next.RateLimitRoutes
+	c.Tenants = next.Tenants
+	c.DefaultTenant = next.DefaultTenant
+	c.TenantRequired = next.TenantRequired
+	c.TenantTrustHeader = next.TenantTrustHeader
+	c.APIKeys = next.APIKeys
+	return c
+}
@@ -0,0 +1,297 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rohitashk/golang-rest-api/internal/domain/ratelimit"
+)
+
+// setting is one configuration value, named the same way in every source:
+// key in files ("mongo.uri"), env in the environment (MONGO_URI) and the key
+// with dashes as a flag (-mongo-uri). Settings marked reload take effect
+// without a restart; see Runtime.
+type setting struct {
+	key    string
+	env    string
+	usage  string
+	secret bool
+	reload bool
+	value  value
+}
+
+// value is a flag.Value that files may also set with structured values.
+type value interface {
+	Set(string) error
+	String() string
+}
+
+type fileValue interface {
+	SetFile(v any) error
+}
+
+func (s setting) flagName() string {
+	return strings.NewReplacer(".", "-", "_", "-").Replace(s.key)
+}
+
+func settingsFor(c *Config) []setting {
+	return []setting{
+		{key: "app_env", env: "APP_ENV", usage: "environment: local, dev or prod", value: (*stringValue)(&c.AppEnv)},
+		{key: "log.level", env: "LOG_LEVEL", usage: "debug, info, warn or error", reload: true, value: (*stringValue)(&c.LogLevel)},
+		{key: "features", env: "FEATURES", usage: `optional APIs to switch, "graphql=false,scim=true"`, reload: true, value: (*featuresValue)(&c.Features)},
+
+		{key: "http.addr", env: "HTTP_ADDR", usage: "HTTP listen address", value: (*stringValue)(&c.HTTPAddr)},
+		{key: "http.request_timeout", env: "REQUEST_TIMEOUT", usage: "per-request timeout", reload: true, value: (*durationValue)(&c.RequestTimeout)},
+		{key: "http.shutdown_delay", env: "SHUTDOWN_DELAY", usage: "time to keep serving after readiness turns off", value: (*durationValue)(&c.ShutdownDelay)},
+		{key: "http.trusted_proxies", env: "TRUSTED_PROXIES", usage: "comma-separated proxy CIDRs trusted for X-Forwarded-For", value: (*listValue)(&c.TrustedProxies)},
+		{key: "grpc.addr", env: "GRPC_ADDR", usage: "gRPC listen address", value: (*stringValue)(&c.GRPCAddr)},
+
+		{key: "tenancy.tenants", env: "TENANTS", usage: "comma-separated tenants requests may act for", reload: true, value: (*listValue)(&c.Tenants)},
+		{key: "tenancy.default", env: "DEFAULT_TENANT", usage: "tenant of requests that name none", reload: true, value: (*stringValue)(&c.DefaultTenant)},
+		{key: "tenancy.required", env: "TENANT_REQUIRED", usage: "reject requests that name no tenant", reload: true, value: (*boolValue)(&c.TenantRequired)},
+		{key: "tenancy.trust_header", env: "TENANT_TRUST_HEADER", usage: "take the tenant of requests without credentials from X-Tenant-ID (behind a gateway only)", reload: true, value: (*boolValue)(&c.TenantTrustHeader)},
+
+		{key: "auth.api_keys", env: "API_KEYS", usage: `comma-separated "tenant:key" API keys; any set requires one on every request`, secret: true, reload: true, value: (*listValue)(&c.APIKeys)},
+
+		{key: "mongo.uri", env: "MONGO_URI", usage: "MongoDB connection string", secret: true, value: (*stringValue)(&c.MongoURI)},
+		{key: "mongo.db", env: "MONGO_DB", usage: "MongoDB database", value: (*stringValue)(&c.MongoDB)},
+		{key: "mongo.connect_timeout", env: "MONGO_CONNECT_TIMEOUT", usage: "MongoDB connect timeout", value: (*durationValue)(&c.MongoConnectTimeout)},
+		{key: "mongo.transactions", env: "MONGO_TRANSACTIONS", usage: "write events in the same transaction (needs a replica set)", value: (*boolValue)(&c.MongoTransactions)},
+		{key: "mongo.migrate_on_start", env: "MONGO_MIGRATE_ON_START", usage: "apply pending migrations at startup", value: (*boolValue)(&c.MongoMigrateOnStart)},
+		{key: "mongo.schema_validation", env: "MONGO_SCHEMA_VALIDATION", usage: "employees validator: off, warn or enforce", value: (*stringValue)(&c.MongoSchemaValidation)},
+
+		{key: "search.index", env: "SEARCH_INDEX", usage: "employee search index: mongo or memory", value: (*stringValue)(&c.SearchIndex)},
+
+		{key: "health.check_timeout", env: "HEALTH_CHECK_TIMEOUT", usage: "timeout of each readiness check", value: (*durationValue)(&c.HealthCheckTimeout)},
+		{key: "idempotency.ttl", env: "IDEMPOTENCY_TTL", usage: "how long Idempotency-Key responses are kept", value: (*durationValue)(&c.IdempotencyTTL)},
+
+		{key: "graphql.max_depth", env: "GRAPHQL_MAX_DEPTH", usage: "maximum GraphQL query depth", value: (*intValue)(&c.GraphQLMaxDepth)},
+		{key: "graphql.max_complexity", env: "GRAPHQL_MAX_COMPLEXITY", usage: "maximum GraphQL query cost", value: (*intValue)(&c.GraphQLMaxComplexity)},
+
+		{key: "outbox.relay_interval", env: "OUTBOX_RELAY_INTERVAL", usage: "how often pending events are relayed", value: (*durationValue)(&c.OutboxRelayInterval)},
+		{key: "outbox.batch_size", env: "OUTBOX_BATCH_SIZE", usage: "events relayed per batch", value: (*intValue)(&c.OutboxBatchSize)},
+
+		{key: "webhook.timeout", env: "WEBHOOK_TIMEOUT", usage: "timeout of a webhook delivery", value: (*durationValue)(&c.WebhookTimeout)},
+		{key: "webhook.max_attempts", env: "WEBHOOK_MAX_ATTEMPTS", usage: "delivery attempts before a webhook delivery fails", value: (*intValue)(&c.WebhookMaxAttempts)},
+		{key: "webhook.allow_private_targets", env: "WEBHOOK_ALLOW_PRIVATE_TARGETS", usage: "let webhooks reach loopback and private addresses (local testing only)", value: (*boolValue)(&c.WebhookAllowPrivateTargets)},
+
+		{key: "scim.bearer_token", env: "SCIM_BEARER_TOKEN", usage: "token SCIM clients must send", secret: true, value: (*stringValue)(&c.SCIMBearerToken)},
+
+		{key: "rate_limit.default", env: "RATE_LIMIT", usage: "per-client limit, COUNT/UNIT[:BURST] or off", reload: true, value: (*limitValue)(&c.RateLimit)},
+		{key: "rate_limit.routes", env: "RATE_LIMIT_ROUTES", usage: `per-route limits, "METHOD /path=LIMIT,..."`, reload: true, value: (*routeLimitsValue)(&c.RateLimitRoutes)},
+
+		{key: "tracing.exporter", env: "TRACING_EXPORTER", usage: "none, stdout or otlp", value: (*stringValue)(&c.TracingExporter)},
+		{key: "tracing.service_name", env: "OTEL_SERVICE_NAME", usage: "service name on spans", value: (*stringValue)(&c.ServiceName)},
+		{key: "tracing.otlp_endpoint", env: "OTEL_EXPORTER_OTLP_ENDPOINT", usage: "OTLP collector host:port", value: (*stringValue)(&c.OTLPEndpoint)},
+		{key: "tracing.otlp_insecure", env: "OTEL_EXPORTER_OTLP_INSECURE", usage: "disable TLS to the collector", value: (*boolValue)(&c.OTLPInsecure)},
+		{key: "tracing.sample_ratio", env: "TRACING_SAMPLE_RATIO", usage: "share of new traces recorded, 0-1", value: (*floatValue)(&c.TracingSampleRatio)},
+	}
+}
+
+type stringValue string
+
+func (v *stringValue) Set(s string) error { *v = stringValue(s); return nil }
+func (v *stringValue) String() string     { return string(*v) }
+
+type durationValue time.Duration
+
+func (v *durationValue) Set(s string) error {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q", s)
+	}
+	*v = durationValue(d)
+	return nil
+}
+func (v *durationValue) String() string { return time.Duration(*v).String() }
+
+type intValue int
+
+func (v *intValue) Set(s string) error {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return fmt.Errorf("invalid integer %q", s)
+	}
+	*v = intValue(n)
+	return nil
+}
+func (v *intValue) String() string { return strconv.Itoa(int(*v)) }
+
+type boolValue bool
+
+func (v *boolValue) Set(s string) error {
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return fmt.Errorf("invalid boolean %q", s)
+	}
+	*v = boolValue(b)
+	return nil
+}
+func (v *boolValue) String() string { return strconv.FormatBool(bool(*v)) }
+
+type floatValue float64
+
+func (v *floatValue) Set(s string) error {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return fmt.Errorf("invalid number %q", s)
+	}
+	*v = floatValue(f)
+	return nil
+}
+func (v *floatValue) String() string { return strconv.FormatFloat(float64(*v), 'f', -1, 64) }
+
+type listValue []string
+
+func (v *listValue) Set(s string) error {
+	*v = nil
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*v = append(*v, item)
+		}
+	}
+	return nil
+}
+func (v *listValue) String() string { return strings.Join(*v, ",") }
+
+func (v *listValue) SetFile(raw any) error {
+	items, ok := raw.([]any)
+	if !ok {
+		return v.Set(fmt.Sprint(raw))
+	}
+	*v = nil
+	for _, item := range items {
+		s, ok := item.(string)
+		if !ok {
+			return fmt.Errorf("list items must be strings, got %v", item)
+		}
+		*v = append(*v, s)
+	}
+	return nil
+}
+
+type limitValue ratelimit.Limit
+
+func (v *limitValue) Set(s string) error {
+	l, err := ratelimit.ParseLimit(s)
+	if err != nil {
+		return err
+	}
+	*v = limitValue(l)
+	return nil
+}
+func (v *limitValue) String() string { return ratelimit.Limit(*v).String() }
+
+type routeLimitsValue map[string]ratelimit.Limit
+
+// Set reads "GET /v1/employees=10/s:20,POST /v1/employees=1/s".
+func (v *routeLimitsValue) Set(s string) error {
+	out := routeLimitsValue{}
+	for _, entry := range strings.Split(s, ",") {
+		route, spec, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			return fmt.Errorf("%q: want METHOD /path=LIMIT", entry)
+		}
+		if err := out.add(route, spec); err != nil {
+			return err
+		}
+	}
+	*v = out
+	return nil
+}
+
+// SetFile takes a map of routes to limits.
+func (v *routeLimitsValue) SetFile(raw any) error {
+	m, ok := raw.(map[string]any)
+	if !ok {
+		return v.Set(fmt.Sprint(raw))
+	}
+	out := routeLimitsValue{}
+	for route, spec := range m {
+		if err := out.add(route, fmt.Sprint(spec)); err != nil {
+			return err
+		}
+	}
+	*v = out
+	return nil
+}
+
+func (v routeLimitsValue) add(route, spec string) error {
+	method, path, ok := strings.Cut(strings.TrimSpace(route), " ")
+	if !ok || method == "" || !strings.HasPrefix(path, "/") {
+		return fmt.Errorf("route %q: want METHOD /path", route)
+	}
+	l, err := ratelimit.ParseLimit(spec)
+	if err != nil {
+		return err
+	}
+	v[strings.ToUpper(method)+" "+path] = l
+	return nil
+}
+
+func (v *routeLimitsValue) String() string {
+	entries := make([]string, 0, len(*v))
+	for route, l := range *v {
+		entries = append(entries, route+"="+l.String())
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, ",")
+}
+
+// featuresValue switches only the features named, leaving the others as they
+// were: "graphql=false,scim=true".
+type featuresValue map[string]bool
+
+func (v *featuresValue) Set(s string) error {
+	for _, entry := range strings.Split(s, ",") {
+		name, on, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			return fmt.Errorf("%q: want NAME=true|false", entry)
+		}
+		b, err := strconv.ParseBool(strings.TrimSpace(on))
+		if err != nil {
+			return fmt.Errorf("feature %s: invalid boolean %q", name, on)
+		}
+		v.set(strings.TrimSpace(name), b)
+	}
+	return nil
+}
+
+// SetFile takes a map of features to booleans.
+func (v *featuresValue) SetFile(raw any) error {
+	m, ok := raw.(map[string]any)
+	if !ok {
+		return v.Set(fmt.Sprint(raw))
+	}
+	for name, on := range m {
+		b, ok := on.(bool)
+		if !ok {
+			return fmt.Errorf("feature %s: want true or false, got %v", name, on)
+		}
+		v.set(name, b)
+	}
+	return nil
+}
+
+func (v *featuresValue) set(name string, on bool) {
+	if *v == nil {
+		*v = featuresValue{}
+	}
+	(*v)[name] = on
+}
+
+func (v *featuresValue) String() string {
+	entries := make([]string, 0, len(*v))
+	for name, on := range *v {
+		entries = append(entries, name+"="+strconv.FormatBool(on))
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, ",")
+}
+
+func sortErrors(errs []error) {
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+}
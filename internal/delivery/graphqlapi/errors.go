@@ -0,0 +1,34 @@
+package graphqlapi
+
+import (
+	"errors"
+
+	"github.com/rohitashk/golang-rest-api/internal/domain"
+)
+
+// resolverError exposes the domain error kind and field details as GraphQL
+// error extensions; non-domain errors are hidden behind a generic message.
+type resolverError struct {
+	msg        string
+	extensions map[string]any
+}
+
+func (e resolverError) Error() string              { return e.msg }
+func (e resolverError) Extensions() map[string]any { return e.extensions }
+
+func wrapError(err error) error {
+	var derr domain.Error
+	if !errors.As(err, &derr) || derr.Kind == domain.ErrKindInternal {
+		return resolverError{msg: "internal server error", extensions: map[string]any{"code": string(domain.ErrKindInternal)}}
+	}
+
+	ext := map[string]any{"code": string(derr.Kind)}
+	if len(derr.Fields) > 0 {
+		fields := make([]map[string]any, 0, len(derr.Fields))
+		for _, f := range derr.Fields {
+			fields = append(fields, map[string]any{"field": f.Field, "rule": f.Rule, "message": f.Message})
+		}
+		ext["errors"] = fields
+	}
+	return resolverError{msg: derr.Message, extensions: ext}
+}
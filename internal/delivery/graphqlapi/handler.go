@@ -0,0 +1,90 @@
+package graphqlapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+
+	"github.com/rohitashk/golang-rest-api/internal/delivery/httpapi/response"
+	"github.com/rohitashk/golang-rest-api/internal/domain"
+	employeeUC "github.com/rohitashk/golang-rest-api/internal/usecase/employee"
+)
+
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+type Handler struct {
+	schema         graphql.Schema
+	svc            *employeeUC.Service
+	limits         Limits
+	requestTimeout func() time.Duration
+}
+
+func NewHandler(svc *employeeUC.Service, limits Limits, requestTimeout func() time.Duration) (*Handler, error) {
+	schema, err := newSchema(svc)
+	if err != nil {
+		return nil, err
+	}
+	if requestTimeout == nil {
+		requestTimeout = func() time.Duration { return 5 * time.Second }
+	}
+	return &Handler{schema: schema, svc: svc, limits: limits, requestTimeout: requestTimeout}, nil
+}
+
+func (h *Handler) Serve(c *gin.Context) {
+	var req Request
+	if c.Request.Method == http.MethodGet {
+		req.Query = c.Query("query")
+		req.OperationName = c.Query("operationName")
+		if v := c.Query("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				response.Error(c, domain.Validation("variables must be a JSON object"))
+				return
+			}
+		}
+	} else if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, err)
+		return
+	}
+	if req.Query == "" {
+		response.Error(c, domain.Validation("query is required"))
+		return
+	}
+
+	doc, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{Body: []byte(req.Query)})})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, &graphql.Result{Errors: gqlerrors.FormatErrors(err)})
+		return
+	}
+	if res := graphql.ValidateDocument(&h.schema, doc, nil); !res.IsValid {
+		c.JSON(http.StatusBadRequest, &graphql.Result{Errors: res.Errors})
+		return
+	}
+	if err := checkLimits(doc, req.OperationName, req.Variables, h.limits); err != nil {
+		c.JSON(http.StatusBadRequest, &graphql.Result{Errors: gqlerrors.FormatErrors(err)})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout())
+	defer cancel()
+	ctx = context.WithValue(ctx, loadersKey{}, newLoaders(ctx, h.svc))
+
+	res := graphql.Execute(graphql.ExecuteParams{
+		Schema:        h.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       ctx,
+	})
+	c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,205 @@
+package graphqlapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/rohitashk/golang-rest-api/internal/adapters/memory"
+	"github.com/rohitashk/golang-rest-api/internal/domain"
+	domainEmployee "github.com/rohitashk/golang-rest-api/internal/domain/employee"
+	"github.com/rohitashk/golang-rest-api/internal/domain/tenant"
+	employeeUC "github.com/rohitashk/golang-rest-api/internal/usecase/employee"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// countingRepository counts the listings and headcounts read, and fails
+// them with err when set.
+type countingRepository struct {
+	*memory.EmployeeRepository
+	lists, headcounts atomic.Int32
+	err               error
+}
+
+func (r *countingRepository) List(ctx context.Context, filter domainEmployee.ListFilter, page domainEmployee.ListPage, fields ...string) ([]domainEmployee.Employee, int64, error) {
+	r.lists.Add(1)
+	if r.err != nil {
+		return nil, 0, r.err
+	}
+	return r.EmployeeRepository.List(ctx, filter, page, fields...)
+}
+
+func (r *countingRepository) Headcount(ctx context.Context, departments ...string) (map[string]int64, error) {
+	r.headcounts.Add(1)
+	return r.EmployeeRepository.Headcount(ctx, departments...)
+}
+
+// newServer serves GraphQL over two managers, Grace in Engineering and Joan
+// in Sales, with two reports each.
+func newServer(t *testing.T, limits Limits) (*gin.Engine, *countingRepository) {
+	t.Helper()
+	repo := &countingRepository{EmployeeRepository: memory.NewEmployeeRepository()}
+	svc := employeeUC.NewService(employeeUC.Deps{Repo: repo})
+	ctx := tenant.WithID(context.Background(), "acme")
+	for _, team := range []struct{ manager, department string }{{"grace", "Engineering"}, {"joan", "Sales"}} {
+		m, err := svc.Create(ctx, employeeUC.CreateInput{FirstName: team.manager, LastName: "Lead", Email: team.manager + "@example.com", Department: team.department, Position: "Director"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range []string{"a", "b"} {
+			if _, err := svc.Create(ctx, employeeUC.CreateInput{
+				FirstName: team.manager + "-" + name, LastName: "Report", Email: team.manager + "-" + name + "@example.com",
+				Department: team.department, Position: "Engineer", ManagerID: m.ID,
+			}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	h, err := NewHandler(svc, limits, func() time.Duration { return time.Second })
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(tenant.WithID(c.Request.Context(), "acme"))
+	})
+	r.POST("/graphql", h.Serve)
+	repo.lists.Store(0)
+	return r, repo
+}
+
+type result struct {
+	Data   map[string]any
+	Errors []struct {
+		Message    string
+		Extensions map[string]any
+	}
+}
+
+func query(t *testing.T, r http.Handler, q string) (int, result) {
+	t.Helper()
+	body, _ := json.Marshal(Request{Query: q})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body)))
+	var res result
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("%v: %s", err, w.Body)
+	}
+	return w.Code, res
+}
+
+func TestLimits(t *testing.T) {
+	r, repo := newServer(t, Limits{MaxDepth: 4, MaxComplexity: 50})
+
+	tests := []struct {
+		name, query, want string
+	}{
+		{"depth", `{ employees { items { manager { manager { id } } } } }`, "depth 5 exceeds the maximum of 4"},
+		// 1 + 30 × (1 + 1 + 1) for the page of items, their ids and departments.
+		{"complexity", `{ employees(limit: 30) { items { id department { name } } } }`, "complexity"},
+		{"fragment", `{ ...deep } fragment deep on Query { employees { items { manager { manager { id } } } } }`, "depth 5"},
+	}
+	for _, tt := range tests {
+		code, res := query(t, r, tt.query)
+		if code != http.StatusBadRequest || len(res.Errors) != 1 || !strings.Contains(res.Errors[0].Message, tt.want) {
+			t.Errorf("%s: %d %+v, want 400 with %q", tt.name, code, res.Errors, tt.want)
+		}
+	}
+	if n := repo.lists.Load(); n != 0 {
+		t.Errorf("rejected queries listed employees %d times", n)
+	}
+
+	if code, res := query(t, r, `{ employees(limit: 5) { total items { id department { name } } } }`); code != http.StatusOK || len(res.Errors) != 0 {
+		t.Errorf("within the limits: %d %+v", code, res.Errors)
+	}
+}
+
+func TestNestedListsAreBatched(t *testing.T) {
+	r, repo := newServer(t, Limits{})
+
+	_, res := query(t, r, `{
+		employees(limit: 10) {
+			items { firstName directReports { total items { firstName manager { firstName } } } department { name headcount } }
+		}
+	}`)
+	if len(res.Errors) != 0 {
+		t.Fatal(res.Errors)
+	}
+	// The top-level page, every employee's reports and every manager:
+	// one listing each, however many employees there are.
+	if n := repo.lists.Load(); n != 3 {
+		t.Errorf("listed %d times, want 3", n)
+	}
+	if n := repo.headcounts.Load(); n != 1 {
+		t.Errorf("counted heads %d times, want 1", n)
+	}
+	reports := map[string]float64{}
+	for _, item := range res.Data["employees"].(map[string]any)["items"].([]any) {
+		e := item.(map[string]any)
+		reports[e["firstName"].(string)] = e["directReports"].(map[string]any)["total"].(float64)
+	}
+	if reports["grace"] != 2 || reports["joan"] != 2 || reports["grace-a"] != 0 || len(reports) != 6 {
+		t.Errorf("direct reports = %v", reports)
+	}
+
+	repo.lists.Store(0)
+	_, res = query(t, r, `{ departments { name employees(limit: 1, offset: 1) { total items { firstName } } } }`)
+	if len(res.Errors) != 0 {
+		t.Fatal(res.Errors)
+	}
+	if n := repo.lists.Load(); n != 1 {
+		t.Errorf("listed %d times for every department, want 1", n)
+	}
+	for _, d := range res.Data["departments"].([]any) {
+		page := d.(map[string]any)["employees"].(map[string]any)
+		if page["total"] != 3.0 || len(page["items"].([]any)) != 1 {
+			t.Errorf("%v: page = %v, want the second of 3", d.(map[string]any)["name"], page)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	r, repo := newServer(t, Limits{})
+
+	// Domain errors keep their kind.
+	repo.err = domain.Validation("invalid filter")
+	_, res := query(t, r, `{ employees { total } }`)
+	if len(res.Errors) != 1 || res.Errors[0].Message != "invalid filter" || res.Errors[0].Extensions["code"] != string(domain.ErrKindValidation) {
+		t.Errorf("validation error = %+v", res.Errors)
+	}
+
+	// Anything else is hidden.
+	repo.err = errors.New("connection refused")
+	_, res = query(t, r, `{ employees { total } }`)
+	if len(res.Errors) != 1 || res.Errors[0].Message != "internal server error" || res.Errors[0].Extensions["code"] != string(domain.ErrKindInternal) {
+		t.Errorf("internal error = %+v", res.Errors)
+	}
+}
+
+func TestWrapError(t *testing.T) {
+	err := wrapError(domain.InvalidFields("invalid input", []domain.FieldError{{Field: "email", Rule: "email", Message: "must be an email"}}))
+	var rerr resolverError
+	if !errors.As(err, &rerr) || rerr.msg != "invalid input" {
+		t.Fatalf("err = %#v", err)
+	}
+	fields, _ := rerr.extensions["errors"].([]map[string]any)
+	if len(fields) != 1 || fields[0]["field"] != "email" || fields[0]["rule"] != "email" {
+		t.Errorf("extensions = %v", rerr.extensions)
+	}
+	if got := wrapError(domain.Internal("failed to list employees", errors.New("boom"))).Error(); got != "internal server error" {
+		t.Errorf("internal error shown as %q", got)
+	}
+}
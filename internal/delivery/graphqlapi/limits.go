@@ -0,0 +1,111 @@
+package graphqlapi
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// defaultListSize is the multiplier for list fields without a limit argument.
+const defaultListSize = 20
+
+type Limits struct {
+	MaxDepth      int
+	MaxComplexity int
+}
+
+// checkLimits rejects operations nested deeper than MaxDepth or whose
+// estimated cost exceeds MaxComplexity. Every field costs 1; the selections
+// of paginated list fields are multiplied by the requested page size.
+func checkLimits(doc *ast.Document, operationName string, vars map[string]any, limits Limits) error {
+	fragments := map[string]*ast.FragmentDefinition{}
+	var op *ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		switch d := def.(type) {
+		case *ast.FragmentDefinition:
+			fragments[d.Name.Value] = d
+		case *ast.OperationDefinition:
+			if operationName == "" || (d.Name != nil && d.Name.Value == operationName) {
+				op = d
+			}
+		}
+	}
+	if op == nil {
+		return nil
+	}
+
+	w := walker{fragments: fragments, vars: vars}
+	depth, cost := w.selectionSet(op.SelectionSet, map[string]bool{})
+	if limits.MaxDepth > 0 && depth > limits.MaxDepth {
+		return fmt.Errorf("query depth %d exceeds the maximum of %d", depth, limits.MaxDepth)
+	}
+	if limits.MaxComplexity > 0 && cost > limits.MaxComplexity {
+		return fmt.Errorf("query complexity %d exceeds the maximum of %d", cost, limits.MaxComplexity)
+	}
+	return nil
+}
+
+type walker struct {
+	fragments map[string]*ast.FragmentDefinition
+	vars      map[string]any
+}
+
+func (w walker) selectionSet(set *ast.SelectionSet, visiting map[string]bool) (depth, cost int) {
+	if set == nil {
+		return 0, 0
+	}
+
+	for _, sel := range set.Selections {
+		var d, c int
+		switch s := sel.(type) {
+		case *ast.Field:
+			childDepth, childCost := w.selectionSet(s.SelectionSet, visiting)
+			d = childDepth + 1
+			c = 1 + childCost*w.listSize(s)
+		case *ast.InlineFragment:
+			d, c = w.selectionSet(s.SelectionSet, visiting)
+		case *ast.FragmentSpread:
+			name := s.Name.Value
+			frag, ok := w.fragments[name]
+			if !ok || visiting[name] {
+				continue
+			}
+			visiting[name] = true
+			d, c = w.selectionSet(frag.SelectionSet, visiting)
+			delete(visiting, name)
+		}
+		depth = max(depth, d)
+		cost += c
+	}
+	return depth, cost
+}
+
+func (w walker) listSize(f *ast.Field) int {
+	if !listFields[f.Name.Value] {
+		return 1
+	}
+	for _, arg := range f.Arguments {
+		if arg.Name.Value != "limit" {
+			continue
+		}
+		switch v := arg.Value.(type) {
+		case *ast.IntValue:
+			if n, err := strconv.Atoi(v.Value); err == nil && n > 0 {
+				return n
+			}
+		case *ast.Variable:
+			switch n := w.vars[v.Name.Value].(type) {
+			case float64:
+				if n > 0 {
+					return int(n)
+				}
+			case int:
+				if n > 0 {
+					return n
+				}
+			}
+		}
+	}
+	return defaultListSize
+}
@@ -0,0 +1,68 @@
+package graphqlapi
+
+import (
+	"sync"
+)
+
+// loader batches lookups made while resolving one level of a query. Load
+// only records the key and returns a thunk; graphql-go runs thunks after all
+// siblings were resolved, so the first thunk fetches every pending key at once.
+type loader[K comparable, V any] struct {
+	mu      sync.Mutex
+	fetch   func(keys []K) (map[K]V, error)
+	pending []K
+	queued  map[K]bool
+	results map[K]V
+	errs    map[K]error
+}
+
+func newLoader[K comparable, V any](fetch func(keys []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{
+		fetch:   fetch,
+		queued:  map[K]bool{},
+		results: map[K]V{},
+		errs:    map[K]error{},
+	}
+}
+
+func (l *loader[K, V]) Load(key K) func() (any, error) {
+	l.mu.Lock()
+	if !l.queued[key] {
+		l.queued[key] = true
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (any, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if _, done := l.errs[key]; !done {
+			l.dispatch()
+		}
+		if err := l.errs[key]; err != nil {
+			return nil, err
+		}
+		v, ok := l.results[key]
+		if !ok {
+			return nil, nil
+		}
+		return v, nil
+	}
+}
+
+func (l *loader[K, V]) dispatch() {
+	keys := l.pending
+	l.pending = nil
+	if len(keys) == 0 {
+		return
+	}
+
+	found, err := l.fetch(keys)
+	for _, k := range keys {
+		l.errs[k] = err
+		if v, ok := found[k]; ok && err == nil {
+			l.results[k] = v
+		}
+	}
+}
@@ -0,0 +1,321 @@
+package graphqlapi
+
+import (
+	"context"
+	"time"
+
+	"github.com/graphql-go/graphql"
+
+	domainEmployee "github.com/rohitashk/golang-rest-api/internal/domain/employee"
+	employeeUC "github.com/rohitashk/golang-rest-api/internal/usecase/employee"
+)
+
+// listFields are the fields taking limit/offset; they drive complexity.
+var listFields = map[string]bool{"employees": true, "directReports": true, "departments": true}
+
+type loadersKey struct{}
+
+// loaders are created per request so batches never mix callers.
+type loaders struct {
+	employees   *loader[string, *domainEmployee.Employee]
+	departments *loader[string, domainEmployee.Department]
+	// reports and members page the employees of a manager or department.
+	reports *loader[listKey, employeePage]
+	members *loader[listKey, employeePage]
+}
+
+// listKey names the page of one parent's employees; parents are batched
+// together when their pages are alike.
+type listKey struct {
+	parent string
+	page   listPage
+}
+
+type listPage struct {
+	status        string
+	limit, offset int64
+}
+
+func (p listPage) input() employeeUC.ListInput {
+	in := employeeUC.ListInput{Limit: p.limit, Offset: p.offset}
+	if p.status != "" {
+		in.Status = &p.status
+	}
+	return in
+}
+
+// pageLoader batches the pages of many parents into one listing per kind of
+// page.
+func pageLoader(list func(parents []string, in employeeUC.ListInput) (map[string]employeeUC.Page, error)) *loader[listKey, employeePage] {
+	return newLoader(func(keys []listKey) (map[listKey]employeePage, error) {
+		parents := map[listPage][]string{}
+		for _, k := range keys {
+			parents[k.page] = append(parents[k.page], k.parent)
+		}
+		out := make(map[listKey]employeePage, len(keys))
+		for page, ids := range parents {
+			found, err := list(ids, page.input())
+			if err != nil {
+				return nil, wrapError(err)
+			}
+			for id, p := range found {
+				out[listKey{parent: id, page: page}] = employeePage{items: p.Items, total: p.Total}
+			}
+		}
+		return out, nil
+	})
+}
+
+func newLoaders(ctx context.Context, svc *employeeUC.Service) *loaders {
+	return &loaders{
+		employees: newLoader(func(ids []string) (map[string]*domainEmployee.Employee, error) {
+			items, err := svc.GetMany(ctx, ids)
+			if err != nil {
+				return nil, wrapError(err)
+			}
+			out := make(map[string]*domainEmployee.Employee, len(items))
+			for i := range items {
+				out[items[i].ID] = &items[i]
+			}
+			return out, nil
+		}),
+		departments: newLoader(func(names []string) (map[string]domainEmployee.Department, error) {
+			items, err := svc.Departments(ctx, names...)
+			if err != nil {
+				return nil, wrapError(err)
+			}
+			out := make(map[string]domainEmployee.Department, len(items))
+			for _, d := range items {
+				out[d.Name] = d
+			}
+			return out, nil
+		}),
+		reports: pageLoader(func(ids []string, in employeeUC.ListInput) (map[string]employeeUC.Page, error) {
+			return svc.ListByManagers(ctx, ids, in)
+		}),
+		members: pageLoader(func(names []string, in employeeUC.ListInput) (map[string]employeeUC.Page, error) {
+			return svc.ListByDepartments(ctx, names, in)
+		}),
+	}
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	l, _ := ctx.Value(loadersKey{}).(*loaders)
+	return l
+}
+
+type employeePage struct {
+	items []domainEmployee.Employee
+	total int64
+}
+
+func newSchema(svc *employeeUC.Service) (graphql.Schema, error) {
+	statusEnum := graphql.NewEnum(graphql.EnumConfig{
+		Name: "EmployeeStatus",
+		Values: graphql.EnumValueConfigMap{
+			"ACTIVE":   {Value: string(domainEmployee.StatusActive)},
+			"INACTIVE": {Value: string(domainEmployee.StatusInactive)},
+		},
+	})
+
+	listArgs := graphql.FieldConfigArgument{
+		"status": {Type: statusEnum},
+		"limit":  {Type: graphql.Int, DefaultValue: defaultListSize},
+		"offset": {Type: graphql.Int, DefaultValue: 0},
+	}
+	withListArgs := func(extra graphql.FieldConfigArgument) graphql.FieldConfigArgument {
+		out := graphql.FieldConfigArgument{}
+		for k, v := range listArgs {
+			out[k] = v
+		}
+		for k, v := range extra {
+			out[k] = v
+		}
+		return out
+	}
+
+	pageArgs := func(p graphql.ResolveParams) listPage {
+		var page listPage
+		if v, ok := p.Args["status"].(string); ok {
+			page.status = v
+		}
+		if v, ok := p.Args["limit"].(int); ok {
+			page.limit = int64(v)
+		}
+		if v, ok := p.Args["offset"].(int); ok {
+			page.offset = int64(v)
+		}
+		return page
+	}
+	listEmployees := func(p graphql.ResolveParams, in employeeUC.ListInput) (any, error) {
+		page := pageArgs(p).input()
+		in.Status, in.Limit, in.Offset = page.Status, page.Limit, page.Offset
+		items, total, err := svc.List(p.Context, in)
+		if err != nil {
+			return nil, wrapError(err)
+		}
+		return employeePage{items: items, total: total}, nil
+	}
+
+	var employeeType, departmentType *graphql.Object
+
+	connectionType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "EmployeeConnection",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"items": {
+					Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(employeeType))),
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						page := p.Source.(employeePage)
+						out := make([]any, 0, len(page.items))
+						for i := range page.items {
+							out = append(out, &page.items[i])
+						}
+						return out, nil
+					},
+				},
+				"total": {
+					Type: graphql.NewNonNull(graphql.Int),
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						return int(p.Source.(employeePage).total), nil
+					},
+				},
+			}
+		}),
+	})
+
+	employeeField := func(get func(e *domainEmployee.Employee) any, t graphql.Output) *graphql.Field {
+		return &graphql.Field{
+			Type: t,
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				return get(p.Source.(*domainEmployee.Employee)), nil
+			},
+		}
+	}
+	nonNullString := graphql.NewNonNull(graphql.String)
+
+	employeeType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Employee",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":        employeeField(func(e *domainEmployee.Employee) any { return e.ID }, graphql.NewNonNull(graphql.ID)),
+				"firstName": employeeField(func(e *domainEmployee.Employee) any { return e.FirstName }, nonNullString),
+				"lastName":  employeeField(func(e *domainEmployee.Employee) any { return e.LastName }, nonNullString),
+				"email":     employeeField(func(e *domainEmployee.Employee) any { return e.Email }, nonNullString),
+				"position":  employeeField(func(e *domainEmployee.Employee) any { return e.Position }, nonNullString),
+				"salary":    employeeField(func(e *domainEmployee.Employee) any { return e.Salary }, graphql.NewNonNull(graphql.Float)),
+				"status":    employeeField(func(e *domainEmployee.Employee) any { return string(e.Status) }, graphql.NewNonNull(statusEnum)),
+				"createdAt": employeeField(func(e *domainEmployee.Employee) any { return e.CreatedAt.UTC().Format(time.RFC3339Nano) }, nonNullString),
+				"updatedAt": employeeField(func(e *domainEmployee.Employee) any { return e.UpdatedAt.UTC().Format(time.RFC3339Nano) }, nonNullString),
+				"department": {
+					Type: graphql.NewNonNull(departmentType),
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						return loadersFrom(p.Context).departments.Load(p.Source.(*domainEmployee.Employee).Department), nil
+					},
+				},
+				"manager": {
+					Type: employeeType,
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						e := p.Source.(*domainEmployee.Employee)
+						if e.ManagerID == "" {
+							return nil, nil
+						}
+						return loadersFrom(p.Context).employees.Load(e.ManagerID), nil
+					},
+				},
+				"directReports": {
+					Type: graphql.NewNonNull(connectionType),
+					Args: withListArgs(nil),
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						id := p.Source.(*domainEmployee.Employee).ID
+						return loadersFrom(p.Context).reports.Load(listKey{parent: id, page: pageArgs(p)}), nil
+					},
+				},
+			}
+		}),
+	})
+
+	departmentType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Department",
+		Fields: graphql.Fields{
+			"name": {
+				Type: nonNullString,
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return p.Source.(domainEmployee.Department).Name, nil
+				},
+			},
+			"headcount": {
+				Type:        graphql.NewNonNull(graphql.Int),
+				Description: "Number of active employees.",
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return int(p.Source.(domainEmployee.Department).Headcount), nil
+				},
+			},
+			"employees": {
+				Type: graphql.NewNonNull(connectionType),
+				Args: listArgs,
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					name := p.Source.(domainEmployee.Department).Name
+					return loadersFrom(p.Context).members.Load(listKey{parent: name, page: pageArgs(p)}), nil
+				},
+			},
+		},
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"employee": {
+				Type: employeeType,
+				Args: graphql.FieldConfigArgument{"id": {Type: graphql.NewNonNull(graphql.ID)}},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return loadersFrom(p.Context).employees.Load(p.Args["id"].(string)), nil
+				},
+			},
+			"employees": {
+				Type: graphql.NewNonNull(connectionType),
+				Args: withListArgs(graphql.FieldConfigArgument{
+					"department": {Type: graphql.String},
+					"managerId":  {Type: graphql.ID},
+					"q":          {Type: graphql.String, Description: "Case-insensitive search in name and email."},
+				}),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					var in employeeUC.ListInput
+					if v, ok := p.Args["department"].(string); ok {
+						in.Department = &v
+					}
+					if v, ok := p.Args["managerId"].(string); ok {
+						in.ManagerID = &v
+					}
+					if v, ok := p.Args["q"].(string); ok {
+						in.Query = &v
+					}
+					return listEmployees(p, in)
+				},
+			},
+			"department": {
+				Type: departmentType,
+				Args: graphql.FieldConfigArgument{"name": {Type: graphql.NewNonNull(graphql.String)}},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return loadersFrom(p.Context).departments.Load(p.Args["name"].(string)), nil
+				},
+			},
+			"departments": {
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(departmentType))),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					items, err := svc.Departments(p.Context)
+					if err != nil {
+						return nil, wrapError(err)
+					}
+					out := make([]any, 0, len(items))
+					for _, d := range items {
+						out = append(out, d)
+					}
+					return out, nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query})
+}
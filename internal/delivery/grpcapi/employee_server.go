@@ -0,0 +1,215 @@
+package grpcapi
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/rohitashk/golang-rest-api/internal/delivery/grpcapi/employeev1"
+	domainEmployee "github.com/rohitashk/golang-rest-api/internal/domain/employee"
+	employeeUC "github.com/rohitashk/golang-rest-api/internal/usecase/employee"
+)
+
+// listPageSize is the page size used to walk the repository while streaming.
+const listPageSize = 200
+
+type EmployeeServer struct {
+	employeev1.UnimplementedEmployeeServiceServer
+
+	svc            *employeeUC.Service
+	requestTimeout func() time.Duration
+}
+
+func NewEmployeeServer(svc *employeeUC.Service, requestTimeout func() time.Duration) *EmployeeServer {
+	if requestTimeout == nil {
+		requestTimeout = func() time.Duration { return 5 * time.Second }
+	}
+	return &EmployeeServer{svc: svc, requestTimeout: requestTimeout}
+}
+
+func (s *EmployeeServer) CreateEmployee(ctx context.Context, req *employeev1.CreateEmployeeRequest) (*employeev1.Employee, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.requestTimeout())
+	defer cancel()
+
+	e, err := s.svc.Create(ctx, employeeUC.CreateInput{
+		FirstName:    req.GetFirstName(),
+		LastName:     req.GetLastName(),
+		Email:        req.GetEmail(),
+		Department:   req.GetDepartment(),
+		Position:     req.GetPosition(),
+		Salary:       req.GetSalary(),
+		Status:       fromProtoStatus(req.GetStatus()),
+		ManagerID:    req.GetManagerId(),
+		Phone:        req.GetPhone(),
+		DateOfBirth:  req.GetDateOfBirth(),
+		CustomFields: req.GetCustomFields().AsMap(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProto(e), nil
+}
+
+func (s *EmployeeServer) GetEmployee(ctx context.Context, req *employeev1.GetEmployeeRequest) (*employeev1.Employee, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.requestTimeout())
+	defer cancel()
+
+	e, err := s.svc.Get(ctx, req.GetId(), req.GetFields()...)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProto(e), nil
+}
+
+func (s *EmployeeServer) ListEmployees(req *employeev1.ListEmployeesRequest, stream employeev1.EmployeeService_ListEmployeesServer) error {
+	var statusPtr *string
+	if st := fromProtoStatus(req.GetStatus()); st != "" {
+		statusPtr = &st
+	}
+
+	remaining := req.GetLimit()
+	var offset int64
+	headerSent := false
+	for {
+		pageSize := int64(listPageSize)
+		if remaining > 0 && remaining < pageSize {
+			pageSize = remaining
+		}
+
+		// Each page gets its own timeout; the stream as a whole is bounded
+		// by the client's deadline.
+		ctx, cancel := context.WithTimeout(stream.Context(), s.requestTimeout())
+		items, total, err := s.svc.List(ctx, employeeUC.ListInput{
+			Department: req.Department,
+			Status:     statusPtr,
+			Query:      req.Query,
+			Limit:      pageSize,
+			Offset:     offset,
+			Fields:     req.GetFields(),
+		})
+		cancel()
+		if err != nil {
+			return toStatus(err)
+		}
+
+		if !headerSent {
+			if err := stream.SendHeader(metadata.Pairs("x-total-count", strconv.FormatInt(total, 10))); err != nil {
+				return err
+			}
+			headerSent = true
+		}
+
+		for i := range items {
+			if err := stream.Send(toProto(&items[i])); err != nil {
+				return err
+			}
+		}
+
+		offset += int64(len(items))
+		if remaining > 0 {
+			remaining -= int64(len(items))
+			if remaining <= 0 {
+				return nil
+			}
+		}
+		if int64(len(items)) < pageSize || offset >= total {
+			return nil
+		}
+	}
+}
+
+func (s *EmployeeServer) UpdateEmployee(ctx context.Context, req *employeev1.UpdateEmployeeRequest) (*employeev1.Employee, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.requestTimeout())
+	defer cancel()
+
+	in := employeeUC.UpdateInput{
+		FirstName:   req.FirstName,
+		LastName:    req.LastName,
+		Email:       req.Email,
+		Department:  req.Department,
+		Position:    req.Position,
+		Salary:      req.Salary,
+		ManagerID:   req.ManagerId,
+		Phone:       req.Phone,
+		DateOfBirth: req.DateOfBirth,
+	}
+	if st := fromProtoStatus(req.GetStatus()); st != "" {
+		in.Status = &st
+	}
+	if req.CustomFields != nil {
+		in.CustomFields = req.CustomFields.AsMap()
+	}
+	if req.IfUpdatedAt != nil {
+		at := req.IfUpdatedAt.AsTime()
+		in.IfUpdatedAt = &at
+	}
+
+	e, err := s.svc.Update(ctx, req.GetId(), in)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProto(e), nil
+}
+
+func (s *EmployeeServer) DeleteEmployee(ctx context.Context, req *employeev1.DeleteEmployeeRequest) (*emptypb.Empty, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.requestTimeout())
+	defer cancel()
+
+	if err := s.svc.Delete(ctx, req.GetId()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func toProto(e *domainEmployee.Employee) *employeev1.Employee {
+	out := &employeev1.Employee{
+		Id:          e.ID,
+		FirstName:   e.FirstName,
+		LastName:    e.LastName,
+		Email:       e.Email,
+		Department:  e.Department,
+		Position:    e.Position,
+		Salary:      e.Salary,
+		Status:      toProtoStatus(e.Status),
+		ManagerId:   e.ManagerID,
+		Phone:       e.Phone,
+		DateOfBirth: e.DateOfBirth,
+	}
+	// Custom field values are strings, numbers and booleans, which a Struct
+	// always holds.
+	if len(e.CustomFields) > 0 {
+		out.CustomFields, _ = structpb.NewStruct(e.CustomFields)
+	}
+	if !e.CreatedAt.IsZero() {
+		out.CreatedAt = timestamppb.New(e.CreatedAt)
+	}
+	if !e.UpdatedAt.IsZero() {
+		out.UpdatedAt = timestamppb.New(e.UpdatedAt)
+	}
+	return out
+}
+
+func toProtoStatus(s domainEmployee.Status) employeev1.EmployeeStatus {
+	switch s {
+	case domainEmployee.StatusActive:
+		return employeev1.EmployeeStatus_EMPLOYEE_STATUS_ACTIVE
+	case domainEmployee.StatusInactive:
+		return employeev1.EmployeeStatus_EMPLOYEE_STATUS_INACTIVE
+	}
+	return employeev1.EmployeeStatus_EMPLOYEE_STATUS_UNSPECIFIED
+}
+
+func fromProtoStatus(s employeev1.EmployeeStatus) string {
+	switch s {
+	case employeev1.EmployeeStatus_EMPLOYEE_STATUS_ACTIVE:
+		return string(domainEmployee.StatusActive)
+	case employeev1.EmployeeStatus_EMPLOYEE_STATUS_INACTIVE:
+		return string(domainEmployee.StatusInactive)
+	}
+	return ""
+}
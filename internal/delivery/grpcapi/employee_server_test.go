@@ -0,0 +1,69 @@
+package grpcapi
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/rohitashk/golang-rest-api/internal/adapters/memory"
+	"github.com/rohitashk/golang-rest-api/internal/delivery/grpcapi/employeev1"
+	"github.com/rohitashk/golang-rest-api/internal/domain/customfield"
+	"github.com/rohitashk/golang-rest-api/internal/domain/tenant"
+	employeeUC "github.com/rohitashk/golang-rest-api/internal/usecase/employee"
+)
+
+func TestEmployeeOptionalFields(t *testing.T) {
+	defs := memory.NewCustomFieldRepository()
+	for _, d := range []customfield.Definition{
+		{Key: "badge", Type: customfield.TypeString},
+		{Key: "floor", Type: customfield.TypeNumber},
+	} {
+		if err := defs.Create(tenant.WithID(context.Background(), "acme"), &d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	client := dialService(t, Tenancy{}, employeeUC.NewService(employeeUC.Deps{Repo: memory.NewEmployeeRepository(), CustomFields: defs}))
+	ctx := withMetadata(t, apiKeyKey, acmeKey)
+
+	fields, _ := structpb.NewStruct(map[string]any{"badge": "AB1234", "floor": 3})
+	created, err := client.CreateEmployee(ctx, &employeev1.CreateEmployeeRequest{
+		FirstName: "Ada", LastName: "Lovelace", Email: "ada@example.com", Department: "R&D", Position: "Engineer",
+		Phone: "+14155550100", DateOfBirth: "1815-12-10", CustomFields: fields,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := client.GetEmployee(ctx, &employeev1.GetEmployeeRequest{Id: created.Id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Phone != "+14155550100" || got.DateOfBirth != "1815-12-10" {
+		t.Errorf("phone %q, date of birth %q", got.Phone, got.DateOfBirth)
+	}
+	if m := got.CustomFields.AsMap(); m["badge"] != "AB1234" || m["floor"] != 3.0 {
+		t.Errorf("custom fields = %v", m)
+	}
+
+	// Only the named custom fields change, and null clears one.
+	empty := ""
+	clear, _ := structpb.NewStruct(map[string]any{"floor": nil})
+	updated, err := client.UpdateEmployee(ctx, &employeev1.UpdateEmployeeRequest{
+		Id: created.Id, Phone: &empty, CustomFields: clear, IfUpdatedAt: got.UpdatedAt,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := updated.CustomFields.AsMap(); updated.Phone != "" || updated.DateOfBirth != "1815-12-10" || len(m) != 1 || m["badge"] != "AB1234" {
+		t.Errorf("updated phone %q, date of birth %q, custom fields %v", updated.Phone, updated.DateOfBirth, m)
+	}
+
+	// The employee changed since got was read.
+	position := "Lead"
+	_, err = client.UpdateEmployee(ctx, &employeev1.UpdateEmployeeRequest{Id: created.Id, Position: &position, IfUpdatedAt: got.UpdatedAt})
+	if status.Code(err) != codes.Aborted {
+		t.Errorf("stale update: %v, want Aborted", err)
+	}
+}
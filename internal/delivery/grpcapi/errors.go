@@ -0,0 +1,74 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/rohitashk/golang-rest-api/internal/domain"
+	domainEmployee "github.com/rohitashk/golang-rest-api/internal/domain/employee"
+)
+
+// toStatus is the gRPC counterpart of response.Error.
+func toStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return status.Error(codes.DeadlineExceeded, "request timed out")
+	}
+	if errors.Is(err, context.Canceled) {
+		return status.Error(codes.Canceled, "request canceled")
+	}
+
+	var derr domain.Error
+	if !errors.As(err, &derr) {
+		return status.Error(codes.Internal, "internal server error")
+	}
+
+	var code codes.Code
+	switch derr.Kind {
+	case domain.ErrKindValidation:
+		code = codes.InvalidArgument
+	case domain.ErrKindNotFound:
+		code = codes.NotFound
+	case domain.ErrKindConflict:
+		code = codes.AlreadyExists
+		// A lost conditional update is not a duplicate; the client reads
+		// again and retries.
+		if errors.Is(err, domainEmployee.ErrModified) {
+			code = codes.Aborted
+		}
+	case domain.ErrKindUnprocessable:
+		code = codes.FailedPrecondition
+	case domain.ErrKindUnauthorized:
+		code = codes.Unauthenticated
+	case domain.ErrKindForbidden:
+		code = codes.PermissionDenied
+	case domain.ErrKindRateLimited:
+		code = codes.ResourceExhausted
+	default:
+		return status.Error(codes.Internal, "internal server error")
+	}
+
+	st := status.New(code, derr.Message)
+	if len(derr.Fields) > 0 {
+		br := &errdetails.BadRequest{}
+		for _, f := range derr.Fields {
+			br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       f.Field,
+				Description: f.Message,
+			})
+		}
+		if withDetails, err := st.WithDetails(br); err == nil {
+			st = withDetails
+		}
+	}
+	return st.Err()
+}
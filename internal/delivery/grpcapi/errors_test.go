@@ -0,0 +1,75 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/rohitashk/golang-rest-api/internal/domain"
+	domainEmployee "github.com/rohitashk/golang-rest-api/internal/domain/employee"
+)
+
+func TestToStatusMapsDomainKinds(t *testing.T) {
+	tests := []struct {
+		err  error
+		code codes.Code
+		msg  string
+	}{
+		{domain.Validation("bad input"), codes.InvalidArgument, "bad input"},
+		{domain.NotFound("no such employee"), codes.NotFound, "no such employee"},
+		{domain.Conflict("taken"), codes.AlreadyExists, "taken"},
+		{fmt.Errorf("update: %w", domainEmployee.ErrModified), codes.Aborted, "employee was changed by another request; read it again and retry"},
+		{domain.Unprocessable("cannot"), codes.FailedPrecondition, "cannot"},
+		{domain.Unauthorized("who are you"), codes.Unauthenticated, "who are you"},
+		{domain.Forbidden("not yours"), codes.PermissionDenied, "not yours"},
+		{domain.RateLimited("slow down"), codes.ResourceExhausted, "slow down"},
+		{fmt.Errorf("wrapped: %w", domain.NotFound("gone")), codes.NotFound, "gone"},
+		{context.DeadlineExceeded, codes.DeadlineExceeded, "request timed out"},
+		{context.Canceled, codes.Canceled, "request canceled"},
+		{domain.Internal("db exploded", errors.New("secret")), codes.Internal, "internal server error"},
+		{errors.New("plain"), codes.Internal, "internal server error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code.String(), func(t *testing.T) {
+			st := status.Convert(toStatus(tt.err))
+			if st.Code() != tt.code || st.Message() != tt.msg {
+				t.Errorf("got %s %q, want %s %q", st.Code(), st.Message(), tt.code, tt.msg)
+			}
+		})
+	}
+}
+
+func TestToStatusKeepsStatusErrors(t *testing.T) {
+	in := status.Error(codes.Unavailable, "down")
+	if got := toStatus(in); got != in {
+		t.Errorf("toStatus changed a status error: %v", got)
+	}
+	if toStatus(nil) != nil {
+		t.Error("toStatus(nil) is not nil")
+	}
+}
+
+func TestToStatusAttachesFieldViolations(t *testing.T) {
+	st := status.Convert(toStatus(domain.InvalidFields("invalid employee", []domain.FieldError{
+		{Field: "email", Rule: "email", Message: "email must be a valid email address"},
+	})))
+	if st.Code() != codes.InvalidArgument {
+		t.Fatalf("code = %s", st.Code())
+	}
+	details := st.Details()
+	if len(details) != 1 {
+		t.Fatalf("details = %v", details)
+	}
+	br, ok := details[0].(*errdetails.BadRequest)
+	if !ok || len(br.FieldViolations) != 1 {
+		t.Fatalf("details[0] = %#v", details[0])
+	}
+	if v := br.FieldViolations[0]; v.Field != "email" || v.Description != "email must be a valid email address" {
+		t.Errorf("violation = %v", v)
+	}
+}
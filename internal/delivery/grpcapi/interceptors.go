@@ -0,0 +1,103 @@
+package grpcapi
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// requestIDKey is the metadata counterpart of the X-Request-Id HTTP header.
+const requestIDKey = "x-request-id"
+
+func unaryLogger(l *slog.Logger) grpc.UnaryServerInterceptor {
+	if l == nil {
+		l = slog.Default()
+	}
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		rid := requestID(ctx)
+		_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, rid))
+
+		resp, err := handler(ctx, req)
+
+		logCall(l, info.FullMethod, rid, start, err)
+		return resp, err
+	}
+}
+
+func streamLogger(l *slog.Logger) grpc.StreamServerInterceptor {
+	if l == nil {
+		l = slog.Default()
+	}
+
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		rid := requestID(ss.Context())
+		_ = ss.SetHeader(metadata.Pairs(requestIDKey, rid))
+
+		err := handler(srv, ss)
+
+		logCall(l, info.FullMethod, rid, start, err)
+		return err
+	}
+}
+
+func unaryRecovery(l *slog.Logger) grpc.UnaryServerInterceptor {
+	if l == nil {
+		l = slog.Default()
+	}
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				l.Error("grpc panic recovered", "method", info.FullMethod, "panic", r)
+				err = status.Error(codes.Internal, "internal server error")
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+func streamRecovery(l *slog.Logger) grpc.StreamServerInterceptor {
+	if l == nil {
+		l = slog.Default()
+	}
+
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				l.Error("grpc panic recovered", "method", info.FullMethod, "panic", r)
+				err = status.Error(codes.Internal, "internal server error")
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+func logCall(l *slog.Logger, method, rid string, start time.Time, err error) {
+	l.Info("grpc request",
+		"method", method,
+		"code", status.Code(err).String(),
+		"latency_ms", time.Since(start).Milliseconds(),
+		"request_id", rid,
+	)
+}
+
+func requestID(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get(requestIDKey); len(v) > 0 && v[0] != "" {
+			return v[0]
+		}
+	}
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,31 @@
+package grpcapi
+
+import (
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+
+	"github.com/rohitashk/golang-rest-api/internal/delivery/grpcapi/employeev1"
+	employeeUC "github.com/rohitashk/golang-rest-api/internal/usecase/employee"
+)
+
+type ServerDeps struct {
+	Logger         *slog.Logger
+	RequestTimeout func() time.Duration
+	EmployeeSvc    *employeeUC.Service
+	Tenancy        Tenancy
+}
+
+func NewServer(deps ServerDeps) *grpc.Server {
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(unaryRecovery(deps.Logger), unaryLogger(deps.Logger), unaryTenant(deps.Tenancy)),
+		grpc.ChainStreamInterceptor(streamRecovery(deps.Logger), streamLogger(deps.Logger), streamTenant(deps.Tenancy)),
+	)
+
+	employeev1.RegisterEmployeeServiceServer(srv, NewEmployeeServer(deps.EmployeeSvc, deps.RequestTimeout))
+	reflection.Register(srv)
+
+	return srv
+}
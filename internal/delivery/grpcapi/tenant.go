@@ -0,0 +1,126 @@
+package grpcapi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/rohitashk/golang-rest-api/internal/auth"
+	"github.com/rohitashk/golang-rest-api/internal/domain"
+	"github.com/rohitashk/golang-rest-api/internal/domain/tenant"
+)
+
+// Metadata counterparts of the X-Tenant-ID, X-API-Key and Authorization HTTP
+// headers.
+const (
+	tenantKey        = "x-tenant-id"
+	apiKeyKey        = "x-api-key"
+	authorizationKey = "authorization"
+)
+
+// Tenancy authenticates every call and resolves its tenant, like
+// middleware.Authenticate and middleware.Tenant do for HTTP. The zero value
+// only allows tenant.Default, to calls without credentials.
+type Tenancy struct {
+	Known   func(id string) bool
+	Default func() string // empty rejects calls that name no tenant
+
+	// Authenticate returns the caller an API key identifies; nil knows none.
+	Authenticate func(key string) (auth.Principal, bool)
+	// TrustHeader takes x-tenant-id from calls without credentials, behind a
+	// gateway that sets or strips it.
+	TrustHeader func() bool
+	// CredentialsRequired rejects calls without credentials.
+	CredentialsRequired func() bool
+}
+
+func (t Tenancy) resolve(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	first := func(key string) string {
+		if v := md.Get(key); len(v) > 0 {
+			return strings.TrimSpace(v[0])
+		}
+		return ""
+	}
+
+	key := first(apiKeyKey)
+	if scheme, token, _ := strings.Cut(first(authorizationKey), " "); key == "" && strings.EqualFold(scheme, "Bearer") {
+		key = strings.TrimSpace(token)
+	}
+	var p auth.Principal
+	if key != "" {
+		ok := false
+		if t.Authenticate != nil {
+			p, ok = t.Authenticate(key)
+		}
+		if !ok {
+			return nil, toStatus(domain.Unauthorized("invalid API key"))
+		}
+	}
+	named := first(tenantKey)
+
+	var id string
+	switch {
+	case p.Tenant != "" && named != "" && named != p.Tenant:
+		return nil, toStatus(domain.Forbidden(tenantKey + " does not match the tenant of the credentials"))
+	case p.Tenant != "":
+		id = p.Tenant
+	case key == "" && t.CredentialsRequired != nil && t.CredentialsRequired():
+		return nil, toStatus(domain.Unauthorized("an API key is required"))
+	case named != "" && (t.TrustHeader == nil || !t.TrustHeader()):
+		return nil, toStatus(domain.Unauthorized(tenantKey + " is only accepted with an API key of that tenant"))
+	case named != "":
+		id = named
+	default:
+		id = tenant.Default
+		if t.Default != nil {
+			id = t.Default()
+		}
+		if id == "" {
+			return nil, toStatus(domain.Unauthorized("an API key is required to name the tenant"))
+		}
+	}
+
+	known := id == tenant.Default
+	if t.Known != nil {
+		known = t.Known(id)
+	}
+	if !known {
+		return nil, toStatus(domain.Forbidden(fmt.Sprintf("unknown tenant %q", id)))
+	}
+	return tenant.WithID(ctx, id), nil
+}
+
+func unaryTenant(t Tenancy) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := t.resolve(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func streamTenant(t Tenancy) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		// Reflection describes the API and reads no tenant's records.
+		if strings.HasPrefix(info.FullMethod, "/grpc.reflection.") {
+			return handler(srv, ss)
+		}
+		ctx, err := t.resolve(ss.Context())
+		if err != nil {
+			return err
+		}
+		return handler(srv, &tenantStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+type tenantStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *tenantStream) Context() context.Context { return s.ctx }
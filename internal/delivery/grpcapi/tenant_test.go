@@ -0,0 +1,139 @@
+package grpcapi
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/rohitashk/golang-rest-api/internal/adapters/memory"
+	"github.com/rohitashk/golang-rest-api/internal/auth"
+	"github.com/rohitashk/golang-rest-api/internal/delivery/grpcapi/employeev1"
+	employeeUC "github.com/rohitashk/golang-rest-api/internal/usecase/employee"
+)
+
+const (
+	acmeKey   = "acme-0123456789abcdef"
+	globexKey = "globex-0123456789abcdef"
+)
+
+// dial serves the employee API in memory for the tenants acme and globex,
+// to callers with keys.
+func dial(t *testing.T, tenancy Tenancy) employeev1.EmployeeServiceClient {
+	t.Helper()
+	return dialService(t, tenancy, employeeUC.NewService(employeeUC.Deps{Repo: memory.NewEmployeeRepository()}))
+}
+
+func dialService(t *testing.T, tenancy Tenancy, svc *employeeUC.Service) employeev1.EmployeeServiceClient {
+	t.Helper()
+	keys, err := auth.ParseKeys([]string{"acme:" + acmeKey, "globex:" + globexKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tenancy.Authenticate = keys.Lookup
+	tenancy.Known = func(id string) bool { return id == "acme" || id == "globex" || id == "default" }
+
+	srv := NewServer(ServerDeps{
+		Logger:      slog.New(slog.NewTextHandler(io.Discard, nil)),
+		EmployeeSvc: svc,
+		Tenancy:     tenancy,
+	})
+	lis := bufconn.Listen(1 << 20)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return employeev1.NewEmployeeServiceClient(conn)
+}
+
+func withMetadata(t *testing.T, kv ...string) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	return metadata.NewOutgoingContext(ctx, metadata.Pairs(kv...))
+}
+
+func TestTenantsCannotReachEachOther(t *testing.T) {
+	client := dial(t, Tenancy{CredentialsRequired: func() bool { return true }})
+
+	created, err := client.CreateEmployee(withMetadata(t, apiKeyKey, acmeKey), &employeev1.CreateEmployeeRequest{
+		FirstName: "Ada", LastName: "Lovelace", Email: "ada@example.com", Department: "R&D", Position: "Engineer", Salary: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetEmployee(withMetadata(t, authorizationKey, "Bearer "+acmeKey), &employeev1.GetEmployeeRequest{Id: created.Id}); err != nil {
+		t.Fatalf("owner read: %v", err)
+	}
+
+	position := "Spy"
+	tests := []struct {
+		name string
+		call func(ctx context.Context) error
+		md   []string
+		code codes.Code
+	}{
+		{"read", get(client, created.Id), []string{apiKeyKey, globexKey}, codes.NotFound},
+		{"update", func(ctx context.Context) error {
+			_, err := client.UpdateEmployee(ctx, &employeev1.UpdateEmployeeRequest{Id: created.Id, Position: &position})
+			return err
+		}, []string{apiKeyKey, globexKey}, codes.NotFound},
+		{"delete", func(ctx context.Context) error {
+			_, err := client.DeleteEmployee(ctx, &employeev1.DeleteEmployeeRequest{Id: created.Id})
+			return err
+		}, []string{authorizationKey, "Bearer " + globexKey}, codes.NotFound},
+		{"name the other tenant", get(client, created.Id), []string{apiKeyKey, globexKey, tenantKey, "acme"}, codes.PermissionDenied},
+		{"tenant without a key", get(client, created.Id), []string{tenantKey, "acme"}, codes.Unauthenticated},
+		{"no credentials", get(client, created.Id), nil, codes.Unauthenticated},
+		{"guessed key", get(client, created.Id), []string{apiKeyKey, "acme-0123456789abcdeX"}, codes.Unauthenticated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := status.Code(tt.call(withMetadata(t, tt.md...))); code != tt.code {
+				t.Errorf("code = %s, want %s", code, tt.code)
+			}
+		})
+	}
+
+	got, err := client.GetEmployee(withMetadata(t, apiKeyKey, acmeKey), &employeev1.GetEmployeeRequest{Id: created.Id})
+	if err != nil || got.Position != "Engineer" {
+		t.Errorf("acme's employee changed: %v, %v", got, err)
+	}
+}
+
+func TestTrustedTenantMetadata(t *testing.T) {
+	client := dial(t, Tenancy{TrustHeader: func() bool { return true }})
+	created, err := client.CreateEmployee(withMetadata(t, tenantKey, "acme"), &employeev1.CreateEmployeeRequest{
+		FirstName: "Ada", LastName: "Lovelace", Email: "ada@example.com", Department: "R&D", Position: "Engineer", Salary: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := get(client, created.Id)(withMetadata(t, apiKeyKey, acmeKey)); err != nil {
+		t.Errorf("acme key cannot read what the gateway wrote for acme: %v", err)
+	}
+	if code := status.Code(get(client, created.Id)(withMetadata(t, tenantKey, "globex"))); code != codes.NotFound {
+		t.Errorf("globex read = %s, want NotFound", code)
+	}
+}
+
+func get(client employeev1.EmployeeServiceClient, id string) func(context.Context) error {
+	return func(ctx context.Context) error {
+		_, err := client.GetEmployee(ctx, &employeev1.GetEmployeeRequest{Id: id})
+		return err
+	}
+}
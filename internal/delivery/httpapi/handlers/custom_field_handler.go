@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/rohitashk/golang-rest-api/internal/delivery/httpapi/openapi"
+	"github.com/rohitashk/golang-rest-api/internal/delivery/httpapi/response"
+	"github.com/rohitashk/golang-rest-api/internal/domain/customfield"
+	customFieldUC "github.com/rohitashk/golang-rest-api/internal/usecase/customfield"
+)
+
+type CustomFieldHandler struct {
+	svc            *customFieldUC.Service
+	requestTimeout func() time.Duration
+}
+
+func NewCustomFieldHandler(svc *customFieldUC.Service, requestTimeout func() time.Duration) *CustomFieldHandler {
+	if requestTimeout == nil {
+		requestTimeout = func() time.Duration { return 5 * time.Second }
+	}
+	return &CustomFieldHandler{svc: svc, requestTimeout: requestTimeout}
+}
+
+type createCustomFieldReq struct {
+	Key        string   `json:"key"`
+	Label      string   `json:"label"`
+	Type       string   `json:"type"`
+	Required   bool     `json:"required"`
+	Enum       []string `json:"enum"`
+	Pattern    string   `json:"pattern"`
+	Department string   `json:"department"` // empty applies to every department
+}
+
+type updateCustomFieldReq struct {
+	Label      *string   `json:"label"`
+	Required   *bool     `json:"required"`
+	Enum       *[]string `json:"enum"`
+	Pattern    *string   `json:"pattern"`
+	Department *string   `json:"department"`
+}
+
+type customFieldDTO struct {
+	ID         string   `json:"id"`
+	Key        string   `json:"key"`
+	Label      string   `json:"label"`
+	Type       string   `json:"type"`
+	Required   bool     `json:"required"`
+	Enum       []string `json:"enum,omitempty"`
+	Pattern    string   `json:"pattern,omitempty"`
+	Department string   `json:"department,omitempty"`
+	CreatedAt  string   `json:"created_at"`
+	UpdatedAt  string   `json:"updated_at"`
+}
+
+func toCustomFieldDTO(d *customfield.Definition) customFieldDTO {
+	return customFieldDTO{
+		ID:         d.ID,
+		Key:        d.Key,
+		Label:      d.Label,
+		Type:       string(d.Type),
+		Required:   d.Required,
+		Enum:       d.Enum,
+		Pattern:    d.Pattern,
+		Department: d.Department,
+		CreatedAt:  d.CreatedAt.UTC().Format(time.RFC3339Nano),
+		UpdatedAt:  d.UpdatedAt.UTC().Format(time.RFC3339Nano),
+	}
+}
+
+func (h *CustomFieldHandler) Create(c *gin.Context) {
+	var req createCustomFieldReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout())
+	defer cancel()
+
+	d, err := h.svc.Create(ctx, customFieldUC.CreateInput{
+		Key:        strings.TrimSpace(req.Key),
+		Label:      strings.TrimSpace(req.Label),
+		Type:       strings.TrimSpace(req.Type),
+		Required:   req.Required,
+		Enum:       req.Enum,
+		Pattern:    req.Pattern,
+		Department: strings.TrimSpace(req.Department),
+	})
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.Created(c, toCustomFieldDTO(d))
+}
+
+func (h *CustomFieldHandler) List(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout())
+	defer cancel()
+
+	defs, err := h.svc.List(ctx)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	out := make([]customFieldDTO, 0, len(defs))
+	for i := range defs {
+		out = append(out, toCustomFieldDTO(&defs[i]))
+	}
+	response.OK(c, out)
+}
+
+func (h *CustomFieldHandler) Get(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout())
+	defer cancel()
+
+	d, err := h.svc.Get(ctx, c.Param("id"))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.OK(c, toCustomFieldDTO(d))
+}
+
+func (h *CustomFieldHandler) Update(c *gin.Context) {
+	var req updateCustomFieldReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout())
+	defer cancel()
+
+	d, err := h.svc.Update(ctx, c.Param("id"), customFieldUC.UpdateInput{
+		Label:      req.Label,
+		Required:   req.Required,
+		Enum:       req.Enum,
+		Pattern:    req.Pattern,
+		Department: req.Department,
+	})
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.OK(c, toCustomFieldDTO(d))
+}
+
+func (h *CustomFieldHandler) Delete(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout())
+	defer cancel()
+
+	if err := h.svc.Delete(ctx, c.Param("id")); err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.NoContent(c)
+}
+
+func customFieldOpenAPIRoutes() []openapi.Route {
+	return []openapi.Route{
+		{
+			Method: http.MethodPost, Path: "/v1/custom-fields", OperationID: "createCustomField", Summary: "Define a custom employee field", Tag: "custom-fields",
+			Description: "Employees carry the field's value in custom_fields under its key. The key and type cannot be changed later.",
+			Request:     []openapi.Body{{ContentType: gin.MIMEJSON, Type: createCustomFieldReq{}, Constraints: customFieldUC.CreateInput{}}},
+			Responses: []openapi.Reply{
+				{Status: http.StatusCreated, ContentType: gin.MIMEJSON, Type: dataEnvelope[customFieldDTO]{}},
+				problem(http.StatusBadRequest), problem(http.StatusConflict), problem(http.StatusInternalServerError),
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/v1/custom-fields", OperationID: "listCustomFields", Summary: "List custom field definitions", Tag: "custom-fields",
+			Responses: []openapi.Reply{
+				{Status: http.StatusOK, ContentType: gin.MIMEJSON, Type: dataEnvelope[[]customFieldDTO]{}},
+				problem(http.StatusInternalServerError),
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/v1/custom-fields/:id", OperationID: "getCustomField", Summary: "Get a custom field definition", Tag: "custom-fields",
+			Responses: []openapi.Reply{
+				{Status: http.StatusOK, ContentType: gin.MIMEJSON, Type: dataEnvelope[customFieldDTO]{}},
+				problem(http.StatusBadRequest), problem(http.StatusNotFound), problem(http.StatusInternalServerError),
+			},
+		},
+		{
+			Method: http.MethodPatch, Path: "/v1/custom-fields/:id", OperationID: "updateCustomField", Summary: "Update a custom field definition", Tag: "custom-fields",
+			Description: "Stored values are not checked again until they are next set.",
+			Request:     []openapi.Body{{ContentType: gin.MIMEJSON, Type: updateCustomFieldReq{}, Constraints: customFieldUC.UpdateInput{}}},
+			Responses: []openapi.Reply{
+				{Status: http.StatusOK, ContentType: gin.MIMEJSON, Type: dataEnvelope[customFieldDTO]{}},
+				problem(http.StatusBadRequest), problem(http.StatusNotFound), problem(http.StatusInternalServerError),
+			},
+		},
+		{
+			Method: http.MethodDelete, Path: "/v1/custom-fields/:id", OperationID: "deleteCustomField", Summary: "Delete a custom field and its values", Tag: "custom-fields",
+			Responses: []openapi.Reply{
+				{Status: http.StatusNoContent},
+				problem(http.StatusBadRequest), problem(http.StatusNotFound), problem(http.StatusInternalServerError),
+			},
+		},
+	}
+}
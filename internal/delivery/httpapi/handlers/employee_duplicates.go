@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/rohitashk/golang-rest-api/internal/delivery/httpapi/openapi"
+	"github.com/rohitashk/golang-rest-api/internal/delivery/httpapi/response"
+	"github.com/rohitashk/golang-rest-api/internal/domain"
+	employeeUC "github.com/rohitashk/golang-rest-api/internal/usecase/employee"
+)
+
+type duplicateDTO struct {
+	Employees       [2]employeeDTO `json:"employees"` // the one created first first
+	Score           float64        `json:"score"`
+	NameSimilarity  float64        `json:"name_similarity"`
+	SamePhone       bool           `json:"same_phone"`
+	SameDateOfBirth bool           `json:"same_date_of_birth"`
+}
+
+type mergeEmployeeReq struct {
+	DuplicateID string `json:"duplicate_id"`
+}
+
+func (h *EmployeeHandler) Duplicates(c *gin.Context) {
+	var in employeeUC.DuplicatesInput
+	if v := c.Query("min_score"); v != "" {
+		score, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			response.Error(c, domain.InvalidFields("invalid duplicate search", []domain.FieldError{
+				{Field: "min_score", Rule: "number", Message: "min_score must be a number"},
+			}))
+			return
+		}
+		in.MinScore = score
+	}
+	in.Limit, _ = strconv.ParseInt(c.DefaultQuery("limit", "20"), 10, 64)
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout())
+	defer cancel()
+
+	dups, err := h.svc.Duplicates(ctx, in)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	out := make([]duplicateDTO, 0, len(dups))
+	for _, d := range dups {
+		out = append(out, duplicateDTO{
+			Employees:       [2]employeeDTO{toDTO(&d.Employees[0]), toDTO(&d.Employees[1])},
+			Score:           d.Score,
+			NameSimilarity:  d.Name,
+			SamePhone:       d.Phone,
+			SameDateOfBirth: d.DateOfBirth,
+		})
+	}
+	response.OK(c, out)
+}
+
+func (h *EmployeeHandler) Merge(c *gin.Context) {
+	id := c.Param("id")
+
+	var req mergeEmployeeReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout())
+	defer cancel()
+
+	e, err := h.svc.Merge(ctx, id, req.DuplicateID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, toDTO(e))
+}
+
+func employeeDuplicatesOpenAPIRoutes() []openapi.Route {
+	return []openapi.Route{
+		{
+			Method: http.MethodGet, Path: "/v1/employees/duplicates", OperationID: "listEmployeeDuplicates", Summary: "Find likely duplicate employees", Tag: "employees",
+			Description: "Pairs of employees that may be the same person, best matches first. The score weighs name similarity (0.6), " +
+				"the same phone number (0.25) and the same date of birth (0.15); different dates of birth take 0.3 off. " +
+				"Only employees sharing a phone number, a date of birth, or a last name and first initial are compared.",
+			Params: []openapi.Parameter{
+				query("min_score", "Lowest score returned, 0-1; default 0.6.", &openapi.Schema{Type: "number"}),
+				query("limit", "Most pairs, 1-200.", &openapi.Schema{Type: "integer"}),
+			},
+			Responses: []openapi.Reply{
+				{Status: http.StatusOK, ContentType: gin.MIMEJSON, Type: dataEnvelope[[]duplicateDTO]{}},
+				problem(http.StatusBadRequest), problem(http.StatusInternalServerError),
+			},
+		},
+		{
+			Method: http.MethodPost, Path: "/v1/employees/:id/merge", OperationID: "mergeEmployee", Summary: "Merge a duplicate into an employee", Tag: "employees",
+			Description: "Deletes the duplicate after moving its direct reports to this employee, which keeps its own values and " +
+				"takes the duplicate's phone number, date of birth, manager and custom fields where it has none. " +
+				"The duplicate's events are kept; its employee.deleted event has merged_into, and this employee gets an employee.merged event.",
+			Request: []openapi.Body{{ContentType: gin.MIMEJSON, Type: mergeEmployeeReq{}}},
+			Responses: []openapi.Reply{
+				{Status: http.StatusOK, ContentType: gin.MIMEJSON, Type: dataEnvelope[employeeDTO]{}},
+				problem(http.StatusBadRequest), problem(http.StatusNotFound), problem(http.StatusInternalServerError),
+			},
+		},
+	}
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/rohitashk/golang-rest-api/internal/delivery/httpapi/openapi"
+	"github.com/rohitashk/golang-rest-api/internal/delivery/httpapi/response"
+	domainEmployee "github.com/rohitashk/golang-rest-api/internal/domain/employee"
+	"github.com/rohitashk/golang-rest-api/internal/domain/event"
+	"github.com/rohitashk/golang-rest-api/internal/domain/tenant"
+)
+
+const (
+	eventStreamContentType = "text/event-stream"
+	lastEventIDHeader      = "Last-Event-ID"
+
+	sseRetry     = 3 * time.Second
+	sseHeartbeat = 15 * time.Second
+)
+
+// EmployeeEventsHandler streams employee changes as Server-Sent Events.
+type EmployeeEventsHandler struct {
+	feed event.Feed
+	done <-chan struct{}
+}
+
+// NewEmployeeEventsHandler ends open streams when done is closed, so they do
+// not hold up a graceful shutdown.
+func NewEmployeeEventsHandler(feed event.Feed, done <-chan struct{}) *EmployeeEventsHandler {
+	return &EmployeeEventsHandler{feed: feed, done: done}
+}
+
+func (h *EmployeeEventsHandler) Stream(c *gin.Context) {
+	department := strings.TrimSpace(c.Query("department"))
+
+	// EventSource only sends Last-Event-ID on reconnect; the query parameter
+	// lets a client resume a stream it opened earlier.
+	cursor := c.GetHeader(lastEventIDHeader)
+	if cursor == "" {
+		cursor = c.Query("last_event_id")
+	}
+
+	ctx := c.Request.Context()
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	notifications, err := h.feed.Subscribe(ctx, cursor)
+	reset := errors.Is(err, event.ErrCursorExpired)
+	if reset {
+		notifications, err = h.feed.Subscribe(ctx, "")
+	}
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	w := c.Writer
+	w.Header().Set("Content-Type", eventStreamContentType)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: %d\n\n", sseRetry.Milliseconds())
+	if reset {
+		// Events were missed; the client should reload its state.
+		fmt.Fprint(w, "event: reset\ndata: {}\n\n")
+	}
+	w.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-h.done:
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+			w.Flush()
+		case n, ok := <-notifications:
+			if !ok {
+				return
+			}
+			if n.Event.Tenant != tenantID || department != "" && !inDepartment(n.Event, department) {
+				continue
+			}
+			body, err := n.Event.Body()
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", n.Cursor, n.Event.Type, body)
+			w.Flush()
+		}
+	}
+}
+
+// inDepartment matches events of employees in department, including those
+// who just moved out of it.
+func inDepartment(e event.Envelope, department string) bool {
+	var p struct {
+		Employee struct {
+			Department string `json:"department"`
+		} `json:"employee"`
+		Changes []event.FieldChange `json:"changes"`
+	}
+	if err := json.Unmarshal(e.Payload, &p); err != nil {
+		return false
+	}
+	if p.Employee.Department == department {
+		return true
+	}
+	for _, c := range p.Changes {
+		if c.Field == domainEmployee.FieldDepartment && c.Old == department {
+			return true
+		}
+	}
+	return false
+}
+
+func employeeEventsOpenAPIRoute() openapi.Route {
+	return openapi.Route{
+		Method: http.MethodGet, Path: "/v1/employees/events", OperationID: "streamEmployeeEvents", Summary: "Stream employee changes", Tag: "employees",
+		Description: "Server-Sent Events stream of employee.created, employee.updated, employee.deleted and employee.merged in the tenant. " +
+			"Each event's data is {id, type, occurred_at, data}. A reset event means the stream could not resume " +
+			"from the given ID and the client should reload.",
+		Params: []openapi.Parameter{
+			query("department", "Only events for employees in, or leaving, this department.", &openapi.Schema{Type: "string"}),
+			query("last_event_id", "Resume after this event; the Last-Event-ID header takes precedence.", &openapi.Schema{Type: "string"}),
+			{Name: lastEventIDHeader, In: "header", Description: "Resume after this event.", Schema: &openapi.Schema{Type: "string"}},
+		},
+		Responses: []openapi.Reply{
+			{Status: http.StatusOK, ContentType: eventStreamContentType, Schema: &openapi.Schema{Type: "string"}},
+			problem(http.StatusInternalServerError),
+		},
+	}
+}
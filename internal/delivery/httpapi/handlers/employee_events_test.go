@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/rohitashk/golang-rest-api/internal/adapters/memory"
+	"github.com/rohitashk/golang-rest-api/internal/domain/event"
+	"github.com/rohitashk/golang-rest-api/internal/domain/tenant"
+)
+
+// eventsServer streams the events of testTenant published to feed.
+func eventsServer(t *testing.T, feed *memory.Broadcaster) *httptest.Server {
+	t.Helper()
+	done := make(chan struct{})
+	h := NewEmployeeEventsHandler(feed, done)
+	r := gin.New()
+	r.GET("/v1/employees/events", func(c *gin.Context) {
+		c.Request = c.Request.WithContext(tenant.WithID(c.Request.Context(), testTenant))
+	}, h.Stream)
+	srv := httptest.NewServer(r)
+	t.Cleanup(func() {
+		close(done)
+		srv.Close()
+	})
+	return srv
+}
+
+type sseEvent struct {
+	id, name string
+	eventID  string // the envelope ID in data
+}
+
+type stream struct {
+	t      *testing.T
+	r      *bufio.Reader
+	cancel context.CancelFunc
+}
+
+// openStream connects and waits for the retry hint, sent once the stream is
+// subscribed, so events published afterwards reach it.
+func openStream(t *testing.T, srv *httptest.Server, query, lastEventID string) *stream {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/v1/employees/events"+query, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lastEventID != "" {
+		req.Header.Set(lastEventIDHeader, lastEventID)
+	}
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	if ct := resp.Header.Get("Content-Type"); resp.StatusCode != http.StatusOK || ct != eventStreamContentType {
+		t.Fatalf("stream = %d %s", resp.StatusCode, ct)
+	}
+	s := &stream{t: t, r: bufio.NewReader(resp.Body), cancel: cancel}
+	if block := s.block(); !strings.HasPrefix(block[0], "retry: ") {
+		t.Fatalf("first block = %q, want the retry hint", block)
+	}
+	return s
+}
+
+// block reads the lines of the next message, skipping heartbeats.
+func (s *stream) block() []string {
+	s.t.Helper()
+	var lines []string
+	for {
+		line, err := s.r.ReadString('\n')
+		if err != nil {
+			s.t.Fatalf("read stream: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "" && len(lines) > 0:
+			return lines
+		case line == "" || strings.HasPrefix(line, ":"):
+		default:
+			lines = append(lines, line)
+		}
+	}
+}
+
+func (s *stream) next() sseEvent {
+	s.t.Helper()
+	var e sseEvent
+	for _, line := range s.block() {
+		field, value, _ := strings.Cut(line, ": ")
+		switch field {
+		case "id":
+			e.id = value
+		case "event":
+			e.name = value
+		case "data":
+			var body struct {
+				ID string `json:"id"`
+			}
+			if err := json.Unmarshal([]byte(value), &body); err != nil {
+				s.t.Fatalf("data %q: %v", value, err)
+			}
+			e.eventID = body.ID
+		}
+	}
+	return e
+}
+
+func (s *stream) close() { s.cancel() }
+
+// publish sends ev of tenantID to feed and returns its envelope ID.
+func publish(t *testing.T, feed *memory.Broadcaster, tenantID string, ev event.Event) string {
+	t.Helper()
+	env, err := event.NewEnvelope(tenantID, ev, time.Now().UTC())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := feed.Publish(context.Background(), env); err != nil {
+		t.Fatal(err)
+	}
+	return env.ID
+}
+
+func created(id, department string) event.EmployeeCreated {
+	return event.EmployeeCreated{Employee: event.EmployeeSnapshot{ID: id, Department: department}}
+}
+
+func TestStreamResumesAfterLastEventID(t *testing.T) {
+	feed := memory.NewBroadcaster(0)
+	srv := eventsServer(t, feed)
+
+	first := openStream(t, srv, "", "")
+	var ids []string
+	for _, id := range []string{"e1", "e2", "e3"} {
+		ids = append(ids, publish(t, feed, testTenant, created(id, "Sales")))
+		publish(t, feed, "globex", created(id, "Sales"))
+	}
+	var cursors []string
+	for i := range ids {
+		e := first.next()
+		if e.name != string(event.TypeEmployeeCreated) || e.eventID != ids[i] || e.id == "" {
+			t.Fatalf("event %d = %+v, want %s", i, e, ids[i])
+		}
+		cursors = append(cursors, e.id)
+	}
+	first.close()
+	ids = append(ids, publish(t, feed, testTenant, created("e4", "Sales")))
+
+	// The header resumes after the event it names, with what was missed.
+	resumed := openStream(t, srv, "", cursors[0])
+	var got []string
+	for range ids[1:] {
+		got = append(got, resumed.next().eventID)
+	}
+	if !slices.Equal(got, ids[1:]) {
+		t.Errorf("resumed with %q, want %q", got, ids[1:])
+	}
+
+	// So does the query parameter, for a client opening a new EventSource.
+	if e := openStream(t, srv, "?last_event_id="+cursors[2], "").next(); e.eventID != ids[3] {
+		t.Errorf("resumed from the query with %+v, want %s", e, ids[3])
+	}
+	// The header takes precedence.
+	if e := openStream(t, srv, "?last_event_id="+cursors[0], cursors[2]).next(); e.eventID != ids[3] {
+		t.Errorf("resumed with %+v, want %s", e, ids[3])
+	}
+}
+
+func TestStreamResetsOnExpiredCursor(t *testing.T) {
+	feed := memory.NewBroadcaster(0)
+	srv := eventsServer(t, feed)
+
+	s := openStream(t, srv, "", "from-another-process-7")
+	if e := s.next(); e.name != "reset" {
+		t.Fatalf("first event = %+v, want reset", e)
+	}
+	id := publish(t, feed, testTenant, created("e1", "Sales"))
+	if e := s.next(); e.eventID != id {
+		t.Errorf("event = %+v, want %s", e, id)
+	}
+}
+
+func TestStreamDepartment(t *testing.T) {
+	feed := memory.NewBroadcaster(0)
+	srv := eventsServer(t, feed)
+
+	s := openStream(t, srv, "?department=Sales", "")
+	publish(t, feed, testTenant, created("e1", "Engineering"))
+	moved := publish(t, feed, testTenant, event.EmployeeUpdated{
+		Employee: event.EmployeeSnapshot{ID: "e2", Department: "Engineering"},
+		Changes:  []event.FieldChange{{Field: "department", Old: "Sales", New: "Engineering"}},
+	})
+	joined := publish(t, feed, testTenant, created("e3", "Sales"))
+
+	for _, want := range []string{moved, joined} {
+		if e := s.next(); e.eventID != want {
+			t.Errorf("event = %+v, want %s", e, want)
+		}
+	}
+}
@@ -16,61 +16,102 @@ import (
 
 type EmployeeHandler struct {
 	svc            *employeeUC.Service
-	requestTimeout time.Duration
+	requestTimeout func() time.Duration
 }
 
-func NewEmployeeHandler(svc *employeeUC.Service, requestTimeout time.Duration) *EmployeeHandler {
-	if requestTimeout <= 0 {
-		requestTimeout = 5 * time.Second
+func NewEmployeeHandler(svc *employeeUC.Service, requestTimeout func() time.Duration) *EmployeeHandler {
+	if requestTimeout == nil {
+		requestTimeout = func() time.Duration { return 5 * time.Second }
 	}
 	return &EmployeeHandler{svc: svc, requestTimeout: requestTimeout}
 }
 
 type createEmployeeReq struct {
-	FirstName  string  `json:"first_name"`
-	LastName   string  `json:"last_name"`
-	Email      string  `json:"email"`
-	Department string  `json:"department"`
-	Position   string  `json:"position"`
-	Salary     float64 `json:"salary"`
-	Status     string  `json:"status"`
+	FirstName    string         `json:"first_name"`
+	LastName     string         `json:"last_name"`
+	Email        string         `json:"email"`
+	Phone        string         `json:"phone"`
+	DateOfBirth  string         `json:"date_of_birth"`
+	Department   string         `json:"department"`
+	Position     string         `json:"position"`
+	Salary       float64        `json:"salary"`
+	Status       string         `json:"status"`
+	ManagerID    string         `json:"manager_id"`
+	CustomFields map[string]any `json:"custom_fields"`
 }
 
 type updateEmployeeReq struct {
-	FirstName  *string  `json:"first_name"`
-	LastName   *string  `json:"last_name"`
-	Email      *string  `json:"email"`
-	Department *string  `json:"department"`
-	Position   *string  `json:"position"`
-	Salary     *float64 `json:"salary"`
-	Status     *string  `json:"status"`
+	FirstName    *string        `json:"first_name"`
+	LastName     *string        `json:"last_name"`
+	Email        *string        `json:"email"`
+	Phone        *string        `json:"phone"`         // "" removes the phone number
+	DateOfBirth  *string        `json:"date_of_birth"` // "" removes the date of birth
+	Department   *string        `json:"department"`
+	Position     *string        `json:"position"`
+	Salary       *float64       `json:"salary"`
+	Status       *string        `json:"status"`
+	ManagerID    *string        `json:"manager_id"`    // "" removes the manager
+	CustomFields map[string]any `json:"custom_fields"` // null values clear fields
 }
 
 type employeeDTO struct {
-	ID         string  `json:"id"`
-	FirstName  string  `json:"first_name"`
-	LastName   string  `json:"last_name"`
-	Email      string  `json:"email"`
-	Department string  `json:"department"`
-	Position   string  `json:"position"`
-	Salary     float64 `json:"salary"`
-	Status     string  `json:"status"`
-	CreatedAt  string  `json:"created_at"`
-	UpdatedAt  string  `json:"updated_at"`
+	ID           string         `json:"id"`
+	FirstName    string         `json:"first_name"`
+	LastName     string         `json:"last_name"`
+	Email        string         `json:"email"`
+	Phone        *string        `json:"phone"`
+	DateOfBirth  *string        `json:"date_of_birth"`
+	Department   string         `json:"department"`
+	Position     string         `json:"position"`
+	Salary       float64        `json:"salary"`
+	Status       string         `json:"status"`
+	ManagerID    *string        `json:"manager_id"`
+	CustomFields map[string]any `json:"custom_fields"`
+	CreatedAt    string         `json:"created_at"`
+	UpdatedAt    string         `json:"updated_at"`
+}
+
+// customFieldsParam filters the list on custom fields, as in
+// ?custom_fields[cost_center]=CC-12.
+const customFieldsParam = "custom_fields"
+
+type listMeta struct {
+	Total  int64 `json:"total"`
+	Limit  int64 `json:"limit"`
+	Offset int64 `json:"offset"`
 }
 
 func toDTO(e *domainEmployee.Employee) employeeDTO {
+	var managerID *string
+	if e.ManagerID != "" {
+		managerID = &e.ManagerID
+	}
+	var phone, dateOfBirth *string
+	if e.Phone != "" {
+		phone = &e.Phone
+	}
+	if e.DateOfBirth != "" {
+		dateOfBirth = &e.DateOfBirth
+	}
+	customFields := e.CustomFields
+	if customFields == nil {
+		customFields = map[string]any{}
+	}
 	return employeeDTO{
-		ID:         e.ID,
-		FirstName:  e.FirstName,
-		LastName:   e.LastName,
-		Email:      e.Email,
-		Department: e.Department,
-		Position:   e.Position,
-		Salary:     e.Salary,
-		Status:     string(e.Status),
-		CreatedAt:  e.CreatedAt.UTC().Format(time.RFC3339Nano),
-		UpdatedAt:  e.UpdatedAt.UTC().Format(time.RFC3339Nano),
+		ID:           e.ID,
+		FirstName:    e.FirstName,
+		LastName:     e.LastName,
+		Email:        e.Email,
+		Phone:        phone,
+		DateOfBirth:  dateOfBirth,
+		Department:   e.Department,
+		Position:     e.Position,
+		Salary:       e.Salary,
+		Status:       string(e.Status),
+		ManagerID:    managerID,
+		CustomFields: customFields,
+		CreatedAt:    e.CreatedAt.UTC().Format(time.RFC3339Nano),
+		UpdatedAt:    e.UpdatedAt.UTC().Format(time.RFC3339Nano),
 	}
 }
 
@@ -81,17 +122,21 @@ func (h *EmployeeHandler) Create(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout())
 	defer cancel()
 
 	e, err := h.svc.Create(ctx, employeeUC.CreateInput{
-		FirstName:  strings.TrimSpace(req.FirstName),
-		LastName:   strings.TrimSpace(req.LastName),
-		Email:      req.Email,
-		Department: strings.TrimSpace(req.Department),
-		Position:   strings.TrimSpace(req.Position),
-		Salary:     req.Salary,
-		Status:     strings.TrimSpace(req.Status),
+		FirstName:    strings.TrimSpace(req.FirstName),
+		LastName:     strings.TrimSpace(req.LastName),
+		Email:        req.Email,
+		Phone:        req.Phone,
+		DateOfBirth:  strings.TrimSpace(req.DateOfBirth),
+		Department:   strings.TrimSpace(req.Department),
+		Position:     strings.TrimSpace(req.Position),
+		Salary:       req.Salary,
+		Status:       strings.TrimSpace(req.Status),
+		ManagerID:    strings.TrimSpace(req.ManagerID),
+		CustomFields: req.CustomFields,
 	})
 	if err != nil {
 		response.Error(c, err)
@@ -104,16 +149,28 @@ func (h *EmployeeHandler) Create(c *gin.Context) {
 func (h *EmployeeHandler) Get(c *gin.Context) {
 	id := c.Param("id")
 
-	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout)
+	view, err := parseView(c)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout())
 	defer cancel()
 
-	e, err := h.svc.Get(ctx, id)
+	e, err := h.svc.Get(ctx, id, view.loadFields()...)
 	if err != nil {
 		response.Error(c, err)
 		return
 	}
 
-	response.OK(c, toDTO(e))
+	out, err := h.render(ctx, []domainEmployee.Employee{*e}, view)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, out[0])
 }
 
 func (h *EmployeeHandler) List(c *gin.Context) {
@@ -132,44 +189,70 @@ func (h *EmployeeHandler) List(c *gin.Context) {
 		statusPtr = &status
 	}
 
+	managerID := strings.TrimSpace(c.Query("manager_id"))
+	var managerPtr *string
+	if managerID != "" {
+		managerPtr = &managerID
+	}
+
 	q := strings.TrimSpace(c.Query("q"))
 	var qPtr *string
 	if q != "" {
 		qPtr = &q
 	}
 
-	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout)
+	view, err := parseView(c)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout())
 	defer cancel()
 
 	items, total, err := h.svc.List(ctx, employeeUC.ListInput{
-		Department: deptPtr,
-		Status:     statusPtr,
-		Query:      qPtr,
-		Limit:      limit,
-		Offset:     offset,
+		Department:   deptPtr,
+		Status:       statusPtr,
+		ManagerID:    managerPtr,
+		Query:        qPtr,
+		CustomFields: c.QueryMap(customFieldsParam),
+		Limit:        limit,
+		Offset:       offset,
+		Fields:       view.loadFields(),
 	})
 	if err != nil {
 		response.Error(c, err)
 		return
 	}
 
-	out := make([]employeeDTO, 0, len(items))
-	for i := range items {
-		e := items[i]
-		out = append(out, toDTO(&e))
+	out, err := h.render(ctx, items, view)
+	if err != nil {
+		response.Error(c, err)
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"data": out,
-		"meta": gin.H{
-			"total":  total,
-			"limit":  limit,
-			"offset": offset,
+		"meta": listMeta{
+			Total:  total,
+			Limit:  limit,
+			Offset: offset,
 		},
 	})
 }
 
 func (h *EmployeeHandler) Update(c *gin.Context) {
+	switch ct := c.ContentType(); ct {
+	case mergePatchContentType, jsonPatchContentType:
+		h.patch(c, ct)
+		return
+	case "", gin.MIMEJSON:
+	default:
+		response.Problem(c, http.StatusUnsupportedMediaType, "unsupported_media_type",
+			"supported content types: application/json, "+mergePatchContentType+", "+jsonPatchContentType, nil)
+		return
+	}
+
 	id := c.Param("id")
 
 	var req updateEmployeeReq
@@ -178,17 +261,21 @@ func (h *EmployeeHandler) Update(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout())
 	defer cancel()
 
 	e, err := h.svc.Update(ctx, id, employeeUC.UpdateInput{
-		FirstName:  req.FirstName,
-		LastName:   req.LastName,
-		Email:      req.Email,
-		Department: req.Department,
-		Position:   req.Position,
-		Salary:     req.Salary,
-		Status:     req.Status,
+		FirstName:    req.FirstName,
+		LastName:     req.LastName,
+		Email:        req.Email,
+		Phone:        req.Phone,
+		DateOfBirth:  req.DateOfBirth,
+		Department:   req.Department,
+		Position:     req.Position,
+		Salary:       req.Salary,
+		Status:       req.Status,
+		ManagerID:    req.ManagerID,
+		CustomFields: req.CustomFields,
 	})
 	if err != nil {
 		response.Error(c, err)
@@ -201,7 +288,7 @@ func (h *EmployeeHandler) Update(c *gin.Context) {
 func (h *EmployeeHandler) Delete(c *gin.Context) {
 	id := c.Param("id")
 
-	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout())
 	defer cancel()
 
 	if err := h.svc.Delete(ctx, id); err != nil {
@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/gin-gonic/gin"
+
+	"github.com/rohitashk/golang-rest-api/internal/delivery/httpapi/response"
+	"github.com/rohitashk/golang-rest-api/internal/domain"
+	employeeUC "github.com/rohitashk/golang-rest-api/internal/usecase/employee"
+)
+
+const (
+	mergePatchContentType = "application/merge-patch+json"
+	jsonPatchContentType  = "application/json-patch+json"
+)
+
+var readOnlyEmployeeFields = map[string]bool{"id": true, "created_at": true, "updated_at": true}
+
+var writableEmployeeFields = map[string]bool{
+	"first_name": true, "last_name": true, "email": true, "phone": true, "date_of_birth": true,
+	"department": true, "position": true, "salary": true, "status": true, "manager_id": true, "custom_fields": true,
+}
+
+// patch applies an RFC 7396 merge patch or RFC 6902 JSON patch to the
+// employee's current representation and turns the result into an UpdateInput.
+// Removing a field or setting it to null clears it.
+func (h *EmployeeHandler) patch(c *gin.Context, contentType string) {
+	id := c.Param("id")
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		response.Error(c, domain.Validation("failed to read request body"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout())
+	defer cancel()
+
+	current, err := h.svc.Get(ctx, id)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	doc, err := json.Marshal(toDTO(current))
+	if err != nil {
+		response.Error(c, domain.Internal("failed to encode employee", err))
+		return
+	}
+
+	patched, err := applyPatch(contentType, doc, body)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	in, err := patchedToUpdateInput(doc, patched)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	// The patch, and its test operations, saw this version; a change made
+	// since must not be overwritten.
+	in.IfUpdatedAt = &current.UpdatedAt
+
+	e, err := h.svc.Update(ctx, id, in)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, toDTO(e))
+}
+
+func applyPatch(contentType string, doc, patch []byte) ([]byte, error) {
+	if !json.Valid(patch) {
+		return nil, domain.Validation("request body is not valid JSON")
+	}
+
+	if contentType == mergePatchContentType {
+		out, err := jsonpatch.MergePatch(doc, patch)
+		if err != nil {
+			return nil, domain.Validation("invalid merge patch document")
+		}
+		return out, nil
+	}
+
+	p, err := jsonpatch.DecodePatch(patch)
+	if err != nil {
+		return nil, domain.Validation("invalid JSON patch document")
+	}
+	out, err := p.Apply(doc)
+	switch {
+	case err == nil:
+		return out, nil
+	case errors.Is(err, jsonpatch.ErrTestFailed):
+		return nil, domain.Conflict("JSON patch test operation failed")
+	default:
+		return nil, domain.Unprocessable("JSON patch could not be applied: " + err.Error())
+	}
+}
+
+func patchedToUpdateInput(original, patched []byte) (employeeUC.UpdateInput, error) {
+	var before, after map[string]any
+	if err := json.Unmarshal(original, &before); err != nil {
+		return employeeUC.UpdateInput{}, domain.Internal("failed to decode employee", err)
+	}
+	if err := json.Unmarshal(patched, &after); err != nil || after == nil {
+		return employeeUC.UpdateInput{}, domain.Validation("patched document must be a JSON object")
+	}
+
+	var fields []domain.FieldError
+	for k := range after {
+		if !readOnlyEmployeeFields[k] && !writableEmployeeFields[k] {
+			fields = append(fields, domain.FieldError{Field: k, Rule: "unknown", Message: k + " is not a known field"})
+		}
+	}
+	for k := range readOnlyEmployeeFields {
+		if !reflect.DeepEqual(before[k], after[k]) {
+			fields = append(fields, domain.FieldError{Field: k, Rule: "readonly", Message: k + " cannot be modified"})
+		}
+	}
+	if len(fields) > 0 {
+		return employeeUC.UpdateInput{}, domain.InvalidFields("patch modifies fields that cannot be changed", fields)
+	}
+
+	// Absent and null fields decode to zero values, which is what clearing
+	// means; validation in the use case rejects clearing required fields.
+	var cur, next createEmployeeReq
+	_ = json.Unmarshal(original, &cur)
+	if err := json.Unmarshal(patched, &next); err != nil {
+		return employeeUC.UpdateInput{}, err
+	}
+
+	var in employeeUC.UpdateInput
+	if next.FirstName != cur.FirstName {
+		in.FirstName = &next.FirstName
+	}
+	if next.LastName != cur.LastName {
+		in.LastName = &next.LastName
+	}
+	if next.Email != cur.Email {
+		in.Email = &next.Email
+	}
+	if next.Phone != cur.Phone {
+		in.Phone = &next.Phone
+	}
+	if next.DateOfBirth != cur.DateOfBirth {
+		in.DateOfBirth = &next.DateOfBirth
+	}
+	if next.Department != cur.Department {
+		in.Department = &next.Department
+	}
+	if next.Position != cur.Position {
+		in.Position = &next.Position
+	}
+	if next.Salary != cur.Salary {
+		in.Salary = &next.Salary
+	}
+	if next.Status != cur.Status {
+		in.Status = &next.Status
+	}
+	if next.ManagerID != cur.ManagerID {
+		in.ManagerID = &next.ManagerID
+	}
+	// Custom fields change one by one; a removed one is cleared with nil.
+	setCustomField := func(k string, v any) {
+		if in.CustomFields == nil {
+			in.CustomFields = map[string]any{}
+		}
+		in.CustomFields[k] = v
+	}
+	for k, v := range next.CustomFields {
+		if old, ok := cur.CustomFields[k]; !ok || !reflect.DeepEqual(old, v) {
+			setCustomField(k, v)
+		}
+	}
+	for k := range cur.CustomFields {
+		if _, ok := next.CustomFields[k]; !ok {
+			setCustomField(k, nil)
+		}
+	}
+	return in, nil
+}
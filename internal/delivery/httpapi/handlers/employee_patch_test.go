@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/rohitashk/golang-rest-api/internal/adapters/memory"
+	domainEmployee "github.com/rohitashk/golang-rest-api/internal/domain/employee"
+	"github.com/rohitashk/golang-rest-api/internal/domain/tenant"
+	employeeUC "github.com/rohitashk/golang-rest-api/internal/usecase/employee"
+)
+
+func init() { gin.SetMode(gin.TestMode) }
+
+const testTenant = "acme"
+
+// newEmployeeServer serves the employee routes over repo, for the tenant
+// testTenant.
+func newEmployeeServer(repo domainEmployee.Repository) *gin.Engine {
+	h := NewEmployeeHandler(employeeUC.NewService(employeeUC.Deps{Repo: repo}), nil)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(tenant.WithID(c.Request.Context(), testTenant))
+		c.Next()
+	})
+	r.GET("/v1/employees/:id", h.Get)
+	r.PATCH("/v1/employees/:id", h.Update)
+	return r
+}
+
+func seedEmployee(t *testing.T, repo domainEmployee.Repository) *domainEmployee.Employee {
+	t.Helper()
+	now := time.Now().UTC()
+	e := &domainEmployee.Employee{
+		FirstName: "Ada", LastName: "Lovelace", Email: "ada@example.com", Phone: "+441234567890",
+		Department: "Engineering", Position: "Engineer", Salary: 100,
+		Status: domainEmployee.StatusActive, CreatedAt: now, UpdatedAt: now,
+	}
+	if err := repo.Create(tenant.WithID(context.Background(), testTenant), e); err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func patchEmployee(srv http.Handler, id, contentType, body string) (*httptest.ResponseRecorder, map[string]any) {
+	req := httptest.NewRequest(http.MethodPatch, "/v1/employees/"+id, strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	var out map[string]any
+	_ = json.Unmarshal(w.Body.Bytes(), &out)
+	return w, out
+}
+
+func TestPatchEmployee(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		status      int
+		want        map[string]any // fields of the returned employee
+	}{
+		{
+			name: "merge patch sets and clears", contentType: mergePatchContentType,
+			body:   `{"position":"Lead","phone":null}`,
+			status: http.StatusOK, want: map[string]any{"position": "Lead", "phone": nil, "first_name": "Ada"},
+		},
+		{
+			name: "json patch replaces and removes", contentType: jsonPatchContentType,
+			body:   `[{"op":"replace","path":"/position","value":"Lead"},{"op":"remove","path":"/phone"}]`,
+			status: http.StatusOK, want: map[string]any{"position": "Lead", "phone": nil, "first_name": "Ada"},
+		},
+		{
+			name: "json patch test that holds", contentType: jsonPatchContentType,
+			body:   `[{"op":"test","path":"/salary","value":100},{"op":"replace","path":"/salary","value":120}]`,
+			status: http.StatusOK, want: map[string]any{"salary": 120.0},
+		},
+		{
+			name: "json patch test that fails", contentType: jsonPatchContentType,
+			body:   `[{"op":"test","path":"/salary","value":90},{"op":"replace","path":"/salary","value":120}]`,
+			status: http.StatusConflict,
+		},
+		{
+			name: "json patch of a missing path", contentType: jsonPatchContentType,
+			body:   `[{"op":"remove","path":"/nickname"}]`,
+			status: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "read-only field", contentType: mergePatchContentType,
+			body:   `{"id":"elsewhere"}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "clearing a required field", contentType: mergePatchContentType,
+			body:   `{"first_name":null}`,
+			status: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := memory.NewEmployeeRepository()
+			e := seedEmployee(t, repo)
+
+			w, body := patchEmployee(newEmployeeServer(repo), e.ID, tt.contentType, tt.body)
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			data, _ := body["data"].(map[string]any)
+			for k, v := range tt.want {
+				if data[k] != v {
+					t.Errorf("%s = %v, want %v", k, data[k], v)
+				}
+			}
+		})
+	}
+}
+
+// changingRepository changes the employee once, right after it is first
+// read, as another request would.
+type changingRepository struct {
+	*memory.EmployeeRepository
+	changed bool
+}
+
+func (r *changingRepository) GetByID(ctx context.Context, id string, fields ...string) (*domainEmployee.Employee, error) {
+	e, err := r.EmployeeRepository.GetByID(ctx, id, fields...)
+	if err != nil || e == nil || r.changed {
+		return e, err
+	}
+	r.changed = true
+	other := *e
+	other.Salary = 500
+	other.UpdatedAt = e.UpdatedAt.Add(time.Second)
+	if err := r.EmployeeRepository.Update(ctx, &other, e.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func TestPatchEmployeeChangedMeanwhile(t *testing.T) {
+	for _, ct := range []string{mergePatchContentType, jsonPatchContentType} {
+		t.Run(ct, func(t *testing.T) {
+			repo := &changingRepository{EmployeeRepository: memory.NewEmployeeRepository()}
+			e := seedEmployee(t, repo.EmployeeRepository)
+
+			body := `{"position":"Lead"}`
+			if ct == jsonPatchContentType {
+				body = `[{"op":"test","path":"/salary","value":100},{"op":"replace","path":"/position","value":"Lead"}]`
+			}
+			w, _ := patchEmployee(newEmployeeServer(repo), e.ID, ct, body)
+			if w.Code != http.StatusConflict {
+				t.Fatalf("status = %d, want 409: %s", w.Code, w.Body)
+			}
+
+			got, _ := repo.EmployeeRepository.GetByID(tenant.WithID(context.Background(), testTenant), e.ID)
+			if got.Salary != 500 || got.Position != "Engineer" {
+				t.Errorf("the other change was overwritten: salary %v, position %q", got.Salary, got.Position)
+			}
+		})
+	}
+}
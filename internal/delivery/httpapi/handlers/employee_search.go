@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/rohitashk/golang-rest-api/internal/delivery/httpapi/openapi"
+	"github.com/rohitashk/golang-rest-api/internal/delivery/httpapi/response"
+	"github.com/rohitashk/golang-rest-api/internal/domain"
+	domainEmployee "github.com/rohitashk/golang-rest-api/internal/domain/employee"
+	employeeUC "github.com/rohitashk/golang-rest-api/internal/usecase/employee"
+)
+
+// searchHit is the employee as the view renders it; E is employeeDTO in the
+// spec.
+type searchHit[E any] struct {
+	Employee   E                 `json:"employee"`
+	Score      float64           `json:"score"`
+	Highlights map[string]string `json:"highlights"` // HTML-escaped field text, matches in <em>
+}
+
+func (h *EmployeeHandler) Search(c *gin.Context) {
+	in := employeeUC.SearchInput{Query: c.Query("q")}
+	var fields []domain.FieldError
+	for name, dst := range map[string]*bool{"prefix": &in.Prefix, "fuzzy": &in.Fuzzy} {
+		v := c.Query(name)
+		if v == "" {
+			continue
+		}
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			fields = append(fields, domain.FieldError{Field: name, Rule: "boolean", Message: name + " must be true or false"})
+		}
+		*dst = b
+	}
+	if len(fields) > 0 {
+		response.Error(c, domain.InvalidFields("invalid search", fields))
+		return
+	}
+	in.Limit, _ = strconv.ParseInt(c.DefaultQuery("limit", "10"), 10, 64)
+	if dept := strings.TrimSpace(c.Query("department")); dept != "" {
+		in.Department = &dept
+	}
+	if status := strings.TrimSpace(c.Query("status")); status != "" {
+		in.Status = &status
+	}
+
+	view, err := parseView(c)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout())
+	defer cancel()
+
+	results, err := h.svc.Search(ctx, in)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	items := make([]domainEmployee.Employee, 0, len(results))
+	for _, r := range results {
+		items = append(items, r.Employee)
+	}
+	rendered, err := h.render(ctx, items, view)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	out := make([]searchHit[any], 0, len(results))
+	for i, r := range results {
+		highlights := r.Highlights
+		if highlights == nil {
+			highlights = map[string]string{}
+		}
+		out = append(out, searchHit[any]{Employee: rendered[i], Score: r.Score, Highlights: highlights})
+	}
+	response.OK(c, out)
+}
+
+func employeeSearchOpenAPIRoute() openapi.Route {
+	return openapi.Route{
+		Method: http.MethodGet, Path: "/v1/employees/search", OperationID: "searchEmployees", Summary: "Search employees", Tag: "employees",
+		Description: "Finds employees whose names, email, department or position contain every word of q, best matches first. " +
+			"Highlights repeat the matching fields, HTML-escaped, with the matched words in <em>.",
+		Params: append([]openapi.Parameter{
+			query("q", "Words to find, at most 10.", &openapi.Schema{Type: "string"}),
+			query("prefix", "Let the last word match the start of longer words, for search-as-you-type.", &openapi.Schema{Type: "boolean"}),
+			query("fuzzy", "Let words of four letters or more match names with a typo, or two from eight letters.", &openapi.Schema{Type: "boolean"}),
+			query("department", "Exact department name.", &openapi.Schema{Type: "string"}),
+			query("status", "Employee status.", &openapi.Schema{Type: "string", Enum: []any{"active", "inactive"}}),
+			query("limit", "Most results, 1-50.", &openapi.Schema{Type: "integer"}),
+		}, viewParams...),
+		Responses: []openapi.Reply{
+			{Status: http.StatusOK, ContentType: gin.MIMEJSON, Type: dataEnvelope[[]searchHit[employeeDTO]]{}},
+			problem(http.StatusBadRequest), problem(http.StatusInternalServerError),
+		},
+	}
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/rohitashk/golang-rest-api/internal/domain"
+	domainEmployee "github.com/rohitashk/golang-rest-api/internal/domain/employee"
+)
+
+const (
+	expandManager    = "manager"
+	expandDepartment = "department"
+
+	// departmentInfoKey holds the expanded department, next to the
+	// department name, which keeps its type.
+	departmentInfoKey = "department_info"
+)
+
+type departmentDTO struct {
+	Name      string `json:"name"`
+	Headcount int64  `json:"headcount"`
+}
+
+// employeeView is the shape requested through ?fields= and ?expand=.
+type employeeView struct {
+	fields           []string // nil means all fields
+	expandManager    bool
+	expandDepartment bool
+}
+
+func parseView(c *gin.Context) (employeeView, error) {
+	// Field names are checked by the use case.
+	v := employeeView{fields: splitList(c.Query("fields"))}
+
+	for _, x := range splitList(c.Query("expand")) {
+		switch x {
+		case expandManager:
+			v.expandManager = true
+		case expandDepartment:
+			v.expandDepartment = true
+		default:
+			return v, domain.InvalidFields("invalid expand", []domain.FieldError{{
+				Field:   "expand",
+				Rule:    "oneof",
+				Message: "expand must be a list of: manager, department",
+			}})
+		}
+	}
+	return v, nil
+}
+
+func (v employeeView) plain() bool {
+	return v.fields == nil && !v.expandManager && !v.expandDepartment
+}
+
+// loadFields is the projection to read from storage: the requested fields
+// plus whatever the expansions need.
+func (v employeeView) loadFields() []string {
+	if v.fields == nil {
+		return nil
+	}
+	out := append([]string{}, v.fields...)
+	if v.expandManager {
+		out = append(out, domainEmployee.FieldManagerID)
+	}
+	if v.expandDepartment {
+		out = append(out, domainEmployee.FieldDepartment)
+	}
+	return out
+}
+
+func (h *EmployeeHandler) render(ctx context.Context, items []domainEmployee.Employee, v employeeView) ([]any, error) {
+	out := make([]any, 0, len(items))
+	if v.plain() {
+		for i := range items {
+			out = append(out, toDTO(&items[i]))
+		}
+		return out, nil
+	}
+
+	managers := map[string]map[string]any{}
+	if v.expandManager {
+		ids := uniqueValues(items, func(e domainEmployee.Employee) string { return e.ManagerID })
+		found, err := h.svc.GetMany(ctx, ids, v.fields...)
+		if err != nil {
+			return nil, err
+		}
+		for i := range found {
+			managers[found[i].ID] = pickFields(toDTO(&found[i]), v.fields)
+		}
+	}
+
+	departments := map[string]departmentDTO{}
+	if v.expandDepartment {
+		names := uniqueValues(items, func(e domainEmployee.Employee) string { return e.Department })
+		found, err := h.svc.Departments(ctx, names...)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range found {
+			departments[d.Name] = departmentDTO{Name: d.Name, Headcount: d.Headcount}
+		}
+	}
+
+	for i := range items {
+		e := &items[i]
+		m := pickFields(toDTO(e), v.fields)
+		if v.expandManager {
+			if mgr, ok := managers[e.ManagerID]; ok {
+				m[expandManager] = mgr
+			} else {
+				m[expandManager] = nil
+			}
+		}
+		if v.expandDepartment {
+			m[departmentInfoKey] = departments[e.Department]
+		}
+		out = append(out, m)
+	}
+	return out, nil
+}
+
+// pickFields returns the selected fields of d; the id is always included.
+func pickFields(d employeeDTO, fields []string) map[string]any {
+	all := map[string]any{
+		domainEmployee.FieldID:          d.ID,
+		domainEmployee.FieldFirstName:   d.FirstName,
+		domainEmployee.FieldLastName:    d.LastName,
+		domainEmployee.FieldEmail:       d.Email,
+		domainEmployee.FieldPhone:       d.Phone,
+		domainEmployee.FieldDateOfBirth: d.DateOfBirth,
+		domainEmployee.FieldDepartment:  d.Department,
+		domainEmployee.FieldPosition:    d.Position,
+		domainEmployee.FieldSalary:      d.Salary,
+		domainEmployee.FieldStatus:      d.Status,
+		domainEmployee.FieldManagerID:   d.ManagerID,
+		domainEmployee.FieldCreatedAt:   d.CreatedAt,
+		domainEmployee.FieldUpdatedAt:   d.UpdatedAt,
+
+		domainEmployee.FieldCustomFields: d.CustomFields,
+	}
+	if fields == nil {
+		return all
+	}
+
+	out := map[string]any{domainEmployee.FieldID: d.ID}
+	for _, f := range fields {
+		if v, ok := all[f]; ok {
+			out[f] = v
+		}
+	}
+	return out
+}
+
+func splitList(raw string) []string {
+	var out []string
+	for _, part := range strings.Split(raw, ",") {
+		if p := strings.TrimSpace(part); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func uniqueValues(items []domainEmployee.Employee, value func(domainEmployee.Employee) string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, e := range items {
+		v := value(e)
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		out = append(out, v)
+	}
+	return out
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"sort"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/rohitashk/golang-rest-api/internal/adapters/memory"
+	"github.com/rohitashk/golang-rest-api/internal/delivery/httpapi/response"
+	"github.com/rohitashk/golang-rest-api/internal/domain/tenant"
+	employeeUC "github.com/rohitashk/golang-rest-api/internal/usecase/employee"
+)
+
+func TestParseView(t *testing.T) {
+	tests := []struct {
+		query string
+		want  employeeView
+	}{
+		{"", employeeView{}},
+		{"fields=first_name,%20email,,", employeeView{fields: []string{"first_name", "email"}}},
+		{"expand=manager", employeeView{expandManager: true}},
+		{"expand=department,manager&fields=email", employeeView{fields: []string{"email"}, expandManager: true, expandDepartment: true}},
+	}
+	for _, tt := range tests {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/v1/employees?"+tt.query, nil)
+		got, err := parseView(c)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q = %+v, %v, want %+v", tt.query, got, err, tt.want)
+		}
+	}
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/v1/employees?expand=manager,team", nil)
+	if _, err := parseView(c); err == nil {
+		t.Error("expand=team: want an error")
+	}
+}
+
+// viewServer serves the employee reads over an organisation of a manager,
+// Grace, and her two reports in Engineering.
+func viewServer(t *testing.T) (*gin.Engine, map[string]string) {
+	t.Helper()
+	svc := employeeUC.NewService(employeeUC.Deps{Repo: memory.NewEmployeeRepository()})
+	ctx := tenant.WithID(context.Background(), testTenant)
+	ids := map[string]string{}
+	for _, in := range []employeeUC.CreateInput{
+		{FirstName: "Grace", LastName: "Hopper", Email: "grace@example.com", Position: "Director"},
+		{FirstName: "Ada", LastName: "Lovelace", Email: "ada@example.com", Position: "Engineer"},
+		{FirstName: "Alan", LastName: "Turing", Email: "alan@example.com", Position: "Engineer"},
+	} {
+		in.Department = "Engineering"
+		if in.FirstName != "Grace" {
+			in.ManagerID = ids["Grace"]
+		}
+		e, err := svc.Create(ctx, in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[in.FirstName] = e.ID
+	}
+
+	h := NewEmployeeHandler(svc, nil)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(tenant.WithID(c.Request.Context(), testTenant))
+	})
+	r.GET("/v1/employees", h.List)
+	r.GET("/v1/employees/:id", h.Get)
+	return r, ids
+}
+
+func get(t *testing.T, r http.Handler, path string, out any) int {
+	t.Helper()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	if err := json.Unmarshal(w.Body.Bytes(), out); err != nil {
+		t.Fatalf("GET %s: %v: %s", path, err, w.Body)
+	}
+	return w.Code
+}
+
+func keys(m map[string]any) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func TestEmployeeFields(t *testing.T) {
+	r, ids := viewServer(t)
+
+	var body struct{ Data map[string]any }
+	if code := get(t, r, "/v1/employees/"+ids["Ada"]+"?fields=first_name,email", &body); code != http.StatusOK {
+		t.Fatalf("status = %d", code)
+	}
+	if got := keys(body.Data); !slices.Equal(got, []string{"email", "first_name", "id"}) {
+		t.Errorf("fields = %q, want the id and those asked for", got)
+	}
+
+	var list struct{ Data []map[string]any }
+	get(t, r, "/v1/employees?fields=last_name", &list)
+	if len(list.Data) != 3 {
+		t.Fatalf("listed %d", len(list.Data))
+	}
+	for _, e := range list.Data {
+		if got := keys(e); !slices.Equal(got, []string{"id", "last_name"}) {
+			t.Errorf("listed fields = %q", got)
+		}
+	}
+
+	var problem response.ErrorBody
+	if code := get(t, r, "/v1/employees?fields=first_name,nickname", &problem); code != http.StatusBadRequest {
+		t.Errorf("unknown field = %d, want 400", code)
+	}
+	if len(problem.Errors) != 1 || problem.Errors[0].Field != "fields" {
+		t.Errorf("errors = %+v, want one on fields", problem.Errors)
+	}
+}
+
+func TestEmployeeExpand(t *testing.T) {
+	r, ids := viewServer(t)
+
+	var body struct{ Data map[string]any }
+	get(t, r, "/v1/employees/"+ids["Ada"]+"?expand=manager,department", &body)
+	// The department keeps its name; the expansion sits beside it.
+	if body.Data["department"] != "Engineering" {
+		t.Errorf("department = %#v, want the name", body.Data["department"])
+	}
+	info, _ := body.Data["department_info"].(map[string]any)
+	if info["name"] != "Engineering" || info["headcount"] != 3.0 {
+		t.Errorf("department_info = %#v", body.Data["department_info"])
+	}
+	manager, _ := body.Data["manager"].(map[string]any)
+	if manager["id"] != ids["Grace"] || manager["first_name"] != "Grace" || body.Data["manager_id"] != ids["Grace"] {
+		t.Errorf("manager = %#v, manager_id %v", manager, body.Data["manager_id"])
+	}
+
+	// Expansions follow the fields asked for, and load what they need.
+	body.Data = nil
+	get(t, r, "/v1/employees/"+ids["Ada"]+"?fields=first_name&expand=manager,department", &body)
+	if got := keys(body.Data); !slices.Equal(got, []string{"department_info", "first_name", "id", "manager"}) {
+		t.Errorf("fields = %q", got)
+	}
+	manager, _ = body.Data["manager"].(map[string]any)
+	if got := keys(manager); !slices.Equal(got, []string{"first_name", "id"}) {
+		t.Errorf("manager fields = %q", got)
+	}
+
+	body.Data = nil
+	get(t, r, "/v1/employees/"+ids["Grace"]+"?expand=manager", &body)
+	if m, ok := body.Data["manager"]; !ok || m != nil {
+		t.Errorf("manager of the director = %#v, want null", m)
+	}
+
+	var problem response.ErrorBody
+	if code := get(t, r, "/v1/employees?expand=team", &problem); code != http.StatusBadRequest || len(problem.Errors) != 1 || problem.Errors[0].Field != "expand" {
+		t.Errorf("expand=team = %d %+v, want 400 on expand", code, problem.Errors)
+	}
+}
@@ -4,12 +4,55 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/rohitashk/golang-rest-api/internal/health"
 )
 
-type HealthHandler struct{}
+type HealthHandler struct {
+	registry *health.Registry
+}
+
+func NewHealthHandler(registry *health.Registry) *HealthHandler {
+	return &HealthHandler{registry: registry}
+}
+
+// Live only shows the process is serving requests; it never checks
+// dependencies, so an outage does not get every instance restarted.
+func (h *HealthHandler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, healthDTO{Status: "ok"})
+}
 
-func NewHealthHandler() *HealthHandler { return &HealthHandler{} }
+func (h *HealthHandler) Ready(c *gin.Context) {
+	report := h.registry.Check(c.Request.Context())
+
+	out := readinessDTO{Status: string(report.Status), Checks: make([]checkDTO, 0, len(report.Checks))}
+	for _, res := range report.Checks {
+		dto := checkDTO{
+			Name:      res.Name,
+			Status:    string(res.Status),
+			LatencyMS: float64(res.Latency.Microseconds()) / 1000,
+		}
+		if res.Err != nil {
+			dto.Error = res.Err.Error()
+		}
+		out.Checks = append(out.Checks, dto)
+	}
+
+	status := http.StatusOK
+	if !report.Ready() {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, out)
+}
+
+type readinessDTO struct {
+	Status string     `json:"status"`
+	Checks []checkDTO `json:"checks"`
+}
 
-func (h *HealthHandler) Get(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+type checkDTO struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
 }
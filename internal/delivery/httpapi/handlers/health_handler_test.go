@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/rohitashk/golang-rest-api/internal/health"
+)
+
+func TestHealth(t *testing.T) {
+	registry := health.NewRegistry(time.Second)
+	var mongoErr error
+	registry.Register("mongodb", health.CheckerFunc(func(context.Context) error { return mongoErr }))
+	h := NewHealthHandler(registry)
+	r := gin.New()
+	r.GET("/livez", h.Live)
+	r.GET("/readyz", h.Ready)
+
+	probe := func(path string) (int, readinessDTO) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		var body readinessDTO
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("GET %s: %v: %s", path, err, w.Body)
+		}
+		return w.Code, body
+	}
+
+	if code, body := probe("/readyz"); code != http.StatusOK || body.Status != "ready" || len(body.Checks) != 1 || body.Checks[0].Status != "up" {
+		t.Errorf("ready = %d %+v", code, body)
+	}
+
+	mongoErr = errors.New("no reachable servers")
+	code, body := probe("/readyz")
+	if code != http.StatusServiceUnavailable || body.Status != "not_ready" || body.Checks[0].Error != "no reachable servers" {
+		t.Errorf("MongoDB down: ready = %d %+v", code, body)
+	}
+	// An outage does not fail liveness.
+	if code, _ := probe("/livez"); code != http.StatusOK {
+		t.Errorf("MongoDB down: live = %d", code)
+	}
+
+	mongoErr = nil
+	registry.ShutDown()
+	if code, body := probe("/readyz"); code != http.StatusServiceUnavailable || body.Status != "shutting_down" {
+		t.Errorf("shutting down: ready = %d %+v", code, body)
+	}
+	if code, body := probe("/livez"); code != http.StatusOK || body.Status != "ok" {
+		t.Errorf("shutting down: live = %d %+v", code, body)
+	}
+}
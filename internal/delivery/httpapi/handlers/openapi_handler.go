@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/rohitashk/golang-rest-api/internal/delivery/graphqlapi"
+	"github.com/rohitashk/golang-rest-api/internal/delivery/httpapi/middleware"
+	"github.com/rohitashk/golang-rest-api/internal/delivery/httpapi/openapi"
+	"github.com/rohitashk/golang-rest-api/internal/delivery/httpapi/response"
+	"github.com/rohitashk/golang-rest-api/internal/delivery/scimapi"
+	employeeUC "github.com/rohitashk/golang-rest-api/internal/usecase/employee"
+)
+
+type OpenAPIHandler struct {
+	doc *openapi.Document
+}
+
+func NewOpenAPIHandler(doc *openapi.Document) *OpenAPIHandler {
+	return &OpenAPIHandler{doc: doc}
+}
+
+func (h *OpenAPIHandler) Spec(c *gin.Context) {
+	c.JSON(http.StatusOK, h.doc)
+}
+
+func (h *OpenAPIHandler) Docs(c *gin.Context) {
+	c.Header("Content-Security-Policy", openapi.DocsCSP)
+	c.Data(http.StatusOK, "text/html; charset=utf-8", openapi.DocsHTML)
+}
+
+// Response envelopes as written by the response package; only used to
+// describe them in the spec.
+type dataEnvelope[T any] struct {
+	Data T `json:"data"`
+}
+
+type listEnvelope[T any] struct {
+	Data []T      `json:"data"`
+	Meta listMeta `json:"meta"`
+}
+
+type healthDTO struct {
+	Status string `json:"status"`
+}
+
+var jsonPatchSchema = &openapi.Schema{
+	Type: "array",
+	Items: &openapi.Schema{
+		Type:     "object",
+		Required: []string{"op", "path"},
+		Properties: map[string]*openapi.Schema{
+			"op":    {Type: "string", Enum: []any{"add", "remove", "replace", "move", "copy", "test"}},
+			"path":  {Type: "string"},
+			"from":  {Type: "string"},
+			"value": {},
+		},
+	},
+}
+
+var graphQLReplies = []openapi.Reply{
+	{Status: http.StatusOK, ContentType: gin.MIMEJSON, Schema: &openapi.Schema{Type: "object", Description: "GraphQL result with data and errors."}},
+	{Status: http.StatusBadRequest, ContentType: gin.MIMEJSON, Schema: &openapi.Schema{Type: "object", Description: "Invalid query or query over the depth/complexity limits."}},
+}
+
+// rateLimited is the reply of the rate limiter in front of every API route.
+var rateLimited = openapi.Reply{
+	Status: http.StatusTooManyRequests, ContentType: response.ProblemContentType, Type: response.ErrorBody{},
+	Description: "Rate limit exceeded; Retry-After gives the seconds to wait.",
+}
+
+// tenantHeader names the tenant on every API route; see middleware.Tenant.
+var tenantHeader = openapi.Parameter{
+	Name: middleware.TenantHeader, In: "header", Schema: &openapi.Schema{Type: "string"},
+	Description: "Repeats the tenant of the API key. Without a key it is only accepted when the service trusts it from a gateway.",
+}
+
+func problem(status int) openapi.Reply {
+	return openapi.Reply{Status: status, ContentType: response.ProblemContentType, Type: response.ErrorBody{}}
+}
+
+func query(name, desc string, schema *openapi.Schema) openapi.Parameter {
+	return openapi.Parameter{Name: name, In: "query", Description: desc, Schema: schema}
+}
+
+var viewParams = []openapi.Parameter{
+	query("fields", "Comma-separated employee fields to return; id is always included.", &openapi.Schema{Type: "string"}),
+	query("expand", "Comma-separated relations to inline: manager adds the manager as manager, department adds its name and headcount as department_info.", &openapi.Schema{Type: "string"}),
+}
+
+// OpenAPIRoutes describes the operations of the API. The router registers
+// each route by its operation ID, with the method and path given here, and
+// documents the routes it registered.
+func OpenAPIRoutes() []openapi.Route {
+	routes := []openapi.Route{
+		{
+			Method: http.MethodGet, Path: "/livez", OperationID: "getLiveness", Summary: "Liveness probe", Tag: "health",
+			Responses: []openapi.Reply{{Status: http.StatusOK, ContentType: gin.MIMEJSON, Type: healthDTO{}}},
+		},
+		{
+			Method: http.MethodGet, Path: "/readyz", OperationID: "getReadiness", Summary: "Readiness probe", Tag: "health",
+			Description: "Checks every dependency; not ready while a dependency is down or the server is shutting down.",
+			Responses: []openapi.Reply{
+				{Status: http.StatusOK, ContentType: gin.MIMEJSON, Type: readinessDTO{}},
+				{Status: http.StatusServiceUnavailable, ContentType: gin.MIMEJSON, Type: readinessDTO{}},
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/metrics", OperationID: "getMetrics", Summary: "Prometheus metrics", Tag: "meta",
+			Responses: []openapi.Reply{{Status: http.StatusOK, ContentType: "text/plain; version=0.0.4", Schema: &openapi.Schema{Type: "string"}}},
+		},
+		{
+			Method: http.MethodGet, Path: "/openapi.json", OperationID: "getOpenAPI", Summary: "This OpenAPI document", Tag: "meta",
+			Responses: []openapi.Reply{{Status: http.StatusOK, ContentType: gin.MIMEJSON, Schema: &openapi.Schema{Type: "object"}}},
+		},
+		{
+			Method: http.MethodGet, Path: "/docs", OperationID: "getDocs", Summary: "Interactive API documentation", Tag: "meta",
+			Responses: []openapi.Reply{{Status: http.StatusOK, ContentType: gin.MIMEHTML, Schema: &openapi.Schema{Type: "string"}}},
+		},
+		{
+			Method: http.MethodGet, Path: "/graphql", OperationID: "queryGraphQL", Summary: "Run a GraphQL query", Tag: "graphql",
+			Params: []openapi.Parameter{
+				{Name: "query", In: "query", Required: true, Schema: &openapi.Schema{Type: "string"}},
+				query("operationName", "", &openapi.Schema{Type: "string"}),
+				query("variables", "JSON-encoded variables object.", &openapi.Schema{Type: "string"}),
+			},
+			Responses: graphQLReplies,
+		},
+		{
+			Method: http.MethodPost, Path: "/graphql", OperationID: "postGraphQL", Summary: "Run a GraphQL query", Tag: "graphql",
+			Request:   []openapi.Body{{ContentType: gin.MIMEJSON, Type: graphqlapi.Request{}}},
+			Responses: graphQLReplies,
+		},
+		{
+			Method: http.MethodPost, Path: "/v1/employees", OperationID: "createEmployee", Summary: "Create an employee", Tag: "employees",
+			Params: []openapi.Parameter{{
+				Name: middleware.IdempotencyKeyHeader, In: "header", Schema: &openapi.Schema{Type: "string"},
+				Description: "Makes the request safe to retry; the first response is replayed for repeated keys.",
+			}},
+			Request: []openapi.Body{{ContentType: gin.MIMEJSON, Type: createEmployeeReq{}, Constraints: employeeUC.CreateInput{}}},
+			Responses: []openapi.Reply{
+				{Status: http.StatusCreated, ContentType: gin.MIMEJSON, Type: dataEnvelope[employeeDTO]{}},
+				problem(http.StatusBadRequest), problem(http.StatusConflict),
+				problem(http.StatusUnprocessableEntity), problem(http.StatusInternalServerError),
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/v1/employees", OperationID: "listEmployees", Summary: "List employees", Tag: "employees",
+			Params: append([]openapi.Parameter{
+				query("limit", "Page size, 1-200.", &openapi.Schema{Type: "integer"}),
+				query("offset", "Number of employees to skip.", &openapi.Schema{Type: "integer"}),
+				query("department", "Exact department name.", &openapi.Schema{Type: "string"}),
+				query("status", "Employee status.", &openapi.Schema{Type: "string", Enum: []any{"active", "inactive"}}),
+				query("manager_id", "Only direct reports of this employee.", &openapi.Schema{Type: "string"}),
+				query("q", "Case-insensitive search in name and email.", &openapi.Schema{Type: "string"}),
+				{
+					Name: customFieldsParam, In: "query", Style: "deepObject", Explode: true,
+					Schema:      &openapi.Schema{Type: "object", AdditionalProperties: &openapi.Schema{Type: "string"}},
+					Description: "Exact custom field values, e.g. custom_fields[cost_center]=CC-12.",
+				},
+			}, viewParams...),
+			Responses: []openapi.Reply{
+				{Status: http.StatusOK, ContentType: gin.MIMEJSON, Type: listEnvelope[employeeDTO]{}},
+				problem(http.StatusBadRequest), problem(http.StatusInternalServerError),
+			},
+		},
+		employeeSearchOpenAPIRoute(),
+		{
+			Method: http.MethodGet, Path: "/v1/employees/:id", OperationID: "getEmployee", Summary: "Get an employee", Tag: "employees",
+			Params: viewParams,
+			Responses: []openapi.Reply{
+				{Status: http.StatusOK, ContentType: gin.MIMEJSON, Type: dataEnvelope[employeeDTO]{}},
+				problem(http.StatusBadRequest), problem(http.StatusNotFound), problem(http.StatusInternalServerError),
+			},
+		},
+		{
+			Method: http.MethodPatch, Path: "/v1/employees/:id", OperationID: "updateEmployee", Summary: "Update an employee", Tag: "employees",
+			Description: "Accepts a partial JSON object, a JSON Merge Patch (RFC 7396) or a JSON Patch (RFC 6902).",
+			Request: []openapi.Body{
+				{ContentType: gin.MIMEJSON, Type: updateEmployeeReq{}, Constraints: employeeUC.UpdateInput{}},
+				{ContentType: mergePatchContentType, Type: updateEmployeeReq{}, Constraints: employeeUC.UpdateInput{}},
+				{ContentType: jsonPatchContentType, Type: jsonPatchSchema},
+			},
+			Responses: []openapi.Reply{
+				{Status: http.StatusOK, ContentType: gin.MIMEJSON, Type: dataEnvelope[employeeDTO]{}},
+				problem(http.StatusBadRequest), problem(http.StatusNotFound), problem(http.StatusConflict),
+				problem(http.StatusUnsupportedMediaType), problem(http.StatusUnprocessableEntity),
+				problem(http.StatusInternalServerError),
+			},
+		},
+		{
+			Method: http.MethodDelete, Path: "/v1/employees/:id", OperationID: "deleteEmployee", Summary: "Delete an employee", Tag: "employees",
+			Responses: []openapi.Reply{
+				{Status: http.StatusNoContent},
+				problem(http.StatusBadRequest), problem(http.StatusNotFound), problem(http.StatusInternalServerError),
+			},
+		},
+	}
+	routes = append(routes, employeeEventsOpenAPIRoute())
+	routes = append(routes, employeeDuplicatesOpenAPIRoutes()...)
+	routes = append(routes, customFieldOpenAPIRoutes()...)
+	routes = append(routes, webhookOpenAPIRoutes()...)
+	routes = append(routes, scimapi.OpenAPIRoutes()...)
+
+	for i := range routes {
+		if routes[i].Tag != "health" && routes[i].Tag != "meta" {
+			// Copied, as routes share parameter slices.
+			routes[i].Params = append(slices.Clip(routes[i].Params), tenantHeader)
+			routes[i].Responses = append(routes[i].Responses, rateLimited, problem(http.StatusUnauthorized), problem(http.StatusForbidden))
+		}
+	}
+	return routes
+}
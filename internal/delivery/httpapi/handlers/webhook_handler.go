@@ -0,0 +1,333 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/rohitashk/golang-rest-api/internal/delivery/httpapi/openapi"
+	"github.com/rohitashk/golang-rest-api/internal/delivery/httpapi/response"
+	domainWebhook "github.com/rohitashk/golang-rest-api/internal/domain/webhook"
+	webhookUC "github.com/rohitashk/golang-rest-api/internal/usecase/webhook"
+)
+
+type WebhookHandler struct {
+	svc            *webhookUC.Service
+	requestTimeout func() time.Duration
+}
+
+func NewWebhookHandler(svc *webhookUC.Service, requestTimeout func() time.Duration) *WebhookHandler {
+	if requestTimeout == nil {
+		requestTimeout = func() time.Duration { return 5 * time.Second }
+	}
+	return &WebhookHandler{svc: svc, requestTimeout: requestTimeout}
+}
+
+type createWebhookReq struct {
+	URL         string   `json:"url"`
+	EventTypes  []string `json:"event_types"`
+	Description string   `json:"description"`
+	Secret      string   `json:"secret"`
+	Active      *bool    `json:"active"`
+}
+
+type updateWebhookReq struct {
+	URL         *string   `json:"url"`
+	EventTypes  *[]string `json:"event_types"`
+	Description *string   `json:"description"`
+	Active      *bool     `json:"active"`
+}
+
+type webhookDTO struct {
+	ID          string   `json:"id"`
+	URL         string   `json:"url"`
+	EventTypes  []string `json:"event_types"` // empty means every event
+	Description string   `json:"description"`
+	Active      bool     `json:"active"`
+	Secret      string   `json:"secret,omitempty"` // only returned on create
+	CreatedAt   string   `json:"created_at"`
+	UpdatedAt   string   `json:"updated_at"`
+}
+
+type deliveryDTO struct {
+	ID            string          `json:"id"`
+	WebhookID     string          `json:"webhook_id"`
+	EventID       string          `json:"event_id"`
+	EventType     string          `json:"event_type"`
+	Status        string          `json:"status"`
+	Tries         int             `json:"tries"`
+	NextAttemptAt *string         `json:"next_attempt_at"` // null unless pending
+	Attempts      []attemptDTO    `json:"attempts"`
+	Payload       json.RawMessage `json:"payload"`
+	CreatedAt     string          `json:"created_at"`
+	UpdatedAt     string          `json:"updated_at"`
+}
+
+type attemptDTO struct {
+	At         string `json:"at"`
+	StatusCode int    `json:"status_code,omitempty"`
+	Error      string `json:"error,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+func toWebhookDTO(s *domainWebhook.Subscription) webhookDTO {
+	eventTypes := s.EventTypes
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+	return webhookDTO{
+		ID:          s.ID,
+		URL:         s.URL,
+		EventTypes:  eventTypes,
+		Description: s.Description,
+		Active:      s.Active,
+		CreatedAt:   s.CreatedAt.UTC().Format(time.RFC3339Nano),
+		UpdatedAt:   s.UpdatedAt.UTC().Format(time.RFC3339Nano),
+	}
+}
+
+func toDeliveryDTO(d *domainWebhook.Delivery) deliveryDTO {
+	var next *string
+	if d.Status == domainWebhook.DeliveryPending {
+		v := d.NextAttemptAt.UTC().Format(time.RFC3339Nano)
+		next = &v
+	}
+	attempts := make([]attemptDTO, 0, len(d.Attempts))
+	for _, a := range d.Attempts {
+		attempts = append(attempts, attemptDTO{
+			At:         a.At.UTC().Format(time.RFC3339Nano),
+			StatusCode: a.StatusCode,
+			Error:      a.Error,
+			DurationMS: a.Duration.Milliseconds(),
+		})
+	}
+	return deliveryDTO{
+		ID:            d.ID,
+		WebhookID:     d.SubscriptionID,
+		EventID:       d.EventID,
+		EventType:     d.EventType,
+		Status:        string(d.Status),
+		Tries:         d.Tries,
+		NextAttemptAt: next,
+		Attempts:      attempts,
+		Payload:       d.Payload,
+		CreatedAt:     d.CreatedAt.UTC().Format(time.RFC3339Nano),
+		UpdatedAt:     d.UpdatedAt.UTC().Format(time.RFC3339Nano),
+	}
+}
+
+func (h *WebhookHandler) Create(c *gin.Context) {
+	var req createWebhookReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout())
+	defer cancel()
+
+	s, err := h.svc.Create(ctx, webhookUC.CreateInput{
+		URL:         strings.TrimSpace(req.URL),
+		EventTypes:  req.EventTypes,
+		Description: strings.TrimSpace(req.Description),
+		Secret:      req.Secret,
+		Active:      req.Active,
+	})
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	out := toWebhookDTO(s)
+	out.Secret = s.Secret
+	response.Created(c, out)
+}
+
+func (h *WebhookHandler) List(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout())
+	defer cancel()
+
+	subs, err := h.svc.List(ctx)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	out := make([]webhookDTO, 0, len(subs))
+	for i := range subs {
+		out = append(out, toWebhookDTO(&subs[i]))
+	}
+	response.OK(c, out)
+}
+
+func (h *WebhookHandler) Get(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout())
+	defer cancel()
+
+	s, err := h.svc.Get(ctx, c.Param("id"))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.OK(c, toWebhookDTO(s))
+}
+
+func (h *WebhookHandler) Update(c *gin.Context) {
+	var req updateWebhookReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout())
+	defer cancel()
+
+	s, err := h.svc.Update(ctx, c.Param("id"), webhookUC.UpdateInput{
+		URL:         req.URL,
+		EventTypes:  req.EventTypes,
+		Description: req.Description,
+		Active:      req.Active,
+	})
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.OK(c, toWebhookDTO(s))
+}
+
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout())
+	defer cancel()
+
+	if err := h.svc.Delete(ctx, c.Param("id")); err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.NoContent(c)
+}
+
+func (h *WebhookHandler) Deliveries(c *gin.Context) {
+	limit, _ := strconv.ParseInt(c.DefaultQuery("limit", "20"), 10, 64)
+	offset, _ := strconv.ParseInt(c.DefaultQuery("offset", "0"), 10, 64)
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout())
+	defer cancel()
+
+	items, total, err := h.svc.Deliveries(ctx, c.Param("id"), limit, offset)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	out := make([]deliveryDTO, 0, len(items))
+	for i := range items {
+		out = append(out, toDeliveryDTO(&items[i]))
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data": out,
+		"meta": listMeta{
+			Total:  total,
+			Limit:  limit,
+			Offset: offset,
+		},
+	})
+}
+
+func (h *WebhookHandler) Delivery(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout())
+	defer cancel()
+
+	d, err := h.svc.Delivery(ctx, c.Param("id"), c.Param("deliveryId"))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	response.OK(c, toDeliveryDTO(d))
+}
+
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout())
+	defer cancel()
+
+	d, err := h.svc.Redeliver(ctx, c.Param("id"), c.Param("deliveryId"))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"data": toDeliveryDTO(d)})
+}
+
+func webhookOpenAPIRoutes() []openapi.Route {
+	return []openapi.Route{
+		{
+			Method: http.MethodPost, Path: "/v1/webhooks", OperationID: "createWebhook", Summary: "Subscribe to employee events", Tag: "webhooks",
+			Description: "Deliveries are POSTed with " + webhookUC.SignatureHeader + " set to sha256=HMAC-SHA256(secret, timestamp + \".\" + body), " +
+				"where timestamp is the " + webhookUC.TimestampHeader + " header. The secret is only returned here.",
+			Request: []openapi.Body{{ContentType: gin.MIMEJSON, Type: createWebhookReq{}, Constraints: webhookUC.CreateInput{}}},
+			Responses: []openapi.Reply{
+				{Status: http.StatusCreated, ContentType: gin.MIMEJSON, Type: dataEnvelope[webhookDTO]{}},
+				problem(http.StatusBadRequest), problem(http.StatusInternalServerError),
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/v1/webhooks", OperationID: "listWebhooks", Summary: "List webhook subscriptions", Tag: "webhooks",
+			Responses: []openapi.Reply{
+				{Status: http.StatusOK, ContentType: gin.MIMEJSON, Type: dataEnvelope[[]webhookDTO]{}},
+				problem(http.StatusInternalServerError),
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/v1/webhooks/:id", OperationID: "getWebhook", Summary: "Get a webhook subscription", Tag: "webhooks",
+			Responses: []openapi.Reply{
+				{Status: http.StatusOK, ContentType: gin.MIMEJSON, Type: dataEnvelope[webhookDTO]{}},
+				problem(http.StatusBadRequest), problem(http.StatusNotFound), problem(http.StatusInternalServerError),
+			},
+		},
+		{
+			Method: http.MethodPatch, Path: "/v1/webhooks/:id", OperationID: "updateWebhook", Summary: "Update a webhook subscription", Tag: "webhooks",
+			Request: []openapi.Body{{ContentType: gin.MIMEJSON, Type: updateWebhookReq{}, Constraints: webhookUC.UpdateInput{}}},
+			Responses: []openapi.Reply{
+				{Status: http.StatusOK, ContentType: gin.MIMEJSON, Type: dataEnvelope[webhookDTO]{}},
+				problem(http.StatusBadRequest), problem(http.StatusNotFound), problem(http.StatusInternalServerError),
+			},
+		},
+		{
+			Method: http.MethodDelete, Path: "/v1/webhooks/:id", OperationID: "deleteWebhook", Summary: "Delete a webhook subscription and its deliveries", Tag: "webhooks",
+			Responses: []openapi.Reply{
+				{Status: http.StatusNoContent},
+				problem(http.StatusBadRequest), problem(http.StatusNotFound), problem(http.StatusInternalServerError),
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/v1/webhooks/:id/deliveries", OperationID: "listWebhookDeliveries", Summary: "Delivery log, newest first", Tag: "webhooks",
+			Params: []openapi.Parameter{
+				query("limit", "Page size, 1-200.", &openapi.Schema{Type: "integer"}),
+				query("offset", "Number of deliveries to skip.", &openapi.Schema{Type: "integer"}),
+			},
+			Responses: []openapi.Reply{
+				{Status: http.StatusOK, ContentType: gin.MIMEJSON, Type: listEnvelope[deliveryDTO]{}},
+				problem(http.StatusBadRequest), problem(http.StatusNotFound), problem(http.StatusInternalServerError),
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/v1/webhooks/:id/deliveries/:deliveryId", OperationID: "getWebhookDelivery", Summary: "Get a delivery with its attempts", Tag: "webhooks",
+			Responses: []openapi.Reply{
+				{Status: http.StatusOK, ContentType: gin.MIMEJSON, Type: dataEnvelope[deliveryDTO]{}},
+				problem(http.StatusBadRequest), problem(http.StatusNotFound), problem(http.StatusInternalServerError),
+			},
+		},
+		{
+			Method: http.MethodPost, Path: "/v1/webhooks/:id/deliveries/:deliveryId/redeliver", OperationID: "redeliverWebhook", Summary: "Send a delivery again", Tag: "webhooks",
+			Description: "Queues the delivery for an immediate attempt with a fresh retry budget.",
+			Responses: []openapi.Reply{
+				{Status: http.StatusAccepted, ContentType: gin.MIMEJSON, Type: dataEnvelope[deliveryDTO]{}},
+				problem(http.StatusBadRequest), problem(http.StatusNotFound), problem(http.StatusInternalServerError),
+			},
+		},
+	}
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/rohitashk/golang-rest-api/internal/auth"
+	"github.com/rohitashk/golang-rest-api/internal/delivery/httpapi/response"
+	"github.com/rohitashk/golang-rest-api/internal/domain"
+)
+
+const (
+	APIKeyHeader = "X-API-Key"
+	// PrincipalKey is the context key under which Authenticate stores the
+	// ID of the caller.
+	PrincipalKey = "principal"
+)
+
+// Authenticate identifies the caller by the key in "Authorization: Bearer"
+// or X-API-Key, stores its ID under PrincipalKey and its tenant under
+// TenantClaimKey. Requests without a key pass unauthenticated, for Tenant to
+// reject or not; a key lookup does not know is rejected.
+func Authenticate(lookup func(key string) (auth.Principal, bool)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := credential(c)
+		if key == "" {
+			c.Next()
+			return
+		}
+		p, ok := auth.Principal{}, false
+		if lookup != nil {
+			p, ok = lookup(key)
+		}
+		if !ok {
+			unauthorized(c, "invalid API key")
+			return
+		}
+		c.Set(PrincipalKey, p.ID)
+		if p.Tenant != "" {
+			c.Set(TenantClaimKey, p.Tenant)
+		}
+		c.Next()
+	}
+}
+
+func unauthorized(c *gin.Context, detail string) {
+	c.Header("WWW-Authenticate", `Bearer realm="api"`)
+	response.Error(c, domain.Unauthorized(detail))
+	c.Abort()
+}
+
+func credential(c *gin.Context) string {
+	if k := strings.TrimSpace(c.GetHeader(APIKeyHeader)); k != "" {
+		return k
+	}
+	scheme, token, _ := strings.Cut(c.GetHeader("Authorization"), " ")
+	if strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
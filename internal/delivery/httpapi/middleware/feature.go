@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/rohitashk/golang-rest-api/internal/delivery/httpapi/response"
+	"github.com/rohitashk/golang-rest-api/internal/domain"
+)
+
+// Feature answers 404 while enabled reports false, as if the routes behind
+// it did not exist. It is checked on every request so a flag can be flipped
+// without a restart.
+func Feature(enabled func() bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !enabled() {
+			response.Error(c, domain.NotFound("not found"))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/rohitashk/golang-rest-api/internal/delivery/httpapi/response"
+	"github.com/rohitashk/golang-rest-api/internal/domain"
+	"github.com/rohitashk/golang-rest-api/internal/domain/idempotency"
+	"github.com/rohitashk/golang-rest-api/internal/domain/tenant"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	defaultIdempotencyKeysTTL = 24 * time.Hour
+)
+
+// Idempotency makes POST requests carrying an Idempotency-Key header safe to
+// retry: the first response is stored and replayed for repeated keys.
+func Idempotency(store idempotency.Store, ttl time.Duration) gin.HandlerFunc {
+	if ttl <= 0 {
+		ttl = defaultIdempotencyKeysTTL
+	}
+
+	return func(c *gin.Context) {
+		key := strings.TrimSpace(c.GetHeader(IdempotencyKeyHeader))
+		if store == nil || key == "" || c.Request.Method != http.MethodPost {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			response.Error(c, domain.Validation("Idempotency-Key must be at most 255 characters"))
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			response.Error(c, domain.Validation("failed to read request body"))
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		// Keys are chosen by clients, so two tenants may pick the same one.
+		if id, ok := tenant.FromContext(c.Request.Context()); ok {
+			key = id + ":" + key
+		}
+
+		now := time.Now().UTC()
+		rec := &idempotency.Record{
+			Key:         key,
+			Fingerprint: fingerprint(c.Request.Method, c.Request.URL.Path, body),
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(ttl),
+		}
+
+		existing, err := store.Reserve(c.Request.Context(), rec)
+		if err != nil {
+			response.Error(c, err)
+			c.Abort()
+			return
+		}
+		if existing != nil {
+			switch {
+			case existing.Fingerprint != rec.Fingerprint:
+				response.Error(c, domain.Unprocessable("Idempotency-Key was already used with a different request"))
+			case !existing.Completed:
+				response.Error(c, domain.Conflict("a request with this Idempotency-Key is still being processed"))
+			default:
+				c.Header(IdempotentReplayedHeader, "true")
+				c.Data(existing.StatusCode, existing.ContentType, existing.Body)
+			}
+			c.Abort()
+			return
+		}
+
+		w := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+
+		stored := false
+		defer func() {
+			// Handler panicked or failed to store: free the key so the
+			// client can retry instead of getting 409s until it expires.
+			if !stored {
+				_ = store.Release(context.WithoutCancel(c.Request.Context()), key)
+			}
+		}()
+
+		c.Next()
+
+		// Server errors are not cached so a retry gets another chance.
+		if w.Status() >= http.StatusInternalServerError {
+			return
+		}
+		rec.Completed = true
+		rec.StatusCode = w.Status()
+		rec.ContentType = w.Header().Get("Content-Type")
+		rec.Body = w.body.Bytes()
+		stored = store.Complete(context.WithoutCancel(c.Request.Context()), rec) == nil
+	}
+}
+
+func fingerprint(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write([]byte(path))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
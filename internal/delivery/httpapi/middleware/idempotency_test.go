@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/rohitashk/golang-rest-api/internal/adapters/memory"
+	"github.com/rohitashk/golang-rest-api/internal/domain/tenant"
+)
+
+// idempotentServer counts the requests its handler runs and answers with
+// the count and the body it got. The tenant comes from X-Test-Tenant.
+func idempotentServer(handler gin.HandlerFunc) (*gin.Engine, *atomic.Int32) {
+	var calls atomic.Int32
+	if handler == nil {
+		handler = func(c *gin.Context) {
+			n := calls.Add(1)
+			body, _ := io.ReadAll(c.Request.Body)
+			c.JSON(http.StatusCreated, gin.H{"call": n, "body": string(body)})
+		}
+	}
+	r := gin.New()
+	r.Use(gin.Recovery(), func(c *gin.Context) {
+		if id := c.GetHeader("X-Test-Tenant"); id != "" {
+			c.Request = c.Request.WithContext(tenant.WithID(c.Request.Context(), id))
+		}
+	}, Idempotency(memory.NewIdempotencyStore(), 0))
+	r.POST("/items", handler)
+	r.POST("/other", handler)
+	r.GET("/items", handler)
+	return r, &calls
+}
+
+func post(r http.Handler, path, key, body string, headers ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyReplay(t *testing.T) {
+	r, calls := idempotentServer(nil)
+
+	first := post(r, "/items", "k1", `{"a":1}`)
+	if first.Code != http.StatusCreated || first.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Fatalf("first = %d %s", first.Code, first.Body)
+	}
+	again := post(r, "/items", "k1", `{"a":1}`)
+	if again.Code != http.StatusCreated || again.Body.String() != first.Body.String() {
+		t.Errorf("replay = %d %s, want %d %s", again.Code, again.Body, first.Code, first.Body)
+	}
+	if again.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Error("replay not marked")
+	}
+	if again.Header().Get("Content-Type") != first.Header().Get("Content-Type") {
+		t.Errorf("replay content type = %q", again.Header().Get("Content-Type"))
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("handler ran %d times, want once", n)
+	}
+
+	// Another key, no key, or another method run the handler.
+	post(r, "/items", "k2", `{"a":1}`)
+	post(r, "/items", "", `{"a":1}`)
+	post(r, "/items", "", `{"a":1}`)
+	req := httptest.NewRequest(http.MethodGet, "/items", nil)
+	req.Header.Set(IdempotencyKeyHeader, "k1")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	if n := calls.Load(); n != 5 {
+		t.Errorf("handler ran %d times, want 5", n)
+	}
+}
+
+func TestIdempotencyMismatch(t *testing.T) {
+	r, calls := idempotentServer(nil)
+	post(r, "/items", "k1", `{"a":1}`)
+
+	if w := post(r, "/items", "k1", `{"a":2}`); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("other body = %d %s, want 422", w.Code, w.Body)
+	}
+	if w := post(r, "/other", "k1", `{"a":1}`); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("other path = %d %s, want 422", w.Code, w.Body)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("handler ran %d times, want once", n)
+	}
+}
+
+func TestIdempotencyPerTenant(t *testing.T) {
+	r, calls := idempotentServer(nil)
+	post(r, "/items", "k1", `{"a":1}`, "X-Test-Tenant", "acme")
+	w := post(r, "/items", "k1", `{"a":2}`, "X-Test-Tenant", "globex")
+	if w.Code != http.StatusCreated || w.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Errorf("another tenant's key = %d %s", w.Code, w.Body)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("handler ran %d times, want twice", n)
+	}
+}
+
+func TestIdempotencyInProgress(t *testing.T) {
+	entered, release := make(chan struct{}), make(chan struct{})
+	r, _ := idempotentServer(func(c *gin.Context) {
+		close(entered)
+		<-release
+		c.Status(http.StatusNoContent)
+	})
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- post(r, "/items", "k1", `{}`) }()
+	<-entered
+	if w := post(r, "/items", "k1", `{}`); w.Code != http.StatusConflict {
+		t.Errorf("while in progress = %d %s, want 409", w.Code, w.Body)
+	}
+	close(release)
+	if w := <-done; w.Code != http.StatusNoContent {
+		t.Errorf("first = %d", w.Code)
+	}
+}
+
+func TestIdempotencyRetriesFailures(t *testing.T) {
+	var calls atomic.Int32
+	r, _ := idempotentServer(func(c *gin.Context) {
+		switch calls.Add(1) {
+		case 1:
+			c.Status(http.StatusServiceUnavailable)
+		case 2:
+			panic("boom")
+		default:
+			c.String(http.StatusCreated, strconv.Itoa(int(calls.Load())))
+		}
+	})
+
+	for i, want := range []int{http.StatusServiceUnavailable, http.StatusInternalServerError, http.StatusCreated, http.StatusCreated} {
+		if w := post(r, "/items", "k1", `{}`); w.Code != want {
+			t.Errorf("attempt %d = %d, want %d", i+1, w.Code, want)
+		}
+	}
+	if n := calls.Load(); n != 3 {
+		t.Errorf("handler ran %d times, want 3: server errors are retried, the success replayed", n)
+	}
+}
+
+func TestIdempotencyKeyTooLong(t *testing.T) {
+	r, calls := idempotentServer(nil)
+	if w := post(r, "/items", strings.Repeat("k", 256), `{}`); w.Code != http.StatusBadRequest {
+		t.Errorf("long key = %d, want 400", w.Code)
+	}
+	if calls.Load() != 0 {
+		t.Error("handler ran")
+	}
+}
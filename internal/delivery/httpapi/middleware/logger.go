@@ -29,13 +29,14 @@ func Logger(l *slog.Logger) gin.HandlerFunc {
 
 		rid, _ := c.Get("request_id")
 
-		l.Info("http request",
+		l.InfoContext(c.Request.Context(), "http request",
 			"status", status,
 			"method", method,
 			"path", path,
 			"ip", clientIP,
 			"latency_ms", latency.Milliseconds(),
 			"request_id", rid,
+			"tenant", c.GetString(TenantKey),
 		)
 	}
 }
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/rohitashk/golang-rest-api/internal/observability"
+)
+
+func TestMetricsLabelRouteTemplates(t *testing.T) {
+	m := observability.NewMetrics()
+	r := gin.New()
+	r.Use(Metrics(m))
+	r.GET("/v1/employees/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.DELETE("/v1/employees/:id", func(c *gin.Context) { c.Status(http.StatusNotFound) })
+
+	for _, req := range []struct{ method, path string }{
+		{http.MethodGet, "/v1/employees/6650c0ffee0000000000a001"},
+		{http.MethodGet, "/v1/employees/6650c0ffee0000000000a002"},
+		{http.MethodDelete, "/v1/employees/6650c0ffee0000000000a003"},
+		{http.MethodGet, "/v1/nothing/here"},
+	} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(req.method, req.path, nil))
+	}
+
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, _ := io.ReadAll(w.Body)
+	out := string(body)
+	for _, want := range []string{
+		`ems_http_requests_total{method="GET",route="/v1/employees/:id",status="200"} 2`,
+		`ems_http_requests_total{method="DELETE",route="/v1/employees/:id",status="404"} 1`,
+		`ems_http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`ems_http_request_duration_seconds_count{method="GET",route="/v1/employees/:id",status="200"} 2`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("metrics miss %s", want)
+		}
+	}
+	if strings.Contains(out, "6650c0ffee") {
+		t.Error("metrics are labelled with a raw path")
+	}
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/rohitashk/golang-rest-api/internal/delivery/httpapi/response"
+	"github.com/rohitashk/golang-rest-api/internal/domain"
+	"github.com/rohitashk/golang-rest-api/internal/domain/ratelimit"
+)
+
+const (
+	RateLimitLimitHeader     = "RateLimit-Limit"
+	RateLimitRemainingHeader = "RateLimit-Remaining"
+	RateLimitResetHeader     = "RateLimit-Reset"
+)
+
+type RateLimitConfig struct {
+	// Policy is read on every request, so limits can change while running.
+	// Routes with their own limit, e.g. "GET /v1/employees/:id", are counted
+	// separately from the rest of the API.
+	Policy func() ratelimit.Policy
+	Logger *slog.Logger
+}
+
+// RateLimit limits each client, identified by the principal Authenticate
+// verified, else by its IP. Unverified credentials never choose the bucket,
+// or a client could start afresh by sending a new one. If the store fails
+// the request is let through.
+func RateLimit(store ratelimit.Store, cfg RateLimitConfig) gin.HandlerFunc {
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return func(c *gin.Context) {
+		limit, scope := cfg.Policy().For(c.Request.Method + " " + c.FullPath())
+		if limit.Unlimited() {
+			c.Next()
+			return
+		}
+
+		d, err := store.Take(c.Request.Context(), clientKey(c)+"|"+scope, limit)
+		if err != nil {
+			logger.WarnContext(c.Request.Context(), "rate limit store failed", "err", err)
+			c.Next()
+			return
+		}
+
+		h := c.Writer.Header()
+		h.Set(RateLimitLimitHeader, strconv.Itoa(d.Limit))
+		h.Set(RateLimitRemainingHeader, strconv.Itoa(d.Remaining))
+		h.Set(RateLimitResetHeader, seconds(d.Reset))
+		if !d.Allowed {
+			retry := seconds(d.RetryAfter)
+			h.Set("Retry-After", retry)
+			response.Error(c, domain.RateLimited(fmt.Sprintf("rate limit exceeded, retry in %s seconds", retry)))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+func clientKey(c *gin.Context) string {
+	if p := c.GetString(PrincipalKey); p != "" {
+		return "principal:" + p
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// seconds rounds up, so a client waiting that long is never early.
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
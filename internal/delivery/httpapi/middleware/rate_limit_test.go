@@ -0,0 +1,154 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/rohitashk/golang-rest-api/internal/delivery/httpapi/response"
+	"github.com/rohitashk/golang-rest-api/internal/domain/ratelimit"
+)
+
+// clockStore keeps buckets like memory.RateLimitStore, on a clock the test
+// moves.
+type clockStore struct {
+	mu      sync.Mutex
+	now     time.Time
+	buckets map[string]ratelimit.Bucket
+}
+
+func (s *clockStore) Take(_ context.Context, key string, limit ratelimit.Limit) (ratelimit.Decision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, d := limit.Take(s.buckets[key], s.now)
+	s.buckets[key] = b
+	return d, nil
+}
+
+func (s *clockStore) advance(d time.Duration) {
+	s.mu.Lock()
+	s.now = s.now.Add(d)
+	s.mu.Unlock()
+}
+
+// limitedServer allows two requests, then one every two seconds.
+func limitedServer(store ratelimit.Store) *gin.Engine {
+	r := gin.New()
+	r.Use(Authenticate(lookup), RateLimit(store, RateLimitConfig{
+		Policy: func() ratelimit.Policy { return ratelimit.Policy{Default: ratelimit.Limit{Rate: 0.5, Burst: 2}} },
+	}))
+	r.GET("/", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	return r
+}
+
+func get(r http.Handler, ip string, headers ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = ip + ":1234"
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimitRefills(t *testing.T) {
+	store := &clockStore{now: time.Unix(1_700_000_000, 0), buckets: map[string]ratelimit.Bucket{}}
+	r := limitedServer(store)
+
+	for i, remaining := range []string{"1", "0"} {
+		w := get(r, "192.0.2.1")
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("request %d = %d", i+1, w.Code)
+		}
+		if got := w.Header().Get(RateLimitLimitHeader); got != "2" {
+			t.Errorf("%s = %q, want 2", RateLimitLimitHeader, got)
+		}
+		if got := w.Header().Get(RateLimitRemainingHeader); got != remaining {
+			t.Errorf("request %d: %s = %q, want %s", i+1, RateLimitRemainingHeader, got, remaining)
+		}
+	}
+
+	w := get(r, "192.0.2.1")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("third request = %d, want 429", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("Retry-After = %q, want 2", got)
+	}
+	if got := w.Header().Get(RateLimitResetHeader); got != "4" {
+		t.Errorf("%s = %q, want 4", RateLimitResetHeader, got)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != response.ProblemContentType {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	var body response.ErrorBody
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Status != http.StatusTooManyRequests || body.Code != "rate_limited" || body.Type != "urn:problem-type:rate_limited" ||
+		body.Detail != "rate limit exceeded, retry in 2 seconds" {
+		t.Errorf("body = %+v", body)
+	}
+
+	store.advance(time.Second)
+	if w := get(r, "192.0.2.1"); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("after 1s = %d, want 429", w.Code)
+	}
+	store.advance(time.Second)
+	if w := get(r, "192.0.2.1"); w.Code != http.StatusNoContent {
+		t.Fatalf("after 2s = %d, want a refilled token", w.Code)
+	}
+}
+
+func TestRateLimitKeysOnVerifiedCallers(t *testing.T) {
+	store := &clockStore{now: time.Unix(1_700_000_000, 0), buckets: map[string]ratelimit.Bucket{}}
+	r := limitedServer(store)
+
+	get(r, "192.0.2.1")
+	get(r, "192.0.2.1")
+	if w := get(r, "192.0.2.1"); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("third request = %d, want 429", w.Code)
+	}
+	// A made-up key neither escapes the limit nor gets a bucket of its own.
+	if w := get(r, "192.0.2.1", APIKeyHeader, "made-up-0123456789"); w.Code != http.StatusUnauthorized {
+		t.Errorf("unknown key = %d, want 401", w.Code)
+	}
+	if len(store.buckets) != 1 {
+		t.Errorf("buckets = %v, want only the IP's", store.buckets)
+	}
+
+	// Verified callers have their own bucket, wherever they come from.
+	for _, ip := range []string{"192.0.2.1", "198.51.100.7"} {
+		if w := get(r, ip, APIKeyHeader, acmeKey); w.Code != http.StatusNoContent {
+			t.Errorf("acme from %s = %d", ip, w.Code)
+		}
+	}
+	if w := get(r, "192.0.2.1", APIKeyHeader, acmeKey); w.Code != http.StatusTooManyRequests {
+		t.Errorf("acme's third request = %d, want 429", w.Code)
+	}
+	if w := get(r, "192.0.2.1", APIKeyHeader, globexKey); w.Code != http.StatusNoContent {
+		t.Errorf("globex = %d", w.Code)
+	}
+	if w := get(r, "198.51.100.7"); w.Code != http.StatusNoContent {
+		t.Errorf("another IP = %d", w.Code)
+	}
+}
+
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, ratelimit.Limit) (ratelimit.Decision, error) {
+	return ratelimit.Decision{}, context.DeadlineExceeded
+}
+
+func TestRateLimitLetsThroughWhenTheStoreFails(t *testing.T) {
+	if w := get(limitedServer(failingStore{}), "192.0.2.1"); w.Code != http.StatusNoContent {
+		t.Errorf("status = %d, want the request let through", w.Code)
+	}
+}
@@ -5,16 +5,29 @@ import (
 	"encoding/hex"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const RequestIDHeader = "X-Request-Id"
 
+// RequestID uses the client's X-Request-Id, or else the trace ID, so a
+// request can be found in the logs and traces by either. The ID is recorded
+// on the request's span.
 func RequestID() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		span := trace.SpanFromContext(c.Request.Context())
+
 		rid := c.GetHeader(RequestIDHeader)
 		if rid == "" {
-			rid = newRequestID()
+			if sc := span.SpanContext(); sc.HasTraceID() {
+				rid = sc.TraceID().String()
+			} else {
+				rid = newRequestID()
+			}
 		}
+		span.SetAttributes(attribute.String("request.id", rid))
+
 		c.Writer.Header().Set(RequestIDHeader, rid)
 		c.Set("request_id", rid)
 		c.Next()
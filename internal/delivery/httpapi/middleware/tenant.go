@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/rohitashk/golang-rest-api/internal/delivery/httpapi/response"
+	"github.com/rohitashk/golang-rest-api/internal/domain"
+	"github.com/rohitashk/golang-rest-api/internal/domain/tenant"
+)
+
+const (
+	TenantHeader = "X-Tenant-ID"
+	// TenantClaimKey is the context key under which Authenticate stores the
+	// tenant of the caller's credentials.
+	TenantClaimKey = "tenant_claim"
+	// TenantKey holds the tenant the request acts for, for logging.
+	TenantKey = "tenant"
+)
+
+// TenantConfig is read on every request, so tenants and keys can change
+// while running. The zero value only allows tenant.Default, to requests
+// with credentials or none.
+type TenantConfig struct {
+	// Known reports whether requests may act for a tenant.
+	Known func(id string) bool
+	// Default is the tenant of requests that name none; empty rejects them.
+	Default func() string
+	// TrustHeader takes X-Tenant-ID from requests without credentials. Only
+	// for deployments behind a gateway that authenticates callers and sets
+	// or strips the header.
+	TrustHeader func() bool
+	// CredentialsRequired rejects requests without credentials.
+	CredentialsRequired func() bool
+}
+
+// Tenant resolves the tenant a request acts for and puts it in the request
+// context for the repositories. A caller acts for the tenant of its
+// credentials; X-Tenant-ID may repeat it but not contradict it. Callers
+// without credentials act for the default tenant, and may only name another
+// when the header is trusted.
+func Tenant(cfg TenantConfig) gin.HandlerFunc {
+	if cfg.Known == nil {
+		cfg.Known = func(id string) bool { return id == tenant.Default }
+	}
+	if cfg.Default == nil {
+		cfg.Default = func() string { return tenant.Default }
+	}
+	if cfg.TrustHeader == nil {
+		cfg.TrustHeader = func() bool { return false }
+	}
+	if cfg.CredentialsRequired == nil {
+		cfg.CredentialsRequired = func() bool { return false }
+	}
+
+	return func(c *gin.Context) {
+		claim := c.GetString(TenantClaimKey)
+		header := strings.TrimSpace(c.GetHeader(TenantHeader))
+		authenticated := c.GetString(PrincipalKey) != ""
+
+		var id string
+		switch {
+		case claim != "" && header != "" && header != claim:
+			response.Error(c, domain.Forbidden(TenantHeader+" does not match the tenant of the credentials"))
+			c.Abort()
+			return
+		case claim != "":
+			id = claim
+		case !authenticated && cfg.CredentialsRequired():
+			unauthorized(c, "an API key is required")
+			return
+		case header != "" && !cfg.TrustHeader():
+			unauthorized(c, TenantHeader+" is only accepted with an API key of that tenant")
+			return
+		case header != "":
+			id = header
+		default:
+			if id = cfg.Default(); id != "" {
+				break
+			}
+			if cfg.TrustHeader() {
+				response.Error(c, domain.Validation(TenantHeader+" header is required"))
+				c.Abort()
+			} else {
+				unauthorized(c, "an API key is required to name the tenant")
+			}
+			return
+		}
+		if !cfg.Known(id) {
+			response.Error(c, domain.Forbidden(fmt.Sprintf("unknown tenant %q", id)))
+			c.Abort()
+			return
+		}
+
+		c.Set(TenantKey, id)
+		c.Request = c.Request.WithContext(tenant.WithID(c.Request.Context(), id))
+		c.Next()
+	}
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/rohitashk/golang-rest-api/internal/auth"
+	"github.com/rohitashk/golang-rest-api/internal/domain/tenant"
+)
+
+func init() { gin.SetMode(gin.TestMode) }
+
+const (
+	acmeKey   = "acme-0123456789abcdef"
+	globexKey = "globex-0123456789abcdef"
+)
+
+func lookup(key string) (auth.Principal, bool) {
+	switch key {
+	case acmeKey:
+		return auth.Principal{ID: "acme:1", Tenant: "acme"}, true
+	case globexKey:
+		return auth.Principal{ID: "globex:1", Tenant: "globex"}, true
+	}
+	return auth.Principal{}, false
+}
+
+// tenantServer answers with the tenant each request acted for.
+func tenantServer(cfg TenantConfig) *gin.Engine {
+	if cfg.Known == nil {
+		cfg.Known = func(id string) bool { return id == "acme" || id == "globex" || id == tenant.Default }
+	}
+	r := gin.New()
+	r.Use(Authenticate(lookup), Tenant(cfg))
+	r.GET("/", func(c *gin.Context) {
+		id, _ := tenant.FromContext(c.Request.Context())
+		c.String(http.StatusOK, id)
+	})
+	return r
+}
+
+func TestTenant(t *testing.T) {
+	yes := func() bool { return true }
+	tests := []struct {
+		name    string
+		cfg     TenantConfig
+		headers map[string]string
+		status  int
+		tenant  string
+	}{
+		{name: "no credentials", status: http.StatusOK, tenant: tenant.Default},
+		{name: "api key", headers: map[string]string{APIKeyHeader: acmeKey}, status: http.StatusOK, tenant: "acme"},
+		{name: "bearer key", headers: map[string]string{"Authorization": "Bearer " + globexKey}, status: http.StatusOK, tenant: "globex"},
+		{name: "key and its tenant", headers: map[string]string{APIKeyHeader: acmeKey, TenantHeader: "acme"}, status: http.StatusOK, tenant: "acme"},
+		{name: "key and another tenant", headers: map[string]string{APIKeyHeader: acmeKey, TenantHeader: "globex"}, status: http.StatusForbidden},
+		{name: "unknown key", headers: map[string]string{APIKeyHeader: "guess-0123456789abcdef"}, status: http.StatusUnauthorized},
+		{name: "header alone", headers: map[string]string{TenantHeader: "acme"}, status: http.StatusUnauthorized},
+		{name: "header alone, trusted", cfg: TenantConfig{TrustHeader: yes}, headers: map[string]string{TenantHeader: "acme"}, status: http.StatusOK, tenant: "acme"},
+		{name: "trusted header does not override a key", cfg: TenantConfig{TrustHeader: yes}, headers: map[string]string{APIKeyHeader: globexKey, TenantHeader: "acme"}, status: http.StatusForbidden},
+		{name: "credentials required", cfg: TenantConfig{CredentialsRequired: yes}, status: http.StatusUnauthorized},
+		{name: "credentials required, trusted header", cfg: TenantConfig{CredentialsRequired: yes, TrustHeader: yes}, headers: map[string]string{TenantHeader: "acme"}, status: http.StatusUnauthorized},
+		{name: "credentials required and given", cfg: TenantConfig{CredentialsRequired: yes}, headers: map[string]string{APIKeyHeader: acmeKey}, status: http.StatusOK, tenant: "acme"},
+		{name: "no default", cfg: TenantConfig{Default: func() string { return "" }}, status: http.StatusUnauthorized},
+		{name: "no default, trusted header missing", cfg: TenantConfig{Default: func() string { return "" }, TrustHeader: yes}, status: http.StatusBadRequest},
+		{name: "unknown tenant", cfg: TenantConfig{Known: func(id string) bool { return id == tenant.Default }}, headers: map[string]string{APIKeyHeader: acmeKey}, status: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			tenantServer(tt.cfg).ServeHTTP(w, req)
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			if tt.status == http.StatusOK && w.Body.String() != tt.tenant {
+				t.Errorf("acted for %q, want %q", w.Body, tt.tenant)
+			}
+			if tt.status == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("401 without WWW-Authenticate")
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordSpans installs a tracer provider keeping every span for the length
+// of the test.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	rec := tracetest.NewSpanRecorder()
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+	return rec
+}
+
+func TestTracing(t *testing.T) {
+	rec := recordSpans(t)
+	r := gin.New()
+	r.Use(Tracing())
+	var handlerSpan trace.SpanContext
+	r.GET("/v1/employees/:id", func(c *gin.Context) {
+		handlerSpan = trace.SpanContextFromContext(c.Request.Context())
+		c.Status(http.StatusNotFound)
+	})
+	r.POST("/v1/employees", func(c *gin.Context) { c.Status(http.StatusInternalServerError) })
+
+	const traceID, parentID = "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"
+	req := httptest.NewRequest(http.MethodGet, "/v1/employees/6650c0ffee0000000000a001", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-"+parentID+"-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/v1/employees", nil))
+
+	spans := rec.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("recorded %d spans", len(spans))
+	}
+
+	get := spans[0]
+	if got := get.SpanContext().TraceID().String(); got != traceID {
+		t.Errorf("trace ID = %s, want the caller's %s", got, traceID)
+	}
+	if got := get.Parent().SpanID().String(); got != parentID || !get.Parent().IsRemote() {
+		t.Errorf("parent = %s, want the caller's span %s", got, parentID)
+	}
+	if handlerSpan.SpanID() != get.SpanContext().SpanID() {
+		t.Error("the handler's context does not carry the server span")
+	}
+	if get.Name() != "GET /v1/employees/:id" || get.SpanKind() != trace.SpanKindServer {
+		t.Errorf("span %q of kind %s", get.Name(), get.SpanKind())
+	}
+	attrs := map[string]any{}
+	for _, kv := range get.Attributes() {
+		attrs[string(kv.Key)] = kv.Value.AsInterface()
+	}
+	if attrs[string(semconv.HTTPRouteKey)] != "/v1/employees/:id" || attrs[string(semconv.HTTPResponseStatusCodeKey)] != int64(404) {
+		t.Errorf("attributes = %v", attrs)
+	}
+	// Client errors are the caller's problem, not the span's.
+	if get.Status().Code != codes.Unset {
+		t.Errorf("404 span status = %v", get.Status())
+	}
+
+	post := spans[1]
+	if post.Parent().IsValid() {
+		t.Errorf("a request without traceparent continued %s", post.Parent().TraceID())
+	}
+	if post.Status().Code != codes.Error || post.Status().Description != "Internal Server Error" {
+		t.Errorf("500 span status = %v", post.Status())
+	}
+}
@@ -0,0 +1,13 @@
+package openapi
+
+import _ "embed"
+
+// DocsHTML renders the document served at /openapi.json. It is
+// self-contained, so the docs page runs no third-party code.
+//
+//go:embed docs.html
+var DocsHTML []byte
+
+// DocsCSP is the Content-Security-Policy of DocsHTML: its inline script and
+// style, and requests to this server only.
+const DocsCSP = "default-src 'none'; script-src 'unsafe-inline'; style-src 'unsafe-inline'; connect-src 'self'"
@@ -0,0 +1,183 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const Version = "3.1.0"
+
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem maps lower-case HTTP methods to operations.
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string              `json:"operationId"`
+	Summary     string              `json:"summary,omitempty"`
+	Description string              `json:"description,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Style       string  `json:"style,omitempty"` // e.g. deepObject
+	Explode     bool    `json:"explode,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// Route describes one gin route. Types are Go values whose JSON encoding is
+// the body; the spec is derived from them by reflection.
+type Route struct {
+	Method      string
+	Path        string // gin syntax, e.g. /v1/employees/:id
+	OperationID string
+	Summary     string
+	Description string
+	Tag         string
+	Params      []Parameter
+	Request     []Body
+	Responses   []Reply
+}
+
+type Body struct {
+	ContentType string
+	Type        any
+	// Constraints is a value whose `validate` tags apply to the JSON fields
+	// of Type with the same name, e.g. the use-case input behind a request.
+	Constraints any
+}
+
+type Reply struct {
+	Status      int
+	Description string
+	ContentType string
+	Type        any // nil for responses without a body
+	Schema      *Schema
+}
+
+func Build(info Info, routes []Route) *Document {
+	g := newGenerator()
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   map[string]PathItem{},
+	}
+
+	for _, rt := range routes {
+		op := &Operation{
+			OperationID: rt.OperationID,
+			Summary:     rt.Summary,
+			Description: rt.Description,
+			Parameters:  rt.Params,
+			Responses:   map[string]Response{},
+		}
+		if rt.Tag != "" {
+			op.Tags = []string{rt.Tag}
+		}
+		for _, name := range pathParams(rt.Path) {
+			op.Parameters = append([]Parameter{{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}}}, op.Parameters...)
+		}
+
+		if len(rt.Request) > 0 {
+			op.RequestBody = &RequestBody{Required: true, Content: map[string]MediaType{}}
+			for _, b := range rt.Request {
+				op.RequestBody.Content[b.ContentType] = MediaType{Schema: g.schemaFor(b.Type, b.Constraints)}
+			}
+		}
+
+		for _, rep := range rt.Responses {
+			desc := rep.Description
+			if desc == "" {
+				desc = http.StatusText(rep.Status)
+			}
+			resp := Response{Description: desc}
+			schema := rep.Schema
+			if schema == nil && rep.Type != nil {
+				schema = g.schemaFor(rep.Type, nil)
+			}
+			if schema != nil {
+				resp.Content = map[string]MediaType{rep.ContentType: {Schema: schema}}
+			}
+			op.Responses[fmt.Sprint(rep.Status)] = resp
+		}
+
+		path := ToOpenAPIPath(rt.Path)
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = PathItem{}
+			doc.Paths[path] = item
+		}
+		item[strings.ToLower(rt.Method)] = op
+	}
+
+	doc.Components.Schemas = g.components
+	return doc
+}
+
+// Missing lists the registered gin routes that the document does not describe.
+func (d *Document) Missing(routes gin.RoutesInfo) []string {
+	var out []string
+	for _, r := range routes {
+		item, ok := d.Paths[ToOpenAPIPath(r.Path)]
+		if !ok || item[strings.ToLower(r.Method)] == nil {
+			out = append(out, r.Method+" "+r.Path)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+var ginParam = regexp.MustCompile(`[:*]([A-Za-z0-9_]+)`)
+
+func ToOpenAPIPath(p string) string {
+	return ginParam.ReplaceAllString(p, "{$1}")
+}
+
+func pathParams(p string) []string {
+	var out []string
+	for _, m := range ginParam.FindAllStringSubmatch(p, -1) {
+		out = append(out, m[1])
+	}
+	return out
+}
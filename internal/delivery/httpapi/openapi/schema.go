@@ -0,0 +1,230 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Schema is the JSON Schema 2020-12 subset used by OpenAPI 3.1.
+type Schema struct {
+	Ref         string             `json:"$ref,omitempty"`
+	Type        any                `json:"type,omitempty"` // string or []string
+	Format      string             `json:"format,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+	Enum        []any              `json:"enum,omitempty"`
+	MinLength   *int               `json:"minLength,omitempty"`
+	MaxLength   *int               `json:"maxLength,omitempty"`
+	Minimum     *float64           `json:"minimum,omitempty"`
+	Maximum     *float64           `json:"maximum,omitempty"`
+	OneOf       []*Schema          `json:"oneOf,omitempty"`
+
+	AdditionalProperties any `json:"additionalProperties,omitempty"`
+}
+
+type generator struct {
+	components map[string]*Schema
+}
+
+func newGenerator() *generator {
+	return &generator{components: map[string]*Schema{}}
+}
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	rawJSONType = reflect.TypeOf(json.RawMessage(nil))
+)
+
+func (g *generator) schemaFor(v any, constraints any) *Schema {
+	if s, ok := v.(*Schema); ok {
+		return s
+	}
+	t := reflect.TypeOf(v)
+	if constraints == nil {
+		return g.schema(t)
+	}
+
+	// Constrained bodies are inlined: the same Go type may be validated
+	// differently depending on the operation.
+	s := g.structSchema(deref(t))
+	applyConstraints(s, reflect.TypeOf(constraints))
+	return s
+}
+
+func (g *generator) schema(t reflect.Type) *Schema {
+	switch t.Kind() {
+	case reflect.Pointer:
+		s := g.schema(t.Elem())
+		return nullable(s)
+	case reflect.Struct:
+		if t == timeType {
+			return &Schema{Type: "string", Format: "date-time"}
+		}
+		name := componentName(t)
+		if name == "" {
+			return g.structSchema(t)
+		}
+		if _, ok := g.components[name]; !ok {
+			g.components[name] = &Schema{} // placeholder for recursive types
+			g.components[name] = g.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	case reflect.Slice, reflect.Array:
+		if t == rawJSONType {
+			return &Schema{}
+		}
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	}
+	return &Schema{}
+}
+
+func (g *generator) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, opts, skip := jsonName(f)
+		if skip {
+			continue
+		}
+		s.Properties[name] = g.schema(f.Type)
+		if !strings.Contains(opts, "omitempty") {
+			s.Required = append(s.Required, name)
+		}
+	}
+	return s
+}
+
+// applyConstraints copies `validate` rules of c onto the matching properties
+// of s. Presence requirements come only from the rules, so the default
+// "every non-omitempty field" list is replaced.
+func applyConstraints(s *Schema, c reflect.Type) {
+	c = deref(c)
+	s.Required = nil
+	for i := 0; i < c.NumField(); i++ {
+		f := c.Field(i)
+		name, _, skip := jsonName(f)
+		prop, ok := s.Properties[name]
+		if skip || !ok {
+			continue
+		}
+		kind := deref(f.Type).Kind()
+		target := prop
+		for _, rule := range strings.Split(f.Tag.Get("validate"), ",") {
+			key, param, _ := strings.Cut(rule, "=")
+			switch key {
+			case "required":
+				s.Required = append(s.Required, name)
+			case "dive":
+				// Later rules apply to the elements.
+				if target.Items == nil {
+					break
+				}
+				target = target.Items
+				kind = deref(f.Type).Elem().Kind()
+			case "email":
+				target.Format = "email"
+			case "http_url":
+				target.Format = "uri"
+			case "oneof":
+				for _, v := range strings.Fields(param) {
+					target.Enum = append(target.Enum, v)
+				}
+			case "min", "gte":
+				setBound(target, kind, param, true)
+			case "max", "lte":
+				setBound(target, kind, param, false)
+			case "len":
+				setBound(target, kind, param, true)
+				setBound(target, kind, param, false)
+			}
+		}
+	}
+}
+
+func setBound(s *Schema, kind reflect.Kind, param string, lower bool) {
+	if kind == reflect.String {
+		n, err := strconv.Atoi(param)
+		if err != nil {
+			return
+		}
+		if lower {
+			s.MinLength = &n
+		} else {
+			s.MaxLength = &n
+		}
+		return
+	}
+	f, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+	if lower {
+		s.Minimum = &f
+	} else {
+		s.Maximum = &f
+	}
+}
+
+func nullable(s *Schema) *Schema {
+	if s.Ref != "" {
+		return &Schema{OneOf: []*Schema{s, {Type: "null"}}}
+	}
+	if t, ok := s.Type.(string); ok {
+		s.Type = []string{t, "null"}
+	}
+	return s
+}
+
+// componentName names reusable schemas after their Go type; anonymous and
+// generic types are inlined.
+func componentName(t reflect.Type) string {
+	name := t.Name()
+	if name == "" || strings.Contains(name, "[") {
+		return ""
+	}
+	r := []rune(name)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+func jsonName(f reflect.StructField) (name, opts string, skip bool) {
+	if !f.IsExported() {
+		return "", "", true
+	}
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", "", true
+	}
+	name, opts, _ = strings.Cut(tag, ",")
+	if name == "" {
+		name = f.Name
+	}
+	return name, opts, false
+}
+
+func deref(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
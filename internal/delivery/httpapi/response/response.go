@@ -3,18 +3,37 @@ package response
 import (
 	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
+	"reflect"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
 
 	"github.com/rohitashk/golang-rest-api/internal/domain"
+	"github.com/rohitashk/golang-rest-api/internal/validation"
 )
 
+const (
+	ProblemContentType = "application/problem+json"
+	problemTypePrefix  = "urn:problem-type:"
+)
+
+// ErrorBody is an RFC 7807 problem details object. Code repeats the domain
+// error kind so clients can switch on it without parsing Type.
 type ErrorBody struct {
-	Code      string `json:"code"`
-	Message   string `json:"message"`
-	RequestID string `json:"request_id,omitempty"`
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Code     string       `json:"code"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
 }
 
 func OK(c *gin.Context, data any) {
@@ -30,46 +49,89 @@ func NoContent(c *gin.Context) {
 }
 
 func Error(c *gin.Context, err error) {
+	status, kind, detail, fields := classify(err)
+	Problem(c, status, string(kind), detail, fields)
+}
+
+func Problem(c *gin.Context, status int, code string, detail string, fields []domain.FieldError) {
 	requestID, _ := c.Get("request_id")
 	rid, _ := requestID.(string)
 
-	status := http.StatusInternalServerError
 	body := ErrorBody{
-		Code:      "internal",
-		Message:   "internal server error",
-		RequestID: rid,
+		Type:     problemTypePrefix + code,
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: rid,
+		Code:     code,
+	}
+	for _, f := range fields {
+		body.Errors = append(body.Errors, FieldError{Field: f.Field, Rule: f.Rule, Message: f.Message})
 	}
 
+	c.Header("Content-Type", ProblemContentType)
+	c.JSON(status, body)
+}
+
+func classify(err error) (int, domain.ErrorKind, string, []domain.FieldError) {
 	// Treat request binding / JSON / validation errors as 400s.
 	// (Gin uses go-playground/validator under the hood for binding tags.)
-	var ve validator.ValidationErrors
+	if fields, ok := validation.Fields(err); ok {
+		return http.StatusBadRequest, domain.ErrKindValidation, "request validation failed", fields
+	}
 	var ute *json.UnmarshalTypeError
+	if errors.As(err, &ute) {
+		field := ute.Field
+		if field == "" {
+			field = "body"
+		}
+		return http.StatusBadRequest, domain.ErrKindValidation, "request validation failed", []domain.FieldError{{
+			Field:   field,
+			Rule:    "type",
+			Message: field + " must be of type " + jsonType(ute.Type.Kind()),
+		}}
+	}
 	var se *json.SyntaxError
-	if errors.As(err, &ve) || errors.As(err, &ute) || errors.As(err, &se) {
-		status = http.StatusBadRequest
-		body.Code = string(domain.ErrKindValidation)
-		body.Message = "invalid request"
-		c.JSON(status, gin.H{"error": body})
-		return
+	if errors.As(err, &se) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return http.StatusBadRequest, domain.ErrKindValidation, "request body is not valid JSON", nil
+	}
+	if errors.Is(err, io.EOF) {
+		return http.StatusBadRequest, domain.ErrKindValidation, "request body is required", nil
 	}
 
 	var derr domain.Error
 	if errors.As(err, &derr) {
-		body.Code = string(derr.Kind)
-		body.Message = derr.Message
-
 		switch derr.Kind {
 		case domain.ErrKindValidation:
-			status = http.StatusBadRequest
+			return http.StatusBadRequest, derr.Kind, derr.Message, derr.Fields
 		case domain.ErrKindNotFound:
-			status = http.StatusNotFound
+			return http.StatusNotFound, derr.Kind, derr.Message, nil
 		case domain.ErrKindConflict:
-			status = http.StatusConflict
-		default:
-			status = http.StatusInternalServerError
-			body.Message = "internal server error"
+			return http.StatusConflict, derr.Kind, derr.Message, nil
+		case domain.ErrKindUnauthorized:
+			return http.StatusUnauthorized, derr.Kind, derr.Message, nil
+		case domain.ErrKindForbidden:
+			return http.StatusForbidden, derr.Kind, derr.Message, nil
+		case domain.ErrKindUnprocessable:
+			return http.StatusUnprocessableEntity, derr.Kind, derr.Message, nil
+		case domain.ErrKindRateLimited:
+			return http.StatusTooManyRequests, derr.Kind, derr.Message, nil
 		}
 	}
 
-	c.JSON(status, gin.H{"error": body})
+	return http.StatusInternalServerError, domain.ErrKindInternal, "internal server error", nil
+}
+
+func jsonType(k reflect.Kind) string {
+	switch k {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct, reflect.Pointer:
+		return "object"
+	}
+	return "number"
 }
@@ -0,0 +1,126 @@
+package response
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/rohitashk/golang-rest-api/internal/domain"
+)
+
+func init() { gin.SetMode(gin.TestMode) }
+
+func render(t *testing.T, err error) (*httptest.ResponseRecorder, ErrorBody) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	c.Set("request_id", "req-1")
+	Error(c, err)
+
+	var body ErrorBody
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("body is not JSON: %v: %s", err, w.Body)
+	}
+	return w, body
+}
+
+func TestErrorMapsDomainKinds(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		code   string
+		detail string
+	}{
+		{domain.Validation("bad input"), http.StatusBadRequest, "validation", "bad input"},
+		{domain.Unauthorized("missing credentials"), http.StatusUnauthorized, "unauthorized", "missing credentials"},
+		{domain.Forbidden("not yours"), http.StatusForbidden, "forbidden", "not yours"},
+		{domain.NotFound("no such employee"), http.StatusNotFound, "not_found", "no such employee"},
+		{domain.Conflict("taken"), http.StatusConflict, "conflict", "taken"},
+		{domain.Unprocessable("cannot"), http.StatusUnprocessableEntity, "unprocessable", "cannot"},
+		{domain.RateLimited("slow down"), http.StatusTooManyRequests, "rate_limited", "slow down"},
+		{fmt.Errorf("wrapped: %w", domain.NotFound("gone")), http.StatusNotFound, "not_found", "gone"},
+		// Internal details never reach the client.
+		{domain.Internal("db exploded", errors.New("secret")), http.StatusInternalServerError, "internal", "internal server error"},
+		{errors.New("plain"), http.StatusInternalServerError, "internal", "internal server error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			w, body := render(t, tt.err)
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d", w.Code, tt.status)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != ProblemContentType {
+				t.Errorf("Content-Type = %q, want %q", ct, ProblemContentType)
+			}
+			want := ErrorBody{
+				Type:     "urn:problem-type:" + tt.code,
+				Title:    http.StatusText(tt.status),
+				Status:   tt.status,
+				Detail:   tt.detail,
+				Instance: "req-1",
+				Code:     tt.code,
+			}
+			if fmt.Sprint(body) != fmt.Sprint(want) {
+				t.Errorf("body = %+v, want %+v", body, want)
+			}
+		})
+	}
+}
+
+func TestErrorListsFieldErrors(t *testing.T) {
+	_, body := render(t, domain.InvalidFields("invalid employee", []domain.FieldError{
+		{Field: "email", Rule: "email", Message: "email must be a valid email address"},
+		{Field: "salary", Rule: "gte", Message: "salary must be at least 0"},
+	}))
+	if body.Status != http.StatusBadRequest || body.Detail != "invalid employee" {
+		t.Fatalf("body = %+v", body)
+	}
+	want := []FieldError{
+		{Field: "email", Rule: "email", Message: "email must be a valid email address"},
+		{Field: "salary", Rule: "gte", Message: "salary must be at least 0"},
+	}
+	if fmt.Sprint(body.Errors) != fmt.Sprint(want) {
+		t.Errorf("errors = %+v, want %+v", body.Errors, want)
+	}
+}
+
+func TestErrorReportsBadJSON(t *testing.T) {
+	var v struct {
+		Salary float64 `json:"salary"`
+	}
+	typeErr := json.Unmarshal([]byte(`{"salary":"high"}`), &v)
+	syntaxErr := json.Unmarshal([]byte(`{"salary":`), &v)
+
+	tests := []struct {
+		name   string
+		err    error
+		detail string
+		fields []FieldError
+	}{
+		{"type", typeErr, "request validation failed", []FieldError{{Field: "salary", Rule: "type", Message: "salary must be of type number"}}},
+		{"syntax", syntaxErr, "request body is not valid JSON", nil},
+		{"truncated", io.ErrUnexpectedEOF, "request body is not valid JSON", nil},
+		{"empty", io.EOF, "request body is required", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, body := render(t, tt.err)
+			if w.Code != http.StatusBadRequest || body.Code != "validation" {
+				t.Fatalf("status %d code %q, want 400 validation", w.Code, body.Code)
+			}
+			if body.Detail != tt.detail {
+				t.Errorf("detail = %q, want %q", body.Detail, tt.detail)
+			}
+			if fmt.Sprint(body.Errors) != fmt.Sprint(tt.fields) {
+				t.Errorf("errors = %+v, want %+v", body.Errors, tt.fields)
+			}
+		})
+	}
+}
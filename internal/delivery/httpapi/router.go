@@ -1,41 +1,196 @@
 package httpapi
 
 import (
+	"fmt"
 	"log/slog"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
 
+	"github.com/rohitashk/golang-rest-api/internal/auth"
+	"github.com/rohitashk/golang-rest-api/internal/config"
+	"github.com/rohitashk/golang-rest-api/internal/delivery/graphqlapi"
 	"github.com/rohitashk/golang-rest-api/internal/delivery/httpapi/handlers"
 	"github.com/rohitashk/golang-rest-api/internal/delivery/httpapi/middleware"
+	"github.com/rohitashk/golang-rest-api/internal/delivery/httpapi/openapi"
+	"github.com/rohitashk/golang-rest-api/internal/delivery/scimapi"
+	"github.com/rohitashk/golang-rest-api/internal/domain/event"
+	"github.com/rohitashk/golang-rest-api/internal/domain/idempotency"
+	"github.com/rohitashk/golang-rest-api/internal/domain/ratelimit"
+	"github.com/rohitashk/golang-rest-api/internal/health"
+	"github.com/rohitashk/golang-rest-api/internal/observability"
+	customFieldUC "github.com/rohitashk/golang-rest-api/internal/usecase/customfield"
 	employeeUC "github.com/rohitashk/golang-rest-api/internal/usecase/employee"
+	webhookUC "github.com/rohitashk/golang-rest-api/internal/usecase/webhook"
+	"github.com/rohitashk/golang-rest-api/internal/validation"
 )
 
 type RouterDeps struct {
 	Logger         *slog.Logger
-	RequestTimeout time.Duration
+	Health         *health.Registry
 	EmployeeSvc    *employeeUC.Service
+	CustomFieldSvc *customFieldUC.Service
+	WebhookSvc     *webhookUC.Service
+
+	// RequestTimeout and Features are read on every request, so they can
+	// change while running. Features reports whether a config.Feature* is on;
+	// nil enables everything.
+	RequestTimeout func() time.Duration
+	Features       func(name string) bool
+
+	// Metrics, when set, instruments every request and is served at /metrics.
+	Metrics *observability.Metrics
+
+	// EventFeed backs GET /v1/employees/events, which is not registered
+	// without it. Streams end when Done is closed.
+	EventFeed event.Feed
+	Done      <-chan struct{}
+
+	// RateLimitStore, when set, enforces RateLimit on every API route.
+	RateLimitStore ratelimit.Store
+	RateLimit      middleware.RateLimitConfig
+	TrustedProxies []string
+
+	// Authenticate returns the caller an API key identifies; nil knows none.
+	Authenticate func(key string) (auth.Principal, bool)
+	// Tenancy resolves the tenant of every API request.
+	Tenancy middleware.TenantConfig
+
+	IdempotencyStore idempotency.Store
+	IdempotencyTTL   time.Duration
+
+	GraphQLLimits graphqlapi.Limits
+
+	// SCIMToken is the bearer token SCIM clients must send; empty disables
+	// authentication.
+	SCIMToken string
 }
 
 func NewRouter(deps RouterDeps) *gin.Engine {
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		validation.UseJSONNames(v)
+	}
+
 	r := gin.New()
+	if err := r.SetTrustedProxies(deps.TrustedProxies); err != nil {
+		panic(fmt.Sprintf("trusted proxies: %v", err))
+	}
 
+	routes := newRouteTable(handlers.OpenAPIRoutes())
+	root := &r.RouterGroup
+
+	if deps.Metrics != nil {
+		// Outside Recovery, so a panic is recorded as the 500 it turns into.
+		r.Use(middleware.Metrics(deps.Metrics))
+		routes.handle(root, "getMetrics", gin.WrapH(deps.Metrics.Handler()))
+	}
 	r.Use(gin.Recovery())
+	r.Use(middleware.Tracing())
 	r.Use(middleware.RequestID())
 	r.Use(middleware.Logger(deps.Logger))
 
-	health := handlers.NewHealthHandler()
-	r.GET("/healthz", health.Get)
+	hh := handlers.NewHealthHandler(deps.Health)
+	routes.handle(root, "getLiveness", hh.Live)
+	routes.handle(root, "getReadiness", hh.Ready)
+
+	// Filled in once every route is registered, before the router serves.
+	spec := new(openapi.Document)
+	docs := handlers.NewOpenAPIHandler(spec)
+	routes.handle(root, "getOpenAPI", docs.Spec)
+	routes.handle(root, "getDocs", docs.Docs)
+
+	feature := func(name string) gin.HandlerFunc {
+		return middleware.Feature(func() bool { return deps.Features == nil || deps.Features(name) })
+	}
+
+	// SCIM is mounted before Authenticate: its clients only hold the SCIM
+	// token, which acts for the default tenant and nowhere else.
+	scim := r.Group(scimapi.BasePath, feature(config.FeatureSCIM), scimapi.Auth(deps.SCIMToken))
+	if deps.RateLimitStore != nil {
+		scim.Use(middleware.RateLimit(deps.RateLimitStore, deps.RateLimit))
+	}
+	scim.Use(middleware.Tenant(middleware.TenantConfig{
+		Known:   deps.Tenancy.Known,
+		Default: deps.Tenancy.Default,
+		// Without a token of its own, SCIM is closed when API keys are.
+		CredentialsRequired: func() bool {
+			return deps.SCIMToken == "" && deps.Tenancy.CredentialsRequired != nil && deps.Tenancy.CredentialsRequired()
+		},
+	}))
+	{
+		sh := scimapi.NewHandler(deps.EmployeeSvc, deps.RequestTimeout)
+		routes.handle(scim, "scimServiceProviderConfig", sh.ServiceProviderConfig)
+		routes.handle(scim, "scimResourceTypes", sh.ResourceTypes)
+		routes.handle(scim, "scimListUsers", sh.ListUsers)
+		routes.handle(scim, "scimCreateUser", sh.CreateUser)
+		routes.handle(scim, "scimGetUser", sh.GetUser)
+		routes.handle(scim, "scimReplaceUser", sh.ReplaceUser)
+		routes.handle(scim, "scimPatchUser", sh.PatchUser)
+		routes.handle(scim, "scimDeleteUser", sh.DeleteUser)
+		routes.handle(scim, "scimListGroups", sh.ListGroups)
+		routes.handle(scim, "scimCreateGroup", sh.CreateGroup)
+		routes.handle(scim, "scimGetGroup", sh.GetGroup)
+		routes.handle(scim, "scimPatchGroup", sh.PatchGroup)
+	}
+
+	// Middleware only applies to routes registered after it, so probes,
+	// metrics and docs above need no credentials and are never rate limited,
+	// and SCIM has its own.
+	r.Use(middleware.Authenticate(deps.Authenticate))
+	if deps.RateLimitStore != nil {
+		r.Use(middleware.RateLimit(deps.RateLimitStore, deps.RateLimit))
+	}
+	r.Use(middleware.Tenant(deps.Tenancy))
 
 	v1 := r.Group("/v1")
+	if deps.IdempotencyStore != nil {
+		v1.Use(middleware.Idempotency(deps.IdempotencyStore, deps.IdempotencyTTL))
+	}
 	{
 		eh := handlers.NewEmployeeHandler(deps.EmployeeSvc, deps.RequestTimeout)
-		v1.POST("/employees", eh.Create)
-		v1.GET("/employees", eh.List)
-		v1.GET("/employees/:id", eh.Get)
-		v1.PATCH("/employees/:id", eh.Update)
-		v1.DELETE("/employees/:id", eh.Delete)
+		routes.handle(v1, "createEmployee", eh.Create)
+		routes.handle(v1, "listEmployees", eh.List)
+		routes.handle(v1, "searchEmployees", eh.Search)
+		routes.handle(v1, "listEmployeeDuplicates", eh.Duplicates)
+		routes.handle(v1, "getEmployee", eh.Get)
+		routes.handle(v1, "updateEmployee", eh.Update)
+		routes.handle(v1, "deleteEmployee", eh.Delete)
+		routes.handle(v1, "mergeEmployee", eh.Merge)
+		if deps.EventFeed != nil {
+			routes.handle(v1, "streamEmployeeEvents", feature(config.FeatureEmployeeEvents), handlers.NewEmployeeEventsHandler(deps.EventFeed, deps.Done).Stream)
+		}
+
+		ch := handlers.NewCustomFieldHandler(deps.CustomFieldSvc, deps.RequestTimeout)
+		routes.handle(v1, "createCustomField", ch.Create)
+		routes.handle(v1, "listCustomFields", ch.List)
+		routes.handle(v1, "getCustomField", ch.Get)
+		routes.handle(v1, "updateCustomField", ch.Update)
+		routes.handle(v1, "deleteCustomField", ch.Delete)
+
+		wh := handlers.NewWebhookHandler(deps.WebhookSvc, deps.RequestTimeout)
+		routes.handle(v1, "createWebhook", wh.Create)
+		routes.handle(v1, "listWebhooks", wh.List)
+		routes.handle(v1, "getWebhook", wh.Get)
+		routes.handle(v1, "updateWebhook", wh.Update)
+		routes.handle(v1, "deleteWebhook", wh.Delete)
+		routes.handle(v1, "listWebhookDeliveries", wh.Deliveries)
+		routes.handle(v1, "getWebhookDelivery", wh.Delivery)
+		routes.handle(v1, "redeliverWebhook", wh.Redeliver)
 	}
 
+	gql, err := graphqlapi.NewHandler(deps.EmployeeSvc, deps.GraphQLLimits, deps.RequestTimeout)
+	if err != nil {
+		panic(fmt.Sprintf("graphql schema: %v", err))
+	}
+	routes.handle(root, "queryGraphQL", feature(config.FeatureGraphQL), gql.Serve)
+	routes.handle(root, "postGraphQL", feature(config.FeatureGraphQL), gql.Serve)
+
+	*spec = *openapi.Build(openapi.Info{
+		Title:   "Employee Management API",
+		Version: "1.0.0",
+	}, routes.served)
+
 	return r
 }
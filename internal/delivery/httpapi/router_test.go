@@ -0,0 +1,274 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/rohitashk/golang-rest-api/internal/adapters/memory"
+	"github.com/rohitashk/golang-rest-api/internal/auth"
+	"github.com/rohitashk/golang-rest-api/internal/delivery/httpapi/handlers"
+	"github.com/rohitashk/golang-rest-api/internal/delivery/httpapi/middleware"
+	"github.com/rohitashk/golang-rest-api/internal/delivery/httpapi/openapi"
+	"github.com/rohitashk/golang-rest-api/internal/health"
+	"github.com/rohitashk/golang-rest-api/internal/observability"
+	customFieldUC "github.com/rohitashk/golang-rest-api/internal/usecase/customfield"
+	employeeUC "github.com/rohitashk/golang-rest-api/internal/usecase/employee"
+	webhookUC "github.com/rohitashk/golang-rest-api/internal/usecase/webhook"
+)
+
+func init() { gin.SetMode(gin.TestMode) }
+
+// testRouter registers every route: all optional dependencies are set.
+func testRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	employees := memory.NewEmployeeRepository()
+	fields := memory.NewCustomFieldRepository()
+	return NewRouter(RouterDeps{
+		Health:         health.NewRegistry(time.Second),
+		EmployeeSvc:    employeeUC.NewService(employeeUC.Deps{Repo: employees, CustomFields: fields}),
+		CustomFieldSvc: customFieldUC.NewService(fields, employees),
+		WebhookSvc:     webhookUC.NewService(nil, nil),
+		Metrics:        observability.NewMetrics(),
+		EventFeed:      memory.NewBroadcaster(10),
+	})
+}
+
+func serveSpec(t *testing.T, r http.Handler) *openapi.Document {
+	t.Helper()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /openapi.json = %d", w.Code)
+	}
+	var doc openapi.Document
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	return &doc
+}
+
+// TestEveryRouteIsDocumented compares what gin serves with the document
+// served at /openapi.json, so a route registered around the route table,
+// or an operation described without a summary or responses, fails it.
+func TestEveryRouteIsDocumented(t *testing.T) {
+	r := testRouter(t)
+	doc := serveSpec(t, r)
+	if missing := doc.Missing(r.Routes()); len(missing) > 0 {
+		t.Errorf("routes missing from the OpenAPI document: %s", strings.Join(missing, ", "))
+	}
+
+	served := map[string]bool{}
+	for _, ri := range r.Routes() {
+		served[ri.Method+" "+openapi.ToOpenAPIPath(ri.Path)] = true
+	}
+	for path, item := range doc.Paths {
+		for method, op := range item {
+			route := strings.ToUpper(method) + " " + path
+			if !served[route] {
+				t.Errorf("%s (%s) is documented but not served", op.OperationID, route)
+			}
+			if op.Summary == "" || len(op.Tags) == 0 || len(op.Responses) == 0 {
+				t.Errorf("%s (%s) lacks a summary, tag or responses", op.OperationID, route)
+			}
+		}
+	}
+}
+
+func TestUndocumentedRoutesAreReported(t *testing.T) {
+	r := testRouter(t)
+	noop := func(*gin.Context) {}
+	r.GET("/debug/vars", noop)
+	r.PUT("/v1/employees/:id", noop) // documented path, undocumented method
+	v1 := r.Group("/v1")
+	v1.GET("/employees/:id/history", noop)
+
+	missing := serveSpec(t, r).Missing(r.Routes())
+	want := []string{"GET /debug/vars", "GET /v1/employees/:id/history", "PUT /v1/employees/:id"}
+	if strings.Join(missing, ", ") != strings.Join(want, ", ") {
+		t.Errorf("Missing = %q, want %q", missing, want)
+	}
+}
+
+func TestEveryDocumentedOperationIsServed(t *testing.T) {
+	r := testRouter(t)
+	served := map[string]bool{}
+	for _, ri := range r.Routes() {
+		served[ri.Method+" "+ri.Path] = true
+	}
+	for _, d := range handlers.OpenAPIRoutes() {
+		if !served[d.Method+" "+d.Path] {
+			t.Errorf("%s (%s %s) is described but not served", d.OperationID, d.Method, d.Path)
+		}
+	}
+}
+
+func TestSpecOmitsRoutesNotRegistered(t *testing.T) {
+	r := NewRouter(RouterDeps{Health: health.NewRegistry(time.Second)})
+	doc := serveSpec(t, r)
+	for _, path := range []string{"/metrics", "/v1/employees/events"} {
+		if _, ok := doc.Paths[path]; ok {
+			t.Errorf("%s is documented but not served", path)
+		}
+	}
+	if missing := doc.Missing(r.Routes()); len(missing) > 0 {
+		t.Errorf("routes missing from the OpenAPI document: %s", strings.Join(missing, ", "))
+	}
+}
+
+func TestDocsPageIsSelfContained(t *testing.T) {
+	w := httptest.NewRecorder()
+	testRouter(t).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /docs = %d", w.Code)
+	}
+	if csp := w.Header().Get("Content-Security-Policy"); !strings.Contains(csp, "default-src 'none'") {
+		t.Errorf("Content-Security-Policy = %q", csp)
+	}
+	if body := w.Body.String(); strings.Contains(body, "://") {
+		t.Error("docs page refers to another origin")
+	}
+}
+
+const (
+	acmeKey   = "acme-0123456789abcdef"
+	globexKey = "globex-0123456789abcdef"
+	scimToken = "scim-0123456789abcdef"
+)
+
+// tenantRouter serves the tenants acme and globex to callers with keys.
+func tenantRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	keys, err := auth.ParseKeys([]string{"acme:" + acmeKey, "globex:" + globexKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	employees := memory.NewEmployeeRepository()
+	fields := memory.NewCustomFieldRepository()
+	svc := employeeUC.NewService(employeeUC.Deps{Repo: employees, CustomFields: fields})
+	return NewRouter(RouterDeps{
+		Health:         health.NewRegistry(time.Second),
+		EmployeeSvc:    svc,
+		CustomFieldSvc: customFieldUC.NewService(fields, employees),
+		WebhookSvc:     webhookUC.NewService(nil, nil),
+		Authenticate:   keys.Lookup,
+		Tenancy: middleware.TenantConfig{
+			Known:               func(id string) bool { return id == "acme" || id == "globex" || id == "default" },
+			CredentialsRequired: func() bool { return true },
+		},
+		SCIMToken: scimToken,
+	})
+}
+
+func call(r http.Handler, method, path, body string, headers ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestTenantsCannotReachEachOther(t *testing.T) {
+	r := tenantRouter(t)
+
+	w := call(r, http.MethodPost, "/v1/employees",
+		`{"first_name":"Ada","last_name":"Lovelace","email":"ada@example.com","department":"R&D","position":"Engineer","salary":1}`,
+		middleware.APIKeyHeader, acmeKey)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create = %d: %s", w.Code, w.Body)
+	}
+	var created struct {
+		Data struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatal(err)
+	}
+	path := "/v1/employees/" + created.Data.ID
+
+	if w := call(r, http.MethodGet, path, "", middleware.APIKeyHeader, acmeKey); w.Code != http.StatusOK {
+		t.Fatalf("owner read = %d: %s", w.Code, w.Body)
+	}
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		body    string
+		headers []string
+		status  int
+	}{
+		{"read", http.MethodGet, path, "", []string{middleware.APIKeyHeader, globexKey}, http.StatusNotFound},
+		{"update", http.MethodPatch, path, `{"position":"Spy"}`, []string{"Authorization", "Bearer " + globexKey}, http.StatusNotFound},
+		{"delete", http.MethodDelete, path, "", []string{middleware.APIKeyHeader, globexKey}, http.StatusNotFound},
+		{"reuse the email", http.MethodPost, "/v1/employees",
+			`{"first_name":"Ada","last_name":"L","email":"ada@example.com","department":"R&D","position":"Engineer","salary":1}`,
+			[]string{middleware.APIKeyHeader, globexKey}, http.StatusCreated},
+		{"name the other tenant", http.MethodGet, path, "", []string{middleware.APIKeyHeader, globexKey, middleware.TenantHeader, "acme"}, http.StatusForbidden},
+		{"header without a key", http.MethodGet, path, "", []string{middleware.TenantHeader, "acme"}, http.StatusUnauthorized},
+		{"header without a key, write", http.MethodDelete, path, "", []string{middleware.TenantHeader, "acme"}, http.StatusUnauthorized},
+		{"no credentials", http.MethodGet, path, "", nil, http.StatusUnauthorized},
+		{"guessed key", http.MethodGet, path, "", []string{middleware.APIKeyHeader, "acme-0123456789abcdeX"}, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := call(r, tt.method, tt.path, tt.body, tt.headers...); w.Code != tt.status {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+		})
+	}
+
+	w = call(r, http.MethodGet, "/v1/employees", "", middleware.APIKeyHeader, globexKey)
+	if strings.Contains(w.Body.String(), created.Data.ID) {
+		t.Errorf("globex lists acme's employee: %s", w.Body)
+	}
+	w = call(r, http.MethodGet, path, "", middleware.APIKeyHeader, acmeKey)
+	if w.Code != http.StatusOK || strings.Contains(w.Body.String(), "Spy") {
+		t.Errorf("acme's employee changed: %d %s", w.Code, w.Body)
+	}
+}
+
+func TestSCIMTokenOnlyOpensSCIM(t *testing.T) {
+	r := tenantRouter(t)
+	if w := call(r, http.MethodGet, "/scim/v2/Users", "", "Authorization", "Bearer "+scimToken); w.Code != http.StatusOK {
+		t.Errorf("SCIM with its token = %d: %s", w.Code, w.Body)
+	}
+	if w := call(r, http.MethodGet, "/scim/v2/Users", "", "Authorization", "Bearer "+acmeKey); w.Code != http.StatusUnauthorized {
+		t.Errorf("SCIM with an API key = %d, want 401", w.Code)
+	}
+
+	// The SCIM token is no API key.
+	for _, headers := range [][]string{
+		{"Authorization", "Bearer " + scimToken},
+		{middleware.APIKeyHeader, scimToken},
+	} {
+		if w := call(r, http.MethodGet, "/v1/employees", "", headers...); w.Code != http.StatusUnauthorized {
+			t.Errorf("GET /v1/employees with the SCIM token in %s = %d, want 401", headers[0], w.Code)
+		}
+		if w := call(r, http.MethodPost, "/v1/webhooks", `{"url":"https://example.com/hook","events":["employee.created"]}`, headers...); w.Code != http.StatusUnauthorized {
+			t.Errorf("POST /v1/webhooks with the SCIM token in %s = %d, want 401", headers[0], w.Code)
+		}
+	}
+}
+
+func TestSCIMClosedWithoutItsToken(t *testing.T) {
+	r := NewRouter(RouterDeps{
+		Health:      health.NewRegistry(time.Second),
+		EmployeeSvc: employeeUC.NewService(employeeUC.Deps{Repo: memory.NewEmployeeRepository()}),
+		Tenancy:     middleware.TenantConfig{CredentialsRequired: func() bool { return true }},
+	})
+	if w := call(r, http.MethodGet, "/scim/v2/Users", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("SCIM without a token configured = %d, want 401 while API keys are required", w.Code)
+	}
+}
@@ -0,0 +1,44 @@
+package httpapi
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/rohitashk/golang-rest-api/internal/delivery/httpapi/openapi"
+)
+
+// routeTable registers routes by the operation describing them, taking the
+// method and path from the description, and records what it registered so
+// the OpenAPI document lists exactly the routes served.
+type routeTable struct {
+	docs   map[string]openapi.Route // by operation ID
+	served []openapi.Route
+}
+
+func newRouteTable(docs []openapi.Route) *routeTable {
+	t := &routeTable{docs: make(map[string]openapi.Route, len(docs))}
+	for _, d := range docs {
+		if _, dup := t.docs[d.OperationID]; dup {
+			panic(fmt.Sprintf("OpenAPI operation %q is described twice", d.OperationID))
+		}
+		t.docs[d.OperationID] = d
+	}
+	return t
+}
+
+// handle registers the operation on g. Like gin's own route conflicts, an
+// operation without a description, or one outside g, is a programming error.
+func (t *routeTable) handle(g *gin.RouterGroup, operationID string, h ...gin.HandlerFunc) {
+	d, ok := t.docs[operationID]
+	if !ok {
+		panic(fmt.Sprintf("no OpenAPI description of operation %q (add it to handlers.OpenAPIRoutes)", operationID))
+	}
+	base := strings.TrimSuffix(g.BasePath(), "/")
+	if !strings.HasPrefix(d.Path, base+"/") {
+		panic(fmt.Sprintf("operation %q at %s is outside route group %s", operationID, d.Path, g.BasePath()))
+	}
+	g.Handle(d.Method, strings.TrimPrefix(d.Path, base), h...)
+	t.served = append(t.served, d)
+}
@@ -0,0 +1,116 @@
+package scimapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/rohitashk/golang-rest-api/internal/domain"
+	"github.com/rohitashk/golang-rest-api/internal/validation"
+)
+
+// scimError is a protocol error with an RFC 7644 scimType.
+type scimError struct {
+	status   int
+	scimType string
+	detail   string
+}
+
+func (e scimError) Error() string { return e.detail }
+
+func invalidSyntax(detail string) error {
+	return scimError{status: http.StatusBadRequest, scimType: "invalidSyntax", detail: detail}
+}
+
+func invalidFilter(detail string) error {
+	return scimError{status: http.StatusBadRequest, scimType: "invalidFilter", detail: detail}
+}
+
+func invalidPath(detail string) error {
+	return scimError{status: http.StatusBadRequest, scimType: "invalidPath", detail: detail}
+}
+
+func invalidValue(detail string) error {
+	return scimError{status: http.StatusBadRequest, scimType: "invalidValue", detail: detail}
+}
+
+func mutability(detail string) error {
+	return scimError{status: http.StatusBadRequest, scimType: "mutability", detail: detail}
+}
+
+func notFound(detail string) error {
+	return scimError{status: http.StatusNotFound, detail: detail}
+}
+
+func writeError(c *gin.Context, err error) {
+	status, scimType, detail := classify(err)
+	c.Header("Content-Type", ContentType)
+	c.JSON(status, ErrorResponse{
+		Schemas:  []string{SchemaError},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   detail,
+	})
+	c.Abort()
+}
+
+func classify(err error) (int, string, string) {
+	var se scimError
+	if errors.As(err, &se) {
+		return se.status, se.scimType, se.detail
+	}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+		return http.StatusBadRequest, "invalidSyntax", "malformed JSON body"
+	}
+
+	if fields, ok := validation.Fields(err); ok {
+		return http.StatusBadRequest, "invalidValue", fieldDetail(fields)
+	}
+
+	var derr domain.Error
+	if errors.As(err, &derr) {
+		switch derr.Kind {
+		case domain.ErrKindValidation, domain.ErrKindUnprocessable:
+			detail := derr.Message
+			if len(derr.Fields) > 0 {
+				detail = fieldDetail(derr.Fields)
+			}
+			return http.StatusBadRequest, "invalidValue", detail
+		case domain.ErrKindNotFound:
+			return http.StatusNotFound, "", derr.Message
+		case domain.ErrKindConflict:
+			return http.StatusConflict, "uniqueness", derr.Message
+		}
+	}
+	return http.StatusInternalServerError, "", "internal error"
+}
+
+// fieldDetail names fields by their SCIM attribute where one exists.
+func fieldDetail(fields []domain.FieldError) string {
+	msgs := make([]string, 0, len(fields))
+	for _, f := range fields {
+		msg := f.Message
+		if attr, ok := scimAttrs[f.Field]; ok {
+			msg = strings.Replace(msg, f.Field, attr, 1)
+		}
+		msgs = append(msgs, msg)
+	}
+	return strings.Join(msgs, "; ")
+}
+
+var scimAttrs = map[string]string{
+	"first_name": "name.givenName",
+	"last_name":  "name.familyName",
+	"email":      "userName",
+	"position":   "title",
+	"department": SchemaEnterpriseUser + ":department",
+	"manager_id": SchemaEnterpriseUser + ":manager",
+	"status":     "active",
+}
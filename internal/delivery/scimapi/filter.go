@@ -0,0 +1,210 @@
+package scimapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// comparison is one `attrPath eq value` term of a filter. A comparison in
+// a value path, like the one in emails[type eq "work"], is on the
+// sub-attribute: emails.type.
+type comparison struct {
+	Attr  string // normalized by normalizeAttr
+	Value any
+}
+
+// parseFilter parses the subset of RFC 7644 filters that maps onto employee
+// queries: `eq` comparisons joined with `and`, optionally parenthesized or
+// in a value path. Anything else, like `or`, `not` or another operator, is
+// an error rather than ignored.
+func parseFilter(s string) ([]comparison, error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &filterParser{tokens: tokens}
+	out, err := p.conjunction("")
+	if err != nil {
+		return nil, err
+	}
+	if tok, ok := p.peek(); ok {
+		return nil, fmt.Errorf("unexpected %s", tok)
+	}
+	return out, nil
+}
+
+type filterParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *filterParser) peek() (string, bool) {
+	if p.pos == len(p.tokens) {
+		return "", false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *filterParser) next() (string, bool) {
+	tok, ok := p.peek()
+	if ok {
+		p.pos++
+	}
+	return tok, ok
+}
+
+// conjunction parses terms joined by `and`. Within a value path, parent is
+// its attribute.
+func (p *filterParser) conjunction(parent string) ([]comparison, error) {
+	var out []comparison
+	for {
+		cmps, err := p.term(parent)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, cmps...)
+
+		tok, _ := p.peek()
+		switch strings.ToLower(tok) {
+		case "and":
+			p.pos++
+		case "or":
+			return nil, fmt.Errorf("logical operator %q is not supported, only and", tok)
+		default:
+			return out, nil
+		}
+	}
+}
+
+// term parses a parenthesized conjunction, a value path or a comparison.
+func (p *filterParser) term(parent string) ([]comparison, error) {
+	tok, ok := p.next()
+	switch {
+	case !ok:
+		return nil, fmt.Errorf("expression expected at the end of the filter")
+	case tok == "(":
+		cmps, err := p.conjunction(parent)
+		if err != nil {
+			return nil, err
+		}
+		if tok, _ := p.next(); tok != ")" {
+			return nil, fmt.Errorf("missing )")
+		}
+		return cmps, nil
+	case strings.EqualFold(tok, "not"):
+		return nil, fmt.Errorf("logical operator %q is not supported, only and", tok)
+	case !isAttrPath(tok):
+		return nil, fmt.Errorf("attribute expected, got %s", tok)
+	}
+
+	attr := tok
+	if parent != "" {
+		attr = parent + "." + attr
+	}
+	if next, _ := p.peek(); next == "[" {
+		if parent != "" {
+			return nil, fmt.Errorf("value paths cannot be nested")
+		}
+		p.pos++
+		cmps, err := p.conjunction(attr)
+		if err != nil {
+			return nil, err
+		}
+		if tok, _ := p.next(); tok != "]" {
+			return nil, fmt.Errorf("missing ]")
+		}
+		return cmps, nil
+	}
+
+	op, ok := p.next()
+	switch {
+	case !ok:
+		return nil, fmt.Errorf("operator expected after %s", tok)
+	case !strings.EqualFold(op, "eq"):
+		return nil, fmt.Errorf("operator %q is not supported, only eq", op)
+	}
+	raw, ok := p.next()
+	if !ok || raw == "(" || raw == ")" || raw == "[" || raw == "]" {
+		return nil, fmt.Errorf("value expected after %s %s", tok, op)
+	}
+	var v any
+	if err := json.Unmarshal([]byte(raw), &v); err != nil {
+		return nil, fmt.Errorf("invalid value %s", raw)
+	}
+	switch v.(type) {
+	case string, bool, float64, nil:
+	default:
+		return nil, fmt.Errorf("invalid value %s", raw)
+	}
+	return []comparison{{Attr: normalizeAttr(attr), Value: v}}, nil
+}
+
+// isAttrPath reports whether tok can be an attribute path, optionally
+// prefixed with a schema URN.
+func isAttrPath(tok string) bool {
+	for _, r := range tok {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("$-_.:", r) {
+			return false
+		}
+	}
+	return tok != ""
+}
+
+// tokenize splits on whitespace, keeping quoted strings (with escapes)
+// whole and making tokens of parentheses and brackets.
+func tokenize(s string) ([]string, error) {
+	var tokens []string
+	var cur strings.Builder
+	flush := func() {
+		if cur.Len() > 0 {
+			tokens = append(tokens, cur.String())
+			cur.Reset()
+		}
+	}
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '"':
+			flush()
+			j := i + 1
+			for ; j < len(s) && s[j] != '"'; j++ {
+				if s[j] == '\\' {
+					j++
+				}
+			}
+			if j >= len(s) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			tokens = append(tokens, s[i:j+1])
+			i = j
+		case c == '(' || c == ')' || c == '[' || c == ']':
+			flush()
+			tokens = append(tokens, string(c))
+		case c == ' ' || c == '\t':
+			flush()
+		default:
+			cur.WriteByte(c)
+		}
+	}
+	flush()
+	return tokens, nil
+}
+
+// normalizeAttr lowercases an attribute path, drops the core schema URN and
+// shortens the enterprise one to "enterprise:".
+func normalizeAttr(attr string) string {
+	a := strings.ToLower(attr)
+	if rest, ok := strings.CutPrefix(a, strings.ToLower(SchemaUser)+":"); ok {
+		return rest
+	}
+	if rest, ok := strings.CutPrefix(a, strings.ToLower(SchemaGroup)+":"); ok {
+		return rest
+	}
+	if rest, ok := strings.CutPrefix(a, strings.ToLower(SchemaEnterpriseUser)+":"); ok {
+		return "enterprise:" + rest
+	}
+	return a
+}
@@ -0,0 +1,187 @@
+package scimapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/rohitashk/golang-rest-api/internal/adapters/memory"
+	"github.com/rohitashk/golang-rest-api/internal/domain/tenant"
+	employeeUC "github.com/rohitashk/golang-rest-api/internal/usecase/employee"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		filter string
+		want   []comparison
+	}{
+		{`userName eq "ada@example.com"`, []comparison{{"username", "ada@example.com"}}},
+		{`active eq true`, []comparison{{"active", true}}},
+		{`userName EQ "a \"b\""`, []comparison{{"username", `a "b"`}}},
+		{
+			`(userName eq "a") and (active eq false)`,
+			[]comparison{{"username", "a"}, {"active", false}},
+		},
+		{
+			`((userName eq "a" and active eq true))`,
+			[]comparison{{"username", "a"}, {"active", true}},
+		},
+		{`emails[type eq "work"]`, []comparison{{"emails.type", "work"}}},
+		{
+			`emails[type eq "work" and value eq "a@example.com"] and active eq true`,
+			[]comparison{{"emails.type", "work"}, {"emails.value", "a@example.com"}, {"active", true}},
+		},
+		{`emails[(value eq "a(b)")]`, []comparison{{"emails.value", "a(b)"}}},
+		{
+			`urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:department eq "Sales"`,
+			[]comparison{{"enterprise:department", "Sales"}},
+		},
+		{
+			`urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:manager[value eq "m1"]`,
+			[]comparison{{"enterprise:manager.value", "m1"}},
+		},
+		{`urn:ietf:params:scim:schemas:core:2.0:User:userName eq "a"`, []comparison{{"username", "a"}}},
+	}
+	for _, tt := range tests {
+		got, err := parseFilter(tt.filter)
+		if err != nil {
+			t.Errorf("%s: %v", tt.filter, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s = %+v, want %+v", tt.filter, got, tt.want)
+		}
+	}
+}
+
+func TestParseFilterRejects(t *testing.T) {
+	for _, filter := range []string{
+		``,
+		`userName`,
+		`userName eq`,
+		`userName ne "a"`,
+		`userName sw "a"`,
+		`title pr`,
+		`userName eq "a" or userName eq "b"`,
+		`not (userName eq "a")`,
+		`userName eq "a" and`,
+		`(userName eq "a"`,
+		`userName eq "a")`,
+		`emails[type eq "work"`,
+		`emails[type eq "work"]]`,
+		`emails[type eq "work" or type eq "home"]`,
+		`emails[value[x eq "a"]]`,
+		`userName eq "a`,
+		`userName eq a`,
+		`userName eq "a" "b"`,
+		`"userName" eq "a"`,
+		`userName eq ["a"]`,
+	} {
+		if got, err := parseFilter(filter); err == nil {
+			t.Errorf("%s = %+v, want an error", filter, got)
+		}
+	}
+}
+
+func TestApplyUserFilter(t *testing.T) {
+	str := func(s string) *string { return &s }
+	tests := []struct {
+		filter    string
+		want      employeeUC.ListInput
+		matchable bool
+	}{
+		{`userName eq "a@example.com"`, employeeUC.ListInput{Email: str("a@example.com")}, true},
+		{`emails[type eq "work" and value eq "a@example.com"]`, employeeUC.ListInput{Email: str("a@example.com")}, true},
+		{`emails[type eq "home"]`, employeeUC.ListInput{}, false},
+		{`active eq false`, employeeUC.ListInput{Status: str("inactive")}, true},
+		{`userName eq "a@example.com" and emails.value eq "A@example.com"`, employeeUC.ListInput{Email: str("A@example.com")}, true},
+		// The second condition must not replace the first.
+		{`userName eq "a@example.com" and userName eq "b@example.com"`, employeeUC.ListInput{Email: str("b@example.com")}, false},
+		{`active eq true and active eq false`, employeeUC.ListInput{Status: str("inactive")}, false},
+		{
+			`urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:department eq "Sales" and urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:department eq "Ops"`,
+			employeeUC.ListInput{Department: str("Ops")},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		var in employeeUC.ListInput
+		matchable, err := applyUserFilter(&in, tt.filter)
+		if err != nil {
+			t.Errorf("%s: %v", tt.filter, err)
+			continue
+		}
+		if matchable != tt.matchable {
+			t.Errorf("%s: matchable = %v, want %v", tt.filter, matchable, tt.matchable)
+		}
+		if matchable && !reflect.DeepEqual(in, tt.want) {
+			t.Errorf("%s: input = %+v, want %+v", tt.filter, in, tt.want)
+		}
+	}
+
+	for _, filter := range []string{`active eq "yes"`, `userName eq true`, `title eq "Engineer"`} {
+		var in employeeUC.ListInput
+		if _, err := applyUserFilter(&in, filter); err == nil {
+			t.Errorf("%s: want an error", filter)
+		}
+	}
+}
+
+func TestListUsersFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := employeeUC.NewService(employeeUC.Deps{Repo: memory.NewEmployeeRepository()})
+	ctx := tenant.WithID(context.Background(), "acme")
+	for _, email := range []string{"ada@example.com", "bob@example.com"} {
+		_, err := svc.Create(ctx, employeeUC.CreateInput{FirstName: "A", LastName: "B", Email: email, Department: "Sales", Position: "Rep"})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	r := gin.New()
+	r.GET("/Users", func(c *gin.Context) {
+		c.Request = c.Request.WithContext(tenant.WithID(c.Request.Context(), "acme"))
+	}, NewHandler(svc, nil).ListUsers)
+	list := func(filter string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/Users?filter="+url.QueryEscape(filter), nil))
+		return w
+	}
+
+	tests := []struct {
+		filter string
+		total  int64
+	}{
+		{`userName eq "ada@example.com"`, 1},
+		{`emails[type eq "work" and value eq "bob@example.com"]`, 1},
+		{`(urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:department eq "Sales")`, 2},
+		{`userName eq "ada@example.com" and userName eq "bob@example.com"`, 0},
+	}
+	for _, tt := range tests {
+		w := list(tt.filter)
+		var body ListResponse[User]
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || w.Code != http.StatusOK {
+			t.Errorf("%s: %d %s", tt.filter, w.Code, w.Body)
+			continue
+		}
+		if body.TotalResults != tt.total || len(body.Resources) != int(tt.total) {
+			t.Errorf("%s: %d results of %d, want %d", tt.filter, len(body.Resources), body.TotalResults, tt.total)
+		}
+	}
+
+	for _, filter := range []string{`userName eq "a" or userName eq "b"`, `userName co "ada"`, `emails[type eq "work"`} {
+		w := list(filter)
+		var body ErrorResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		if w.Code != http.StatusBadRequest || body.ScimType != "invalidFilter" {
+			t.Errorf("%s: %d %+v, want 400 invalidFilter", filter, w.Code, body)
+		}
+	}
+}
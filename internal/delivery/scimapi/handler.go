@@ -0,0 +1,655 @@
+package scimapi
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	domainEmployee "github.com/rohitashk/golang-rest-api/internal/domain/employee"
+	employeeUC "github.com/rohitashk/golang-rest-api/internal/usecase/employee"
+)
+
+const (
+	// BasePath is where the router mounts the SCIM endpoints.
+	BasePath = "/scim/v2"
+
+	defaultCount = 100
+	maxCount     = 200
+)
+
+type Handler struct {
+	svc            *employeeUC.Service
+	requestTimeout func() time.Duration
+}
+
+func NewHandler(svc *employeeUC.Service, requestTimeout func() time.Duration) *Handler {
+	if requestTimeout == nil {
+		requestTimeout = func() time.Duration { return 5 * time.Second }
+	}
+	return &Handler{svc: svc, requestTimeout: requestTimeout}
+}
+
+// Auth requires "Authorization: Bearer <token>". An empty token disables the
+// check.
+func Auth(token string) gin.HandlerFunc {
+	want := []byte("Bearer " + token)
+	return func(c *gin.Context) {
+		if token == "" {
+			c.Next()
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(c.GetHeader("Authorization")), want) != 1 {
+			c.Header("WWW-Authenticate", `Bearer realm="scim"`)
+			writeError(c, scimError{status: http.StatusUnauthorized, detail: "missing or invalid bearer token"})
+			return
+		}
+		c.Next()
+	}
+}
+
+func (h *Handler) ServiceProviderConfig(c *gin.Context) {
+	supported := func(ok bool) gin.H { return gin.H{"supported": ok} }
+	render(c, http.StatusOK, gin.H{
+		"schemas":        []string{SchemaSPConfig},
+		"patch":          supported(true),
+		"bulk":           gin.H{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         gin.H{"supported": true, "maxResults": maxCount},
+		"changePassword": supported(false),
+		"sort":           supported(false),
+		"etag":           supported(false),
+		"authenticationSchemes": []gin.H{{
+			"type":        "oauthbearertoken",
+			"name":        "Bearer token",
+			"description": "Static bearer token configured with SCIM_BEARER_TOKEN.",
+		}},
+		"meta": gin.H{"resourceType": "ServiceProviderConfig", "location": baseURL(c) + "/ServiceProviderConfig"},
+	})
+}
+
+func (h *Handler) ResourceTypes(c *gin.Context) {
+	base := baseURL(c)
+	types := []gin.H{
+		{
+			"schemas": []string{SchemaResourceType}, "id": "User", "name": "User", "endpoint": "/Users",
+			"schema":           SchemaUser,
+			"schemaExtensions": []gin.H{{"schema": SchemaEnterpriseUser, "required": true}},
+			"meta":             gin.H{"resourceType": "ResourceType", "location": base + "/ResourceTypes/User"},
+		},
+		{
+			"schemas": []string{SchemaResourceType}, "id": "Group", "name": "Group", "endpoint": "/Groups",
+			"description": "Departments.",
+			"schema":      SchemaGroup,
+			"meta":        gin.H{"resourceType": "ResourceType", "location": base + "/ResourceTypes/Group"},
+		},
+	}
+	render(c, http.StatusOK, ListResponse[gin.H]{
+		Schemas: []string{SchemaListResponse}, TotalResults: int64(len(types)), StartIndex: 1,
+		ItemsPerPage: len(types), Resources: types,
+	})
+}
+
+func (h *Handler) ListUsers(c *gin.Context) {
+	startIndex, count, err := paging(c)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	in := employeeUC.ListInput{Offset: startIndex - 1, Limit: int64(count)}
+	if count == 0 {
+		in.Limit = 1 // only totalResults is wanted
+	}
+	if f := c.Query("filter"); f != "" {
+		matchable, err := applyUserFilter(&in, f)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		if !matchable {
+			render(c, http.StatusOK, ListResponse[User]{
+				Schemas: []string{SchemaListResponse}, StartIndex: startIndex, Resources: []User{},
+			})
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout())
+	defer cancel()
+
+	items, total, err := h.svc.List(ctx, in)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	if count == 0 {
+		items = nil
+	}
+
+	base := baseURL(c)
+	users := make([]User, 0, len(items))
+	for i := range items {
+		users = append(users, toUser(&items[i], base))
+	}
+	render(c, http.StatusOK, ListResponse[User]{
+		Schemas: []string{SchemaListResponse}, TotalResults: total, StartIndex: startIndex,
+		ItemsPerPage: len(users), Resources: users,
+	})
+}
+
+func (h *Handler) GetUser(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout())
+	defer cancel()
+
+	e, err := h.svc.Get(ctx, c.Param("id"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	render(c, http.StatusOK, toUser(e, baseURL(c)))
+}
+
+func (h *Handler) CreateUser(c *gin.Context) {
+	var u User
+	if err := json.NewDecoder(c.Request.Body).Decode(&u); err != nil {
+		writeError(c, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout())
+	defer cancel()
+
+	in := userToUpdate(&u)
+	e, err := h.svc.Create(ctx, employeeUC.CreateInput{
+		FirstName:  *in.FirstName,
+		LastName:   *in.LastName,
+		Email:      *in.Email,
+		Department: *in.Department,
+		Position:   *in.Position,
+		Status:     *in.Status,
+		ManagerID:  *in.ManagerID,
+	})
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.Header("Location", baseURL(c)+"/Users/"+e.ID)
+	render(c, http.StatusCreated, toUser(e, baseURL(c)))
+}
+
+// ReplaceUser implements PUT: attributes missing from the body are reset,
+// except salary, which SCIM does not carry.
+func (h *Handler) ReplaceUser(c *gin.Context) {
+	var u User
+	if err := json.NewDecoder(c.Request.Body).Decode(&u); err != nil {
+		writeError(c, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout())
+	defer cancel()
+
+	e, err := h.svc.Update(ctx, c.Param("id"), userToUpdate(&u))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	render(c, http.StatusOK, toUser(e, baseURL(c)))
+}
+
+func (h *Handler) PatchUser(c *gin.Context) {
+	var req PatchRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		writeError(c, err)
+		return
+	}
+	in, err := userPatch(req.Operations)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout())
+	defer cancel()
+
+	e, err := h.svc.Update(ctx, c.Param("id"), in)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	render(c, http.StatusOK, toUser(e, baseURL(c)))
+}
+
+func (h *Handler) DeleteUser(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout())
+	defer cancel()
+
+	if err := h.svc.Delete(ctx, c.Param("id")); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) ListGroups(c *gin.Context) {
+	startIndex, count, err := paging(c)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	var names []string
+	filtered := false
+	if f := c.Query("filter"); f != "" {
+		cmps, err := parseFilter(f)
+		if err != nil {
+			writeError(c, invalidFilter(err.Error()))
+			return
+		}
+		filtered = true
+		conflict := false
+		for _, cmp := range cmps {
+			s, ok := cmp.Value.(string)
+			if cmp.Attr != "displayname" || !ok {
+				writeError(c, invalidFilter("only displayName eq \"...\" is supported for groups"))
+				return
+			}
+			if len(names) == 0 {
+				names = append(names, s)
+			}
+			conflict = conflict || names[0] != s
+		}
+		if conflict {
+			names = nil // a department has a single name
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout())
+	defer cancel()
+
+	if !filtered {
+		depts, err := h.svc.Departments(ctx)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		for _, d := range depts {
+			names = append(names, d.Name)
+		}
+	}
+
+	withMembers := !excluded(c, "members")
+	var groups []Group
+	for _, name := range names {
+		members, err := h.members(ctx, name)
+		if err != nil {
+			writeError(c, err)
+			return
+		}
+		if len(members) > 0 {
+			groups = append(groups, toGroup(name, members, withMembers, baseURL(c)))
+		}
+	}
+
+	total := int64(len(groups))
+	from := min(startIndex-1, total)
+	to := min(from+int64(count), total)
+	page := groups[from:to]
+	if page == nil {
+		page = []Group{}
+	}
+	render(c, http.StatusOK, ListResponse[Group]{
+		Schemas: []string{SchemaListResponse}, TotalResults: total, StartIndex: startIndex,
+		ItemsPerPage: len(page), Resources: page,
+	})
+}
+
+func (h *Handler) GetGroup(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout())
+	defer cancel()
+
+	name, members, err := h.group(ctx, c.Param("id"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	render(c, http.StatusOK, toGroup(name, members, !excluded(c, "members"), baseURL(c)))
+}
+
+// CreateGroup moves the members into the department; a department only
+// exists while it has employees, so members are required.
+func (h *Handler) CreateGroup(c *gin.Context) {
+	var g Group
+	if err := json.NewDecoder(c.Request.Body).Decode(&g); err != nil {
+		writeError(c, err)
+		return
+	}
+	name := strings.TrimSpace(g.DisplayName)
+	if name == "" {
+		writeError(c, invalidValue("displayName is required"))
+		return
+	}
+	if len(g.Members) == 0 {
+		writeError(c, invalidValue("a group is a department and needs at least one member"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout())
+	defer cancel()
+
+	if err := h.moveInto(ctx, name, g.Members); err != nil {
+		writeError(c, err)
+		return
+	}
+	members, err := h.members(ctx, name)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	c.Header("Location", baseURL(c)+"/Groups/"+groupID(name))
+	render(c, http.StatusCreated, toGroup(name, members, true, baseURL(c)))
+}
+
+// PatchGroup supports adding members (moving employees into the department)
+// and renaming. Members cannot be removed: every employee belongs to some
+// department, so they are moved by adding them to another group.
+func (h *Handler) PatchGroup(c *gin.Context) {
+	var req PatchRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		writeError(c, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout())
+	defer cancel()
+
+	name, members, err := h.group(ctx, c.Param("id"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+
+	for _, op := range req.Operations {
+		path := strings.ToLower(valueFilter.ReplaceAllString(op.Path, ""))
+		value := op.Value
+		if path == "" {
+			obj, ok := op.Value.(map[string]any)
+			if !ok {
+				writeError(c, invalidSyntax("an operation without a path needs an object value"))
+				return
+			}
+			for k, v := range obj {
+				path, value = strings.ToLower(k), v
+			}
+			if len(obj) != 1 {
+				writeError(c, invalidSyntax("set one attribute per operation"))
+				return
+			}
+		}
+
+		switch {
+		case strings.EqualFold(op.Op, "remove"):
+			writeError(c, mutability("members cannot be removed from a department; add them to another group"))
+			return
+		case path == "members":
+			refs, err := memberRefs(value)
+			if err != nil {
+				writeError(c, err)
+				return
+			}
+			if strings.EqualFold(op.Op, "replace") {
+				if err := checkReplaceKeepsMembers(members, refs); err != nil {
+					writeError(c, err)
+					return
+				}
+			}
+			if err := h.moveInto(ctx, name, refs); err != nil {
+				writeError(c, err)
+				return
+			}
+		case path == "displayname":
+			newName, ok := value.(string)
+			if !ok || strings.TrimSpace(newName) == "" {
+				writeError(c, invalidValue("displayName must be a non-empty string"))
+				return
+			}
+			refs := make([]Member, 0, len(members))
+			for _, m := range members {
+				refs = append(refs, Member{Value: m.ID})
+			}
+			name = strings.TrimSpace(newName)
+			if err := h.moveInto(ctx, name, refs); err != nil {
+				writeError(c, err)
+				return
+			}
+		default:
+			writeError(c, invalidPath(fmt.Sprintf("attribute %q is not supported", op.Path)))
+			return
+		}
+
+		if members, err = h.members(ctx, name); err != nil {
+			writeError(c, err)
+			return
+		}
+	}
+
+	render(c, http.StatusOK, toGroup(name, members, true, baseURL(c)))
+}
+
+func (h *Handler) group(ctx context.Context, id string) (string, []domainEmployee.Employee, error) {
+	name, ok := departmentFromGroupID(id)
+	if !ok {
+		return "", nil, notFound("group not found")
+	}
+	members, err := h.members(ctx, name)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(members) == 0 {
+		return "", nil, notFound("group not found")
+	}
+	return name, members, nil
+}
+
+func (h *Handler) members(ctx context.Context, department string) ([]domainEmployee.Employee, error) {
+	var out []domainEmployee.Employee
+	for {
+		items, total, err := h.svc.List(ctx, employeeUC.ListInput{
+			Department: &department,
+			Limit:      maxCount,
+			Offset:     int64(len(out)),
+			Fields:     []string{domainEmployee.FieldFirstName, domainEmployee.FieldLastName},
+		})
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, items...)
+		if len(items) == 0 || int64(len(out)) >= total {
+			return out, nil
+		}
+	}
+}
+
+func (h *Handler) moveInto(ctx context.Context, department string, refs []Member) error {
+	for _, m := range refs {
+		if _, err := h.svc.Update(ctx, m.Value, employeeUC.UpdateInput{Department: &department}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func memberRefs(v any) ([]Member, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, invalidValue("members must be an array")
+	}
+	var refs []Member
+	if err := json.Unmarshal(b, &refs); err != nil {
+		return nil, invalidValue("members must be an array of {\"value\": id}")
+	}
+	return refs, nil
+}
+
+func checkReplaceKeepsMembers(current []domainEmployee.Employee, refs []Member) error {
+	keep := make(map[string]bool, len(refs))
+	for _, r := range refs {
+		keep[r.Value] = true
+	}
+	for _, m := range current {
+		if !keep[m.ID] {
+			return mutability(fmt.Sprintf("member %s cannot be removed from a department; add them to another group", m.ID))
+		}
+	}
+	return nil
+}
+
+// userToUpdate maps every attribute of a full User resource; absent ones get
+// their defaults.
+func userToUpdate(u *User) employeeUC.UpdateInput {
+	email := strings.TrimSpace(u.UserName)
+	if email == "" {
+		for _, e := range u.Emails {
+			if e.Primary || email == "" {
+				email = strings.TrimSpace(e.Value)
+			}
+		}
+	}
+
+	var first, last string
+	if u.Name != nil {
+		first, last = strings.TrimSpace(u.Name.GivenName), strings.TrimSpace(u.Name.FamilyName)
+	}
+
+	status := string(domainEmployee.StatusActive)
+	if u.Active != nil && !*u.Active {
+		status = string(domainEmployee.StatusInactive)
+	}
+
+	var department, manager string
+	if u.Enterprise != nil {
+		department = strings.TrimSpace(u.Enterprise.Department)
+		if u.Enterprise.Manager != nil {
+			manager = strings.TrimSpace(u.Enterprise.Manager.Value)
+		}
+	}
+	position := strings.TrimSpace(u.Title)
+
+	return employeeUC.UpdateInput{
+		FirstName:  &first,
+		LastName:   &last,
+		Email:      &email,
+		Department: &department,
+		Position:   &position,
+		Status:     &status,
+		ManagerID:  &manager,
+	}
+}
+
+// applyUserFilter narrows in to the users filter matches. It returns false
+// when no user can match, as when an attribute is compared with two
+// different values.
+func applyUserFilter(in *employeeUC.ListInput, filter string) (bool, error) {
+	cmps, err := parseFilter(filter)
+	if err != nil {
+		return false, invalidFilter(err.Error())
+	}
+	matchable := true
+	set := func(dst **string, v string, equal func(a, b string) bool) {
+		if *dst != nil && !equal(**dst, v) {
+			matchable = false
+		}
+		*dst = &v
+	}
+	exact := func(a, b string) bool { return a == b }
+
+	for _, cmp := range cmps {
+		switch cmp.Attr {
+		case "active":
+			b, ok := cmp.Value.(bool)
+			if !ok {
+				return false, invalidFilter("active must be compared with true or false")
+			}
+			status := string(domainEmployee.StatusInactive)
+			if b {
+				status = string(domainEmployee.StatusActive)
+			}
+			set(&in.Status, status, exact)
+			continue
+		}
+
+		s, ok := cmp.Value.(string)
+		if !ok {
+			return false, invalidFilter(fmt.Sprintf("%s must be compared with a string", cmp.Attr))
+		}
+		switch cmp.Attr {
+		case "username", "emails", "emails.value":
+			set(&in.Email, s, strings.EqualFold)
+		case "emails.type":
+			// Every user has a single email, of type work.
+			matchable = matchable && strings.EqualFold(s, "work")
+		case "enterprise:department":
+			set(&in.Department, s, exact)
+		case "enterprise:manager", "enterprise:manager.value":
+			set(&in.ManagerID, s, exact)
+		default:
+			return false, invalidFilter(fmt.Sprintf("filtering on %q is not supported", cmp.Attr))
+		}
+	}
+	return matchable, nil
+}
+
+// paging reads the 1-based startIndex and count parameters.
+func paging(c *gin.Context) (int64, int, error) {
+	startIndex := int64(1)
+	if v := c.Query("startIndex"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return 0, 0, invalidValue("startIndex must be an integer")
+		}
+		startIndex = max(n, 1)
+	}
+
+	count := defaultCount
+	if v := c.Query("count"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return 0, 0, invalidValue("count must be an integer")
+		}
+		count = min(max(n, 0), maxCount)
+	}
+	return startIndex, count, nil
+}
+
+func excluded(c *gin.Context, attr string) bool {
+	for _, a := range strings.Split(c.Query("excludedAttributes"), ",") {
+		if strings.EqualFold(strings.TrimSpace(a), attr) {
+			return true
+		}
+	}
+	return false
+}
+
+func baseURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if p := c.GetHeader("X-Forwarded-Proto"); p != "" {
+		scheme = p
+	}
+	return scheme + "://" + c.Request.Host + BasePath
+}
+
+func render(c *gin.Context, status int, body any) {
+	c.Header("Content-Type", ContentType)
+	c.JSON(status, body)
+}
@@ -0,0 +1,98 @@
+package scimapi
+
+import (
+	"net/http"
+
+	"github.com/rohitashk/golang-rest-api/internal/delivery/httpapi/openapi"
+)
+
+func errorReply(status int) openapi.Reply {
+	return openapi.Reply{Status: status, ContentType: ContentType, Type: ErrorResponse{}}
+}
+
+func reply(status int, typ any) openapi.Reply {
+	return openapi.Reply{Status: status, ContentType: ContentType, Type: typ}
+}
+
+var (
+	anyObject = &openapi.Schema{Type: "object"}
+
+	listParams = []openapi.Parameter{
+		{Name: "filter", In: "query", Description: "RFC 7644 filter; eq comparisons joined with and.", Schema: &openapi.Schema{Type: "string"}},
+		{Name: "startIndex", In: "query", Description: "1-based index of the first result.", Schema: &openapi.Schema{Type: "integer"}},
+		{Name: "count", In: "query", Description: "Page size, 0-200.", Schema: &openapi.Schema{Type: "integer"}},
+	}
+	groupParams = []openapi.Parameter{
+		{Name: "excludedAttributes", In: "query", Description: "Set to members to omit group members.", Schema: &openapi.Schema{Type: "string"}},
+	}
+)
+
+// OpenAPIRoutes describes the SCIM endpoints for the HTTP API document.
+func OpenAPIRoutes() []openapi.Route {
+	p := func(path string) string { return BasePath + path }
+	body := func(typ any) []openapi.Body { return []openapi.Body{{ContentType: ContentType, Type: typ}} }
+	userErrors := []openapi.Reply{
+		errorReply(http.StatusBadRequest), errorReply(http.StatusUnauthorized), errorReply(http.StatusNotFound),
+		errorReply(http.StatusConflict), errorReply(http.StatusInternalServerError),
+	}
+
+	return []openapi.Route{
+		{
+			Method: http.MethodGet, Path: p("/ServiceProviderConfig"), OperationID: "scimServiceProviderConfig", Summary: "SCIM service provider configuration", Tag: "scim",
+			Responses: []openapi.Reply{{Status: http.StatusOK, ContentType: ContentType, Schema: anyObject}},
+		},
+		{
+			Method: http.MethodGet, Path: p("/ResourceTypes"), OperationID: "scimResourceTypes", Summary: "SCIM resource types", Tag: "scim",
+			Responses: []openapi.Reply{{Status: http.StatusOK, ContentType: ContentType, Schema: anyObject}},
+		},
+		{
+			Method: http.MethodGet, Path: p("/Users"), OperationID: "scimListUsers", Summary: "List users", Tag: "scim",
+			Description: "Filterable on userName, emails.value, active and the enterprise department and manager.",
+			Params:      listParams,
+			Responses:   append([]openapi.Reply{reply(http.StatusOK, ListResponse[User]{})}, userErrors...),
+		},
+		{
+			Method: http.MethodPost, Path: p("/Users"), OperationID: "scimCreateUser", Summary: "Provision a user", Tag: "scim",
+			Request:   body(User{}),
+			Responses: append([]openapi.Reply{reply(http.StatusCreated, User{})}, userErrors...),
+		},
+		{
+			Method: http.MethodGet, Path: p("/Users/:id"), OperationID: "scimGetUser", Summary: "Get a user", Tag: "scim",
+			Responses: append([]openapi.Reply{reply(http.StatusOK, User{})}, userErrors...),
+		},
+		{
+			Method: http.MethodPut, Path: p("/Users/:id"), OperationID: "scimReplaceUser", Summary: "Replace a user", Tag: "scim",
+			Request:   body(User{}),
+			Responses: append([]openapi.Reply{reply(http.StatusOK, User{})}, userErrors...),
+		},
+		{
+			Method: http.MethodPatch, Path: p("/Users/:id"), OperationID: "scimPatchUser", Summary: "Patch a user", Tag: "scim",
+			Request:   body(PatchRequest{}),
+			Responses: append([]openapi.Reply{reply(http.StatusOK, User{})}, userErrors...),
+		},
+		{
+			Method: http.MethodDelete, Path: p("/Users/:id"), OperationID: "scimDeleteUser", Summary: "Deprovision a user", Tag: "scim",
+			Responses: append([]openapi.Reply{{Status: http.StatusNoContent}}, userErrors...),
+		},
+		{
+			Method: http.MethodGet, Path: p("/Groups"), OperationID: "scimListGroups", Summary: "List departments as groups", Tag: "scim",
+			Params:    append(append([]openapi.Parameter{}, listParams...), groupParams...),
+			Responses: append([]openapi.Reply{reply(http.StatusOK, ListResponse[Group]{})}, userErrors...),
+		},
+		{
+			Method: http.MethodPost, Path: p("/Groups"), OperationID: "scimCreateGroup", Summary: "Move members into a department", Tag: "scim",
+			Request:   body(Group{}),
+			Responses: append([]openapi.Reply{reply(http.StatusCreated, Group{})}, userErrors...),
+		},
+		{
+			Method: http.MethodGet, Path: p("/Groups/:id"), OperationID: "scimGetGroup", Summary: "Get a department as a group", Tag: "scim",
+			Params:    groupParams,
+			Responses: append([]openapi.Reply{reply(http.StatusOK, Group{})}, userErrors...),
+		},
+		{
+			Method: http.MethodPatch, Path: p("/Groups/:id"), OperationID: "scimPatchGroup", Summary: "Add members to or rename a department", Tag: "scim",
+			Request:   body(PatchRequest{}),
+			Responses: append([]openapi.Reply{reply(http.StatusOK, Group{})}, userErrors...),
+		},
+	}
+}
@@ -0,0 +1,198 @@
+package scimapi
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	domainEmployee "github.com/rohitashk/golang-rest-api/internal/domain/employee"
+	employeeUC "github.com/rohitashk/golang-rest-api/internal/usecase/employee"
+)
+
+// valueFilter matches value selection filters in paths such as
+// emails[type eq "work"].value; employees have a single email, so the
+// selection is irrelevant.
+var valueFilter = regexp.MustCompile(`\[[^\]]*\]`)
+
+// userPatch turns PATCH operations on a User into an employee update.
+func userPatch(ops []PatchOperation) (employeeUC.UpdateInput, error) {
+	var in employeeUC.UpdateInput
+	for _, op := range ops {
+		path := normalizeAttr(valueFilter.ReplaceAllString(op.Path, ""))
+
+		switch strings.ToLower(op.Op) {
+		case "add", "replace":
+			if path == "" {
+				obj, ok := op.Value.(map[string]any)
+				if !ok {
+					return in, invalidSyntax("an operation without a path needs an object value")
+				}
+				if err := setUserAttrs(&in, "", obj); err != nil {
+					return in, err
+				}
+				continue
+			}
+			if err := setUserAttr(&in, path, op.Value); err != nil {
+				return in, err
+			}
+		case "remove":
+			switch path {
+			case "enterprise:manager", "enterprise:manager.value":
+				empty := ""
+				in.ManagerID = &empty
+			case "":
+				return in, invalidSyntax("remove needs a path")
+			default:
+				return in, mutability(fmt.Sprintf("attribute %q cannot be removed", op.Path))
+			}
+		default:
+			return in, invalidSyntax(fmt.Sprintf("unsupported operation %q", op.Op))
+		}
+	}
+	return in, nil
+}
+
+func setUserAttrs(in *employeeUC.UpdateInput, prefix string, obj map[string]any) error {
+	for k, v := range obj {
+		attr := normalizeAttr(k)
+		if attr == strings.ToLower(SchemaEnterpriseUser) {
+			sub, ok := v.(map[string]any)
+			if !ok {
+				return invalidValue("enterprise extension must be an object")
+			}
+			if err := setUserAttrs(in, "enterprise:", sub); err != nil {
+				return err
+			}
+			continue
+		}
+		if prefix != "" && !strings.HasPrefix(attr, prefix) {
+			attr = prefix + attr
+		}
+		if err := setUserAttr(in, attr, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setUserAttr(in *employeeUC.UpdateInput, attr string, v any) error {
+	switch attr {
+	case "active":
+		active, err := boolValue(v)
+		if err != nil {
+			return err
+		}
+		status := string(domainEmployee.StatusInactive)
+		if active {
+			status = string(domainEmployee.StatusActive)
+		}
+		in.Status = &status
+	case "username", "emails.value":
+		s, err := stringValue(attr, v)
+		if err != nil {
+			return err
+		}
+		in.Email = &s
+	case "emails":
+		s, err := primaryEmail(v)
+		if err != nil {
+			return err
+		}
+		in.Email = &s
+	case "name":
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return invalidValue("name must be an object")
+		}
+		for k, sub := range obj {
+			if err := setUserAttr(in, "name."+strings.ToLower(k), sub); err != nil {
+				return err
+			}
+		}
+	case "name.givenname":
+		s, err := stringValue(attr, v)
+		if err != nil {
+			return err
+		}
+		in.FirstName = &s
+	case "name.familyname":
+		s, err := stringValue(attr, v)
+		if err != nil {
+			return err
+		}
+		in.LastName = &s
+	case "title":
+		s, err := stringValue(attr, v)
+		if err != nil {
+			return err
+		}
+		in.Position = &s
+	case "enterprise:department":
+		s, err := stringValue(attr, v)
+		if err != nil {
+			return err
+		}
+		in.Department = &s
+	case "enterprise:manager", "enterprise:manager.value":
+		if obj, ok := v.(map[string]any); ok {
+			v = obj["value"]
+		}
+		s := ""
+		if v != nil {
+			var err error
+			if s, err = stringValue(attr, v); err != nil {
+				return err
+			}
+		}
+		in.ManagerID = &s
+	case "id", "schemas", "meta", "externalid", "displayname", "name.formatted":
+		// Read-only or derived from other attributes.
+	default:
+		return invalidPath(fmt.Sprintf("attribute %q is not supported", attr))
+	}
+	return nil
+}
+
+func stringValue(attr string, v any) (string, error) {
+	s, ok := v.(string)
+	if !ok {
+		return "", invalidValue(fmt.Sprintf("%s must be a string", attr))
+	}
+	return strings.TrimSpace(s), nil
+}
+
+// boolValue also accepts "true"/"false" strings, which some identity
+// providers send for active.
+func boolValue(v any) (bool, error) {
+	switch b := v.(type) {
+	case bool:
+		return b, nil
+	case string:
+		switch strings.ToLower(b) {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+	}
+	return false, invalidValue("active must be a boolean")
+}
+
+func primaryEmail(v any) (string, error) {
+	list, ok := v.([]any)
+	if !ok || len(list) == 0 {
+		return "", invalidValue("emails must be a non-empty array")
+	}
+	chosen := list[0]
+	for _, item := range list {
+		if obj, ok := item.(map[string]any); ok && obj["primary"] == true {
+			chosen = item
+			break
+		}
+	}
+	obj, ok := chosen.(map[string]any)
+	if !ok {
+		return "", invalidValue("emails entries must be objects")
+	}
+	return stringValue("emails.value", obj["value"])
+}
@@ -0,0 +1,171 @@
+package scimapi
+
+import (
+	"encoding/base64"
+	"time"
+
+	domainEmployee "github.com/rohitashk/golang-rest-api/internal/domain/employee"
+)
+
+const (
+	ContentType = "application/scim+json"
+
+	SchemaUser           = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SchemaEnterpriseUser = "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"
+	SchemaGroup          = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SchemaListResponse   = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SchemaPatchOp        = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SchemaError          = "urn:ietf:params:scim:api:messages:2.0:Error"
+	SchemaSPConfig       = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	SchemaResourceType   = "urn:ietf:params:scim:schemas:core:2.0:ResourceType"
+)
+
+// User maps onto an employee: userName and the primary email are both the
+// employee email, title is the position and the enterprise extension carries
+// department and manager.
+type User struct {
+	Schemas     []string        `json:"schemas"`
+	ID          string          `json:"id,omitempty"`
+	UserName    string          `json:"userName"`
+	Name        *Name           `json:"name,omitempty"`
+	DisplayName string          `json:"displayName,omitempty"`
+	Title       string          `json:"title,omitempty"`
+	Active      *bool           `json:"active,omitempty"`
+	Emails      []Email         `json:"emails,omitempty"`
+	Enterprise  *EnterpriseUser `json:"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User,omitempty"`
+	Meta        *Meta           `json:"meta,omitempty"`
+}
+
+type Name struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+type Email struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+type EnterpriseUser struct {
+	Department string   `json:"department,omitempty"`
+	Manager    *Manager `json:"manager,omitempty"`
+}
+
+type Manager struct {
+	Value       string `json:"value"`
+	Ref         string `json:"$ref,omitempty"`
+	DisplayName string `json:"displayName,omitempty"`
+}
+
+// Group is a department. Its ID is the encoded department name, since
+// departments have no identity of their own.
+type Group struct {
+	Schemas     []string `json:"schemas"`
+	ID          string   `json:"id,omitempty"`
+	DisplayName string   `json:"displayName"`
+	Members     []Member `json:"members,omitempty"`
+	Meta        *Meta    `json:"meta,omitempty"`
+}
+
+type Member struct {
+	Value   string `json:"value"`
+	Ref     string `json:"$ref,omitempty"`
+	Display string `json:"display,omitempty"`
+}
+
+type Meta struct {
+	ResourceType string `json:"resourceType"`
+	Created      string `json:"created,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+	Location     string `json:"location,omitempty"`
+}
+
+type ListResponse[T any] struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int64    `json:"totalResults"`
+	StartIndex   int64    `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    []T      `json:"Resources"`
+}
+
+type PatchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []PatchOperation `json:"Operations"`
+}
+
+type PatchOperation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path,omitempty"`
+	Value any    `json:"value,omitempty"`
+}
+
+type ErrorResponse struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+}
+
+func toUser(e *domainEmployee.Employee, baseURL string) User {
+	active := e.Status == domainEmployee.StatusActive
+	u := User{
+		Schemas:  []string{SchemaUser, SchemaEnterpriseUser},
+		ID:       e.ID,
+		UserName: e.Email,
+		Name: &Name{
+			Formatted:  e.FirstName + " " + e.LastName,
+			GivenName:  e.FirstName,
+			FamilyName: e.LastName,
+		},
+		DisplayName: e.FirstName + " " + e.LastName,
+		Title:       e.Position,
+		Active:      &active,
+		Emails:      []Email{{Value: e.Email, Type: "work", Primary: true}},
+		Enterprise:  &EnterpriseUser{Department: e.Department},
+		Meta: &Meta{
+			ResourceType: "User",
+			Created:      e.CreatedAt.UTC().Format(time.RFC3339),
+			LastModified: e.UpdatedAt.UTC().Format(time.RFC3339),
+			Location:     baseURL + "/Users/" + e.ID,
+		},
+	}
+	if e.ManagerID != "" {
+		u.Enterprise.Manager = &Manager{Value: e.ManagerID, Ref: baseURL + "/Users/" + e.ManagerID}
+	}
+	return u
+}
+
+func groupID(department string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(department))
+}
+
+func departmentFromGroupID(id string) (string, bool) {
+	b, err := base64.RawURLEncoding.DecodeString(id)
+	if err != nil || len(b) == 0 {
+		return "", false
+	}
+	return string(b), true
+}
+
+func toGroup(department string, members []domainEmployee.Employee, withMembers bool, baseURL string) Group {
+	id := groupID(department)
+	g := Group{
+		Schemas:     []string{SchemaGroup},
+		ID:          id,
+		DisplayName: department,
+		Meta:        &Meta{ResourceType: "Group", Location: baseURL + "/Groups/" + id},
+	}
+	if withMembers {
+		g.Members = make([]Member, 0, len(members))
+		for _, m := range members {
+			g.Members = append(g.Members, Member{
+				Value:   m.ID,
+				Ref:     baseURL + "/Users/" + m.ID,
+				Display: m.FirstName + " " + m.LastName,
+			})
+		}
+	}
+	return g
+}
@@ -0,0 +1,139 @@
+package customfield
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Type string
+
+const (
+	TypeString  Type = "string"
+	TypeNumber  Type = "number"
+	TypeBoolean Type = "boolean"
+	TypeDate    Type = "date" // YYYY-MM-DD
+	TypeEnum    Type = "enum" // one of Definition.Enum
+)
+
+var Types = []Type{TypeString, TypeNumber, TypeBoolean, TypeDate, TypeEnum}
+
+// MaxStringLength bounds string values.
+const MaxStringLength = 1000
+
+var keyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,62}$`)
+
+// ValidKey reports whether key can name a custom field. Keys are used as
+// document paths, so they cannot contain dots or dollar signs.
+func ValidKey(key string) bool { return keyPattern.MatchString(key) }
+
+// Definition is an attribute a tenant adds to its employees. Values are
+// stored under Key in the employee's custom fields.
+type Definition struct {
+	ID    string
+	Key   string
+	Label string
+	Type  Type
+	// Required fields must be set on the employees the definition applies to.
+	Required bool
+	Enum     []string // allowed values of an enum field
+	// Pattern is a regular expression a string value must match in full.
+	Pattern string
+	// Department limits the field to one department's employees; empty means
+	// every employee.
+	Department string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// AppliesTo reports whether employees of department can have the field.
+func (d *Definition) AppliesTo(department string) bool {
+	return d.Department == "" || d.Department == department
+}
+
+// Value checks v against the definition and returns it as stored: a
+// string, float64 or bool. Numbers, booleans and dates may also be given
+// in their text form, as in CSV files and query strings. The error message
+// is meant for the caller.
+func (d *Definition) Value(v any) (any, error) {
+	s, isString := v.(string)
+	if isString {
+		s = strings.TrimSpace(s)
+	}
+
+	switch d.Type {
+	case TypeString:
+		if !isString {
+			return nil, fmt.Errorf("%s must be a string", d.Key)
+		}
+		if len([]rune(s)) > MaxStringLength {
+			return nil, fmt.Errorf("%s must be at most %d characters long", d.Key, MaxStringLength)
+		}
+		if d.Pattern != "" {
+			// Checked when the definition was saved.
+			re, err := regexp.Compile(`^(?:` + d.Pattern + `)$`)
+			if err != nil || !re.MatchString(s) {
+				return nil, fmt.Errorf("%s must match %s", d.Key, d.Pattern)
+			}
+		}
+		return s, nil
+	case TypeNumber:
+		var f float64
+		switch n := v.(type) {
+		case float64:
+			f = n
+		case int:
+			f = float64(n)
+		case string:
+			var err error
+			if f, err = strconv.ParseFloat(s, 64); err != nil {
+				f = math.NaN()
+			}
+		default:
+			f = math.NaN()
+		}
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, fmt.Errorf("%s must be a number", d.Key)
+		}
+		return f, nil
+	case TypeBoolean:
+		switch b := v.(type) {
+		case bool:
+			return b, nil
+		case string:
+			if p, err := strconv.ParseBool(s); err == nil {
+				return p, nil
+			}
+		}
+		return nil, fmt.Errorf("%s must be true or false", d.Key)
+	case TypeDate:
+		if isString {
+			if _, err := time.Parse(time.DateOnly, s); err == nil {
+				return s, nil
+			}
+		}
+		return nil, fmt.Errorf("%s must be a date like 2024-01-31", d.Key)
+	case TypeEnum:
+		if isString && slices.Contains(d.Enum, s) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("%s must be one of: %s", d.Key, strings.Join(d.Enum, ", "))
+	}
+	return nil, fmt.Errorf("%s has unknown type %q", d.Key, d.Type)
+}
+
+// Repository only sees the definitions of the tenant in ctx. Keys are
+// unique per tenant.
+type Repository interface {
+	Create(ctx context.Context, d *Definition) error
+	GetByID(ctx context.Context, id string) (*Definition, error)
+	// List returns every definition, ordered by key.
+	List(ctx context.Context) ([]Definition, error)
+	Update(ctx context.Context, d *Definition) error
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,86 @@
+package customfield
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValue(t *testing.T) {
+	badge := &Definition{Key: "badge", Type: TypeString, Pattern: `[A-Z]{2}\d{4}`}
+	tests := []struct {
+		def  *Definition
+		in   any
+		want any
+	}{
+		{&Definition{Key: "nickname", Type: TypeString}, "  Ada ", "Ada"},
+		{badge, "AB1234", "AB1234"},
+		{&Definition{Key: "floor", Type: TypeNumber}, 3.5, 3.5},
+		{&Definition{Key: "floor", Type: TypeNumber}, 3, 3.0},
+		{&Definition{Key: "floor", Type: TypeNumber}, " 12 ", 12.0},
+		{&Definition{Key: "remote", Type: TypeBoolean}, true, true},
+		{&Definition{Key: "remote", Type: TypeBoolean}, "false", false},
+		{&Definition{Key: "badge_expiry", Type: TypeDate}, "2024-02-29", "2024-02-29"},
+		{&Definition{Key: "shirt", Type: TypeEnum, Enum: []string{"S", "M", "L"}}, "M", "M"},
+	}
+	for _, tt := range tests {
+		got, err := tt.def.Value(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("%s(%#v) = %#v, %v, want %#v", tt.def.Key, tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestValueRejects(t *testing.T) {
+	tests := []struct {
+		def  *Definition
+		in   any
+		want string
+	}{
+		{&Definition{Key: "nickname", Type: TypeString}, 3.0, "must be a string"},
+		{&Definition{Key: "nickname", Type: TypeString}, strings.Repeat("a", MaxStringLength+1), "at most"},
+		// The pattern must match the whole value.
+		{&Definition{Key: "badge", Type: TypeString, Pattern: `[A-Z]{2}\d{4}`}, "AB12345", "must match"},
+		{&Definition{Key: "floor", Type: TypeNumber}, "three", "must be a number"},
+		{&Definition{Key: "floor", Type: TypeNumber}, "NaN", "must be a number"},
+		{&Definition{Key: "floor", Type: TypeNumber}, "Inf", "must be a number"},
+		{&Definition{Key: "floor", Type: TypeNumber}, true, "must be a number"},
+		{&Definition{Key: "remote", Type: TypeBoolean}, "maybe", "true or false"},
+		{&Definition{Key: "remote", Type: TypeBoolean}, 1.0, "true or false"},
+		{&Definition{Key: "badge_expiry", Type: TypeDate}, "2023-02-29", "must be a date"},
+		{&Definition{Key: "badge_expiry", Type: TypeDate}, "29/02/2024", "must be a date"},
+		{&Definition{Key: "shirt", Type: TypeEnum, Enum: []string{"S", "M", "L"}}, "m", "one of: S, M, L"},
+		{&Definition{Key: "odd", Type: "money"}, "1", "unknown type"},
+	}
+	for _, tt := range tests {
+		got, err := tt.def.Value(tt.in)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s(%#v) = %#v, %v, want an error containing %q", tt.def.Key, tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestValidKey(t *testing.T) {
+	for key, want := range map[string]bool{
+		"badge":                 true,
+		"badge_2":               true,
+		"Badge":                 false,
+		"2badge":                false,
+		"_badge":                false,
+		"badge.number":          false,
+		"$where":                false,
+		"":                      false,
+		strings.Repeat("a", 63): true,
+		strings.Repeat("a", 64): false,
+	} {
+		if got := ValidKey(key); got != want {
+			t.Errorf("ValidKey(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestAppliesTo(t *testing.T) {
+	everyone, sales := &Definition{}, &Definition{Department: "Sales"}
+	if !everyone.AppliesTo("Sales") || !sales.AppliesTo("Sales") || sales.AppliesTo("Engineering") {
+		t.Error("AppliesTo ignores the department")
+	}
+}
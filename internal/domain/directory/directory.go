@@ -0,0 +1,46 @@
+package directory
+
+import (
+	"context"
+	"strings"
+)
+
+// Entry is a directory object. Attribute names are stored lowercased since
+// LDAP compares them case-insensitively.
+type Entry struct {
+	DN    string
+	Attrs map[string][]string
+}
+
+func NewEntry(dn string) Entry {
+	return Entry{DN: dn, Attrs: map[string][]string{}}
+}
+
+func (e Entry) Add(attr string, values ...string) {
+	key := strings.ToLower(attr)
+	e.Attrs[key] = append(e.Attrs[key], values...)
+}
+
+// Get returns the first value of attr, or "" when it is absent.
+func (e Entry) Get(attr string) string {
+	if v := e.Attrs[strings.ToLower(attr)]; len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// Source reads every entry to synchronize.
+type Source interface {
+	Entries(ctx context.Context) ([]Entry, error)
+}
+
+// NormalizeDN makes DNs comparable: LDAP ignores case and spaces around
+// separators.
+func NormalizeDN(dn string) string {
+	parts := strings.Split(dn, ",")
+	for i, p := range parts {
+		k, v, _ := strings.Cut(p, "=")
+		parts[i] = strings.ToLower(strings.TrimSpace(k)) + "=" + strings.ToLower(strings.TrimSpace(v))
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,38 @@
+package employee
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rohitashk/golang-rest-api/internal/search"
+)
+
+// DuplicateKeys are the keys under which e is compared with other employees
+// when looking for duplicates: its phone number, its date of birth, and its
+// last name with its first initial, either way round. Stores keep them so
+// they can group employees without loading them.
+func DuplicateKeys(e *Employee) []string {
+	keys := make([]string, 0, 4)
+	if e.Phone != "" {
+		keys = append(keys, "phone:"+e.Phone)
+	}
+	if e.DateOfBirth != "" {
+		keys = append(keys, "dob:"+e.DateOfBirth)
+	}
+	first, last := NameKey(e.FirstName), NameKey(e.LastName)
+	if first != "" && last != "" {
+		keys = append(keys, "name:"+initial(first)+" "+last, "name:"+initial(last)+" "+first)
+	}
+	return keys
+}
+
+// NameKey is a name in lowercase without punctuation, so "O'Neil" and
+// "oneil" compare equal.
+func NameKey(name string) string {
+	return strings.Join(search.Words(name), "")
+}
+
+func initial(s string) string {
+	_, n := utf8.DecodeRuneInString(s)
+	return s[:n]
+}
@@ -12,14 +12,58 @@ const (
 )
 
 type Employee struct {
-	ID         string
-	FirstName  string
-	LastName   string
-	Email      string
-	Department string
-	Position   string
-	Salary     float64
-	Status     Status
-	CreatedAt  time.Time
-	UpdatedAt  time.Time
+	ID           string
+	FirstName    string
+	LastName     string
+	Email        string
+	Phone        string // E.164, e.g. +14155550100; empty when unknown
+	DateOfBirth  string // YYYY-MM-DD; empty when unknown
+	Department   string
+	Position     string
+	Salary       float64
+	Status       Status
+	ManagerID    string         // empty when the employee has no manager
+	CustomFields map[string]any // by definition key; see package customfield
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// Department is derived from the employees that reference it; there is no
+// separate department record.
+type Department struct {
+	Name      string
+	Headcount int64 // active employees
+}
+
+// Field names used to select a subset of an employee's attributes.
+const (
+	FieldID          = "id"
+	FieldFirstName   = "first_name"
+	FieldLastName    = "last_name"
+	FieldEmail       = "email"
+	FieldPhone       = "phone"
+	FieldDateOfBirth = "date_of_birth"
+	FieldDepartment  = "department"
+	FieldPosition    = "position"
+	FieldSalary      = "salary"
+	FieldStatus      = "status"
+	FieldManagerID   = "manager_id"
+	FieldCreatedAt   = "created_at"
+	FieldUpdatedAt   = "updated_at"
+
+	FieldCustomFields = "custom_fields"
+)
+
+var Fields = []string{
+	FieldID, FieldFirstName, FieldLastName, FieldEmail, FieldPhone, FieldDateOfBirth, FieldDepartment,
+	FieldPosition, FieldSalary, FieldStatus, FieldManagerID, FieldCreatedAt, FieldUpdatedAt, FieldCustomFields,
+}
+
+func IsField(name string) bool {
+	for _, f := range Fields {
+		if f == name {
+			return true
+		}
+	}
+	return false
 }
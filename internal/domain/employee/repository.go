@@ -1,23 +1,56 @@
 package employee
 
-import "context"
+import (
+	"context"
+	"time"
+
+	"github.com/rohitashk/golang-rest-api/internal/domain"
+)
+
+// ErrModified is returned by Repository.Update when the employee changed
+// after it was read.
+var ErrModified = domain.Conflict("employee was changed by another request; read it again and retry")
 
 type ListFilter struct {
+	IDs        []string
 	Department *string
 	Status     *Status
-	Query      *string // search in name/email
+	ManagerID  *string
+	// ManagerIDs and Departments match employees with any of the given
+	// managers or departments.
+	ManagerIDs  []string
+	Departments []string
+	Email       *string // exact, case-insensitive
+	Query       *string // search in name/email
+	// CustomFields matches employees whose custom fields have exactly these
+	// values.
+	CustomFields map[string]any
 }
 
 type ListPage struct {
-	Limit  int64
+	Limit  int64 // zero lists all
 	Offset int64
 }
 
+// Repository only reads and writes the employees of the tenant in ctx, and
+// fails without one. Methods taking fields only load those attributes (plus
+// the ID); no fields means the whole employee.
 type Repository interface {
 	Create(ctx context.Context, e *Employee) error
-	GetByID(ctx context.Context, id string) (*Employee, error)
+	GetByID(ctx context.Context, id string, fields ...string) (*Employee, error)
 	GetByEmail(ctx context.Context, email string) (*Employee, error)
-	List(ctx context.Context, filter ListFilter, page ListPage) ([]Employee, int64, error)
-	Update(ctx context.Context, e *Employee) error
+	List(ctx context.Context, filter ListFilter, page ListPage, fields ...string) ([]Employee, int64, error)
+	// Headcount returns active employees per department, for all departments
+	// when none are given.
+	Headcount(ctx context.Context, departments ...string) (map[string]int64, error)
+	// Update replaces e only if it was last updated at prevUpdatedAt, the
+	// UpdatedAt it was read with, and fails with a conflict otherwise. A zero
+	// prevUpdatedAt replaces it whatever its state.
+	Update(ctx context.Context, e *Employee, prevUpdatedAt time.Time) error
 	Delete(ctx context.Context, id string) error
+	// DuplicateBlocks groups the IDs of employees sharing a DuplicateKeys
+	// key, one block per key shared by two to maxBlock employees.
+	DuplicateBlocks(ctx context.Context, maxBlock int) ([][]string, error)
+	// RemoveCustomField clears the custom field key on every employee.
+	RemoveCustomField(ctx context.Context, key string) error
 }
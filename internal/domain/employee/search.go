@@ -0,0 +1,21 @@
+package employee
+
+import "context"
+
+// SearchQuery asks for employees matching every term. Terms are lowercase
+// words, as search.Words splits them.
+type SearchQuery struct {
+	Terms      []string
+	Prefix     bool // the last term also matches the start of longer words
+	Fuzzy      bool // terms also match words with a few typos
+	Department *string
+	Status     *Status
+	Limit      int64 // most candidates to return
+}
+
+// Searcher finds the employees of the tenant in ctx that may match a query,
+// best first. It may return loose matches; the caller ranks the candidates
+// with search.Rank, which drops them.
+type Searcher interface {
+	Search(ctx context.Context, q SearchQuery) ([]Employee, error)
+}
@@ -5,20 +5,31 @@ import "fmt"
 type ErrorKind string
 
 const (
-	ErrKindNotFound     ErrorKind = "not_found"
-	ErrKindConflict     ErrorKind = "conflict"
-	ErrKindValidation   ErrorKind = "validation"
-	ErrKindUnauthorized ErrorKind = "unauthorized"
-	ErrKindForbidden    ErrorKind = "forbidden"
-	ErrKindInternal     ErrorKind = "internal"
+	ErrKindNotFound      ErrorKind = "not_found"
+	ErrKindConflict      ErrorKind = "conflict"
+	ErrKindValidation    ErrorKind = "validation"
+	ErrKindUnprocessable ErrorKind = "unprocessable"
+	ErrKindUnauthorized  ErrorKind = "unauthorized"
+	ErrKindForbidden     ErrorKind = "forbidden"
+	ErrKindRateLimited   ErrorKind = "rate_limited"
+	ErrKindInternal      ErrorKind = "internal"
 )
 
 type Error struct {
 	Kind    ErrorKind
 	Message string
+	Fields  []FieldError
 	Cause   error
 }
 
+// FieldError describes why a single input field was rejected. Field uses the
+// name the client sent (e.g. "first_name"), Rule the failed constraint.
+type FieldError struct {
+	Field   string
+	Rule    string
+	Message string
+}
+
 func (e Error) Error() string {
 	if e.Cause != nil {
 		return fmt.Sprintf("%s: %s: %v", e.Kind, e.Message, e.Cause)
@@ -28,9 +39,31 @@ func (e Error) Error() string {
 
 func (e Error) Unwrap() error { return e.Cause }
 
+// Is matches an Error of the same kind and message, so errors declared as
+// variables, like employee.ErrModified, can be found with errors.Is.
+func (e Error) Is(target error) bool {
+	t, ok := target.(Error)
+	return ok && t.Kind == e.Kind && t.Message == e.Message
+}
+
 func NotFound(msg string) error   { return Error{Kind: ErrKindNotFound, Message: msg} }
 func Conflict(msg string) error   { return Error{Kind: ErrKindConflict, Message: msg} }
 func Validation(msg string) error { return Error{Kind: ErrKindValidation, Message: msg} }
+func InvalidFields(msg string, fields []FieldError) error {
+	return Error{Kind: ErrKindValidation, Message: msg, Fields: fields}
+}
+func Unprocessable(msg string) error {
+	return Error{Kind: ErrKindUnprocessable, Message: msg}
+}
+func Unauthorized(msg string) error {
+	return Error{Kind: ErrKindUnauthorized, Message: msg}
+}
+func Forbidden(msg string) error {
+	return Error{Kind: ErrKindForbidden, Message: msg}
+}
+func RateLimited(msg string) error {
+	return Error{Kind: ErrKindRateLimited, Message: msg}
+}
 func Internal(msg string, cause error) error {
 	return Error{Kind: ErrKindInternal, Message: msg, Cause: cause}
 }
@@ -0,0 +1,119 @@
+package event
+
+import (
+	"reflect"
+	"sort"
+	"time"
+
+	domainEmployee "github.com/rohitashk/golang-rest-api/internal/domain/employee"
+)
+
+type EmployeeSnapshot struct {
+	ID           string         `json:"id"`
+	FirstName    string         `json:"first_name"`
+	LastName     string         `json:"last_name"`
+	Email        string         `json:"email"`
+	Phone        string         `json:"phone,omitempty"`
+	DateOfBirth  string         `json:"date_of_birth,omitempty"`
+	Department   string         `json:"department"`
+	Position     string         `json:"position"`
+	Salary       float64        `json:"salary"`
+	Status       string         `json:"status"`
+	ManagerID    string         `json:"manager_id,omitempty"`
+	CustomFields map[string]any `json:"custom_fields,omitempty"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+}
+
+func Snapshot(e *domainEmployee.Employee) EmployeeSnapshot {
+	return EmployeeSnapshot{
+		ID:           e.ID,
+		FirstName:    e.FirstName,
+		LastName:     e.LastName,
+		Email:        e.Email,
+		Phone:        e.Phone,
+		DateOfBirth:  e.DateOfBirth,
+		Department:   e.Department,
+		Position:     e.Position,
+		Salary:       e.Salary,
+		Status:       string(e.Status),
+		ManagerID:    e.ManagerID,
+		CustomFields: e.CustomFields,
+		CreatedAt:    e.CreatedAt,
+		UpdatedAt:    e.UpdatedAt,
+	}
+}
+
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   any    `json:"old"`
+	New   any    `json:"new"`
+}
+
+type EmployeeCreated struct {
+	Employee EmployeeSnapshot `json:"employee"`
+}
+
+type EmployeeUpdated struct {
+	Employee EmployeeSnapshot `json:"employee"`
+	Changes  []FieldChange    `json:"changes"`
+}
+
+type EmployeeDeleted struct {
+	Employee   EmployeeSnapshot `json:"employee"`
+	MergedInto string           `json:"merged_into,omitempty"` // the employee that replaced it
+}
+
+// EmployeeMerged is recorded on the surviving employee when a duplicate is
+// merged into it; the duplicate's own history ends with its deletion.
+type EmployeeMerged struct {
+	Employee  EmployeeSnapshot `json:"employee"`
+	Duplicate EmployeeSnapshot `json:"duplicate"`
+	Changes   []FieldChange    `json:"changes"`
+}
+
+func (EmployeeCreated) EventType() Type       { return TypeEmployeeCreated }
+func (e EmployeeCreated) AggregateID() string { return e.Employee.ID }
+func (EmployeeUpdated) EventType() Type       { return TypeEmployeeUpdated }
+func (e EmployeeUpdated) AggregateID() string { return e.Employee.ID }
+func (EmployeeDeleted) EventType() Type       { return TypeEmployeeDeleted }
+func (e EmployeeDeleted) AggregateID() string { return e.Employee.ID }
+func (EmployeeMerged) EventType() Type        { return TypeEmployeeMerged }
+func (e EmployeeMerged) AggregateID() string  { return e.Employee.ID }
+
+// Diff lists the attributes that differ between two versions of an employee.
+// Custom fields are compared one by one, as custom_fields.<key>; a field
+// that is not set is nil.
+func Diff(before, after *domainEmployee.Employee) []FieldChange {
+	var out []FieldChange
+	add := func(field string, old, new any) {
+		if !reflect.DeepEqual(old, new) {
+			out = append(out, FieldChange{Field: field, Old: old, New: new})
+		}
+	}
+	add(domainEmployee.FieldFirstName, before.FirstName, after.FirstName)
+	add(domainEmployee.FieldLastName, before.LastName, after.LastName)
+	add(domainEmployee.FieldEmail, before.Email, after.Email)
+	add(domainEmployee.FieldPhone, before.Phone, after.Phone)
+	add(domainEmployee.FieldDateOfBirth, before.DateOfBirth, after.DateOfBirth)
+	add(domainEmployee.FieldDepartment, before.Department, after.Department)
+	add(domainEmployee.FieldPosition, before.Position, after.Position)
+	add(domainEmployee.FieldSalary, before.Salary, after.Salary)
+	add(domainEmployee.FieldStatus, string(before.Status), string(after.Status))
+	add(domainEmployee.FieldManagerID, before.ManagerID, after.ManagerID)
+
+	keys := make([]string, 0, len(before.CustomFields)+len(after.CustomFields))
+	for k := range before.CustomFields {
+		keys = append(keys, k)
+	}
+	for k := range after.CustomFields {
+		if _, ok := before.CustomFields[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		add(domainEmployee.FieldCustomFields+"."+k, before.CustomFields[k], after.CustomFields[k])
+	}
+	return out
+}
@@ -0,0 +1,120 @@
+package event
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+type Type string
+
+const (
+	TypeEmployeeCreated Type = "employee.created"
+	TypeEmployeeUpdated Type = "employee.updated"
+	TypeEmployeeDeleted Type = "employee.deleted"
+	TypeEmployeeMerged  Type = "employee.merged"
+)
+
+// Event is a domain event. Implementations are JSON-encoded into the
+// envelope payload, so their json tags are part of the public contract.
+type Event interface {
+	EventType() Type
+	AggregateID() string
+}
+
+// Envelope is an event as stored in the outbox and handed to publishers.
+type Envelope struct {
+	ID          string
+	Tenant      string // whose records the event describes
+	Type        Type
+	AggregateID string
+	OccurredAt  time.Time
+	Payload     []byte // JSON
+	Attempts    int
+}
+
+// Outbox stores events written in the same transaction as the change they
+// describe until a relay has published them.
+type Outbox interface {
+	Append(ctx context.Context, events ...Envelope) error
+	// Claim leases up to limit unpublished events, oldest first. A claimed
+	// event is not handed out again until the lease expires.
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]Envelope, error)
+	MarkPublished(ctx context.Context, id string) error
+	// MarkFailed records the error and makes the event claimable again at
+	// retryAt.
+	MarkFailed(ctx context.Context, id string, reason string, retryAt time.Time) error
+}
+
+// Body is the JSON form of an event shared by every outgoing channel.
+func (e Envelope) Body() ([]byte, error) {
+	return json.Marshal(struct {
+		ID         string          `json:"id"`
+		Type       Type            `json:"type"`
+		OccurredAt time.Time       `json:"occurred_at"`
+		Data       json.RawMessage `json:"data"`
+	}{e.ID, e.Type, e.OccurredAt, e.Payload})
+}
+
+type Publisher interface {
+	Publish(ctx context.Context, e Envelope) error
+}
+
+type PublisherFunc func(ctx context.Context, e Envelope) error
+
+func (f PublisherFunc) Publish(ctx context.Context, e Envelope) error { return f(ctx, e) }
+
+// Publishers fans an event out to every publisher. When one fails the event
+// is retried for all of them, so publishers must tolerate duplicates.
+type Publishers []Publisher
+
+func (ps Publishers) Publish(ctx context.Context, e Envelope) error {
+	for _, p := range ps {
+		if err := p.Publish(ctx, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewEnvelope encodes ev of tenantID for the outbox under a fresh random ID.
+func NewEnvelope(tenantID string, ev Event, at time.Time) (Envelope, error) {
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return Envelope{}, err
+	}
+
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return Envelope{}, err
+	}
+
+	return Envelope{
+		ID:          hex.EncodeToString(id[:]),
+		Tenant:      tenantID,
+		Type:        ev.EventType(),
+		AggregateID: ev.AggregateID(),
+		OccurredAt:  at,
+		Payload:     payload,
+	}, nil
+}
+
+// ErrCursorExpired is returned by a Feed that can no longer resume from the
+// given cursor.
+var ErrCursorExpired = errors.New("event cursor expired")
+
+// Notification is an event read from a Feed. Cursor is opaque and resumes
+// the feed right after this event.
+type Notification struct {
+	Cursor string
+	Event  Envelope
+}
+
+// Feed streams events as they are recorded. The channel is closed when ctx
+// ends or the feed fails; subscribers reconnect with the last cursor seen.
+type Feed interface {
+	Subscribe(ctx context.Context, cursor string) (<-chan Notification, error)
+}
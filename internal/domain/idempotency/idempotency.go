@@ -0,0 +1,25 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+)
+
+type Record struct {
+	Key         string
+	Fingerprint string // hash of method, path and body of the original request
+	Completed   bool
+	StatusCode  int
+	ContentType string
+	Body        []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
+
+type Store interface {
+	// Reserve claims rec.Key for a new request. When the key is already taken
+	// the stored record is returned and nothing is written.
+	Reserve(ctx context.Context, rec *Record) (*Record, error)
+	Complete(ctx context.Context, rec *Record) error
+	Release(ctx context.Context, key string) error
+}
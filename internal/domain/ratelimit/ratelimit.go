@@ -0,0 +1,139 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit is a token bucket: it holds up to Burst tokens and refills at Rate
+// tokens per second. Each request takes one. The zero Limit means no limit.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+func (l Limit) Unlimited() bool { return l.Rate <= 0 || l.Burst <= 0 }
+
+// ParseLimit reads "COUNT/UNIT[:BURST]", where UNIT is s, m or h, e.g.
+// "600/m" or "10/s:50". The burst defaults to COUNT. "0" or "off" means no
+// limit.
+func ParseLimit(s string) (Limit, error) {
+	s = strings.TrimSpace(s)
+	if s == "0" || s == "off" {
+		return Limit{}, nil
+	}
+
+	spec, burstStr, hasBurst := strings.Cut(s, ":")
+	countStr, unit, ok := strings.Cut(spec, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("rate limit %q: want COUNT/UNIT[:BURST]", s)
+	}
+	count, err := strconv.Atoi(countStr)
+	if err != nil || count <= 0 {
+		return Limit{}, fmt.Errorf("rate limit %q: count must be a positive integer", s)
+	}
+
+	var per time.Duration
+	switch unit {
+	case "s":
+		per = time.Second
+	case "m":
+		per = time.Minute
+	case "h":
+		per = time.Hour
+	default:
+		return Limit{}, fmt.Errorf("rate limit %q: unit must be s, m or h", s)
+	}
+
+	l := Limit{Rate: float64(count) / per.Seconds(), Burst: count}
+	if hasBurst {
+		if l.Burst, err = strconv.Atoi(burstStr); err != nil || l.Burst <= 0 {
+			return Limit{}, fmt.Errorf("rate limit %q: burst must be a positive integer", s)
+		}
+	}
+	return l, nil
+}
+
+func (l Limit) String() string {
+	if l.Unlimited() {
+		return "off"
+	}
+	return strconv.FormatFloat(l.Rate, 'f', -1, 64) + "/s:" + strconv.Itoa(l.Burst)
+}
+
+// Policy picks the limit of a route: its own entry in Routes, keyed by
+// "METHOD /route/template", or Default.
+type Policy struct {
+	Default Limit
+	Routes  map[string]Limit
+}
+
+// For returns the limit of route and the scope its requests are counted in,
+// "*" for routes sharing Default.
+func (p Policy) For(route string) (Limit, string) {
+	if l, ok := p.Routes[route]; ok {
+		return l, route
+	}
+	return p.Default, "*"
+}
+
+// Bucket is the state a Store keeps per key.
+type Bucket struct {
+	Tokens  float64
+	Updated time.Time
+}
+
+type Decision struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// RetryAfter is how long until a request would be allowed; zero when
+	// this one was.
+	RetryAfter time.Duration
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
+}
+
+// Take refills b up to now and takes a token if one is available. Stores
+// call it while holding b, so every store applies the same algorithm. A zero
+// Bucket is a full one.
+func (l Limit) Take(b Bucket, now time.Time) (Bucket, Decision) {
+	if b.Updated.IsZero() {
+		b = Bucket{Tokens: float64(l.Burst), Updated: now}
+	}
+	if elapsed := now.Sub(b.Updated).Seconds(); elapsed > 0 {
+		b.Tokens = math.Min(float64(l.Burst), b.Tokens+elapsed*l.Rate)
+		b.Updated = now
+	}
+
+	d := Decision{Limit: l.Burst}
+	if b.Tokens >= 1 {
+		b.Tokens--
+		d.Allowed = true
+	} else {
+		d.RetryAfter = l.duration(1 - b.Tokens)
+	}
+	d.Remaining = int(b.Tokens)
+	d.Reset = l.duration(float64(l.Burst) - b.Tokens)
+	return b, d
+}
+
+// Full reports whether b has refilled completely by now, so a store can
+// forget it.
+func (l Limit) Full(b Bucket, now time.Time) bool {
+	return b.Tokens+now.Sub(b.Updated).Seconds()*l.Rate >= float64(l.Burst)
+}
+
+func (l Limit) duration(tokens float64) time.Duration {
+	return time.Duration(tokens / l.Rate * float64(time.Second))
+}
+
+// Store holds buckets. An in-process store limits each instance separately;
+// a shared one (e.g. Redis) limits clients across all of them.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Decision, error)
+}
@@ -0,0 +1,45 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"regexp"
+
+	"github.com/rohitashk/golang-rest-api/internal/domain"
+)
+
+// Default is the tenant of records written before tenancy existed, and of
+// requests that name none unless configured otherwise.
+const Default = "default"
+
+var idPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+
+// Valid reports whether id can name a tenant: lowercase letters, digits,
+// "-" and "_", at most 63 characters.
+func Valid(id string) bool { return idPattern.MatchString(id) }
+
+type contextKey struct{}
+
+var errMissing = errors.New("tenant.WithID was not called")
+
+// WithID returns ctx acting for tenant id. Every stored record belongs to
+// one tenant, and repositories only touch those of the tenant in their ctx.
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the tenant ctx acts for, if any.
+func FromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(contextKey{}).(string)
+	return id, ok && id != ""
+}
+
+// Require returns the tenant ctx acts for. A context without one is a wiring
+// bug, so it fails closed instead of reaching every tenant's records.
+func Require(ctx context.Context) (string, error) {
+	id, ok := FromContext(ctx)
+	if !ok {
+		return "", domain.Internal("no tenant in context", errMissing)
+	}
+	return id, nil
+}
@@ -0,0 +1,9 @@
+package domain
+
+import "context"
+
+// Transactor runs fn atomically. Repositories must use the ctx passed to fn
+// for their writes to take part in the transaction.
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -0,0 +1,92 @@
+package webhook
+
+import (
+	"context"
+	"time"
+)
+
+// Subscription receives a signed POST for every event of its tenant whose
+// type it lists, or for every event when EventTypes is empty.
+type Subscription struct {
+	ID          string
+	URL         string
+	Secret      string
+	EventTypes  []string
+	Description string
+	Active      bool
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (s *Subscription) Wants(eventType string) bool {
+	if !s.Active {
+		return false
+	}
+	if len(s.EventTypes) == 0 {
+		return true
+	}
+	for _, t := range s.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	DeliveryFailed    DeliveryStatus = "failed" // gave up after the last retry
+)
+
+// Delivery is one event sent to one subscription, with every attempt made.
+type Delivery struct {
+	ID             string
+	Tenant         string
+	SubscriptionID string
+	EventID        string
+	EventType      string
+	Payload        []byte // request body
+	Status         DeliveryStatus
+	Tries          int       // attempts since the delivery was last queued
+	Attempts       []Attempt // most recent last, capped
+	NextAttemptAt  time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+type Attempt struct {
+	At         time.Time
+	StatusCode int // 0 when no response was received
+	Error      string
+	Duration   time.Duration
+}
+
+// SubscriptionRepository only sees the subscriptions of the tenant in ctx.
+type SubscriptionRepository interface {
+	Create(ctx context.Context, s *Subscription) error
+	GetByID(ctx context.Context, id string) (*Subscription, error)
+	List(ctx context.Context) ([]Subscription, error)
+	Update(ctx context.Context, s *Subscription) error
+	Delete(ctx context.Context, id string) error
+}
+
+type DeliveryPage struct {
+	Limit  int64
+	Offset int64
+}
+
+type DeliveryRepository interface {
+	// Create ignores a delivery whose subscription already has one for the
+	// same event, so republishing an event does not notify twice.
+	Create(ctx context.Context, d *Delivery) error
+	GetByID(ctx context.Context, id string) (*Delivery, error)
+	ListBySubscription(ctx context.Context, subscriptionID string, page DeliveryPage) ([]Delivery, int64, error)
+	// ClaimDue leases up to limit pending deliveries whose next attempt is
+	// due by pushing NextAttemptAt lease into the future.
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]Delivery, error)
+	Update(ctx context.Context, d *Delivery) error
+	DeleteBySubscription(ctx context.Context, subscriptionID string) error
+}
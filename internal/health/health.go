@@ -0,0 +1,115 @@
+// Package health tracks whether the service can take traffic. Adapters
+// register a Checker for each dependency they need.
+package health
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+type CheckerFunc func(ctx context.Context) error
+
+func (f CheckerFunc) Check(ctx context.Context) error { return f(ctx) }
+
+type Status string
+
+const (
+	StatusReady        Status = "ready"
+	StatusNotReady     Status = "not_ready"
+	StatusShuttingDown Status = "shutting_down"
+
+	StatusUp   Status = "up"
+	StatusDown Status = "down"
+)
+
+type Result struct {
+	Name    string
+	Status  Status
+	Latency time.Duration
+	Err     error
+}
+
+type Report struct {
+	Status Status
+	Checks []Result // sorted by name
+}
+
+func (r Report) Ready() bool { return r.Status == StatusReady }
+
+// Registry runs the registered checks concurrently, each bounded by the
+// registry's timeout.
+type Registry struct {
+	timeout time.Duration
+
+	mu       sync.RWMutex
+	checkers map[string]Checker
+
+	shuttingDown atomic.Bool
+}
+
+func NewRegistry(timeout time.Duration) *Registry {
+	return &Registry{timeout: timeout, checkers: map[string]Checker{}}
+}
+
+// Register adds a check under name, replacing any previous one.
+func (r *Registry) Register(name string, c Checker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checkers[name] = c
+}
+
+// ShutDown makes the service report not ready for the rest of its life,
+// so load balancers stop routing to it while in-flight requests drain.
+func (r *Registry) ShutDown() {
+	r.shuttingDown.Store(true)
+}
+
+func (r *Registry) Check(ctx context.Context) Report {
+	if r.shuttingDown.Load() {
+		return Report{Status: StatusShuttingDown}
+	}
+
+	r.mu.RLock()
+	results := make([]Result, 0, len(r.checkers))
+	checkers := make([]Checker, 0, len(r.checkers))
+	for name, c := range r.checkers {
+		results = append(results, Result{Name: name})
+		checkers = append(checkers, c)
+	}
+	r.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for i := range checkers {
+		wg.Add(1)
+		go func(res *Result, c Checker) {
+			defer wg.Done()
+			cctx, cancel := context.WithTimeout(ctx, r.timeout)
+			defer cancel()
+
+			start := time.Now()
+			res.Err = c.Check(cctx)
+			res.Latency = time.Since(start)
+			res.Status = StatusUp
+			if res.Err != nil {
+				res.Status = StatusDown
+			}
+		}(&results[i], checkers[i])
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+	report := Report{Status: StatusReady, Checks: results}
+	for _, res := range results {
+		if res.Status == StatusDown {
+			report.Status = StatusNotReady
+		}
+	}
+	return report
+}
@@ -0,0 +1,85 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func up(context.Context) error { return nil }
+
+func TestCheckAggregates(t *testing.T) {
+	r := NewRegistry(time.Second)
+	if rep := r.Check(context.Background()); !rep.Ready() || len(rep.Checks) != 0 {
+		t.Errorf("no checks: %+v, want ready", rep)
+	}
+
+	r.Register("mongodb", CheckerFunc(up))
+	r.Register("cache", CheckerFunc(up))
+	rep := r.Check(context.Background())
+	if !rep.Ready() || len(rep.Checks) != 2 || rep.Checks[0].Name != "cache" || rep.Checks[1].Name != "mongodb" {
+		t.Fatalf("report = %+v, want both up, sorted by name", rep)
+	}
+	for _, res := range rep.Checks {
+		if res.Status != StatusUp || res.Err != nil {
+			t.Errorf("%s = %+v", res.Name, res)
+		}
+	}
+
+	// One failing check makes the service not ready; the others still report.
+	down := errors.New("connection refused")
+	r.Register("cache", CheckerFunc(func(context.Context) error { return down }))
+	rep = r.Check(context.Background())
+	if rep.Ready() || rep.Status != StatusNotReady {
+		t.Errorf("status = %s, want not ready", rep.Status)
+	}
+	if res := rep.Checks[0]; res.Status != StatusDown || !errors.Is(res.Err, down) {
+		t.Errorf("cache = %+v", res)
+	}
+	if res := rep.Checks[1]; res.Status != StatusUp {
+		t.Errorf("mongodb = %+v", res)
+	}
+}
+
+func TestCheckTimeout(t *testing.T) {
+	r := NewRegistry(20 * time.Millisecond)
+	r.Register("slow", CheckerFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}))
+	r.Register("fast", CheckerFunc(up))
+
+	start := time.Now()
+	rep := r.Check(context.Background())
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Check took %v, want it bounded by the timeout", elapsed)
+	}
+	if rep.Ready() {
+		t.Error("ready with a timed-out check")
+	}
+	if res := rep.Checks[1]; res.Name != "slow" || res.Status != StatusDown || !errors.Is(res.Err, context.DeadlineExceeded) {
+		t.Errorf("slow = %+v", res)
+	}
+	if res := rep.Checks[0]; res.Status != StatusUp {
+		t.Errorf("fast = %+v, want it unaffected", res)
+	}
+}
+
+func TestShutDown(t *testing.T) {
+	r := NewRegistry(time.Second)
+	called := false
+	r.Register("mongodb", CheckerFunc(func(context.Context) error {
+		called = true
+		return nil
+	}))
+
+	r.ShutDown()
+	rep := r.Check(context.Background())
+	if rep.Ready() || rep.Status != StatusShuttingDown {
+		t.Errorf("status = %s, want shutting down", rep.Status)
+	}
+	if called {
+		t.Error("checks run while shutting down")
+	}
+}
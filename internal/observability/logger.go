@@ -1,20 +1,44 @@
 package observability
 
 import (
+	"context"
 	"log/slog"
 	"os"
-)
 
-func NewLogger(appEnv string) *slog.Logger {
-	level := slog.LevelInfo
-	if appEnv == "local" || appEnv == "dev" {
-		level = slog.LevelDebug
-	}
+	"go.opentelemetry.io/otel/trace"
+)
 
+// NewLogger logs JSON at level, which may be a *slog.LevelVar to change it
+// while running.
+func NewLogger(appEnv string, level slog.Leveler) *slog.Logger {
 	handler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
 		Level:     level,
 		AddSource: appEnv == "local",
 	})
 
-	return slog.New(handler)
+	return slog.New(traceHandler{handler})
+}
+
+// traceHandler adds the trace and span IDs of the record's context, so the
+// *Context logging methods tie log lines to traces.
+type traceHandler struct {
+	slog.Handler
+}
+
+func (h traceHandler) Handle(ctx context.Context, r slog.Record) error {
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(
+			slog.String("trace_id", sc.TraceID().String()),
+			slog.String("span_id", sc.SpanID().String()),
+		)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h traceHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return traceHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h traceHandler) WithGroup(name string) slog.Handler {
+	return traceHandler{h.Handler.WithGroup(name)}
 }
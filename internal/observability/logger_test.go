@@ -0,0 +1,49 @@
+package observability
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestLoggerAddsTraceContext(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(traceHandler{slog.NewJSONHandler(&buf, nil)})
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID, SpanID: spanID, TraceFlags: trace.FlagsSampled,
+	}))
+
+	record := func(log func()) map[string]any {
+		t.Helper()
+		buf.Reset()
+		log()
+		var out map[string]any
+		if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+			t.Fatalf("%v: %s", err, buf.String())
+		}
+		return out
+	}
+
+	got := record(func() { logger.InfoContext(ctx, "served") })
+	if got["trace_id"] != traceID.String() || got["span_id"] != spanID.String() {
+		t.Errorf("record = %v, want the trace and span IDs", got)
+	}
+
+	// Derived loggers keep adding them, next to their own attributes.
+	got = record(func() { logger.With("tenant", "acme").InfoContext(ctx, "served") })
+	if got["tenant"] != "acme" || got["trace_id"] != traceID.String() {
+		t.Errorf("record = %v", got)
+	}
+
+	got = record(func() { logger.Info("started") })
+	if _, ok := got["trace_id"]; ok {
+		t.Errorf("record without a span = %v", got)
+	}
+}
@@ -0,0 +1,121 @@
+package observability
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/rohitashk/golang-rest-api/internal/domain"
+)
+
+const namespace = "ems"
+
+// Metrics holds the application's Prometheus collectors. Each instance has
+// its own registry, so several can coexist in one process.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+	dbDuration   *prometheus.HistogramVec
+	dbErrors     *prometheus.CounterVec
+}
+
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: "http", Name: "requests_total",
+			Help: "HTTP requests by route template, method and status code.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Subsystem: "http", Name: "request_duration_seconds",
+			Help:    "HTTP request latency by route template, method and status code.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		dbDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Subsystem: "db", Name: "operation_duration_seconds",
+			Help:    "Repository operation latency.",
+			Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"repository", "method"}),
+		dbErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: "db", Name: "operation_errors_total",
+			Help: "Repository operations that failed; not-found results and rejected input are not errors.",
+		}, []string{"repository", "method"}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests, m.httpDuration, m.dbDuration, m.dbErrors,
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// ObserveHTTP records a finished request. route is the template the request
+// matched, e.g. /v1/employees/:id, which keeps the label set bounded.
+func (m *Metrics) ObserveHTTP(method, route, status string, d time.Duration) {
+	m.httpRequests.WithLabelValues(method, route, status).Inc()
+	m.httpDuration.WithLabelValues(method, route, status).Observe(d.Seconds())
+}
+
+// ObserveDB records a repository call. Only internal errors count as
+// failures; domain errors such as a conflict are the database working as
+// intended.
+func (m *Metrics) ObserveDB(repository, method string, d time.Duration, err error) {
+	m.dbDuration.WithLabelValues(repository, method).Observe(d.Seconds())
+	if err == nil {
+		return
+	}
+	var derr domain.Error
+	if errors.As(err, &derr) && derr.Kind != domain.ErrKindInternal {
+		return
+	}
+	m.dbErrors.WithLabelValues(repository, method).Inc()
+}
+
+// RegisterHeadcount exports active employees per tenant and department,
+// queried on each scrape.
+func (m *Metrics) RegisterHeadcount(headcount func(ctx context.Context) (map[string]map[string]int64, error), timeout time.Duration) {
+	m.registry.MustRegister(&headcountCollector{headcount: headcount, timeout: timeout})
+}
+
+var headcountDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "employees", "active"),
+	"Active employees by tenant and department.",
+	[]string{"tenant", "department"}, nil,
+)
+
+type headcountCollector struct {
+	headcount func(ctx context.Context) (map[string]map[string]int64, error)
+	timeout   time.Duration
+}
+
+func (c *headcountCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- headcountDesc
+}
+
+func (c *headcountCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	counts, err := c.headcount(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(headcountDesc, err)
+		return
+	}
+	for tenantID, depts := range counts {
+		for dept, n := range depts {
+			ch <- prometheus.MustNewConstMetric(headcountDesc, prometheus.GaugeValue, float64(n), tenantID, dept)
+		}
+	}
+}
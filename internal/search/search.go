@@ -0,0 +1,319 @@
+// Package search is the text matching shared by the employee search
+// backends: how text splits into words, when a query term matches a word,
+// and how a matching employee is scored and highlighted. Backends only find
+// candidates; Rank decides what matches, so every backend answers alike.
+// Similarity compares names for the duplicate finder.
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Words returns the distinct lowercase words of texts, in the order they
+// first appear. A word is a run of letters and digits, so an email address
+// is several words.
+func Words(texts ...string) []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, text := range texts {
+		for _, sp := range spans(text) {
+			w := strings.ToLower(text[sp[0]:sp[1]])
+			if !seen[w] {
+				seen[w] = true
+				out = append(out, w)
+			}
+		}
+	}
+	return out
+}
+
+// spans are the byte offsets of the words in text.
+func spans(text string) [][2]int {
+	var out [][2]int
+	start := -1
+	for i, r := range text {
+		word := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case word && start < 0:
+			start = i
+		case !word && start >= 0:
+			out = append(out, [2]int{start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		out = append(out, [2]int{start, len(text)})
+	}
+	return out
+}
+
+// MaxEdits is how many typos a fuzzy term may have. Short terms get none,
+// as a single edit would match most short words.
+func MaxEdits(term string) int {
+	switch n := utf8.RuneCountInString(term); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// gramPad marks the ends of a word in its grams. It is not a letter or
+// digit, so it never occurs within a word.
+const gramPad = "_"
+
+// Grams are the trigrams of word with two pads at either end, so "ann" has
+// "__a", "_an", "ann", "nn_" and "n__". A word within MaxEdits typos of a
+// term shares a gram with it wherever the typos are, even in the first
+// letter, as terms long enough to allow typos have too many grams for them
+// all to change. Backends look fuzzy candidates up by them.
+func Grams(word string) []string {
+	r := []rune(gramPad + gramPad + word + gramPad + gramPad)
+	out := make([]string, 0, len(r)-2)
+	seen := map[string]bool{}
+	for i := 0; i+3 <= len(r); i++ {
+		g := string(r[i : i+3])
+		if !seen[g] {
+			seen[g] = true
+			out = append(out, g)
+		}
+	}
+	return out
+}
+
+// Distance is the number of insertions, deletions, substitutions and
+// transpositions of adjacent letters that turn a into b. It stops counting
+// past limit and returns limit+1.
+func Distance(a, b string, limit int) int {
+	ra, rb := []rune(a), []rune(b)
+	if d := len(ra) - len(rb); d > limit || -d > limit {
+		return limit + 1
+	}
+
+	// Three rows of the edit matrix: two back, the previous and the current.
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		best := cur[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+			best = min(best, cur[j])
+		}
+		if best > limit {
+			return limit + 1
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return min(prev[len(rb)], limit+1)
+}
+
+// Similarity is the Jaro-Winkler similarity of a and b, from 0 for nothing
+// in common to 1 for equal strings. Unlike Distance it favours strings that
+// share a start, which suits names, and is comparable across lengths.
+func Similarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 || len(rb) == 0 {
+		return 0
+	}
+
+	// Letters match when equal and no further apart than half the longer
+	// string.
+	window := max(0, max(len(ra), len(rb))/2-1)
+	matchedA := make([]bool, len(ra))
+	matchedB := make([]bool, len(rb))
+	matches := 0
+	for i, r := range ra {
+		for j := max(0, i-window); j < min(len(rb), i+window+1); j++ {
+			if !matchedB[j] && rb[j] == r {
+				matchedA[i], matchedB[j] = true, true
+				matches++
+				break
+			}
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	// Transpositions are matched letters that come in a different order.
+	transposed, j := 0, 0
+	for i, r := range ra {
+		if !matchedA[i] {
+			continue
+		}
+		for !matchedB[j] {
+			j++
+		}
+		if rb[j] != r {
+			transposed++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(ra)) + m/float64(len(rb)) + (m-float64(transposed/2))/m) / 3
+
+	prefix := 0
+	for prefix < min(4, len(ra), len(rb)) && ra[prefix] == rb[prefix] {
+		prefix++
+	}
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
+
+// Kind is how a term matched a word; later kinds are better matches.
+type Kind int
+
+const (
+	NoMatch Kind = iota
+	FuzzyMatch
+	PrefixMatch
+	ExactMatch
+)
+
+// Match reports how term matches word, and the typos a fuzzy match has.
+// prefix lets term match the start of a longer word, fuzzy lets it match a
+// word within MaxEdits typos.
+func Match(term, word string, prefix, fuzzy bool) (Kind, int) {
+	switch {
+	case term == word:
+		return ExactMatch, 0
+	case prefix && strings.HasPrefix(word, term):
+		return PrefixMatch, 0
+	}
+	if limit := MaxEdits(term); fuzzy && limit > 0 {
+		if prefix && utf8.RuneCountInString(word) > utf8.RuneCountInString(term) {
+			// A misspelled start of a longer word, as typed so far.
+			word = string([]rune(word)[:utf8.RuneCountInString(term)])
+		}
+		if d := Distance(term, word, limit); d <= limit {
+			return FuzzyMatch, d
+		}
+	}
+	return NoMatch, 0
+}
+
+// Query is a parsed search. Prefix applies to the last term only, the one a
+// user may still be typing.
+type Query struct {
+	Terms  []string
+	Prefix bool
+	Fuzzy  bool
+}
+
+func Parse(text string, prefix, fuzzy bool) Query {
+	return Query{Terms: Words(text), Prefix: prefix, Fuzzy: fuzzy}
+}
+
+// prefix reports whether term i may match the start of a word.
+func (q Query) prefix(i int) bool {
+	return q.Prefix && i == len(q.Terms)-1
+}
+
+// Field is one searchable attribute of a document.
+type Field struct {
+	Name   string
+	Text   string
+	Weight float64
+	Fuzzy  bool // typos are tolerated in this field when the query is fuzzy
+}
+
+// Result is how well a document matches a query. Highlights has the text
+// of every matching field, HTML-escaped, with the matched words in <em>.
+type Result struct {
+	Score      float64
+	Highlights map[string]string
+}
+
+// Rank scores fields against q. Every term must match a word of some field,
+// otherwise ok is false. A term scores its best match: the field's weight,
+// less for a prefix of a longer word and less again for each typo.
+func Rank(q Query, fields []Field) (_ Result, ok bool) {
+	type word struct {
+		span [2]int
+		text string
+	}
+	words := make([][]word, len(fields))
+	for i, f := range fields {
+		for _, sp := range spans(f.Text) {
+			words[i] = append(words[i], word{span: sp, text: strings.ToLower(f.Text[sp[0]:sp[1]])})
+		}
+	}
+
+	var r Result
+	matched := make([][]bool, len(fields))
+	for i := range fields {
+		matched[i] = make([]bool, len(words[i]))
+	}
+	for ti, term := range q.Terms {
+		best := 0.0
+		for fi, f := range fields {
+			for wi, w := range words[fi] {
+				kind, edits := Match(term, w.text, q.prefix(ti), q.Fuzzy && f.Fuzzy)
+				if kind == NoMatch {
+					continue
+				}
+				matched[fi][wi] = true
+				best = max(best, f.Weight*Factor(kind, edits))
+			}
+		}
+		if best == 0 {
+			return Result{}, false
+		}
+		r.Score += best
+	}
+
+	for fi, f := range fields {
+		var b strings.Builder
+		last, found := 0, false
+		for wi, w := range words[fi] {
+			if !matched[fi][wi] {
+				continue
+			}
+			b.WriteString(html.EscapeString(f.Text[last:w.span[0]]))
+			b.WriteString("<em>" + html.EscapeString(f.Text[w.span[0]:w.span[1]]) + "</em>")
+			last, found = w.span[1], true
+		}
+		if !found {
+			continue
+		}
+		b.WriteString(html.EscapeString(f.Text[last:]))
+		if r.Highlights == nil {
+			r.Highlights = map[string]string{}
+		}
+		r.Highlights[f.Name] = b.String()
+	}
+	return r, true
+}
+
+// Factor is the share of a field's weight a match of kind with edits typos
+// scores.
+func Factor(kind Kind, edits int) float64 {
+	switch kind {
+	case ExactMatch:
+		return 1
+	case PrefixMatch:
+		return 0.6
+	default:
+		return 0.4 / float64(max(edits, 1))
+	}
+}
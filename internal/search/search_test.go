@@ -0,0 +1,177 @@
+package search
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestWords(t *testing.T) {
+	got := Words("Ada O'Neil", "ada.oneil@example.com")
+	want := []string{"ada", "o", "neil", "oneil", "example", "com"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Words = %q, want %q", got, want)
+	}
+}
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		limit int
+		want  int
+	}{
+		{"john", "john", 2, 0},
+		{"jhon", "john", 2, 1},
+		{"jon", "john", 2, 1},
+		{"kohn", "john", 2, 1},
+		{"smiht", "smith", 2, 1},
+		{"jonathan", "jhonatan", 2, 2},
+		{"smith", "jones", 2, 3},
+		{"a", "abcdef", 2, 3},
+	}
+	for _, tt := range tests {
+		if got := Distance(tt.a, tt.b, tt.limit); got != tt.want {
+			t.Errorf("Distance(%q, %q, %d) = %d, want %d", tt.a, tt.b, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestGrams(t *testing.T) {
+	got := Grams("ann")
+	want := []string{"__a", "_an", "ann", "nn_", "n__"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Grams = %q, want %q", got, want)
+	}
+}
+
+// Every word a fuzzy term matches must share a gram with it, or backends
+// looking candidates up by grams would miss it.
+func TestGramsFindFuzzyMatches(t *testing.T) {
+	tests := []struct{ term, word string }{
+		{"kohn", "john"},   // first letter
+		{"ohn", "john"},    // too short for typos, so not a match
+		{"jhon", "john"},   // transposed
+		{"ojhn", "john"},   // transposed first letters
+		{"johm", "john"},   // last letter
+		{"smiht", "smith"}, // transposed last letters
+		{"xonathax", "jonathan"},
+		{"ojnathna", "jonathan"},
+		{"onathanx", "jonathan"},
+	}
+	for _, tt := range tests {
+		kind, _ := Match(tt.term, tt.word, false, true)
+		if kind == NoMatch {
+			if MaxEdits(tt.term) > 0 {
+				t.Errorf("%q does not match %q", tt.term, tt.word)
+			}
+			continue
+		}
+		if !shareGram(tt.term, tt.word) {
+			t.Errorf("%q matches %q but shares no gram with it", tt.term, tt.word)
+		}
+	}
+}
+
+func shareGram(a, b string) bool {
+	grams := Grams(b)
+	for _, g := range Grams(a) {
+		if slices.Contains(grams, g) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		term, word    string
+		prefix, fuzzy bool
+		kind          Kind
+		edits         int
+	}{
+		{"smith", "smith", false, false, ExactMatch, 0},
+		{"smi", "smith", true, false, PrefixMatch, 0},
+		{"smi", "smith", false, false, NoMatch, 0},
+		{"smiht", "smith", false, false, NoMatch, 0},
+		{"smiht", "smith", false, true, FuzzyMatch, 1},
+		{"dmith", "smith", false, true, FuzzyMatch, 1},
+		{"jonh", "johnson", true, true, FuzzyMatch, 1}, // a misspelled start
+		{"ann", "anne", false, true, NoMatch, 0},       // too short for typos
+	}
+	for _, tt := range tests {
+		kind, edits := Match(tt.term, tt.word, tt.prefix, tt.fuzzy)
+		if kind != tt.kind || edits != tt.edits {
+			t.Errorf("Match(%q, %q, %v, %v) = %v, %d; want %v, %d", tt.term, tt.word, tt.prefix, tt.fuzzy, kind, edits, tt.kind, tt.edits)
+		}
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	if got := Similarity("martha", "martha"); got != 1 {
+		t.Errorf("equal = %v", got)
+	}
+	if got := Similarity("", "martha"); got != 0 {
+		t.Errorf("empty = %v", got)
+	}
+	close, far := Similarity("martha", "marhta"), Similarity("martha", "jones")
+	if close < 0.9 || far > 0.5 {
+		t.Errorf("martha/marhta = %v, martha/jones = %v", close, far)
+	}
+}
+
+func TestRank(t *testing.T) {
+	fields := func(first, last, email string) []Field {
+		return []Field{
+			{Name: "first_name", Text: first, Weight: 3, Fuzzy: true},
+			{Name: "last_name", Text: last, Weight: 3, Fuzzy: true},
+			{Name: "email", Text: email, Weight: 2},
+		}
+	}
+
+	exact, ok := Rank(Parse("smith", false, false), fields("Ann", "Smith", "ann@example.com"))
+	if !ok || exact.Score != 3 {
+		t.Fatalf("exact = %+v, %v", exact, ok)
+	}
+	inEmail, ok := Rank(Parse("smith", false, false), fields("Bob", "Jones", "smith@example.com"))
+	if !ok || inEmail.Score >= exact.Score {
+		t.Errorf("email match = %+v, %v; want below a name match", inEmail, ok)
+	}
+	prefix, ok := Rank(Parse("smi", true, false), fields("Ann", "Smith", "ann@example.com"))
+	if !ok || prefix.Score >= exact.Score {
+		t.Errorf("prefix match = %+v, %v; want below a whole word", prefix, ok)
+	}
+	fuzzy, ok := Rank(Parse("smiht", false, true), fields("Ann", "Smith", "ann@example.com"))
+	if !ok || fuzzy.Score >= prefix.Score {
+		t.Errorf("fuzzy match = %+v, %v; want below a prefix", fuzzy, ok)
+	}
+	if _, ok := Rank(Parse("ann smiht", false, false), fields("Ann", "Smith", "ann@example.com")); ok {
+		t.Error("a misspelled term matched without fuzzy")
+	}
+	if _, ok := Rank(Parse("smiht", false, true), fields("Bob", "Jones", "smiht@example.com")); !ok {
+		t.Error("an exact email match was not found with fuzzy on")
+	}
+}
+
+func TestRankHighlights(t *testing.T) {
+	r, ok := Rank(Parse("neil ada", false, false), []Field{
+		{Name: "first_name", Text: "Ada <b>", Weight: 3},
+		{Name: "last_name", Text: "O'Neil", Weight: 3},
+		{Name: "email", Text: "ada@example.com", Weight: 2},
+		{Name: "position", Text: "Engineer", Weight: 1},
+	})
+	if !ok {
+		t.Fatal("no match")
+	}
+	want := map[string]string{
+		"first_name": "<em>Ada</em> &lt;b&gt;",
+		"last_name":  "O&#39;<em>Neil</em>",
+		"email":      "<em>ada</em>@example.com",
+	}
+	if len(r.Highlights) != len(want) {
+		t.Errorf("highlights = %q, want %q", r.Highlights, want)
+	}
+	for name, h := range want {
+		if r.Highlights[name] != h {
+			t.Errorf("highlight of %s = %q, want %q", name, r.Highlights[name], h)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package customfield
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+
+	"github.com/rohitashk/golang-rest-api/internal/domain"
+	domainCustomField "github.com/rohitashk/golang-rest-api/internal/domain/customfield"
+	domainEmployee "github.com/rohitashk/golang-rest-api/internal/domain/employee"
+	"github.com/rohitashk/golang-rest-api/internal/validation"
+)
+
+type CreateInput struct {
+	Key        string   `json:"key" validate:"required,max=63"`
+	Label      string   `json:"label" validate:"max=120"`
+	Type       string   `json:"type" validate:"required,oneof=string number boolean date enum"`
+	Required   bool     `json:"required"`
+	Enum       []string `json:"enum" validate:"max=100,dive,min=1,max=120"`
+	Pattern    string   `json:"pattern" validate:"max=500"`
+	Department string   `json:"department" validate:"max=120"`
+}
+
+// UpdateInput fields left nil are not changed. The key and type are fixed,
+// as stored values depend on them.
+type UpdateInput struct {
+	Label      *string   `json:"label" validate:"omitnil,max=120"`
+	Required   *bool     `json:"required"`
+	Enum       *[]string `json:"enum" validate:"omitnil,max=100,dive,min=1,max=120"`
+	Pattern    *string   `json:"pattern" validate:"omitnil,max=500"`
+	Department *string   `json:"department" validate:"omitnil,max=120"`
+}
+
+type Service struct {
+	defs      domainCustomField.Repository
+	employees domainEmployee.Repository
+	validate  *validator.Validate
+	now       func() time.Time
+}
+
+func NewService(defs domainCustomField.Repository, employees domainEmployee.Repository) *Service {
+	return &Service{
+		defs:      defs,
+		employees: employees,
+		validate:  validation.New(),
+		now:       time.Now,
+	}
+}
+
+func (s *Service) Create(ctx context.Context, in CreateInput) (*domainCustomField.Definition, error) {
+	in.Key = strings.TrimSpace(in.Key)
+	if err := s.validate.Struct(in); err != nil {
+		return nil, validation.Error(err)
+	}
+
+	if in.Label == "" {
+		in.Label = in.Key
+	}
+
+	now := s.now().UTC()
+	d := &domainCustomField.Definition{
+		Key:        in.Key,
+		Label:      in.Label,
+		Type:       domainCustomField.Type(in.Type),
+		Required:   in.Required,
+		Enum:       in.Enum,
+		Pattern:    in.Pattern,
+		Department: in.Department,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := check(d); err != nil {
+		return nil, err
+	}
+	if err := s.defs.Create(ctx, d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (s *Service) Get(ctx context.Context, id string) (*domainCustomField.Definition, error) {
+	d, err := s.defs.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if d == nil {
+		return nil, domain.NotFound("custom field not found")
+	}
+	return d, nil
+}
+
+func (s *Service) List(ctx context.Context) ([]domainCustomField.Definition, error) {
+	return s.defs.List(ctx)
+}
+
+// Update changes a definition. Values stored before are not checked again;
+// they are when the employee's field is next set.
+func (s *Service) Update(ctx context.Context, id string, in UpdateInput) (*domainCustomField.Definition, error) {
+	if err := s.validate.Struct(in); err != nil {
+		return nil, validation.Error(err)
+	}
+
+	d, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if in.Label != nil {
+		d.Label = *in.Label
+	}
+	if in.Required != nil {
+		d.Required = *in.Required
+	}
+	if in.Enum != nil {
+		d.Enum = *in.Enum
+	}
+	if in.Pattern != nil {
+		d.Pattern = *in.Pattern
+	}
+	if in.Department != nil {
+		d.Department = *in.Department
+	}
+	if err := check(d); err != nil {
+		return nil, err
+	}
+
+	d.UpdatedAt = s.now().UTC()
+	if err := s.defs.Update(ctx, d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Delete removes the definition and its value from every employee.
+func (s *Service) Delete(ctx context.Context, id string) error {
+	d, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.defs.Delete(ctx, id); err != nil {
+		return err
+	}
+	return s.employees.RemoveCustomField(ctx, d.Key)
+}
+
+// check validates what the struct tags cannot: the key's form, and the
+// options that only make sense for some types.
+func check(d *domainCustomField.Definition) error {
+	var fields []domain.FieldError
+	if !domainCustomField.ValidKey(d.Key) {
+		fields = append(fields, domain.FieldError{
+			Field: "key", Rule: "pattern",
+			Message: "key must start with a lowercase letter and contain only lowercase letters, digits and underscores",
+		})
+	}
+	if d.Type == domainCustomField.TypeEnum && len(d.Enum) == 0 {
+		fields = append(fields, domain.FieldError{Field: "enum", Rule: "required", Message: "enum is required for enum fields"})
+	}
+	if d.Type != domainCustomField.TypeEnum && len(d.Enum) > 0 {
+		fields = append(fields, domain.FieldError{Field: "enum", Rule: "excluded", Message: "enum is only allowed for enum fields"})
+	}
+	if d.Pattern != "" {
+		if d.Type != domainCustomField.TypeString {
+			fields = append(fields, domain.FieldError{Field: "pattern", Rule: "excluded", Message: "pattern is only allowed for string fields"})
+		} else if _, err := regexp.Compile(d.Pattern); err != nil {
+			fields = append(fields, domain.FieldError{Field: "pattern", Rule: "regexp", Message: fmt.Sprintf("pattern is not a valid regular expression: %v", err)})
+		}
+	}
+	if len(fields) > 0 {
+		return domain.InvalidFields("invalid custom field", fields)
+	}
+	return nil
+}
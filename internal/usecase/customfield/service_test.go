@@ -0,0 +1,123 @@
+package customfield
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rohitashk/golang-rest-api/internal/adapters/memory"
+	"github.com/rohitashk/golang-rest-api/internal/domain"
+	"github.com/rohitashk/golang-rest-api/internal/domain/tenant"
+	employeeUC "github.com/rohitashk/golang-rest-api/internal/usecase/employee"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+// fieldErrors returns the fields err rejects, or nil when it is not a
+// validation error.
+func fieldErrors(err error) map[string]string {
+	var derr domain.Error
+	if !errors.As(err, &derr) || derr.Kind != domain.ErrKindValidation {
+		return nil
+	}
+	out := map[string]string{}
+	for _, f := range derr.Fields {
+		out[f.Field] = f.Rule
+	}
+	return out
+}
+
+func TestCreateRejects(t *testing.T) {
+	ctx := tenant.WithID(context.Background(), "acme")
+	s := NewService(memory.NewCustomFieldRepository(), memory.NewEmployeeRepository())
+
+	tests := []struct {
+		in    CreateInput
+		field string
+		rule  string
+	}{
+		{CreateInput{Key: "Badge", Type: "string"}, "key", "pattern"},
+		{CreateInput{Key: "badge.number", Type: "string"}, "key", "pattern"},
+		{CreateInput{Key: "badge", Type: "money"}, "type", "oneof"},
+		{CreateInput{Key: "shirt", Type: "enum"}, "enum", "required"},
+		{CreateInput{Key: "floor", Type: "number", Enum: []string{"1"}}, "enum", "excluded"},
+		{CreateInput{Key: "floor", Type: "number", Pattern: `\d+`}, "pattern", "excluded"},
+		{CreateInput{Key: "badge", Type: "string", Pattern: `[A-Z`}, "pattern", "regexp"},
+	}
+	for _, tt := range tests {
+		_, err := s.Create(ctx, tt.in)
+		if got := fieldErrors(err); got[tt.field] != tt.rule {
+			t.Errorf("%+v: err = %v, want %s rejected by %s", tt.in, err, tt.field, tt.rule)
+		}
+	}
+}
+
+func TestCreateUpdate(t *testing.T) {
+	ctx := tenant.WithID(context.Background(), "acme")
+	s := NewService(memory.NewCustomFieldRepository(), memory.NewEmployeeRepository())
+
+	d, err := s.Create(ctx, CreateInput{Key: " shirt ", Type: "enum", Enum: []string{"S", "M"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Key != "shirt" || d.Label != "shirt" {
+		t.Errorf("created %+v, want the key trimmed and used as the label", d)
+	}
+
+	var derr domain.Error
+	if _, err := s.Create(ctx, CreateInput{Key: "shirt", Type: "string"}); !errors.As(err, &derr) || derr.Kind != domain.ErrKindConflict {
+		t.Errorf("duplicate key: err = %v, want a conflict", err)
+	}
+	// Keys are unique per tenant.
+	if _, err := s.Create(tenant.WithID(context.Background(), "globex"), CreateInput{Key: "shirt", Type: "string"}); err != nil {
+		t.Errorf("another tenant's key: %v", err)
+	}
+
+	if _, err := s.Update(ctx, d.ID, UpdateInput{Enum: &[]string{}}); fieldErrors(err)["enum"] != "required" {
+		t.Errorf("emptied enum: err = %v", err)
+	}
+	got, err := s.Update(ctx, d.ID, UpdateInput{Enum: &[]string{"S", "M", "L"}, Required: ptr(true), Label: ptr("Shirt size")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Enum) != 3 || !got.Required || got.Label != "Shirt size" || got.Key != "shirt" {
+		t.Errorf("updated %+v", got)
+	}
+}
+
+func TestDeleteRemovesValues(t *testing.T) {
+	ctx := tenant.WithID(context.Background(), "acme")
+	defs, repo := memory.NewCustomFieldRepository(), memory.NewEmployeeRepository()
+	s := NewService(defs, repo)
+	employees := employeeUC.NewService(employeeUC.Deps{Repo: repo, CustomFields: defs})
+
+	d, err := s.Create(ctx, CreateInput{Key: "badge", Type: "string"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Create(ctx, CreateInput{Key: "floor", Type: "number"}); err != nil {
+		t.Fatal(err)
+	}
+	e, err := employees.Create(ctx, employeeUC.CreateInput{
+		FirstName: "Ada", LastName: "Lovelace", Email: "ada@example.com", Department: "Engineering", Position: "Engineer",
+		CustomFields: map[string]any{"badge": "AB1234", "floor": 3},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Delete(ctx, d.ID); err != nil {
+		t.Fatal(err)
+	}
+	got, err := employees.Get(ctx, e.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := got.CustomFields["badge"]; ok || got.CustomFields["floor"] != 3.0 {
+		t.Errorf("custom fields after deleting badge = %v", got.CustomFields)
+	}
+	var derr domain.Error
+	if err := s.Delete(ctx, d.ID); !errors.As(err, &derr) || derr.Kind != domain.ErrKindNotFound {
+		t.Errorf("second delete: err = %v", err)
+	}
+}
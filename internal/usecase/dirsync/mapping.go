@@ -0,0 +1,136 @@
+package dirsync
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/rohitashk/golang-rest-api/internal/domain/directory"
+	domainEmployee "github.com/rohitashk/golang-rest-api/internal/domain/employee"
+)
+
+// FieldManager maps to the manager's DN or email; it is resolved to an
+// employee ID when the sync is applied.
+const FieldManager = "manager"
+
+var mappableFields = map[string]bool{
+	domainEmployee.FieldFirstName:  true,
+	domainEmployee.FieldLastName:   true,
+	domainEmployee.FieldEmail:      true,
+	domainEmployee.FieldDepartment: true,
+	domainEmployee.FieldPosition:   true,
+	domainEmployee.FieldSalary:     true,
+	domainEmployee.FieldStatus:     true,
+	FieldManager:                   true,
+}
+
+// Rule fills one employee field from the first present attribute.
+type Rule struct {
+	Field      string   `json:"field"`
+	Attributes []string `json:"attributes"`
+	// Default is used when none of the attributes is present. Without one the
+	// field is left unchanged on existing employees.
+	Default string `json:"default,omitempty"`
+	// Values rewrites source values, e.g. {"TRUE": "inactive"}; lookups
+	// ignore case.
+	Values map[string]string `json:"values,omitempty"`
+	// Transform is applied before Values: lower, upper, trim or rdn (the value
+	// of a DN's first component).
+	Transform string `json:"transform,omitempty"`
+}
+
+type Mapping struct {
+	Rules []Rule `json:"rules"`
+}
+
+// DefaultMapping reads inetOrgPerson entries.
+func DefaultMapping() Mapping {
+	return Mapping{Rules: []Rule{
+		{Field: domainEmployee.FieldFirstName, Attributes: []string{"givenName"}},
+		{Field: domainEmployee.FieldLastName, Attributes: []string{"sn"}},
+		{Field: domainEmployee.FieldEmail, Attributes: []string{"mail"}, Transform: "lower"},
+		{Field: domainEmployee.FieldDepartment, Attributes: []string{"departmentNumber", "ou"}},
+		{Field: domainEmployee.FieldPosition, Attributes: []string{"title"}},
+		{Field: FieldManager, Attributes: []string{"manager"}},
+	}}
+}
+
+// LoadMapping reads a JSON mapping and validates it.
+func LoadMapping(r io.Reader) (Mapping, error) {
+	var m Mapping
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&m); err != nil {
+		return Mapping{}, fmt.Errorf("decode mapping: %w", err)
+	}
+	return m, m.Validate()
+}
+
+func (m Mapping) Validate() error {
+	seen := map[string]bool{}
+	for i, r := range m.Rules {
+		if !mappableFields[r.Field] {
+			return fmt.Errorf("rule %d: unknown field %q", i, r.Field)
+		}
+		if seen[r.Field] {
+			return fmt.Errorf("rule %d: field %q is mapped twice", i, r.Field)
+		}
+		seen[r.Field] = true
+		if len(r.Attributes) == 0 && r.Default == "" {
+			return fmt.Errorf("rule %d (%s): needs attributes or a default", i, r.Field)
+		}
+		switch r.Transform {
+		case "", "lower", "upper", "trim", "rdn":
+		default:
+			return fmt.Errorf("rule %d (%s): unknown transform %q", i, r.Field, r.Transform)
+		}
+	}
+	if !seen[domainEmployee.FieldEmail] {
+		return fmt.Errorf("mapping must include %s, employees are matched on it", domainEmployee.FieldEmail)
+	}
+	return nil
+}
+
+// apply returns the mapped fields present for e; unmapped and absent fields
+// are missing from the result.
+func (m Mapping) apply(e directory.Entry) map[string]string {
+	out := map[string]string{}
+	for _, r := range m.Rules {
+		v, ok := "", false
+		for _, a := range r.Attributes {
+			if v = strings.TrimSpace(e.Get(a)); v != "" {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			if r.Default == "" {
+				continue
+			}
+			v = r.Default
+		}
+
+		switch r.Transform {
+		case "lower":
+			v = strings.ToLower(v)
+		case "upper":
+			v = strings.ToUpper(v)
+		case "trim":
+			v = strings.TrimSpace(v)
+		case "rdn":
+			first, _, _ := strings.Cut(v, ",")
+			if _, val, found := strings.Cut(first, "="); found {
+				v = strings.TrimSpace(val)
+			}
+		}
+		for from, to := range r.Values {
+			if strings.EqualFold(from, v) {
+				v = to
+				break
+			}
+		}
+		out[r.Field] = v
+	}
+	return out
+}
@@ -0,0 +1,99 @@
+package dirsync
+
+import (
+	"maps"
+	"strings"
+	"testing"
+
+	"github.com/rohitashk/golang-rest-api/internal/domain/directory"
+)
+
+func entry(dn string, attrs map[string]string) directory.Entry {
+	e := directory.NewEntry(dn)
+	for k, v := range attrs {
+		e.Add(k, v)
+	}
+	return e
+}
+
+func TestMappingApply(t *testing.T) {
+	m, err := LoadMapping(strings.NewReader(`{"rules": [
+		{"field": "email", "attributes": ["mail", "userPrincipalName"], "transform": "lower"},
+		{"field": "department", "attributes": ["departmentNumber", "ou"], "default": "Unassigned"},
+		{"field": "position", "attributes": ["title"], "transform": "trim"},
+		{"field": "status", "attributes": ["nsAccountLock"], "transform": "upper", "values": {"true": "inactive", "FALSE": "active"}},
+		{"field": "manager", "attributes": ["manager"], "transform": "rdn"},
+		{"field": "salary", "attributes": ["employeeSalary"]}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		attrs map[string]string
+		want  map[string]string
+	}{
+		{
+			name: "first present attribute wins",
+			attrs: map[string]string{
+				"userPrincipalName": "ADA@CORP.example.com", "ou": "Research", "title": "  Engineer ",
+				"nsAccountLock": "true", "manager": "uid=grace, ou=People,dc=example,dc=com",
+			},
+			want: map[string]string{
+				"email": "ada@corp.example.com", "department": "Research", "position": "Engineer",
+				"status": "inactive", "manager": "grace",
+			},
+		},
+		{
+			name:  "default when absent or blank",
+			attrs: map[string]string{"mail": "Grace@Example.com", "departmentNumber": "   ", "nsAccountLock": "False", "employeeSalary": "1200.50"},
+			want:  map[string]string{"email": "grace@example.com", "department": "Unassigned", "status": "active", "salary": "1200.50"},
+		},
+		{
+			name:  "unmapped values pass through",
+			attrs: map[string]string{"mail": "x@example.com", "nsAccountLock": "maybe", "MANAGER": "grace@example.com"},
+			want:  map[string]string{"email": "x@example.com", "department": "Unassigned", "status": "MAYBE", "manager": "grace@example.com"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := m.apply(entry("uid=x,dc=example,dc=com", tt.attrs)); !maps.Equal(got, tt.want) {
+				t.Errorf("apply = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultMapping(t *testing.T) {
+	m := DefaultMapping()
+	if err := m.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	got := m.apply(entry("uid=ada,dc=example,dc=com", map[string]string{
+		"givenName": "Ada", "sn": "Lovelace", "mail": "Ada@Example.com", "ou": "R&D", "title": "Engineer",
+	}))
+	want := map[string]string{
+		"first_name": "Ada", "last_name": "Lovelace", "email": "ada@example.com", "department": "R&D", "position": "Engineer",
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("apply = %v, want %v", got, want)
+	}
+}
+
+func TestLoadMappingRejects(t *testing.T) {
+	for _, tt := range []struct {
+		json, err string
+	}{
+		{`{"rules": [{"field": "first_name", "attributes": ["givenName"]}]}`, "mapping must include email"},
+		{`{"rules": [{"field": "email", "attributes": ["mail"]}, {"field": "email", "attributes": ["upn"]}]}`, `field "email" is mapped twice`},
+		{`{"rules": [{"field": "email", "attributes": ["mail"]}, {"field": "id", "attributes": ["uid"]}]}`, `unknown field "id"`},
+		{`{"rules": [{"field": "email"}]}`, "needs attributes or a default"},
+		{`{"rules": [{"field": "email", "attributes": ["mail"], "transform": "title"}]}`, `unknown transform "title"`},
+		{`{"rules": [{"field": "email", "attrs": ["mail"]}]}`, "decode mapping"},
+	} {
+		if _, err := LoadMapping(strings.NewReader(tt.json)); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: err = %v, want %q", tt.json, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package dirsync
+
+import (
+	"fmt"
+	"io"
+
+	employeeUC "github.com/rohitashk/golang-rest-api/internal/usecase/employee"
+)
+
+type ActionKind string
+
+const (
+	ActionCreate    ActionKind = "create"
+	ActionUpdate    ActionKind = "update"
+	ActionUnchanged ActionKind = "unchanged"
+)
+
+type Change struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+type Action struct {
+	Kind       ActionKind `json:"action"`
+	DN         string     `json:"dn"`
+	Email      string     `json:"email"`
+	EmployeeID string     `json:"employee_id,omitempty"`
+	Changes    []Change   `json:"changes,omitempty"`
+	Warnings   []string   `json:"warnings,omitempty"`
+
+	create  employeeUC.CreateInput
+	update  employeeUC.UpdateInput
+	manager string // email of the manager to set, when it changes
+}
+
+type Skip struct {
+	DN     string `json:"dn"`
+	Reason string `json:"reason"`
+}
+
+// Report is the diff between the directory and the stored employees.
+type Report struct {
+	Actions []Action `json:"actions"`
+	Skipped []Skip   `json:"skipped"`
+}
+
+func (r *Report) skip(dn, reason string) {
+	r.Skipped = append(r.Skipped, Skip{DN: dn, Reason: reason})
+}
+
+func (r *Report) Count(kind ActionKind) int {
+	n := 0
+	for _, a := range r.Actions {
+		if a.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+// WriteText writes a human-readable diff; unchanged employees are only
+// counted.
+func (r *Report) WriteText(w io.Writer) error {
+	ew := &errWriter{w: w}
+	for _, a := range r.Actions {
+		switch a.Kind {
+		case ActionCreate:
+			ew.printf("+ create %s (%s)\n", a.Email, a.DN)
+		case ActionUpdate:
+			ew.printf("~ update %s (%s)\n", a.Email, a.EmployeeID)
+		default:
+			if len(a.Warnings) == 0 {
+				continue
+			}
+			ew.printf("  unchanged %s\n", a.Email)
+		}
+		for _, c := range a.Changes {
+			if a.Kind == ActionCreate {
+				ew.printf("    %s: %q\n", c.Field, c.New)
+			} else {
+				ew.printf("    %s: %q -> %q\n", c.Field, c.Old, c.New)
+			}
+		}
+		for _, warn := range a.Warnings {
+			ew.printf("    warning: %s\n", warn)
+		}
+	}
+	for _, s := range r.Skipped {
+		ew.printf("! skip %s: %s\n", s.DN, s.Reason)
+	}
+	ew.printf("%d to create, %d to update, %d unchanged, %d skipped\n",
+		r.Count(ActionCreate), r.Count(ActionUpdate), r.Count(ActionUnchanged), len(r.Skipped))
+	return ew.err
+}
+
+type Failure struct {
+	Email string `json:"email"`
+	Error string `json:"error"`
+}
+
+type Result struct {
+	Created  int       `json:"created"`
+	Updated  int       `json:"updated"`
+	Failures []Failure `json:"failures"`
+}
+
+func (r *Result) fail(email string, err error) {
+	r.Failures = append(r.Failures, Failure{Email: email, Error: err.Error()})
+}
+
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (e *errWriter) printf(format string, args ...any) {
+	if e.err == nil {
+		_, e.err = fmt.Fprintf(e.w, format, args...)
+	}
+}
@@ -0,0 +1,294 @@
+package dirsync
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+
+	"github.com/rohitashk/golang-rest-api/internal/domain/directory"
+	domainEmployee "github.com/rohitashk/golang-rest-api/internal/domain/employee"
+	employeeUC "github.com/rohitashk/golang-rest-api/internal/usecase/employee"
+	"github.com/rohitashk/golang-rest-api/internal/validation"
+)
+
+// Syncer plans and applies changes that bring employees in line with a
+// directory. Employees missing from the directory are left alone.
+type Syncer struct {
+	repo     domainEmployee.Repository
+	svc      *employeeUC.Service
+	mapping  Mapping
+	validate *validator.Validate
+}
+
+// NewSyncer reads employees from repo and writes them through svc, so writes
+// are validated and emit events like API writes.
+func NewSyncer(repo domainEmployee.Repository, svc *employeeUC.Service, mapping Mapping) *Syncer {
+	return &Syncer{repo: repo, svc: svc, mapping: mapping, validate: validation.New()}
+}
+
+type record struct {
+	dn     string
+	email  string
+	fields map[string]string
+}
+
+// Plan compares the entries with the stored employees without changing
+// anything.
+func (s *Syncer) Plan(ctx context.Context, entries []directory.Entry) (*Report, error) {
+	report := &Report{}
+
+	var records []record
+	emailByDN := map[string]string{}
+	dnByEmail := map[string]string{}
+	for _, e := range entries {
+		fields := s.mapping.apply(e)
+		email := strings.ToLower(fields[domainEmployee.FieldEmail])
+		if email == "" {
+			report.skip(e.DN, "no email")
+			continue
+		}
+		if dn, ok := dnByEmail[email]; ok {
+			report.skip(e.DN, fmt.Sprintf("email %s is also used by %s", email, dn))
+			continue
+		}
+		fields[domainEmployee.FieldEmail] = email
+		dnByEmail[email] = e.DN
+		emailByDN[directory.NormalizeDN(e.DN)] = email
+		records = append(records, record{dn: e.DN, email: email, fields: fields})
+	}
+
+	managerEmails := map[string]string{} // employee ID -> email
+	for _, rec := range records {
+		var warnings []string
+		manager, hasManager := rec.fields[FieldManager]
+		if hasManager {
+			manager, hasManager = resolveManager(manager, emailByDN)
+			if !hasManager {
+				warnings = append(warnings, fmt.Sprintf("manager %s is not in the directory; left unchanged", rec.fields[FieldManager]))
+			} else if manager == rec.email {
+				warnings = append(warnings, "employee is their own manager; left unchanged")
+				hasManager = false
+			}
+		}
+
+		existing, err := s.repo.GetByEmail(ctx, rec.email)
+		if err != nil {
+			return nil, err
+		}
+
+		var a Action
+		if existing == nil {
+			a, err = s.planCreate(rec)
+		} else {
+			a, err = s.planUpdate(ctx, rec, existing, managerEmails)
+		}
+		if err != nil {
+			report.skip(rec.dn, err.Error())
+			continue
+		}
+		if hasManager {
+			current := ""
+			if existing != nil && existing.ManagerID != "" {
+				if current, err = s.emailOf(ctx, existing.ManagerID, managerEmails); err != nil {
+					return nil, err
+				}
+			}
+			if manager != current {
+				a.Changes = append(a.Changes, Change{Field: FieldManager, Old: current, New: manager})
+				a.manager = manager
+			}
+		}
+		if a.Kind == ActionUnchanged && len(a.Changes) > 0 {
+			a.Kind = ActionUpdate
+		}
+		a.Warnings = warnings
+		report.Actions = append(report.Actions, a)
+	}
+	return report, nil
+}
+
+func (s *Syncer) planCreate(rec record) (Action, error) {
+	f := rec.fields
+	in := employeeUC.CreateInput{
+		FirstName:  f[domainEmployee.FieldFirstName],
+		LastName:   f[domainEmployee.FieldLastName],
+		Email:      rec.email,
+		Department: f[domainEmployee.FieldDepartment],
+		Position:   f[domainEmployee.FieldPosition],
+		Status:     f[domainEmployee.FieldStatus],
+	}
+	if v, ok := f[domainEmployee.FieldSalary]; ok {
+		salary, err := parseSalary(v)
+		if err != nil {
+			return Action{}, err
+		}
+		in.Salary = salary
+	}
+	if err := s.validate.Struct(in); err != nil {
+		return Action{}, invalid(err)
+	}
+
+	a := Action{Kind: ActionCreate, DN: rec.dn, Email: rec.email, create: in}
+	for _, field := range domainEmployee.Fields {
+		if v, ok := f[field]; ok && field != domainEmployee.FieldEmail {
+			a.Changes = append(a.Changes, Change{Field: field, New: v})
+		}
+	}
+	return a, nil
+}
+
+func (s *Syncer) planUpdate(ctx context.Context, rec record, e *domainEmployee.Employee, managerEmails map[string]string) (Action, error) {
+	a := Action{Kind: ActionUnchanged, DN: rec.dn, Email: rec.email, EmployeeID: e.ID}
+	in := &a.update
+
+	str := func(field, old string, dst **string) {
+		v, ok := rec.fields[field]
+		if ok && v != old {
+			*dst = &v
+			a.Changes = append(a.Changes, Change{Field: field, Old: old, New: v})
+		}
+	}
+	str(domainEmployee.FieldFirstName, e.FirstName, &in.FirstName)
+	str(domainEmployee.FieldLastName, e.LastName, &in.LastName)
+	str(domainEmployee.FieldDepartment, e.Department, &in.Department)
+	str(domainEmployee.FieldPosition, e.Position, &in.Position)
+	str(domainEmployee.FieldStatus, string(e.Status), &in.Status)
+
+	if v, ok := rec.fields[domainEmployee.FieldSalary]; ok {
+		salary, err := parseSalary(v)
+		if err != nil {
+			return Action{}, err
+		}
+		if salary != e.Salary {
+			in.Salary = &salary
+			a.Changes = append(a.Changes, Change{
+				Field: domainEmployee.FieldSalary,
+				Old:   strconv.FormatFloat(e.Salary, 'f', -1, 64),
+				New:   strconv.FormatFloat(salary, 'f', -1, 64),
+			})
+		}
+	}
+	if err := s.validate.Struct(*in); err != nil {
+		return Action{}, invalid(err)
+	}
+	if len(a.Changes) > 0 {
+		a.Kind = ActionUpdate
+	}
+	managerEmails[e.ID] = e.Email
+	return a, nil
+}
+
+func (s *Syncer) emailOf(ctx context.Context, id string, cache map[string]string) (string, error) {
+	if email, ok := cache[id]; ok {
+		return email, nil
+	}
+	e, err := s.repo.GetByID(ctx, id, domainEmployee.FieldEmail)
+	if err != nil {
+		return "", err
+	}
+	email := ""
+	if e != nil {
+		email = e.Email
+	}
+	cache[id] = email
+	return email, nil
+}
+
+// Apply carries out a plan. Employees are created and updated first and
+// managers set afterwards, so a manager created in the same run can be
+// referenced. Failures are collected rather than stopping the run.
+func (s *Syncer) Apply(ctx context.Context, report *Report) (*Result, error) {
+	res := &Result{}
+	failed := map[int]bool{}
+
+	for i := range report.Actions {
+		a := &report.Actions[i]
+		var err error
+		switch a.Kind {
+		case ActionCreate:
+			var e *domainEmployee.Employee
+			if e, err = s.svc.Create(ctx, a.create); err == nil {
+				a.EmployeeID = e.ID
+				res.Created++
+			}
+		case ActionUpdate:
+			// An update of only the manager is counted in the second pass.
+			if !noChanges(a.update) {
+				if _, err = s.svc.Update(ctx, a.EmployeeID, a.update); err == nil {
+					res.Updated++
+				}
+			}
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return res, ctx.Err()
+			}
+			failed[i] = true
+			res.fail(a.Email, err)
+		}
+	}
+
+	for i := range report.Actions {
+		a := &report.Actions[i]
+		if a.manager == "" || failed[i] {
+			continue
+		}
+		manager, err := s.repo.GetByEmail(ctx, a.manager)
+		if err != nil {
+			return res, err
+		}
+		if manager == nil {
+			res.fail(a.Email, fmt.Errorf("manager %s does not exist", a.manager))
+			continue
+		}
+		if _, err := s.svc.Update(ctx, a.EmployeeID, employeeUC.UpdateInput{ManagerID: &manager.ID}); err != nil {
+			if ctx.Err() != nil {
+				return res, ctx.Err()
+			}
+			res.fail(a.Email, err)
+			continue
+		}
+		if a.Kind == ActionUpdate && noChanges(a.update) {
+			res.Updated++
+		}
+	}
+	return res, nil
+}
+
+func noChanges(in employeeUC.UpdateInput) bool {
+	return reflect.DeepEqual(in, employeeUC.UpdateInput{})
+}
+
+// resolveManager turns a manager attribute (a DN or an email) into the
+// manager's email.
+func resolveManager(ref string, emailByDN map[string]string) (string, bool) {
+	if strings.Contains(ref, "@") && !strings.Contains(ref, "=") {
+		return strings.ToLower(ref), true
+	}
+	email, ok := emailByDN[directory.NormalizeDN(ref)]
+	return email, ok
+}
+
+func parseSalary(v string) (float64, error) {
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, fmt.Errorf("salary %q is not a number", v)
+	}
+	return f, nil
+}
+
+func invalid(err error) error {
+	fields, ok := validation.Fields(err)
+	if !ok {
+		return err
+	}
+	msgs := make([]string, len(fields))
+	for i, f := range fields {
+		msgs[i] = f.Message
+	}
+	return fmt.Errorf("%s", strings.Join(msgs, "; "))
+}
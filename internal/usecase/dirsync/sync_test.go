@@ -0,0 +1,236 @@
+package dirsync
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/rohitashk/golang-rest-api/internal/adapters/memory"
+	"github.com/rohitashk/golang-rest-api/internal/domain/directory"
+	domainEmployee "github.com/rohitashk/golang-rest-api/internal/domain/employee"
+	"github.com/rohitashk/golang-rest-api/internal/domain/tenant"
+	employeeUC "github.com/rohitashk/golang-rest-api/internal/usecase/employee"
+)
+
+const people = "ou=People,dc=example,dc=com"
+
+func person(uid string, attrs map[string]string) directory.Entry {
+	return entry("uid="+uid+","+people, attrs)
+}
+
+// directoryFixture stores grace and ken; the directory promotes grace, adds
+// ada reporting to grace and linus reporting to ada, and leaves ken alone.
+func directoryFixture(t *testing.T) (context.Context, *memory.EmployeeRepository, *Syncer, []directory.Entry) {
+	t.Helper()
+	ctx := tenant.WithID(context.Background(), "acme")
+	repo := memory.NewEmployeeRepository()
+	svc := employeeUC.NewService(employeeUC.Deps{Repo: repo})
+	for _, in := range []employeeUC.CreateInput{
+		{FirstName: "Grace", LastName: "Hopper", Email: "grace@example.com", Department: "Navy", Position: "Captain", Salary: 100},
+		{FirstName: "Ken", LastName: "Thompson", Email: "ken@example.com", Department: "Unix", Position: "Engineer", Salary: 100},
+	} {
+		if _, err := svc.Create(ctx, in); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries := []directory.Entry{
+		person("ada", map[string]string{
+			"givenName": "Ada", "sn": "Lovelace", "mail": "Ada@Example.com", "ou": "R&D", "title": "Engineer",
+			"manager": "UID=grace, OU=People, DC=example, DC=com",
+		}),
+		person("grace", map[string]string{"givenName": "Grace", "sn": "Hopper", "mail": "grace@example.com", "ou": "Navy", "title": "Rear Admiral"}),
+		person("linus", map[string]string{
+			"givenName": "Linus", "sn": "Torvalds", "mail": "linus@example.com", "ou": "Kernel", "title": "Maintainer",
+			"manager": "uid=ada," + people,
+		}),
+		person("ken", map[string]string{
+			"givenName": "Ken", "sn": "Thompson", "mail": "ken@example.com", "ou": "Unix", "title": "Engineer",
+			"manager": "uid=dmr," + people,
+		}),
+		person("nomail", map[string]string{"givenName": "No", "sn": "Mail", "ou": "R&D", "title": "Ghost"}),
+		person("ada2", map[string]string{"givenName": "Ada", "sn": "Again", "mail": "ada@example.com", "ou": "R&D", "title": "Copy"}),
+		person("noname", map[string]string{"givenName": "No", "mail": "noname@example.com", "ou": "R&D", "title": "Ghost"}),
+	}
+	return ctx, repo, NewSyncer(repo, svc, DefaultMapping()), entries
+}
+
+func TestPlanIsADryRun(t *testing.T) {
+	ctx, repo, syncer, entries := directoryFixture(t)
+
+	report, err := syncer.Plan(ctx, entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, total, _ := repo.List(ctx, domainEmployee.ListFilter{}, domainEmployee.ListPage{}); total != 2 {
+		t.Errorf("planning stored %d employees, want the 2 there were", total)
+	}
+	if grace, _ := repo.GetByEmail(ctx, "grace@example.com"); grace.Position != "Captain" {
+		t.Errorf("planning changed grace's position to %q", grace.Position)
+	}
+
+	if c, u, n := report.Count(ActionCreate), report.Count(ActionUpdate), report.Count(ActionUnchanged); c != 2 || u != 1 || n != 1 {
+		t.Errorf("create %d, update %d, unchanged %d; want 2, 1, 1", c, u, n)
+	}
+	skipped := map[string]string{}
+	for _, s := range report.Skipped {
+		skipped[s.DN] = s.Reason
+	}
+	if len(skipped) != 3 ||
+		skipped["uid=nomail,"+people] != "no email" ||
+		skipped["uid=ada2,"+people] != "email ada@example.com is also used by uid=ada,"+people ||
+		!strings.Contains(skipped["uid=noname,"+people], "last_name") {
+		t.Errorf("skipped = %v", skipped)
+	}
+
+	byEmail := map[string]Action{}
+	for _, a := range report.Actions {
+		byEmail[a.Email] = a
+	}
+	if a := byEmail["ada@example.com"]; a.Kind != ActionCreate || !hasChange(a, Change{Field: FieldManager, New: "grace@example.com"}) {
+		t.Errorf("ada = %+v", a)
+	}
+	if a := byEmail["grace@example.com"]; a.Kind != ActionUpdate || len(a.Changes) != 1 ||
+		!hasChange(a, Change{Field: domainEmployee.FieldPosition, Old: "Captain", New: "Rear Admiral"}) {
+		t.Errorf("grace = %+v", a)
+	}
+	if a := byEmail["ken@example.com"]; a.Kind != ActionUnchanged || len(a.Warnings) != 1 || !strings.Contains(a.Warnings[0], "not in the directory") {
+		t.Errorf("ken = %+v", a)
+	}
+
+	var text bytes.Buffer
+	if err := report.WriteText(&text); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"+ create ada@example.com (uid=ada," + people + ")\n    first_name: \"Ada\"\n",
+		"~ update grace@example.com",
+		`    position: "Captain" -> "Rear Admiral"`,
+		"  unchanged ken@example.com\n    warning: manager uid=dmr,",
+		"! skip uid=nomail," + people + ": no email",
+		"2 to create, 1 to update, 1 unchanged, 3 skipped\n",
+	} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("report lacks %q:\n%s", want, text.String())
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	ctx, repo, syncer, entries := directoryFixture(t)
+	report, err := syncer.Plan(ctx, entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := syncer.Apply(ctx, report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Created != 2 || res.Updated != 1 || len(res.Failures) != 0 {
+		t.Errorf("result = %+v", res)
+	}
+
+	get := func(email string) *domainEmployee.Employee {
+		t.Helper()
+		e, err := repo.GetByEmail(ctx, email)
+		if err != nil || e == nil {
+			t.Fatalf("%s: %v, %v", email, e, err)
+		}
+		return e
+	}
+	grace, ada, linus := get("grace@example.com"), get("ada@example.com"), get("linus@example.com")
+	if grace.Position != "Rear Admiral" {
+		t.Errorf("grace's position = %q", grace.Position)
+	}
+	if ada.ManagerID != grace.ID || ada.Department != "R&D" || ada.FirstName != "Ada" {
+		t.Errorf("ada = %+v", ada)
+	}
+	// linus's manager did not exist before this run.
+	if linus.ManagerID != ada.ID {
+		t.Errorf("linus's manager = %q, want ada %q", linus.ManagerID, ada.ID)
+	}
+	if ken := get("ken@example.com"); ken.ManagerID != "" {
+		t.Errorf("ken's manager = %q", ken.ManagerID)
+	}
+
+	again, err := syncer.Plan(ctx, entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c, u := again.Count(ActionCreate), again.Count(ActionUpdate); c != 0 || u != 0 {
+		t.Errorf("after applying, the plan still has %d creates and %d updates: %+v", c, u, again.Actions)
+	}
+}
+
+func TestApplyManagerOnlyChange(t *testing.T) {
+	ctx, repo, syncer, entries := directoryFixture(t)
+	if _, err := syncer.Apply(ctx, mustPlan(t, ctx, syncer, entries)); err != nil {
+		t.Fatal(err)
+	}
+
+	// ken moves under grace without any other change.
+	entries[3] = person("ken", map[string]string{
+		"givenName": "Ken", "sn": "Thompson", "mail": "ken@example.com", "ou": "Unix", "title": "Engineer",
+		"manager": "grace@example.com",
+	})
+	report := mustPlan(t, ctx, syncer, entries)
+	res, err := syncer.Apply(ctx, report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Created != 0 || res.Updated != 1 || len(res.Failures) != 0 {
+		t.Errorf("result = %+v", res)
+	}
+	ken, _ := repo.GetByEmail(ctx, "ken@example.com")
+	grace, _ := repo.GetByEmail(ctx, "grace@example.com")
+	if ken.ManagerID != grace.ID {
+		t.Errorf("ken's manager = %q, want grace %q", ken.ManagerID, grace.ID)
+	}
+}
+
+func TestApplyCollectsFailures(t *testing.T) {
+	ctx, repo, syncer, entries := directoryFixture(t)
+	report := mustPlan(t, ctx, syncer, entries)
+
+	// grace leaves between the plan and the apply.
+	grace, _ := repo.GetByEmail(ctx, "grace@example.com")
+	if err := repo.Delete(ctx, grace.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := syncer.Apply(ctx, report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	failed := map[string]string{}
+	for _, f := range res.Failures {
+		failed[f.Email] = f.Error
+	}
+	if res.Created != 2 || len(failed) != 2 || failed["grace@example.com"] == "" ||
+		failed["ada@example.com"] != "manager grace@example.com does not exist" {
+		t.Errorf("result = %+v", res)
+	}
+	if linus, _ := repo.GetByEmail(ctx, "linus@example.com"); linus == nil || linus.ManagerID == "" {
+		t.Errorf("linus = %+v, want him created under ada despite the failures", linus)
+	}
+}
+
+func mustPlan(t *testing.T, ctx context.Context, s *Syncer, entries []directory.Entry) *Report {
+	t.Helper()
+	report, err := s.Plan(ctx, entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return report
+}
+
+func hasChange(a Action, want Change) bool {
+	for _, c := range a.Changes {
+		if c == want {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+package employee
+
+import (
+	"context"
+	"maps"
+	"sort"
+
+	"github.com/rohitashk/golang-rest-api/internal/domain"
+	"github.com/rohitashk/golang-rest-api/internal/domain/customfield"
+	domainEmployee "github.com/rohitashk/golang-rest-api/internal/domain/employee"
+)
+
+func (s *Service) definitions(ctx context.Context) (map[string]customfield.Definition, error) {
+	if s.fields == nil {
+		return nil, nil
+	}
+	defs, err := s.fields.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]customfield.Definition, len(defs))
+	for _, d := range defs {
+		out[d.Key] = d
+	}
+	return out, nil
+}
+
+// setCustomFields applies set to e's custom fields, where a nil value clears
+// a field. Values of fields that no longer apply to e's department are
+// dropped. Required fields are checked when they are cleared, and all of
+// them when checkRequired is set: on create and when e changes department.
+func (s *Service) setCustomFields(ctx context.Context, e *domainEmployee.Employee, set map[string]any, checkRequired bool) error {
+	if len(set) == 0 && !checkRequired {
+		return nil
+	}
+	defs, err := s.definitions(ctx)
+	if err != nil {
+		return err
+	}
+
+	values := maps.Clone(e.CustomFields)
+	if values == nil {
+		values = map[string]any{}
+	}
+	var fields []domain.FieldError
+	for _, key := range sortedKeys(set) {
+		field := domainEmployee.FieldCustomFields + "." + key
+		d, ok := defs[key]
+		switch {
+		case !ok:
+			fields = append(fields, domain.FieldError{Field: field, Rule: "unknown", Message: key + " is not a custom field"})
+		case !d.AppliesTo(e.Department):
+			fields = append(fields, domain.FieldError{Field: field, Rule: "department", Message: key + " only applies to the " + d.Department + " department"})
+		case set[key] == nil:
+			delete(values, key)
+		default:
+			v, err := d.Value(set[key])
+			if err != nil {
+				fields = append(fields, domain.FieldError{Field: field, Rule: string(d.Type), Message: err.Error()})
+				continue
+			}
+			values[key] = v
+		}
+	}
+
+	for _, key := range sortedKeys(defs) {
+		d := defs[key]
+		_, has := values[key]
+		_, given := set[key]
+		switch {
+		case !d.AppliesTo(e.Department):
+			delete(values, key)
+		// A value given but rejected was reported above.
+		case d.Required && !has && set[key] == nil && (checkRequired || given):
+			fields = append(fields, domain.FieldError{
+				Field: domainEmployee.FieldCustomFields + "." + key, Rule: "required", Message: key + " is required",
+			})
+		}
+	}
+	if len(fields) > 0 {
+		return domain.InvalidFields("invalid custom fields", fields)
+	}
+
+	if len(values) == 0 {
+		values = nil
+	}
+	e.CustomFields = values
+	return nil
+}
+
+// customFieldFilter converts filter values from text to the types of their
+// fields.
+func (s *Service) customFieldFilter(ctx context.Context, in map[string]string) (map[string]any, error) {
+	if len(in) == 0 {
+		return nil, nil
+	}
+	defs, err := s.definitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]any, len(in))
+	var fields []domain.FieldError
+	for _, key := range sortedKeys(in) {
+		field := domainEmployee.FieldCustomFields + "." + key
+		d, ok := defs[key]
+		if !ok {
+			fields = append(fields, domain.FieldError{Field: field, Rule: "unknown", Message: key + " is not a custom field"})
+			continue
+		}
+		v, err := d.Value(in[key])
+		if err != nil {
+			fields = append(fields, domain.FieldError{Field: field, Rule: string(d.Type), Message: err.Error()})
+			continue
+		}
+		out[key] = v
+	}
+	if len(fields) > 0 {
+		return nil, domain.InvalidFields("invalid custom field filter", fields)
+	}
+	return out, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,196 @@
+package employee
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rohitashk/golang-rest-api/internal/adapters/memory"
+	"github.com/rohitashk/golang-rest-api/internal/domain"
+	"github.com/rohitashk/golang-rest-api/internal/domain/customfield"
+	"github.com/rohitashk/golang-rest-api/internal/domain/tenant"
+)
+
+// newCustomFieldService returns a service of the tenant acme whose employees
+// have a required badge, a floor, a remote flag and, in Sales only, a
+// required quota.
+func newCustomFieldService(t *testing.T) (context.Context, *Service) {
+	t.Helper()
+	ctx := tenant.WithID(context.Background(), "acme")
+	defs := memory.NewCustomFieldRepository()
+	for _, d := range []customfield.Definition{
+		{Key: "badge", Type: customfield.TypeString, Required: true, Pattern: `[A-Z]{2}\d{4}`},
+		{Key: "floor", Type: customfield.TypeNumber},
+		{Key: "remote", Type: customfield.TypeBoolean},
+		{Key: "quota", Type: customfield.TypeNumber, Required: true, Department: "Sales"},
+	} {
+		if err := defs.Create(ctx, &d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return ctx, NewService(Deps{Repo: memory.NewEmployeeRepository(), CustomFields: defs})
+}
+
+// fieldRules returns the rule each field of a validation error broke.
+func fieldRules(t *testing.T, err error) map[string]string {
+	t.Helper()
+	var derr domain.Error
+	if !errors.As(err, &derr) || derr.Kind != domain.ErrKindValidation {
+		t.Fatalf("err = %v, want a validation error", err)
+	}
+	out := map[string]string{}
+	for _, f := range derr.Fields {
+		out[f.Field] = f.Rule
+	}
+	return out
+}
+
+func TestCreateCustomFields(t *testing.T) {
+	ctx, s := newCustomFieldService(t)
+
+	e := create(t, ctx, s, CreateInput{
+		FirstName: "Ada", LastName: "Lovelace", Email: "ada@example.com",
+		CustomFields: map[string]any{"badge": "AB1234", "floor": "3", "remote": true},
+	})
+	// Values given in text form are stored with their field's type.
+	if e.CustomFields["badge"] != "AB1234" || e.CustomFields["floor"] != 3.0 || e.CustomFields["remote"] != true {
+		t.Errorf("custom fields = %#v", e.CustomFields)
+	}
+
+	// Every problem is reported at once.
+	_, err := s.Create(ctx, CreateInput{
+		FirstName: "Bob", LastName: "Smith", Email: "bob@example.com", Department: "Engineering", Position: "Engineer",
+		CustomFields: map[string]any{"badge": "ab1234", "floor": "three", "quota": 10, "desk": "4A"},
+	})
+	want := map[string]string{
+		"custom_fields.badge": "string",
+		"custom_fields.floor": "number",
+		"custom_fields.quota": "department",
+		"custom_fields.desk":  "unknown",
+	}
+	if got := fieldRules(t, err); len(got) != len(want) {
+		t.Errorf("rules = %v, want %v", got, want)
+	} else {
+		for field, rule := range want {
+			if got[field] != rule {
+				t.Errorf("%s: rule = %q, want %q", field, got[field], rule)
+			}
+		}
+	}
+
+	// Required fields are checked for the employee's department.
+	_, err = s.Create(ctx, CreateInput{
+		FirstName: "Cy", LastName: "Jones", Email: "cy@example.com", Department: "Sales", Position: "Rep",
+		CustomFields: map[string]any{"badge": "CJ0001"},
+	})
+	if got := fieldRules(t, err); len(got) != 1 || got["custom_fields.quota"] != "required" {
+		t.Errorf("rules = %v, want quota required", got)
+	}
+	_, err = s.Create(ctx, CreateInput{
+		FirstName: "Dee", LastName: "Brown", Email: "dee@example.com", Department: "Engineering", Position: "Engineer",
+	})
+	if got := fieldRules(t, err); got["custom_fields.badge"] != "required" {
+		t.Errorf("rules = %v, want badge required", got)
+	}
+}
+
+func TestUpdateCustomFields(t *testing.T) {
+	ctx, s := newCustomFieldService(t)
+	e := create(t, ctx, s, CreateInput{
+		FirstName: "Ada", LastName: "Lovelace", Email: "ada@example.com",
+		CustomFields: map[string]any{"badge": "AB1234", "floor": 3},
+	})
+
+	// Only the named fields change, and nil clears one.
+	got, err := s.Update(ctx, e.ID, UpdateInput{CustomFields: map[string]any{"floor": nil, "remote": "true"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.CustomFields) != 2 || got.CustomFields["badge"] != "AB1234" || got.CustomFields["remote"] != true {
+		t.Errorf("custom fields = %#v", got.CustomFields)
+	}
+
+	_, err = s.Update(ctx, e.ID, UpdateInput{CustomFields: map[string]any{"badge": nil}})
+	if rules := fieldRules(t, err); rules["custom_fields.badge"] != "required" {
+		t.Errorf("cleared a required field: rules = %v", rules)
+	}
+
+	// Moving to Sales needs its required fields.
+	sales := "Sales"
+	_, err = s.Update(ctx, e.ID, UpdateInput{Department: &sales})
+	if rules := fieldRules(t, err); rules["custom_fields.quota"] != "required" {
+		t.Errorf("moved without a quota: rules = %v", rules)
+	}
+	got, err = s.Update(ctx, e.ID, UpdateInput{Department: &sales, CustomFields: map[string]any{"quota": 50000}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.CustomFields["quota"] != 50000.0 {
+		t.Errorf("custom fields = %#v", got.CustomFields)
+	}
+
+	// Moving back drops the fields that no longer apply.
+	engineering := "Engineering"
+	got, err = s.Update(ctx, e.ID, UpdateInput{Department: &engineering})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := got.CustomFields["quota"]; ok {
+		t.Errorf("quota kept outside Sales: %#v", got.CustomFields)
+	}
+}
+
+func TestListCustomFieldFilter(t *testing.T) {
+	ctx, s := newCustomFieldService(t)
+	for _, in := range []CreateInput{
+		{FirstName: "Ada", Email: "ada@example.com", CustomFields: map[string]any{"badge": "AA0001", "floor": 3, "remote": true}},
+		{FirstName: "Bob", Email: "bob@example.com", CustomFields: map[string]any{"badge": "BB0002", "floor": 3}},
+		{FirstName: "Cy", Email: "cy@example.com", CustomFields: map[string]any{"badge": "CC0003", "floor": 4, "remote": true}},
+		{FirstName: "Dee", Email: "dee@example.com", CustomFields: map[string]any{"badge": "DD0004"}},
+	} {
+		in.LastName = "Test"
+		create(t, ctx, s, in)
+	}
+
+	tests := []struct {
+		filter map[string]string
+		want   []string
+	}{
+		// Query string values match by their field's type.
+		{map[string]string{"floor": "3"}, []string{"ada@example.com", "bob@example.com"}},
+		{map[string]string{"floor": "3.0"}, []string{"ada@example.com", "bob@example.com"}},
+		{map[string]string{"floor": "3", "remote": "true"}, []string{"ada@example.com"}},
+		{map[string]string{"badge": "DD0004"}, []string{"dee@example.com"}},
+		{map[string]string{"remote": "false"}, nil},
+	}
+	for _, tt := range tests {
+		list, total, err := s.List(ctx, ListInput{CustomFields: tt.filter})
+		if err != nil {
+			t.Errorf("%v: %v", tt.filter, err)
+			continue
+		}
+		got := map[string]bool{}
+		for _, e := range list {
+			got[e.Email] = true
+		}
+		if int(total) != len(tt.want) || len(got) != len(tt.want) {
+			t.Errorf("%v: got %v (total %d), want %v", tt.filter, got, total, tt.want)
+			continue
+		}
+		for _, email := range tt.want {
+			if !got[email] {
+				t.Errorf("%v: %s missing from %v", tt.filter, email, got)
+			}
+		}
+	}
+
+	for filter, rule := range map[string]map[string]string{
+		"floor=three": {"floor": "three"},
+		"desk=4A":     {"desk": "4A"},
+	} {
+		_, _, err := s.List(ctx, ListInput{CustomFields: rule})
+		if rules := fieldRules(t, err); len(rules) != 1 {
+			t.Errorf("%s: rules = %v", filter, rules)
+		}
+	}
+}
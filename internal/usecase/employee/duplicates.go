@@ -0,0 +1,317 @@
+package employee
+
+import (
+	"context"
+	"maps"
+	"sort"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/rohitashk/golang-rest-api/internal/domain"
+	domainEmployee "github.com/rohitashk/golang-rest-api/internal/domain/employee"
+	"github.com/rohitashk/golang-rest-api/internal/domain/event"
+	"github.com/rohitashk/golang-rest-api/internal/search"
+	"github.com/rohitashk/golang-rest-api/internal/validation"
+)
+
+const (
+	defaultMinDuplicateScore = 0.6
+	// maxDuplicateBlock skips blocks of employees sharing a key that many
+	// people share, like a switchboard number; comparing them all costs
+	// more than it finds.
+	maxDuplicateBlock = 100
+	// duplicateLoadBatch is how many employees of the blocks are read at a
+	// time.
+	duplicateLoadBatch = 500
+)
+
+// Weights of the duplicate score. Equal names alone reach the default
+// minimum; a phone number or date of birth in common makes up for names
+// written differently, and different dates of birth rule most pairs out.
+const (
+	nameWeight        = 0.6
+	phoneWeight       = 0.25
+	dateOfBirthWeight = 0.15
+	dateOfBirthClash  = 0.3
+)
+
+type DuplicatesInput struct {
+	MinScore float64 `json:"min_score" validate:"gte=0,lte=1"` // zero means 0.6
+	Limit    int64   `json:"limit"`
+}
+
+// Duplicate is a pair of employees that may be the same person, the one
+// created first first. Name is how alike their names are, from 0 to 1.
+type Duplicate struct {
+	Employees   [2]domainEmployee.Employee
+	Score       float64
+	Name        float64
+	Phone       bool
+	DateOfBirth bool
+}
+
+// Duplicates finds pairs of employees that may be the same person entered
+// twice, usually under two email addresses, best matches first. Only
+// employees sharing a phone number, a date of birth, or a last name and
+// first initial (either way round) are compared.
+func (s *Service) Duplicates(ctx context.Context, in DuplicatesInput) (_ []Duplicate, err error) {
+	ctx, span := startSpan(ctx, "Duplicates")
+	defer span.end(&err)
+
+	if err := s.validate.Struct(in); err != nil {
+		return nil, validation.Error(err)
+	}
+	if in.MinScore == 0 {
+		in.MinScore = defaultMinDuplicateScore
+	}
+	if in.Limit <= 0 || in.Limit > 200 {
+		in.Limit = 20
+	}
+
+	blocks, err := s.repo.DuplicateBlocks(ctx, maxDuplicateBlock)
+	if err != nil {
+		return nil, err
+	}
+	employees, err := s.loadBlocked(ctx, blocks)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []Duplicate
+	compared := map[[2]string]bool{}
+	for _, block := range blocks {
+		for x, i := range block {
+			for _, j := range block[x+1:] {
+				a, okA := employees[i]
+				b, okB := employees[j]
+				pair := [2]string{min(i, j), max(i, j)}
+				if !okA || !okB || compared[pair] {
+					// Deleted since the blocks were made, or seen in another block.
+					continue
+				}
+				compared[pair] = true
+				if d := compare(a, b); d.Score >= in.MinScore {
+					out = append(out, d)
+				}
+			}
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Employees[0].ID != b.Employees[0].ID {
+			return a.Employees[0].ID < b.Employees[0].ID
+		}
+		return a.Employees[1].ID < b.Employees[1].ID
+	})
+	if int64(len(out)) > in.Limit {
+		out = out[:in.Limit]
+	}
+	span.SetAttributes(attribute.Int("duplicates.employees", len(employees)), attribute.Int("duplicates.pairs", len(compared)))
+	return out, nil
+}
+
+// loadBlocked reads the employees of blocks, by ID.
+func (s *Service) loadBlocked(ctx context.Context, blocks [][]string) (map[string]*domainEmployee.Employee, error) {
+	var ids []string
+	seen := map[string]bool{}
+	for _, block := range blocks {
+		for _, id := range block {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	out := make(map[string]*domainEmployee.Employee, len(ids))
+	for start := 0; start < len(ids); start += duplicateLoadBatch {
+		batch := ids[start:min(start+duplicateLoadBatch, len(ids))]
+		list, _, err := s.repo.List(ctx, domainEmployee.ListFilter{IDs: batch}, domainEmployee.ListPage{})
+		if err != nil {
+			return nil, err
+		}
+		for i := range list {
+			out[list[i].ID] = &list[i]
+		}
+	}
+	return out, nil
+}
+
+func compare(a, b *domainEmployee.Employee) Duplicate {
+	if b.CreatedAt.Before(a.CreatedAt) || (b.CreatedAt.Equal(a.CreatedAt) && b.ID < a.ID) {
+		a, b = b, a
+	}
+	d := Duplicate{Employees: [2]domainEmployee.Employee{*a, *b}}
+
+	nameKey := domainEmployee.NameKey
+	nameA := nameKey(a.FirstName) + " " + nameKey(a.LastName)
+	d.Name = max(
+		search.Similarity(nameA, nameKey(b.FirstName)+" "+nameKey(b.LastName)),
+		search.Similarity(nameA, nameKey(b.LastName)+" "+nameKey(b.FirstName)),
+	)
+	d.Phone = a.Phone != "" && a.Phone == b.Phone
+	d.DateOfBirth = a.DateOfBirth != "" && a.DateOfBirth == b.DateOfBirth
+
+	d.Score = nameWeight * d.Name
+	if d.Phone {
+		d.Score += phoneWeight
+	}
+	switch {
+	case d.DateOfBirth:
+		d.Score += dateOfBirthWeight
+	case a.DateOfBirth != "" && b.DateOfBirth != "":
+		d.Score = max(0, d.Score-dateOfBirthClash)
+	}
+	return d
+}
+
+// Merge folds the employee duplicateID into id and deletes it. The survivor
+// keeps its own values and takes the duplicate's phone number, date of
+// birth, manager and custom fields where it has none. The duplicate's
+// reports move to the survivor. Its recorded events are kept; its deletion
+// names the survivor, and the survivor records an employee.merged event.
+func (s *Service) Merge(ctx context.Context, id, duplicateID string) (_ *domainEmployee.Employee, err error) {
+	ctx, span := startSpan(ctx, "Merge", idAttr(id), attribute.String("employee.duplicate_id", duplicateID))
+	defer span.end(&err)
+
+	if duplicateID == "" || duplicateID == id {
+		return nil, domain.InvalidFields("invalid merge", []domain.FieldError{{
+			Field: "duplicate_id", Rule: "required", Message: "duplicate_id must name another employee",
+		}})
+	}
+	e, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	d, err := s.repo.GetByID(ctx, duplicateID)
+	if err != nil {
+		return nil, err
+	}
+	if d == nil {
+		return nil, domain.NotFound("duplicate employee not found")
+	}
+	before := *e
+
+	if e.Phone == "" {
+		e.Phone = d.Phone
+	}
+	if e.DateOfBirth == "" {
+		e.DateOfBirth = d.DateOfBirth
+	}
+	if e.ManagerID == "" || e.ManagerID == d.ID {
+		// The duplicate's manager may be below the survivor, when taking
+		// it would make a cycle; the survivor then has none.
+		e.ManagerID = ""
+		cycle, err := s.reachesMerged(ctx, d.ManagerID, e.ID, d.ID)
+		if err != nil {
+			return nil, err
+		}
+		if !cycle {
+			e.ManagerID = d.ManagerID
+		}
+	}
+	if err := s.mergeCustomFields(ctx, e, d); err != nil {
+		return nil, err
+	}
+
+	now := s.now().UTC()
+	e.UpdatedAt = now
+	var moved int
+	err = s.withinTx(ctx, func(ctx context.Context) error {
+		moved = 0 // the transaction may be retried
+		if err := s.repo.Update(ctx, e, before.UpdatedAt); err != nil {
+			return err
+		}
+		// Read in the transaction, so an employee assigned to the duplicate
+		// meanwhile is moved too rather than left with a deleted manager.
+		reports, _, err := s.repo.List(ctx, domainEmployee.ListFilter{ManagerID: &d.ID}, domainEmployee.ListPage{})
+		if err != nil {
+			return err
+		}
+		for i := range reports {
+			r := &reports[i]
+			if r.ID == e.ID {
+				continue
+			}
+			old := *r
+			r.ManagerID = e.ID
+			r.UpdatedAt = now
+			if err := s.repo.Update(ctx, r, old.UpdatedAt); err != nil {
+				return err
+			}
+			if err := s.record(ctx, event.EmployeeUpdated{Employee: event.Snapshot(r), Changes: event.Diff(&old, r)}); err != nil {
+				return err
+			}
+			moved++
+		}
+		if err := s.repo.Delete(ctx, d.ID); err != nil {
+			return err
+		}
+		if err := s.record(ctx, event.EmployeeDeleted{Employee: event.Snapshot(d), MergedInto: e.ID}); err != nil {
+			return err
+		}
+		return s.record(ctx, event.EmployeeMerged{
+			Employee:  event.Snapshot(e),
+			Duplicate: event.Snapshot(d),
+			Changes:   event.Diff(&before, e),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("employee.reports_moved", moved))
+	return e, nil
+}
+
+// reachesMerged reports whether the reporting line from managerID up reaches
+// id or duplicateID, whose reports are about to report to id.
+func (s *Service) reachesMerged(ctx context.Context, managerID, id, duplicateID string) (bool, error) {
+	cur := managerID
+	for depth := 0; cur != "" && depth < maxManagerChain; depth++ {
+		if cur == id || cur == duplicateID {
+			return true, nil
+		}
+		m, err := s.repo.GetByID(ctx, cur, domainEmployee.FieldManagerID)
+		if err != nil || m == nil {
+			return false, err
+		}
+		cur = m.ManagerID
+	}
+	return false, nil
+}
+
+// mergeCustomFields gives e the custom fields of d it has no value for,
+// where they apply to e's department.
+func (s *Service) mergeCustomFields(ctx context.Context, e, d *domainEmployee.Employee) error {
+	if len(d.CustomFields) == 0 {
+		return nil
+	}
+	defs, err := s.definitions(ctx)
+	if err != nil {
+		return err
+	}
+	values := maps.Clone(e.CustomFields)
+	if values == nil {
+		values = map[string]any{}
+	}
+	for _, key := range sortedKeys(d.CustomFields) {
+		def, ok := defs[key]
+		if _, has := values[key]; has || !ok || !def.AppliesTo(e.Department) {
+			continue
+		}
+		v, err := def.Value(d.CustomFields[key])
+		if err != nil {
+			// Stored before the definition changed; it no longer fits.
+			continue
+		}
+		values[key] = v
+	}
+	if len(values) > 0 {
+		e.CustomFields = values
+	}
+	return nil
+}
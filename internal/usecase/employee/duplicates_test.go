@@ -0,0 +1,255 @@
+package employee
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/rohitashk/golang-rest-api/internal/adapters/memory"
+	"github.com/rohitashk/golang-rest-api/internal/domain"
+	domainEmployee "github.com/rohitashk/golang-rest-api/internal/domain/employee"
+	"github.com/rohitashk/golang-rest-api/internal/domain/event"
+	"github.com/rohitashk/golang-rest-api/internal/domain/tenant"
+)
+
+// newTestService returns a service of the tenant acme on memory stores
+// whose clock moves a second per call, so employees created one after the
+// other are ordered.
+func newTestService(t *testing.T) (context.Context, *Service, *memory.Outbox) {
+	t.Helper()
+	outbox := memory.NewOutbox()
+	s := NewService(Deps{Repo: memory.NewEmployeeRepository(), Outbox: outbox})
+	clock := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	s.now = func() time.Time {
+		clock = clock.Add(time.Second)
+		return clock
+	}
+	return tenant.WithID(context.Background(), "acme"), s, outbox
+}
+
+func create(t *testing.T, ctx context.Context, s *Service, in CreateInput) *domainEmployee.Employee {
+	t.Helper()
+	if in.Department == "" {
+		in.Department = "Engineering"
+	}
+	if in.Position == "" {
+		in.Position = "Engineer"
+	}
+	e, err := s.Create(ctx, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func TestCompareScores(t *testing.T) {
+	alice := &domainEmployee.Employee{ID: "a", FirstName: "Alice", LastName: "O'Neil"}
+	tests := []struct {
+		name  string
+		other domainEmployee.Employee
+		a, b  string // dates of birth
+		phone bool
+		want  float64
+	}{
+		{name: "same name", other: domainEmployee.Employee{FirstName: "alice", LastName: "ONeil"}, want: nameWeight},
+		{name: "swapped", other: domainEmployee.Employee{FirstName: "ONeil", LastName: "Alice"}, want: nameWeight},
+		{name: "same phone", other: domainEmployee.Employee{FirstName: "Alice", LastName: "ONeil"}, phone: true, want: nameWeight + phoneWeight},
+		{name: "same birth date", other: domainEmployee.Employee{FirstName: "Alice", LastName: "ONeil"}, a: "1990-01-02", b: "1990-01-02", want: nameWeight + dateOfBirthWeight},
+		{name: "birth dates clash", other: domainEmployee.Employee{FirstName: "Alice", LastName: "ONeil"}, a: "1990-01-02", b: "1991-01-02", want: nameWeight - dateOfBirthClash},
+		{name: "one birth date", other: domainEmployee.Employee{FirstName: "Alice", LastName: "ONeil"}, a: "1990-01-02", want: nameWeight},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := *alice, tt.other
+			b.ID = "b"
+			a.DateOfBirth, b.DateOfBirth = tt.a, tt.b
+			if tt.phone {
+				a.Phone, b.Phone = "+14155550101", "+14155550101"
+			}
+			d := compare(&a, &b)
+			if math.Abs(d.Score-tt.want) > 1e-9 {
+				t.Errorf("score = %v, want %v", d.Score, tt.want)
+			}
+			if d.Phone != tt.phone || d.DateOfBirth != (tt.a != "" && tt.a == tt.b) {
+				t.Errorf("phone, birth date = %v, %v", d.Phone, d.DateOfBirth)
+			}
+		})
+	}
+
+	d := compare(&domainEmployee.Employee{FirstName: "Alice", LastName: "Smith"}, &domainEmployee.Employee{FirstName: "Bob", LastName: "Jones"})
+	if d.Score >= defaultMinDuplicateScore {
+		t.Errorf("different names score %v", d.Score)
+	}
+}
+
+func TestCompareOrdersByCreation(t *testing.T) {
+	now := time.Now()
+	older := &domainEmployee.Employee{ID: "2", FirstName: "A", LastName: "B", CreatedAt: now}
+	newer := &domainEmployee.Employee{ID: "1", FirstName: "A", LastName: "B", CreatedAt: now.Add(time.Second)}
+	d := compare(newer, older)
+	if d.Employees[0].ID != "2" || d.Employees[1].ID != "1" {
+		t.Errorf("order = %s, %s; want the older first", d.Employees[0].ID, d.Employees[1].ID)
+	}
+}
+
+func TestDuplicates(t *testing.T) {
+	ctx, s, _ := newTestService(t)
+	alice := create(t, ctx, s, CreateInput{FirstName: "Alice", LastName: "Smith", Email: "alice@example.com", Phone: "+14155550101", DateOfBirth: "1990-01-02"})
+	swapped := create(t, ctx, s, CreateInput{FirstName: "Smith", LastName: "Alice", Email: "asmith@example.com", DateOfBirth: "1990-01-02"})
+	typo := create(t, ctx, s, CreateInput{FirstName: "Alise", LastName: "Smyth", Email: "alise@example.com", Phone: "+14155550101"})
+	create(t, ctx, s, CreateInput{FirstName: "Bob", LastName: "Jones", Email: "bob@example.com"})
+	create(t, ctx, s, CreateInput{FirstName: "Alice", LastName: "Smith", Email: "alice2@example.com", DateOfBirth: "1985-05-05"})
+
+	got, err := s.Duplicates(ctx, DuplicatesInput{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pairs := map[[2]string]float64{}
+	for i, d := range got {
+		if d.Score < defaultMinDuplicateScore {
+			t.Errorf("pair %d scores %v, below the minimum", i, d.Score)
+		}
+		if i > 0 && d.Score > got[i-1].Score {
+			t.Errorf("pair %d scores more than the one before", i)
+		}
+		pairs[[2]string{d.Employees[0].ID, d.Employees[1].ID}] = d.Score
+	}
+	if _, ok := pairs[[2]string{alice.ID, swapped.ID}]; !ok {
+		t.Errorf("names swapped not found in %v", pairs)
+	}
+	if _, ok := pairs[[2]string{alice.ID, typo.ID}]; !ok {
+		t.Errorf("misspelt name with the same phone not found in %v", pairs)
+	}
+	for pair := range pairs {
+		for _, id := range pair {
+			if id != alice.ID && id != swapped.ID && id != typo.ID {
+				t.Errorf("unexpected pair %v", pair)
+			}
+		}
+	}
+
+	top, err := s.Duplicates(ctx, DuplicatesInput{Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(top) != 1 || top[0].Score != got[0].Score {
+		t.Errorf("limit 1 = %+v", top)
+	}
+	strict, err := s.Duplicates(ctx, DuplicatesInput{MinScore: 0.9})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range strict {
+		if d.Score < 0.9 {
+			t.Errorf("score %v below min_score", d.Score)
+		}
+	}
+	if len(strict) >= len(got) {
+		t.Errorf("min_score 0.9 kept %d of %d pairs", len(strict), len(got))
+	}
+}
+
+func TestDuplicatesSkipsLargeBlocks(t *testing.T) {
+	repo := memory.NewEmployeeRepository()
+	ctx := tenant.WithID(context.Background(), "acme")
+	for i := 0; i <= maxDuplicateBlock; i++ {
+		e := &domainEmployee.Employee{FirstName: "Shared", LastName: "Phone", Email: fmt.Sprintf("e%d@example.com", i), Phone: "+14155550100"}
+		if err := repo.Create(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	blocks, err := repo.DuplicateBlocks(ctx, maxDuplicateBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks) != 0 {
+		t.Errorf("%d blocks of %d employees sharing every key, want none", len(blocks), len(blocks[0]))
+	}
+}
+
+func TestMerge(t *testing.T) {
+	ctx, s, outbox := newTestService(t)
+	boss := create(t, ctx, s, CreateInput{FirstName: "Carol", LastName: "Boss", Email: "carol@example.com"})
+	survivor := create(t, ctx, s, CreateInput{FirstName: "Alice", LastName: "Smith", Email: "alice@example.com"})
+	dup := create(t, ctx, s, CreateInput{FirstName: "Alice", LastName: "Smith", Email: "asmith@example.com", Phone: "+14155550101", DateOfBirth: "1990-01-02", ManagerID: boss.ID})
+	report := create(t, ctx, s, CreateInput{FirstName: "Dan", LastName: "Report", Email: "dan@example.com", ManagerID: dup.ID})
+
+	merged, err := s.Merge(ctx, survivor.ID, dup.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.Email != "alice@example.com" || merged.Phone != "+14155550101" || merged.DateOfBirth != "1990-01-02" || merged.ManagerID != boss.ID {
+		t.Errorf("merged = %+v", merged)
+	}
+	if d, _ := s.repo.GetByID(ctx, dup.ID); d != nil {
+		t.Error("duplicate not deleted")
+	}
+	r, err := s.Get(ctx, report.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.ManagerID != survivor.ID {
+		t.Errorf("report's manager = %q, want the survivor", r.ManagerID)
+	}
+
+	history, err := outbox.History(ctx, dup.ID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := history[len(history)-1]
+	var deleted event.EmployeeDeleted
+	if err := json.Unmarshal(last.Payload, &deleted); err != nil {
+		t.Fatal(err)
+	}
+	if last.Type != event.TypeEmployeeDeleted || deleted.MergedInto != survivor.ID {
+		t.Errorf("duplicate's last event = %s merged into %q", last.Type, deleted.MergedInto)
+	}
+	history, err = outbox.History(ctx, survivor.ID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last := history[len(history)-1]; last.Type != event.TypeEmployeeMerged {
+		t.Errorf("survivor's last event = %s", last.Type)
+	}
+	history, err = outbox.History(ctx, report.ID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last := history[len(history)-1]; last.Type != event.TypeEmployeeUpdated {
+		t.Errorf("report's last event = %s", last.Type)
+	}
+}
+
+func TestMergeKeepsSurvivorValues(t *testing.T) {
+	ctx, s, _ := newTestService(t)
+	survivor := create(t, ctx, s, CreateInput{FirstName: "Alice", LastName: "Smith", Email: "alice@example.com", Phone: "+14155550199"})
+	dup := create(t, ctx, s, CreateInput{FirstName: "Alice", LastName: "Smith", Email: "asmith@example.com", Phone: "+14155550101", ManagerID: survivor.ID})
+
+	merged, err := s.Merge(ctx, survivor.ID, dup.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.Phone != "+14155550199" {
+		t.Errorf("phone = %q, want the survivor's", merged.Phone)
+	}
+	if merged.ManagerID != "" {
+		t.Errorf("manager = %q; taking the duplicate's would make the survivor its own manager", merged.ManagerID)
+	}
+}
+
+func TestMergeRejects(t *testing.T) {
+	ctx, s, _ := newTestService(t)
+	alice := create(t, ctx, s, CreateInput{FirstName: "Alice", LastName: "Smith", Email: "alice@example.com"})
+
+	var derr domain.Error
+	if _, err := s.Merge(ctx, alice.ID, alice.ID); !errors.As(err, &derr) || derr.Kind != domain.ErrKindValidation {
+		t.Errorf("merge into itself: %v", err)
+	}
+	if _, err := s.Merge(ctx, alice.ID, "missing"); !errors.As(err, &derr) || derr.Kind != domain.ErrKindNotFound {
+		t.Errorf("merge of a missing duplicate: %v", err)
+	}
+}
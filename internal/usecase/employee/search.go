@@ -0,0 +1,123 @@
+package employee
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/rohitashk/golang-rest-api/internal/domain"
+	domainEmployee "github.com/rohitashk/golang-rest-api/internal/domain/employee"
+	"github.com/rohitashk/golang-rest-api/internal/search"
+	"github.com/rohitashk/golang-rest-api/internal/validation"
+)
+
+const (
+	maxSearchTerms = 10
+	// searchCandidates is how many candidates are ranked per search.
+	// Searchers return the best first, so only matches about as good as
+	// the last one are missed beyond it.
+	searchCandidates = 500
+)
+
+// SearchInput is a full-text search over names, email, department and
+// position. Prefix lets the last word of Query match the start of a longer
+// word, for search-as-you-type; Fuzzy lets words match names with typos.
+type SearchInput struct {
+	Query      string  `json:"q" validate:"required,max=200"`
+	Prefix     bool    `json:"prefix"`
+	Fuzzy      bool    `json:"fuzzy"`
+	Department *string `json:"department" validate:"omitnil,max=120"`
+	Status     *string `json:"status" validate:"omitnil,oneof=active inactive"`
+	Limit      int64   `json:"limit"`
+}
+
+// SearchResult is a matching employee, best first. Highlights has the
+// matching fields by name; see search.Result.
+type SearchResult struct {
+	Employee   domainEmployee.Employee
+	Score      float64
+	Highlights map[string]string
+}
+
+func (s *Service) Search(ctx context.Context, in SearchInput) (_ []SearchResult, err error) {
+	ctx, span := startSpan(ctx, "Search", attribute.Bool("search.prefix", in.Prefix), attribute.Bool("search.fuzzy", in.Fuzzy))
+	defer span.end(&err)
+
+	if s.search == nil {
+		return nil, domain.Internal("search is not configured", nil)
+	}
+	in.Query = strings.TrimSpace(in.Query)
+	if err := s.validate.Struct(in); err != nil {
+		return nil, validation.Error(err)
+	}
+	q := search.Parse(in.Query, in.Prefix, in.Fuzzy)
+	switch {
+	case len(q.Terms) == 0:
+		return nil, domain.InvalidFields("invalid search", []domain.FieldError{
+			{Field: "q", Rule: "required", Message: "q must contain a letter or digit"},
+		})
+	case len(q.Terms) > maxSearchTerms:
+		return nil, domain.InvalidFields("invalid search", []domain.FieldError{
+			{Field: "q", Rule: "max", Message: "q must have at most 10 words"},
+		})
+	}
+	if in.Limit <= 0 || in.Limit > 50 {
+		in.Limit = 10
+	}
+
+	var status *domainEmployee.Status
+	if in.Status != nil {
+		st := domainEmployee.Status(*in.Status)
+		status = &st
+	}
+	candidates, err := s.search.Search(ctx, domainEmployee.SearchQuery{
+		Terms:      q.Terms,
+		Prefix:     q.Prefix,
+		Fuzzy:      q.Fuzzy,
+		Department: in.Department,
+		Status:     status,
+		Limit:      searchCandidates,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]SearchResult, 0, len(candidates))
+	for _, e := range candidates {
+		if r, ok := search.Rank(q, searchFields(&e)); ok {
+			out = append(out, SearchResult{Employee: e, Score: r.Score, Highlights: r.Highlights})
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Employee.LastName != b.Employee.LastName {
+			return a.Employee.LastName < b.Employee.LastName
+		}
+		if a.Employee.FirstName != b.Employee.FirstName {
+			return a.Employee.FirstName < b.Employee.FirstName
+		}
+		return a.Employee.ID < b.Employee.ID
+	})
+	if int64(len(out)) > in.Limit {
+		out = out[:in.Limit]
+	}
+	span.SetAttributes(attribute.Int("search.candidates", len(candidates)), attribute.Int("search.results", len(out)))
+	return out, nil
+}
+
+// searchFields weighs names over email, and both over department and
+// position, which many employees share. Typos are only forgiven in names.
+func searchFields(e *domainEmployee.Employee) []search.Field {
+	return []search.Field{
+		{Name: domainEmployee.FieldFirstName, Text: e.FirstName, Weight: 3, Fuzzy: true},
+		{Name: domainEmployee.FieldLastName, Text: e.LastName, Weight: 3, Fuzzy: true},
+		{Name: domainEmployee.FieldEmail, Text: e.Email, Weight: 2},
+		{Name: domainEmployee.FieldDepartment, Text: e.Department, Weight: 1},
+		{Name: domainEmployee.FieldPosition, Text: e.Position, Weight: 1},
+	}
+}
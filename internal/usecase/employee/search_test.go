@@ -0,0 +1,177 @@
+package employee
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/rohitashk/golang-rest-api/internal/adapters/memory"
+	"github.com/rohitashk/golang-rest-api/internal/domain/tenant"
+)
+
+// newSearchService returns a service of the tenant acme answering searches
+// from an in-process index.
+func newSearchService(t *testing.T) (context.Context, *Service) {
+	t.Helper()
+	repo := memory.NewEmployeeRepository()
+	index := memory.NewEmployeeIndex(repo, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go index.Follow(ctx, memory.NewBroadcaster(0))
+	return tenant.WithID(ctx, "acme"), NewService(Deps{Repo: repo, Search: index})
+}
+
+// runSearch waits for the index to follow changes before searching.
+func runSearch(t *testing.T, ctx context.Context, s *Service, in SearchInput) []SearchResult {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		out, err := s.Search(ctx, in)
+		if err == nil {
+			return out
+		}
+		if time.Now().After(deadline) {
+			t.Fatal(err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func names(results []SearchResult) []string {
+	out := make([]string, len(results))
+	for i, r := range results {
+		out[i] = r.Employee.FirstName + " " + r.Employee.LastName
+	}
+	return out
+}
+
+func TestSearchRanking(t *testing.T) {
+	ctx, s := newSearchService(t)
+	create(t, ctx, s, CreateInput{FirstName: "Bob", LastName: "Jones", Email: "smith@example.com"})
+	create(t, ctx, s, CreateInput{FirstName: "Ann", LastName: "Smith", Email: "ann@example.com"})
+	create(t, ctx, s, CreateInput{FirstName: "Cy", LastName: "Smithson", Email: "cy@example.com"})
+	create(t, ctx, s, CreateInput{FirstName: "Dee", LastName: "Brown", Email: "dee@example.com", Department: "Smith Works"})
+
+	got := names(runSearch(t, ctx, s, SearchInput{Query: "smith", Prefix: true}))
+	want := []string{"Ann Smith", "Bob Jones", "Cy Smithson", "Dee Brown"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("results = %q, want %q", got, want)
+	}
+
+	got = names(runSearch(t, ctx, s, SearchInput{Query: "smith"}))
+	want = []string{"Ann Smith", "Bob Jones", "Dee Brown"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("without prefix, results = %q, want %q", got, want)
+	}
+
+	got = names(runSearch(t, ctx, s, SearchInput{Query: "smith", Limit: 1}))
+	if len(got) != 1 || got[0] != "Ann Smith" {
+		t.Errorf("limit 1 = %q", got)
+	}
+
+	dept := "Smith Works"
+	got = names(runSearch(t, ctx, s, SearchInput{Query: "smith", Department: &dept}))
+	if len(got) != 1 || got[0] != "Dee Brown" {
+		t.Errorf("in Smith Works = %q", got)
+	}
+}
+
+func TestSearchPrefix(t *testing.T) {
+	ctx, s := newSearchService(t)
+	create(t, ctx, s, CreateInput{FirstName: "Ada", LastName: "Lovelace", Email: "ada@example.com"})
+	create(t, ctx, s, CreateInput{FirstName: "Ada", LastName: "Byron", Email: "byron@example.com"})
+
+	got := names(runSearch(t, ctx, s, SearchInput{Query: "ada lov", Prefix: true}))
+	if len(got) != 1 || got[0] != "Ada Lovelace" {
+		t.Errorf("ada lov = %q", got)
+	}
+	// Only the last term may be a prefix.
+	if got := runSearch(t, ctx, s, SearchInput{Query: "lov ada", Prefix: true}); len(got) != 0 {
+		t.Errorf("lov ada = %q", names(got))
+	}
+	if got := runSearch(t, ctx, s, SearchInput{Query: "ada lov"}); len(got) != 0 {
+		t.Errorf("ada lov without prefix = %q", names(got))
+	}
+}
+
+func TestSearchFuzzy(t *testing.T) {
+	ctx, s := newSearchService(t)
+	create(t, ctx, s, CreateInput{FirstName: "John", LastName: "Smith", Email: "john@example.com"})
+	create(t, ctx, s, CreateInput{FirstName: "Jonathan", LastName: "Doe", Email: "jd@example.com"})
+	create(t, ctx, s, CreateInput{FirstName: "Mark", LastName: "Engineer", Email: "mark@example.com"})
+
+	tests := []struct {
+		query  string
+		prefix bool
+		want   string
+	}{
+		{query: "jhon smiht", want: "John Smith"},
+		{query: "kohn", want: "John Smith"},       // typo in the first letter
+		{query: "dmith", want: "John Smith"},      // typo in the first letter
+		{query: "xonathax", want: "Jonathan Doe"}, // two typos in a long name
+		{query: "jonh", prefix: true, want: "Jonathan Doe"},
+	}
+	for _, tt := range tests {
+		got := names(runSearch(t, ctx, s, SearchInput{Query: tt.query, Prefix: tt.prefix, Fuzzy: true}))
+		found := false
+		for _, name := range got {
+			found = found || name == tt.want
+		}
+		if !found {
+			t.Errorf("%q found %q, want %s", tt.query, got, tt.want)
+		}
+		if got := runSearch(t, ctx, s, SearchInput{Query: tt.query, Prefix: tt.prefix}); len(got) != 0 {
+			t.Errorf("%q without fuzzy found %q", tt.query, names(got))
+		}
+	}
+
+	// Typos are only forgiven in names.
+	if got := runSearch(t, ctx, s, SearchInput{Query: "exmple", Fuzzy: true}); len(got) != 0 {
+		t.Errorf("misspelled email found %q", names(got))
+	}
+	// The whole word ranks above the misspelled one.
+	got := names(runSearch(t, ctx, s, SearchInput{Query: "john", Fuzzy: true}))
+	if len(got) == 0 || got[0] != "John Smith" {
+		t.Errorf("john = %q", got)
+	}
+}
+
+func TestSearchHighlights(t *testing.T) {
+	ctx, s := newSearchService(t)
+	create(t, ctx, s, CreateInput{FirstName: "Ann", LastName: "O'Neil", Email: "ann.oneil@example.com", Position: "Neil's <deputy>"})
+
+	got := runSearch(t, ctx, s, SearchInput{Query: "neil"})
+	if len(got) != 1 {
+		t.Fatalf("results = %q", names(got))
+	}
+	want := map[string]string{
+		"last_name": "O&#39;<em>Neil</em>",
+		"position":  "<em>Neil</em>&#39;s &lt;deputy&gt;",
+	}
+	if len(got[0].Highlights) != len(want) {
+		t.Errorf("highlights = %q, want %q", got[0].Highlights, want)
+	}
+	for name, h := range want {
+		if got[0].Highlights[name] != h {
+			t.Errorf("highlight of %s = %q, want %q", name, got[0].Highlights[name], h)
+		}
+	}
+}
+
+// The best match must reach the ranking even when more employees than it
+// ranks match the query.
+func TestSearchCandidatesBestFirst(t *testing.T) {
+	ctx, s := newSearchService(t)
+	for i := 0; i < searchCandidates; i++ {
+		create(t, ctx, s, CreateInput{FirstName: "Employee", LastName: fmt.Sprintf("Smithers%d", i), Email: fmt.Sprintf("e%d@example.com", i)})
+	}
+	create(t, ctx, s, CreateInput{FirstName: "Ann", LastName: "Smith", Email: "ann@example.com"})
+
+	got := names(runSearch(t, ctx, s, SearchInput{Query: "smith", Prefix: true, Limit: 1}))
+	if len(got) != 1 || got[0] != "Ann Smith" {
+		t.Errorf("results = %q, want Ann Smith", got)
+	}
+}
@@ -2,61 +2,122 @@ package employee
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/go-playground/validator/v10"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/rohitashk/golang-rest-api/internal/domain"
+	"github.com/rohitashk/golang-rest-api/internal/domain/customfield"
 	domainEmployee "github.com/rohitashk/golang-rest-api/internal/domain/employee"
+	"github.com/rohitashk/golang-rest-api/internal/domain/event"
+	"github.com/rohitashk/golang-rest-api/internal/domain/tenant"
+	"github.com/rohitashk/golang-rest-api/internal/validation"
 )
 
+// json tags name the fields in validation errors; they match the HTTP API.
 type CreateInput struct {
-	FirstName  string  `validate:"required,min=1,max=100"`
-	LastName   string  `validate:"required,min=1,max=100"`
-	Email      string  `validate:"required,email,max=320"`
-	Department string  `validate:"required,min=1,max=120"`
-	Position   string  `validate:"required,min=1,max=120"`
-	Salary     float64 `validate:"gte=0,lte=1000000000"`
-	Status     string  `validate:"omitempty,oneof=active inactive"`
+	FirstName    string         `json:"first_name" validate:"required,min=1,max=100"`
+	LastName     string         `json:"last_name" validate:"required,min=1,max=100"`
+	Email        string         `json:"email" validate:"required,email,max=320"`
+	Phone        string         `json:"phone" validate:"omitempty,e164"`
+	DateOfBirth  string         `json:"date_of_birth" validate:"omitempty,datetime=2006-01-02"`
+	Department   string         `json:"department" validate:"required,min=1,max=120"`
+	Position     string         `json:"position" validate:"required,min=1,max=120"`
+	Salary       float64        `json:"salary" validate:"gte=0,lte=1000000000"`
+	Status       string         `json:"status" validate:"omitempty,oneof=active inactive"`
+	ManagerID    string         `json:"manager_id" validate:"omitempty,max=64"`
+	CustomFields map[string]any `json:"custom_fields"`
 }
 
+// UpdateInput fields left nil are not changed. A non-nil field is validated
+// like its CreateInput counterpart, so required fields cannot be blanked.
+// Phone and DateOfBirth are cleared by "". CustomFields only changes the
+// fields it names; a nil value clears one.
 type UpdateInput struct {
-	FirstName  *string  `validate:"omitempty,min=1,max=100"`
-	LastName   *string  `validate:"omitempty,min=1,max=100"`
-	Email      *string  `validate:"omitempty,email,max=320"`
-	Department *string  `validate:"omitempty,min=1,max=120"`
-	Position   *string  `validate:"omitempty,min=1,max=120"`
-	Salary     *float64 `validate:"omitempty,gte=0,lte=1000000000"`
-	Status     *string  `validate:"omitempty,oneof=active inactive"`
+	FirstName    *string        `json:"first_name" validate:"omitnil,min=1,max=100"`
+	LastName     *string        `json:"last_name" validate:"omitnil,min=1,max=100"`
+	Email        *string        `json:"email" validate:"omitnil,email,max=320"`
+	Phone        *string        `json:"phone" validate:"omitnil,e164|len=0"`
+	DateOfBirth  *string        `json:"date_of_birth" validate:"omitnil,datetime=2006-01-02|len=0"`
+	Department   *string        `json:"department" validate:"omitnil,min=1,max=120"`
+	Position     *string        `json:"position" validate:"omitnil,min=1,max=120"`
+	Salary       *float64       `json:"salary" validate:"omitnil,gte=0,lte=1000000000"`
+	Status       *string        `json:"status" validate:"omitnil,oneof=active inactive"`
+	ManagerID    *string        `json:"manager_id" validate:"omitnil,max=64"` // "" removes the manager
+	CustomFields map[string]any `json:"custom_fields"`
+
+	// IfUpdatedAt makes the update conditional: it fails with a conflict
+	// unless the employee was last updated at this time, as when it was read.
+	IfUpdatedAt *time.Time `json:"-"`
 }
 
 type ListInput struct {
-	Department *string
-	Status     *string
-	Query      *string
-	Limit      int64
-	Offset     int64
+	Department   *string
+	Status       *string
+	ManagerID    *string
+	Email        *string
+	Query        *string
+	CustomFields map[string]string // values in text form, as in a query string
+	Limit        int64
+	Offset       int64
+	Fields       []string
+}
+
+// maxManagerChain bounds the walk up the reporting line when checking for
+// cycles.
+const maxManagerChain = 64
+
+type Deps struct {
+	Repo domainEmployee.Repository
+
+	// Outbox receives the domain events of every write. When nil no events
+	// are recorded.
+	Outbox event.Outbox
+	// Tx makes a write and its events atomic. When nil they are written
+	// one after the other.
+	Tx domain.Transactor
+	// CustomFields defines the custom fields employees may have. When nil
+	// they have none.
+	CustomFields customfield.Repository
+	// Search finds candidates for Search. When nil Search fails.
+	Search domainEmployee.Searcher
 }
 
 type Service struct {
 	repo     domainEmployee.Repository
+	outbox   event.Outbox
+	tx       domain.Transactor
+	fields   customfield.Repository
+	search   domainEmployee.Searcher
 	validate *validator.Validate
 	now      func() time.Time
 }
 
-func NewService(repo domainEmployee.Repository) *Service {
+func NewService(deps Deps) *Service {
 	return &Service{
-		repo:     repo,
-		validate: validator.New(),
+		repo:     deps.Repo,
+		outbox:   deps.Outbox,
+		tx:       deps.Tx,
+		fields:   deps.CustomFields,
+		search:   deps.Search,
+		validate: validation.New(),
 		now:      time.Now,
 	}
 }
 
-func (s *Service) Create(ctx context.Context, in CreateInput) (*domainEmployee.Employee, error) {
+func (s *Service) Create(ctx context.Context, in CreateInput) (_ *domainEmployee.Employee, err error) {
+	ctx, span := startSpan(ctx, "Create")
+	defer span.end(&err)
+
 	in.Email = strings.TrimSpace(strings.ToLower(in.Email))
+	in.Phone = normalizePhone(in.Phone)
 	if err := s.validate.Struct(in); err != nil {
-		return nil, domain.Validation(err.Error())
+		return nil, validation.Error(err)
 	}
 
 	existing, err := s.repo.GetByEmail(ctx, in.Email)
@@ -66,6 +127,9 @@ func (s *Service) Create(ctx context.Context, in CreateInput) (*domainEmployee.E
 	if existing != nil {
 		return nil, domain.Conflict("employee with this email already exists")
 	}
+	if err := s.checkManager(ctx, "", in.ManagerID); err != nil {
+		return nil, err
+	}
 
 	status := domainEmployee.StatusActive
 	if in.Status != "" {
@@ -74,25 +138,44 @@ func (s *Service) Create(ctx context.Context, in CreateInput) (*domainEmployee.E
 
 	now := s.now().UTC()
 	e := &domainEmployee.Employee{
-		FirstName:  in.FirstName,
-		LastName:   in.LastName,
-		Email:      in.Email,
-		Department: in.Department,
-		Position:   in.Position,
-		Salary:     in.Salary,
-		Status:     status,
-		CreatedAt:  now,
-		UpdatedAt:  now,
-	}
-
-	if err := s.repo.Create(ctx, e); err != nil {
+		FirstName:   in.FirstName,
+		LastName:    in.LastName,
+		Email:       in.Email,
+		Phone:       in.Phone,
+		DateOfBirth: in.DateOfBirth,
+		Department:  in.Department,
+		Position:    in.Position,
+		Salary:      in.Salary,
+		Status:      status,
+		ManagerID:   in.ManagerID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.setCustomFields(ctx, e, in.CustomFields, true); err != nil {
+		return nil, err
+	}
+
+	err = s.withinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, e); err != nil {
+			return err
+		}
+		return s.record(ctx, event.EmployeeCreated{Employee: event.Snapshot(e)})
+	})
+	if err != nil {
 		return nil, err
 	}
 	return e, nil
 }
 
-func (s *Service) Get(ctx context.Context, id string) (*domainEmployee.Employee, error) {
-	e, err := s.repo.GetByID(ctx, id)
+func (s *Service) Get(ctx context.Context, id string, fields ...string) (_ *domainEmployee.Employee, err error) {
+	ctx, span := startSpan(ctx, "Get", idAttr(id))
+	defer span.end(&err)
+
+	if err := validateFields(fields); err != nil {
+		return nil, err
+	}
+
+	e, err := s.repo.GetByID(ctx, id, fields...)
 	if err != nil {
 		return nil, err
 	}
@@ -102,7 +185,123 @@ func (s *Service) Get(ctx context.Context, id string) (*domainEmployee.Employee,
 	return e, nil
 }
 
-func (s *Service) List(ctx context.Context, in ListInput) ([]domainEmployee.Employee, int64, error) {
+// GetMany returns the employees with the given IDs; unknown IDs are skipped.
+func (s *Service) GetMany(ctx context.Context, ids []string, fields ...string) (_ []domainEmployee.Employee, err error) {
+	ctx, span := startSpan(ctx, "GetMany", attribute.Int("employee.ids", len(ids)))
+	defer span.end(&err)
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	if err := validateFields(fields); err != nil {
+		return nil, err
+	}
+
+	page := domainEmployee.ListPage{Limit: int64(len(ids))}
+	items, _, err := s.repo.List(ctx, domainEmployee.ListFilter{IDs: ids}, page, fields...)
+	return items, err
+}
+
+// Departments returns the named departments with their active headcount.
+func (s *Service) Departments(ctx context.Context, names ...string) (_ []domainEmployee.Department, err error) {
+	ctx, span := startSpan(ctx, "Departments")
+	defer span.end(&err)
+
+	counts, err := s.repo.Headcount(ctx, names...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(names) == 0 {
+		for name := range counts {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	out := make([]domainEmployee.Department, 0, len(names))
+	for _, name := range names {
+		out = append(out, domainEmployee.Department{Name: name, Headcount: counts[name]})
+	}
+	return out, nil
+}
+
+func (s *Service) List(ctx context.Context, in ListInput) (_ []domainEmployee.Employee, _ int64, err error) {
+	ctx, span := startSpan(ctx, "List")
+	defer span.end(&err)
+
+	filter, page, err := s.listQuery(ctx, in)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.repo.List(ctx, filter, page, in.Fields...)
+}
+
+// Page is one page of a listing and the number of employees it pages.
+type Page struct {
+	Items []domainEmployee.Employee
+	Total int64
+}
+
+// ListByManagers lists the reports of each manager in one read, paging them
+// per manager as List would. in.ManagerID is ignored.
+func (s *Service) ListByManagers(ctx context.Context, managerIDs []string, in ListInput) (_ map[string]Page, err error) {
+	ctx, span := startSpan(ctx, "ListByManagers", attribute.Int("employee.managers", len(managerIDs)))
+	defer span.end(&err)
+
+	return s.listBy(ctx, in, managerIDs, func(f *domainEmployee.ListFilter) { f.ManagerIDs = managerIDs },
+		func(e *domainEmployee.Employee) string { return e.ManagerID })
+}
+
+// ListByDepartments lists the employees of each department in one read,
+// paging them per department as List would. in.Department is ignored.
+func (s *Service) ListByDepartments(ctx context.Context, departments []string, in ListInput) (_ map[string]Page, err error) {
+	ctx, span := startSpan(ctx, "ListByDepartments", attribute.Int("employee.departments", len(departments)))
+	defer span.end(&err)
+
+	return s.listBy(ctx, in, departments, func(f *domainEmployee.ListFilter) { f.Departments = departments },
+		func(e *domainEmployee.Employee) string { return e.Department })
+}
+
+// listBy reads every employee of the groups at once and pages each group in
+// memory; every key gets a page, empty when the group has nobody.
+func (s *Service) listBy(ctx context.Context, in ListInput, keys []string, scope func(*domainEmployee.ListFilter), keyOf func(*domainEmployee.Employee) string) (map[string]Page, error) {
+	in.ManagerID, in.Department, in.Fields = nil, nil, nil
+	filter, page, err := s.listQuery(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	scope(&filter)
+
+	out := make(map[string]Page, len(keys))
+	for _, k := range keys {
+		out[k] = Page{}
+	}
+	if len(keys) == 0 {
+		return out, nil
+	}
+	items, _, err := s.repo.List(ctx, filter, domainEmployee.ListPage{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		k := keyOf(&items[i])
+		p := out[k]
+		if p.Total >= page.Offset && int64(len(p.Items)) < page.Limit {
+			p.Items = append(p.Items, items[i])
+		}
+		p.Total++
+		out[k] = p
+	}
+	return out, nil
+}
+
+// listQuery turns in into a repository filter and page, with the page size
+// bounded.
+func (s *Service) listQuery(ctx context.Context, in ListInput) (domainEmployee.ListFilter, domainEmployee.ListPage, error) {
+	if err := validateFields(in.Fields); err != nil {
+		return domainEmployee.ListFilter{}, domainEmployee.ListPage{}, err
+	}
 	if in.Limit <= 0 || in.Limit > 200 {
 		in.Limit = 20
 	}
@@ -116,24 +315,42 @@ func (s *Service) List(ctx context.Context, in ListInput) ([]domainEmployee.Empl
 		status = &st
 	}
 
+	customFields, err := s.customFieldFilter(ctx, in.CustomFields)
+	if err != nil {
+		return domainEmployee.ListFilter{}, domainEmployee.ListPage{}, err
+	}
+
 	filter := domainEmployee.ListFilter{
-		Department: in.Department,
-		Status:     status,
-		Query:      in.Query,
+		Department:   in.Department,
+		Status:       status,
+		ManagerID:    in.ManagerID,
+		Email:        in.Email,
+		Query:        in.Query,
+		CustomFields: customFields,
 	}
-	page := domainEmployee.ListPage{Limit: in.Limit, Offset: in.Offset}
-	return s.repo.List(ctx, filter, page)
+	return filter, domainEmployee.ListPage{Limit: in.Limit, Offset: in.Offset}, nil
 }
 
-func (s *Service) Update(ctx context.Context, id string, in UpdateInput) (*domainEmployee.Employee, error) {
+func (s *Service) Update(ctx context.Context, id string, in UpdateInput) (_ *domainEmployee.Employee, err error) {
+	ctx, span := startSpan(ctx, "Update", idAttr(id))
+	defer span.end(&err)
+
+	if in.Phone != nil {
+		phone := normalizePhone(*in.Phone)
+		in.Phone = &phone
+	}
 	if err := s.validate.Struct(in); err != nil {
-		return nil, domain.Validation(err.Error())
+		return nil, validation.Error(err)
 	}
 
 	e, err := s.Get(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	if in.IfUpdatedAt != nil && !e.UpdatedAt.Equal(*in.IfUpdatedAt) {
+		return nil, domainEmployee.ErrModified
+	}
+	before := *e
 
 	if in.Email != nil {
 		v := strings.TrimSpace(strings.ToLower(*in.Email))
@@ -154,7 +371,15 @@ func (s *Service) Update(ctx context.Context, id string, in UpdateInput) (*domai
 	if in.LastName != nil {
 		e.LastName = *in.LastName
 	}
+	if in.Phone != nil {
+		e.Phone = *in.Phone
+	}
+	if in.DateOfBirth != nil {
+		e.DateOfBirth = *in.DateOfBirth
+	}
+	moved := false
 	if in.Department != nil {
+		moved = *in.Department != e.Department
 		e.Department = *in.Department
 	}
 	if in.Position != nil {
@@ -166,18 +391,142 @@ func (s *Service) Update(ctx context.Context, id string, in UpdateInput) (*domai
 	if in.Status != nil && *in.Status != "" {
 		e.Status = domainEmployee.Status(*in.Status)
 	}
+	if in.ManagerID != nil && *in.ManagerID != e.ManagerID {
+		if err := s.checkManager(ctx, e.ID, *in.ManagerID); err != nil {
+			return nil, err
+		}
+		e.ManagerID = *in.ManagerID
+	}
+	if in.CustomFields != nil || moved {
+		if err := s.setCustomFields(ctx, e, in.CustomFields, moved); err != nil {
+			return nil, err
+		}
+	}
 
+	// Only a conditional update fails when the employee changed meanwhile;
+	// otherwise the last write wins, as before IfUpdatedAt existed.
+	var prevUpdatedAt time.Time
+	if in.IfUpdatedAt != nil {
+		prevUpdatedAt = *in.IfUpdatedAt
+	}
 	e.UpdatedAt = s.now().UTC()
-	if err := s.repo.Update(ctx, e); err != nil {
+	err = s.withinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Update(ctx, e, prevUpdatedAt); err != nil {
+			return err
+		}
+		changes := event.Diff(&before, e)
+		if len(changes) == 0 {
+			return nil
+		}
+		return s.record(ctx, event.EmployeeUpdated{Employee: event.Snapshot(e), Changes: changes})
+	})
+	if err != nil {
 		return nil, err
 	}
 	return e, nil
 }
 
-func (s *Service) Delete(ctx context.Context, id string) error {
+func (s *Service) Delete(ctx context.Context, id string) (err error) {
+	ctx, span := startSpan(ctx, "Delete", idAttr(id))
+	defer span.end(&err)
+
 	// ensure not-found is consistent
-	if _, err := s.Get(ctx, id); err != nil {
+	e, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	return s.withinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Delete(ctx, id); err != nil {
+			return err
+		}
+		return s.record(ctx, event.EmployeeDeleted{Employee: event.Snapshot(e)})
+	})
+}
+
+func (s *Service) withinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if s.tx == nil {
+		return fn(ctx)
+	}
+	return s.tx.WithinTx(ctx, fn)
+}
+
+func (s *Service) record(ctx context.Context, ev event.Event) error {
+	if s.outbox == nil {
+		return nil
+	}
+	tenantID, err := tenant.Require(ctx)
+	if err != nil {
 		return err
 	}
-	return s.repo.Delete(ctx, id)
+	env, err := event.NewEnvelope(tenantID, ev, s.now().UTC())
+	if err != nil {
+		return domain.Internal("failed to encode event", err)
+	}
+	return s.outbox.Append(ctx, env)
+}
+
+// checkManager verifies that managerID exists and that making it the manager
+// of employeeID (empty for a new employee) does not create a reporting cycle.
+func (s *Service) checkManager(ctx context.Context, employeeID, managerID string) error {
+	if managerID == "" {
+		return nil
+	}
+
+	seen := map[string]bool{}
+	if employeeID != "" {
+		seen[employeeID] = true
+	}
+
+	id := managerID
+	for depth := 0; id != "" && depth < maxManagerChain; depth++ {
+		if seen[id] {
+			return invalidManager("cycle", "manager_id would create a reporting cycle")
+		}
+		seen[id] = true
+
+		m, err := s.repo.GetByID(ctx, id, domainEmployee.FieldManagerID)
+		if err != nil {
+			var derr domain.Error
+			if depth == 0 && errors.As(err, &derr) && derr.Kind == domain.ErrKindValidation {
+				return invalidManager("exists", "manager_id must reference an existing employee")
+			}
+			return err
+		}
+		if m == nil {
+			if depth == 0 {
+				return invalidManager("exists", "manager_id must reference an existing employee")
+			}
+			return nil
+		}
+		id = m.ManagerID
+	}
+	return nil
+}
+
+// normalizePhone drops the spaces and punctuation people write numbers
+// with, so "+1 (415) 555-0100" is stored, and compared, as +14155550100.
+func normalizePhone(phone string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(" -().", r) {
+			return -1
+		}
+		return r
+	}, phone)
+}
+
+func invalidManager(rule, msg string) error {
+	return domain.InvalidFields("invalid manager", []domain.FieldError{{Field: "manager_id", Rule: rule, Message: msg}})
+}
+
+func validateFields(fields []string) error {
+	for _, f := range fields {
+		if !domainEmployee.IsField(f) {
+			return domain.InvalidFields("invalid field selection", []domain.FieldError{{
+				Field:   "fields",
+				Rule:    "oneof",
+				Message: fmt.Sprintf("unknown field %q, must be one of: %s", f, strings.Join(domainEmployee.Fields, ", ")),
+			}})
+		}
+	}
+	return nil
 }
@@ -0,0 +1,57 @@
+package employee
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rohitashk/golang-rest-api/internal/adapters/memory"
+	domainEmployee "github.com/rohitashk/golang-rest-api/internal/domain/employee"
+)
+
+// racingRepository changes the employee's salary right after each read, as
+// a request running alongside would.
+type racingRepository struct {
+	*memory.EmployeeRepository
+}
+
+func (r racingRepository) GetByID(ctx context.Context, id string, fields ...string) (*domainEmployee.Employee, error) {
+	e, err := r.EmployeeRepository.GetByID(ctx, id, fields...)
+	if err != nil || e == nil {
+		return e, err
+	}
+	other := *e
+	other.Salary++
+	other.UpdatedAt = e.UpdatedAt.Add(1)
+	if err := r.EmployeeRepository.Update(ctx, &other, e.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func TestUpdateIsOnlyConditionalWhenAsked(t *testing.T) {
+	ctx, s, _ := newTestService(t)
+	e := create(t, ctx, s, CreateInput{FirstName: "Ada", LastName: "Lovelace", Email: "ada@example.com", Salary: 100})
+	s.repo = racingRepository{s.repo.(*memory.EmployeeRepository)}
+
+	lead := "Lead"
+	got, err := s.Update(ctx, e.ID, UpdateInput{Position: &lead})
+	if err != nil {
+		t.Fatalf("plain update of an employee changed meanwhile: %v", err)
+	}
+	if got.Position != "Lead" {
+		t.Errorf("position = %q", got.Position)
+	}
+
+	// A conditional update made with what was read fails, even when the
+	// change lands after the service's own read.
+	read, err := s.repo.GetByID(ctx, e.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	staff := "Staff"
+	_, err = s.Update(ctx, e.ID, UpdateInput{Position: &staff, IfUpdatedAt: &read.UpdatedAt})
+	if !errors.Is(err, domainEmployee.ErrModified) {
+		t.Errorf("conditional update: err = %v, want ErrModified", err)
+	}
+}
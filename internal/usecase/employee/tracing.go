@@ -0,0 +1,42 @@
+package employee
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/rohitashk/golang-rest-api/internal/domain"
+)
+
+var tracer = otel.Tracer("github.com/rohitashk/golang-rest-api/internal/usecase/employee")
+
+type span struct {
+	trace.Span
+}
+
+func startSpan(ctx context.Context, method string, attrs ...attribute.KeyValue) (context.Context, span) {
+	ctx, s := tracer.Start(ctx, "employee.Service."+method, trace.WithAttributes(attrs...))
+	return ctx, span{s}
+}
+
+// end is deferred with the method's error result. Every error is recorded,
+// but only internal ones mark the span as failed; a not-found or invalid
+// input is the use case working.
+func (s span) end(err *error) {
+	if e := *err; e != nil {
+		s.RecordError(e)
+		var derr domain.Error
+		if !errors.As(e, &derr) || derr.Kind == domain.ErrKindInternal {
+			s.SetStatus(codes.Error, e.Error())
+		}
+	}
+	s.End()
+}
+
+func idAttr(id string) attribute.KeyValue {
+	return attribute.String("employee.id", id)
+}
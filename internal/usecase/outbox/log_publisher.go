@@ -0,0 +1,22 @@
+package outbox
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/rohitashk/golang-rest-api/internal/domain/event"
+)
+
+// LogPublisher writes each event to the log. It is the default sink until a
+// real broker is configured.
+func LogPublisher(logger *slog.Logger) event.Publisher {
+	return event.PublisherFunc(func(ctx context.Context, e event.Envelope) error {
+		logger.InfoContext(ctx, "domain event",
+			"event_id", e.ID,
+			"type", e.Type,
+			"aggregate_id", e.AggregateID,
+			"occurred_at", e.OccurredAt,
+		)
+		return nil
+	})
+}
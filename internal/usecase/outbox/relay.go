@@ -0,0 +1,114 @@
+package outbox
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/rohitashk/golang-rest-api/internal/domain/event"
+)
+
+type RelayConfig struct {
+	// Interval is the pause between polls once the outbox is drained.
+	Interval  time.Duration
+	BatchSize int
+	// Lease is how long a claimed event is hidden from other relays. It must
+	// comfortably exceed the time a publish takes.
+	Lease time.Duration
+	// MaxBackoff caps the exponential delay between retries of an event.
+	MaxBackoff time.Duration
+}
+
+// Relay moves events from the outbox to a publisher. An event is marked
+// published only after Publish returns nil, so delivery is at least once:
+// a crash between the two repeats the event.
+type Relay struct {
+	outbox    event.Outbox
+	publisher event.Publisher
+	logger    *slog.Logger
+	cfg       RelayConfig
+	now       func() time.Time
+}
+
+func NewRelay(outbox event.Outbox, publisher event.Publisher, logger *slog.Logger, cfg RelayConfig) *Relay {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.Lease <= 0 {
+		cfg.Lease = 30 * time.Second
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = 10 * time.Minute
+	}
+	return &Relay{outbox: outbox, publisher: publisher, logger: logger, cfg: cfg, now: time.Now}
+}
+
+// Run relays events until ctx is cancelled.
+func (r *Relay) Run(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		n, err := r.RunOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			r.logger.Error("outbox relay failed", "err", err)
+		}
+
+		// A full batch means there is likely more waiting.
+		if n == r.cfg.BatchSize {
+			timer.Reset(0)
+		} else {
+			timer.Reset(r.cfg.Interval)
+		}
+	}
+}
+
+// RunOnce relays one batch and returns how many events it claimed.
+func (r *Relay) RunOnce(ctx context.Context) (int, error) {
+	events, err := r.outbox.Claim(ctx, r.cfg.BatchSize, r.cfg.Lease)
+	if err != nil {
+		return len(events), err
+	}
+
+	for _, e := range events {
+		if err := r.publisher.Publish(ctx, e); err != nil {
+			retryAt := r.now().Add(r.backoff(e.Attempts))
+			r.logger.Warn("event publish failed",
+				"event_id", e.ID,
+				"type", e.Type,
+				"attempts", e.Attempts,
+				"retry_at", retryAt,
+				"err", err,
+			)
+			if err := r.outbox.MarkFailed(ctx, e.ID, err.Error(), retryAt); err != nil {
+				return len(events), err
+			}
+			continue
+		}
+		if err := r.outbox.MarkPublished(ctx, e.ID); err != nil {
+			return len(events), err
+		}
+	}
+	return len(events), nil
+}
+
+// backoff doubles from one second per attempt up to MaxBackoff.
+func (r *Relay) backoff(attempts int) time.Duration {
+	d := time.Second
+	for i := 1; i < attempts && d < r.cfg.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > r.cfg.MaxBackoff {
+		d = r.cfg.MaxBackoff
+	}
+	return d
+}
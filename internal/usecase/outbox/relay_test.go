@@ -0,0 +1,200 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rohitashk/golang-rest-api/internal/adapters/memory"
+	"github.com/rohitashk/golang-rest-api/internal/domain/event"
+)
+
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// recorder is a publisher that records what it published and fails the
+// events in fail, once each.
+type recorder struct {
+	mu        sync.Mutex
+	published []string
+	fail      map[string]bool
+}
+
+func (p *recorder) Publish(_ context.Context, e event.Envelope) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.fail[e.ID] {
+		delete(p.fail, e.ID)
+		return errors.New("broker unavailable")
+	}
+	p.published = append(p.published, e.ID)
+	return nil
+}
+
+func (p *recorder) ids() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Clone(p.published)
+}
+
+// appendEvents adds an event per ID to the outbox, in that order, a minute
+// apart from an hour ago.
+func appendEvents(t *testing.T, o *memory.Outbox, ids ...string) {
+	t.Helper()
+	start := time.Now().Add(-time.Hour)
+	for i, id := range ids {
+		err := o.Append(context.Background(), event.Envelope{
+			ID: id, Tenant: "acme", Type: event.TypeEmployeeCreated, AggregateID: "e" + id,
+			OccurredAt: start.Add(time.Duration(i) * time.Minute), Payload: []byte(`{}`),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRelayPublishesOldestFirstInBatches(t *testing.T) {
+	o := memory.NewOutbox()
+	appendEvents(t, o, "a", "b", "c")
+	p := &recorder{}
+	r := NewRelay(o, p, discard, RelayConfig{BatchSize: 2})
+
+	for i, want := range []int{2, 1, 0} {
+		n, err := r.RunOnce(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != want {
+			t.Errorf("run %d claimed %d, want %d", i+1, n, want)
+		}
+	}
+	if got := p.ids(); !slices.Equal(got, []string{"a", "b", "c"}) {
+		t.Errorf("published %q", got)
+	}
+
+	// Published events are acknowledged and never claimed again.
+	left, err := o.Claim(context.Background(), 10, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(left) != 0 {
+		t.Errorf("%d events left after publishing", len(left))
+	}
+}
+
+func TestRelayRetriesFailedEvents(t *testing.T) {
+	o := memory.NewOutbox()
+	appendEvents(t, o, "a", "b")
+	p := &recorder{fail: map[string]bool{"a": true}}
+	r := NewRelay(o, p, discard, RelayConfig{})
+
+	if _, err := r.RunOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := p.ids(); !slices.Equal(got, []string{"b"}) {
+		t.Fatalf("published %q; a failure must not hold back later events", got)
+	}
+	// a waits out its backoff.
+	if n, _ := r.RunOnce(context.Background()); n != 0 {
+		t.Errorf("claimed %d during the backoff", n)
+	}
+}
+
+func TestRelayRetriesUntilPublished(t *testing.T) {
+	o := memory.NewOutbox()
+	appendEvents(t, o, "a")
+	p := &recorder{fail: map[string]bool{"a": true}}
+	r := NewRelay(o, p, discard, RelayConfig{})
+	// With the relay's clock an hour behind, every retry is due at once.
+	r.now = func() time.Time { return time.Now().Add(-time.Hour) }
+
+	for i, want := range [][]string{nil, {"a"}} {
+		if n, err := r.RunOnce(context.Background()); err != nil || n != 1 {
+			t.Fatalf("run %d claimed %d, %v", i+1, n, err)
+		}
+		if got := p.ids(); !slices.Equal(got, want) {
+			t.Errorf("run %d published %q, want %q", i+1, got, want)
+		}
+	}
+	if n, _ := r.RunOnce(context.Background()); n != 0 {
+		t.Errorf("claimed %d after publishing", n)
+	}
+}
+
+func TestRelaySkipsLeasedEvents(t *testing.T) {
+	o := memory.NewOutbox()
+	appendEvents(t, o, "a", "b")
+	// Another relay holds a.
+	if claimed, err := o.Claim(context.Background(), 1, time.Hour); err != nil || len(claimed) != 1 || claimed[0].ID != "a" {
+		t.Fatalf("claim = %+v, %v", claimed, err)
+	}
+
+	p := &recorder{}
+	r := NewRelay(o, p, discard, RelayConfig{})
+	if _, err := r.RunOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := p.ids(); !slices.Equal(got, []string{"b"}) {
+		t.Errorf("published %q, want only b", got)
+	}
+}
+
+func TestRelayRun(t *testing.T) {
+	o := memory.NewOutbox()
+	appendEvents(t, o, "a", "b", "c")
+	p := &recorder{}
+	r := NewRelay(o, p, discard, RelayConfig{BatchSize: 1, Interval: time.Hour})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		r.Run(ctx)
+		close(done)
+	}()
+	// Full batches are followed at once, not after Interval.
+	deadline := time.Now().Add(5 * time.Second)
+	for len(p.ids()) < 3 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	<-done
+	if got := p.ids(); !slices.Equal(got, []string{"a", "b", "c"}) {
+		t.Errorf("published %q", got)
+	}
+}
+
+func TestRelayBackoff(t *testing.T) {
+	r := NewRelay(nil, nil, discard, RelayConfig{MaxBackoff: 10 * time.Second})
+	for attempts, want := range map[int]time.Duration{
+		0: time.Second, 1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 8 * time.Second, 5: 10 * time.Second, 50: 10 * time.Second,
+	} {
+		if got := r.backoff(attempts); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+}
+
+func TestPublishersRetryAll(t *testing.T) {
+	o := memory.NewOutbox()
+	appendEvents(t, o, "a")
+	first, second := &recorder{}, &recorder{fail: map[string]bool{"a": true}}
+	r := NewRelay(o, event.Publishers{first, second}, discard, RelayConfig{})
+	r.now = func() time.Time { return time.Now().Add(-time.Hour) }
+
+	for i := 0; i < 2; i++ {
+		if _, err := r.RunOnce(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The first publisher sees a twice, so it must tolerate duplicates.
+	if got := first.ids(); !slices.Equal(got, []string{"a", "a"}) {
+		t.Errorf("first published %q", got)
+	}
+	if got := second.ids(); !slices.Equal(got, []string{"a"}) {
+		t.Errorf("second published %q", got)
+	}
+}
@@ -0,0 +1,86 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// maxRedirects bounds the redirects followed for one delivery.
+const maxRedirects = 3
+
+// ErrPrivateTarget is returned for a delivery to an address that is not
+// public, such as loopback, a private range or the cloud metadata service.
+var ErrPrivateTarget = errors.New("webhook target is not a public address")
+
+// sharedPrefixes are not caught by the netip predicates but are no more
+// public than 10.0.0.0/8.
+var sharedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("100.64.0.0/10"), // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),  // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"), // benchmarking
+	netip.MustParsePrefix("64:ff9b::/96"),  // NAT64, may reach IPv4 internals
+}
+
+// publicAddr reports whether a delivery may be sent to addr.
+func publicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsUnspecified() || addr.IsLoopback() || addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() {
+		return false
+	}
+	if addr.Is4() && addr.As4()[0] == 0 { // "this network"
+		return false
+	}
+	for _, p := range sharedPrefixes {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// NewClient returns the HTTP client deliveries are sent with. Subscription
+// URLs are chosen by API callers, so unless allowPrivate is set the client
+// refuses to connect to anything but public addresses. The check runs on the
+// address actually dialled, after DNS resolution, so neither a hostname
+// resolving to 127.0.0.1 nor a redirect to 169.254.169.254 gets through.
+// allowPrivate is for local testing against a receiver on the same machine.
+func NewClient(allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: 10 * time.Second, KeepAlive: 30 * time.Second}
+	if !allowPrivate {
+		dialer.Control = func(_, address string, _ syscall.RawConn) error {
+			ap, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !publicAddr(ap.Addr()) {
+				return fmt.Errorf("%w: %s", ErrPrivateTarget, ap.Addr())
+			}
+			return nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would dial the target itself, past the check above.
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return fmt.Errorf("redirect to unsupported scheme %q", req.URL.Scheme)
+			}
+			return nil
+		},
+	}
+}
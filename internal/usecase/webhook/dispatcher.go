@@ -0,0 +1,217 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rohitashk/golang-rest-api/internal/domain/tenant"
+	domainWebhook "github.com/rohitashk/golang-rest-api/internal/domain/webhook"
+)
+
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	TimestampHeader = "X-Webhook-Timestamp"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+
+	// maxAttemptLog bounds the attempts kept on a delivery.
+	maxAttemptLog = 20
+)
+
+// Sign returns the signature header value for a delivery body: the hex
+// HMAC-SHA256 of "<timestamp>.<body>" keyed with the subscription secret.
+// Receivers recompute it and reject stale timestamps to prevent replays.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+type DispatcherConfig struct {
+	Interval  time.Duration
+	BatchSize int
+	// Timeout bounds a single HTTP attempt.
+	Timeout time.Duration
+	// MaxAttempts is how many times a delivery is tried before it is marked
+	// failed; redelivery starts a new budget.
+	MaxAttempts int
+	MaxBackoff  time.Duration
+}
+
+// Dispatcher sends pending deliveries. Any 2xx response counts as success;
+// anything else is retried with exponential backoff.
+type Dispatcher struct {
+	subs       domainWebhook.SubscriptionRepository
+	deliveries domainWebhook.DeliveryRepository
+	client     *http.Client
+	logger     *slog.Logger
+	cfg        DispatcherConfig
+	now        func() time.Time
+}
+
+func NewDispatcher(subs domainWebhook.SubscriptionRepository, deliveries domainWebhook.DeliveryRepository, client *http.Client, logger *slog.Logger, cfg DispatcherConfig) *Dispatcher {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 20
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 8
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = time.Hour
+	}
+	return &Dispatcher{
+		subs:       subs,
+		deliveries: deliveries,
+		client:     client,
+		logger:     logger,
+		cfg:        cfg,
+		now:        time.Now,
+	}
+}
+
+// Run sends deliveries until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		n, err := d.RunOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			d.logger.Error("webhook dispatch failed", "err", err)
+		}
+
+		if n == d.cfg.BatchSize {
+			timer.Reset(0)
+		} else {
+			timer.Reset(d.cfg.Interval)
+		}
+	}
+}
+
+// RunOnce sends one batch of due deliveries and returns how many it claimed.
+func (d *Dispatcher) RunOnce(ctx context.Context) (int, error) {
+	// The lease covers every attempt in the batch being made in sequence.
+	lease := time.Duration(d.cfg.BatchSize)*d.cfg.Timeout + time.Minute
+	due, err := d.deliveries.ClaimDue(ctx, d.cfg.BatchSize, lease)
+	if err != nil {
+		return len(due), err
+	}
+
+	// One delivery failing to be recorded must not hold up the others; its
+	// lease expires and it is claimed again.
+	for i := range due {
+		if err := d.deliver(ctx, &due[i]); err != nil {
+			if ctx.Err() != nil {
+				return len(due), ctx.Err()
+			}
+			d.logger.Error("webhook delivery not recorded", "delivery_id", due[i].ID, "subscription_id", due[i].SubscriptionID, "err", err)
+		}
+	}
+	return len(due), nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, del *domainWebhook.Delivery) error {
+	sub, err := d.subs.GetByID(tenant.WithID(ctx, del.Tenant), del.SubscriptionID)
+	if err != nil {
+		return err
+	}
+
+	var attempt domainWebhook.Attempt
+	if sub == nil || !sub.Active {
+		attempt = domainWebhook.Attempt{At: d.now().UTC(), Error: "subscription is disabled"}
+		del.Tries = d.cfg.MaxAttempts // no point retrying
+	} else {
+		attempt = d.send(ctx, sub, del)
+		del.Tries++
+	}
+
+	del.Attempts = append(del.Attempts, attempt)
+	if len(del.Attempts) > maxAttemptLog {
+		del.Attempts = del.Attempts[len(del.Attempts)-maxAttemptLog:]
+	}
+
+	now := d.now().UTC()
+	switch {
+	case attempt.Error == "" && attempt.StatusCode >= 200 && attempt.StatusCode < 300:
+		del.Status = domainWebhook.DeliverySucceeded
+	case del.Tries >= d.cfg.MaxAttempts:
+		del.Status = domainWebhook.DeliveryFailed
+		d.logger.Warn("webhook delivery failed", "delivery_id", del.ID, "subscription_id", del.SubscriptionID, "tries", del.Tries)
+	default:
+		del.NextAttemptAt = now.Add(d.backoff(del.Tries))
+	}
+	del.UpdatedAt = now
+
+	return d.deliveries.Update(ctx, del)
+}
+
+func (d *Dispatcher) send(ctx context.Context, sub *domainWebhook.Subscription, del *domainWebhook.Delivery) domainWebhook.Attempt {
+	ctx, cancel := context.WithTimeout(ctx, d.cfg.Timeout)
+	defer cancel()
+
+	start := d.now()
+	attempt := domainWebhook.Attempt{At: start.UTC()}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(del.Payload))
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	ts := start.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "employee-management-webhooks/1.0")
+	req.Header.Set(EventHeader, del.EventType)
+	req.Header.Set(DeliveryHeader, del.ID)
+	req.Header.Set(TimestampHeader, strconv.FormatInt(ts, 10))
+	req.Header.Set(SignatureHeader, Sign(sub.Secret, ts, del.Payload))
+
+	resp, err := d.client.Do(req)
+	attempt.Duration = d.now().Sub(start)
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	defer resp.Body.Close()
+	// Drain a little so the connection can be reused.
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	attempt.StatusCode = resp.StatusCode
+	return attempt
+}
+
+// backoff doubles from ten seconds per try up to MaxBackoff.
+func (d *Dispatcher) backoff(tries int) time.Duration {
+	b := 10 * time.Second
+	for i := 1; i < tries && b < d.cfg.MaxBackoff; i++ {
+		b *= 2
+	}
+	if b > d.cfg.MaxBackoff {
+		b = d.cfg.MaxBackoff
+	}
+	return b
+}
@@ -0,0 +1,334 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rohitashk/golang-rest-api/internal/adapters/memory"
+	"github.com/rohitashk/golang-rest-api/internal/domain/event"
+	"github.com/rohitashk/golang-rest-api/internal/domain/tenant"
+	domainWebhook "github.com/rohitashk/golang-rest-api/internal/domain/webhook"
+)
+
+const testSecret = "whsec_0123456789abcdef"
+
+// fixture is a subscription of the tenant acme to a receiver in this
+// process. The dispatcher's clock is an hour behind, so every retry it
+// schedules is already due and each RunOnce makes the next attempt.
+type fixture struct {
+	svc        *Service
+	dispatcher *Dispatcher
+	deliveries domainWebhook.DeliveryRepository
+	sub        *domainWebhook.Subscription
+	start      time.Time
+}
+
+func newFixture(t *testing.T, receiver http.Handler, deliveries domainWebhook.DeliveryRepository) *fixture {
+	t.Helper()
+	srv := httptest.NewServer(receiver)
+	t.Cleanup(srv.Close)
+
+	subs := memory.NewWebhookRepository()
+	if deliveries == nil {
+		deliveries = memory.NewWebhookDeliveryRepository()
+	}
+	svc := NewService(subs, deliveries)
+	sub, err := svc.Create(tenant.WithID(context.Background(), "acme"), CreateInput{URL: srv.URL + "/hooks", Secret: testSecret})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+	d := NewDispatcher(subs, deliveries, NewClient(true), slog.New(slog.NewTextHandler(io.Discard, nil)), DispatcherConfig{
+		MaxAttempts: 3,
+	})
+	d.now = func() time.Time { return start }
+	return &fixture{svc: svc, dispatcher: d, deliveries: deliveries, sub: sub, start: start}
+}
+
+// publish queues an event for the subscription and returns its delivery.
+func (f *fixture) publish(t *testing.T, eventID string) domainWebhook.Delivery {
+	t.Helper()
+	err := f.svc.Publish(context.Background(), event.Envelope{
+		ID: eventID, Tenant: "acme", Type: event.TypeEmployeeCreated, OccurredAt: f.start, Payload: []byte(`{"id":"1"}`),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	list, _, err := f.svc.Deliveries(tenant.WithID(context.Background(), "acme"), f.sub.ID, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range list {
+		if d.EventID == eventID {
+			return d
+		}
+	}
+	t.Fatalf("no delivery for %s", eventID)
+	return domainWebhook.Delivery{}
+}
+
+func (f *fixture) run(t *testing.T, want int) {
+	t.Helper()
+	n, err := f.dispatcher.RunOnce(context.Background())
+	if err != nil || n != want {
+		t.Fatalf("RunOnce = %d, %v; want %d", n, err, want)
+	}
+}
+
+func (f *fixture) get(t *testing.T, id string) *domainWebhook.Delivery {
+	t.Helper()
+	d, err := f.deliveries.GetByID(context.Background(), id)
+	if err != nil || d == nil {
+		t.Fatalf("GetByID = %v, %v", d, err)
+	}
+	return d
+}
+
+func TestDispatcherSignsDeliveries(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		received []*http.Request
+		bodies   [][]byte
+	)
+	f := newFixture(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		received, bodies = append(received, r), append(bodies, body)
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}), nil)
+	del := f.publish(t, "evt-1")
+
+	f.run(t, 1)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 1 {
+		t.Fatalf("receiver got %d requests", len(received))
+	}
+	r, body := received[0], bodies[0]
+	if r.Method != http.MethodPost || r.URL.Path != "/hooks" || r.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("request = %s %s (%s)", r.Method, r.URL.Path, r.Header.Get("Content-Type"))
+	}
+	if r.Header.Get(EventHeader) != "employee.created" || r.Header.Get(DeliveryHeader) != del.ID {
+		t.Errorf("event = %q, delivery = %q", r.Header.Get(EventHeader), r.Header.Get(DeliveryHeader))
+	}
+	ts, err := strconv.ParseInt(r.Header.Get(TimestampHeader), 10, 64)
+	if err != nil || ts != f.start.Unix() {
+		t.Fatalf("timestamp = %q", r.Header.Get(TimestampHeader))
+	}
+	if got := r.Header.Get(SignatureHeader); got != Sign(testSecret, ts, body) {
+		t.Errorf("signature %q does not verify", got)
+	}
+	if got := r.Header.Get(SignatureHeader); got == Sign("whsec_someone-else", ts, body) || got == Sign(testSecret, ts+1, body) {
+		t.Error("signature does not depend on the secret and timestamp")
+	}
+	if !strings.Contains(string(body), `"id":"evt-1"`) || !strings.Contains(string(body), `"data":{"id":"1"}`) {
+		t.Errorf("body = %s", body)
+	}
+
+	got := f.get(t, del.ID)
+	if got.Status != domainWebhook.DeliverySucceeded || got.Tries != 1 || len(got.Attempts) != 1 || got.Attempts[0].StatusCode != http.StatusNoContent {
+		t.Errorf("delivery = %+v", got)
+	}
+	f.run(t, 0)
+}
+
+func TestDispatcherRetriesWithBackoffThenRedelivers(t *testing.T) {
+	var status atomic.Int32
+	status.Store(http.StatusServiceUnavailable)
+	var calls atomic.Int32
+	f := newFixture(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(int(status.Load()))
+	}), nil)
+	del := f.publish(t, "evt-1")
+
+	for try, backoff := range []time.Duration{10 * time.Second, 20 * time.Second} {
+		f.run(t, 1)
+		got := f.get(t, del.ID)
+		if got.Status != domainWebhook.DeliveryPending || got.Tries != try+1 {
+			t.Fatalf("after try %d: %s, %d tries", try+1, got.Status, got.Tries)
+		}
+		if want := f.start.Add(backoff); !got.NextAttemptAt.Equal(want) {
+			t.Errorf("after try %d: next attempt %s, want %s", try+1, got.NextAttemptAt, want)
+		}
+	}
+	f.run(t, 1)
+	got := f.get(t, del.ID)
+	if got.Status != domainWebhook.DeliveryFailed || got.Tries != 3 || len(got.Attempts) != 3 {
+		t.Fatalf("after the last try: %s, %d tries, %d attempts", got.Status, got.Tries, len(got.Attempts))
+	}
+	for _, a := range got.Attempts {
+		if a.StatusCode != http.StatusServiceUnavailable {
+			t.Errorf("attempt = %+v", a)
+		}
+	}
+	f.run(t, 0)
+
+	status.Store(http.StatusOK)
+	ctx := tenant.WithID(context.Background(), "acme")
+	if _, err := f.svc.Redeliver(tenant.WithID(context.Background(), "globex"), f.sub.ID, del.ID); err == nil {
+		t.Error("another tenant redelivered acme's delivery")
+	}
+	redelivered, err := f.svc.Redeliver(ctx, f.sub.ID, del.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if redelivered.Status != domainWebhook.DeliveryPending || redelivered.Tries != 0 {
+		t.Errorf("redelivered = %s, %d tries", redelivered.Status, redelivered.Tries)
+	}
+	f.run(t, 1)
+	got = f.get(t, del.ID)
+	if got.Status != domainWebhook.DeliverySucceeded || got.Tries != 1 || len(got.Attempts) != 4 {
+		t.Errorf("after redelivery: %s, %d tries, %d attempts", got.Status, got.Tries, len(got.Attempts))
+	}
+	if calls.Load() != 4 {
+		t.Errorf("receiver called %d times, want 4", calls.Load())
+	}
+}
+
+// failingDeliveries cannot record the outcome of one delivery.
+type failingDeliveries struct {
+	*memory.WebhookDeliveryRepository
+	failID string
+}
+
+func (r *failingDeliveries) Update(ctx context.Context, d *domainWebhook.Delivery) error {
+	if d.ID == r.failID {
+		return errors.New("write failed")
+	}
+	return r.WebhookDeliveryRepository.Update(ctx, d)
+}
+
+func TestRunOnceContinuesPastAFailedDelivery(t *testing.T) {
+	var calls atomic.Int32
+	deliveries := &failingDeliveries{WebhookDeliveryRepository: memory.NewWebhookDeliveryRepository()}
+	f := newFixture(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}), deliveries)
+	first := f.publish(t, "evt-1")
+	second := f.publish(t, "evt-2")
+	deliveries.failID = first.ID
+
+	f.run(t, 2)
+	if calls.Load() != 2 {
+		t.Errorf("receiver called %d times, want 2", calls.Load())
+	}
+	if got := f.get(t, second.ID); got.Status != domainWebhook.DeliverySucceeded {
+		t.Errorf("second delivery = %s, want it sent despite the first", got.Status)
+	}
+	if got := f.get(t, first.ID); got.Status != domainWebhook.DeliveryPending || got.Tries != 0 {
+		t.Errorf("first delivery = %s, %d tries; want it left for its lease to expire", got.Status, got.Tries)
+	}
+}
+
+func TestPublicAddr(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"93.184.216.34":          true,
+		"2606:2800:220:1::":      true,
+		"127.0.0.1":              false,
+		"::1":                    false,
+		"10.1.2.3":               false,
+		"172.16.0.1":             false,
+		"192.168.1.1":            false,
+		"169.254.169.254":        false,
+		"fe80::1":                false,
+		"fd00:ec2::254":          false,
+		"100.64.0.1":             false,
+		"0.0.0.0":                false,
+		"::":                     false,
+		"::ffff:127.0.0.1":       false,
+		"::ffff:169.254.169.254": false,
+		"224.0.0.1":              false,
+	} {
+		if got := publicAddr(netip.MustParseAddr(addr)); got != want {
+			t.Errorf("publicAddr(%s) = %v, want %v", addr, got, want)
+		}
+	}
+}
+
+// redirectTo answers requests for hooks.example.com with a redirect, without
+// the network, and hands everything else to the client's transport.
+type redirectTo struct {
+	location string
+	next     http.RoundTripper
+}
+
+func (rt redirectTo) RoundTrip(r *http.Request) (*http.Response, error) {
+	if r.URL.Host != "hooks.example.com" {
+		return rt.next.RoundTrip(r)
+	}
+	return &http.Response{
+		StatusCode: http.StatusFound,
+		Header:     http.Header{"Location": {rt.location}},
+		Body:       http.NoBody,
+		Request:    r,
+	}, nil
+}
+
+func TestClientRefusesPrivateTargets(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { calls.Add(1) }))
+	defer srv.Close()
+
+	client := NewClient(false)
+	for _, target := range []string{srv.URL, "http://169.254.169.254/latest/meta-data/", "http://[::1]:1/"} {
+		resp, err := client.Get(target)
+		if err == nil {
+			resp.Body.Close()
+		}
+		if !errors.Is(err, ErrPrivateTarget) {
+			t.Errorf("GET %s: err = %v, want ErrPrivateTarget", target, err)
+		}
+	}
+
+	for _, location := range []string{"http://169.254.169.254/latest/meta-data/", srv.URL} {
+		redirecting := *client
+		redirecting.Transport = redirectTo{location: location, next: client.Transport}
+		resp, err := redirecting.Get("http://hooks.example.com/")
+		if err == nil {
+			resp.Body.Close()
+		}
+		if !errors.Is(err, ErrPrivateTarget) {
+			t.Errorf("redirect to %s: err = %v, want ErrPrivateTarget", location, err)
+		}
+	}
+	if calls.Load() != 0 {
+		t.Errorf("the loopback receiver was reached %d times", calls.Load())
+	}
+
+	if resp, err := NewClient(true).Get(srv.URL); err != nil {
+		t.Errorf("allowing private targets: %v", err)
+	} else {
+		resp.Body.Close()
+	}
+}
+
+func TestDispatcherRecordsRefusedTargets(t *testing.T) {
+	var calls atomic.Int32
+	f := newFixture(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { calls.Add(1) }), nil)
+	f.dispatcher.client = NewClient(false)
+	del := f.publish(t, "evt-1")
+
+	f.run(t, 1)
+	got := f.get(t, del.ID)
+	if calls.Load() != 0 {
+		t.Error("the loopback receiver was reached")
+	}
+	if got.Status != domainWebhook.DeliveryPending || len(got.Attempts) != 1 || !strings.Contains(got.Attempts[0].Error, ErrPrivateTarget.Error()) {
+		t.Errorf("delivery = %s, attempts %+v", got.Status, got.Attempts)
+	}
+}
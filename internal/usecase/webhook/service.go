@@ -0,0 +1,232 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+
+	"github.com/rohitashk/golang-rest-api/internal/domain"
+	"github.com/rohitashk/golang-rest-api/internal/domain/event"
+	"github.com/rohitashk/golang-rest-api/internal/domain/tenant"
+	domainWebhook "github.com/rohitashk/golang-rest-api/internal/domain/webhook"
+	"github.com/rohitashk/golang-rest-api/internal/validation"
+)
+
+type CreateInput struct {
+	URL         string   `json:"url" validate:"required,http_url,max=2048"`
+	EventTypes  []string `json:"event_types" validate:"dive,oneof=employee.created employee.updated employee.deleted employee.merged"`
+	Description string   `json:"description" validate:"max=500"`
+	// Secret is generated when empty.
+	Secret string `json:"secret" validate:"omitempty,min=16,max=256"`
+	Active *bool  `json:"active"`
+}
+
+// UpdateInput fields left nil are not changed.
+type UpdateInput struct {
+	URL         *string   `json:"url" validate:"omitnil,http_url,max=2048"`
+	EventTypes  *[]string `json:"event_types" validate:"omitnil,dive,oneof=employee.created employee.updated employee.deleted employee.merged"`
+	Description *string   `json:"description" validate:"omitnil,max=500"`
+	Active      *bool     `json:"active"`
+}
+
+type Service struct {
+	subs       domainWebhook.SubscriptionRepository
+	deliveries domainWebhook.DeliveryRepository
+	validate   *validator.Validate
+	now        func() time.Time
+}
+
+func NewService(subs domainWebhook.SubscriptionRepository, deliveries domainWebhook.DeliveryRepository) *Service {
+	return &Service{
+		subs:       subs,
+		deliveries: deliveries,
+		validate:   validation.New(),
+		now:        time.Now,
+	}
+}
+
+func (s *Service) Create(ctx context.Context, in CreateInput) (*domainWebhook.Subscription, error) {
+	if err := s.validate.Struct(in); err != nil {
+		return nil, validation.Error(err)
+	}
+
+	secret := in.Secret
+	if secret == "" {
+		var err error
+		if secret, err = newSecret(); err != nil {
+			return nil, domain.Internal("failed to generate secret", err)
+		}
+	}
+	active := true
+	if in.Active != nil {
+		active = *in.Active
+	}
+
+	now := s.now().UTC()
+	sub := &domainWebhook.Subscription{
+		URL:         in.URL,
+		Secret:      secret,
+		EventTypes:  in.EventTypes,
+		Description: in.Description,
+		Active:      active,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.subs.Create(ctx, sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+func (s *Service) Get(ctx context.Context, id string) (*domainWebhook.Subscription, error) {
+	sub, err := s.subs.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if sub == nil {
+		return nil, domain.NotFound("webhook not found")
+	}
+	return sub, nil
+}
+
+func (s *Service) List(ctx context.Context) ([]domainWebhook.Subscription, error) {
+	return s.subs.List(ctx)
+}
+
+func (s *Service) Update(ctx context.Context, id string, in UpdateInput) (*domainWebhook.Subscription, error) {
+	if err := s.validate.Struct(in); err != nil {
+		return nil, validation.Error(err)
+	}
+
+	sub, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if in.URL != nil {
+		sub.URL = *in.URL
+	}
+	if in.EventTypes != nil {
+		sub.EventTypes = *in.EventTypes
+	}
+	if in.Description != nil {
+		sub.Description = *in.Description
+	}
+	if in.Active != nil {
+		sub.Active = *in.Active
+	}
+
+	sub.UpdatedAt = s.now().UTC()
+	if err := s.subs.Update(ctx, sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+func (s *Service) Delete(ctx context.Context, id string) error {
+	if _, err := s.Get(ctx, id); err != nil {
+		return err
+	}
+	if err := s.subs.Delete(ctx, id); err != nil {
+		return err
+	}
+	return s.deliveries.DeleteBySubscription(ctx, id)
+}
+
+// Deliveries returns the delivery log of a subscription, newest first.
+func (s *Service) Deliveries(ctx context.Context, id string, limit, offset int64) ([]domainWebhook.Delivery, int64, error) {
+	if _, err := s.Get(ctx, id); err != nil {
+		return nil, 0, err
+	}
+	if limit <= 0 || limit > 200 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.deliveries.ListBySubscription(ctx, id, domainWebhook.DeliveryPage{Limit: limit, Offset: offset})
+}
+
+func (s *Service) Delivery(ctx context.Context, id, deliveryID string) (*domainWebhook.Delivery, error) {
+	// Deliveries are reached through their subscription, which is scoped to
+	// the tenant.
+	if _, err := s.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	d, err := s.deliveries.GetByID(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if d == nil || d.SubscriptionID != id {
+		return nil, domain.NotFound("delivery not found")
+	}
+	return d, nil
+}
+
+// Redeliver queues a delivery to be sent again right away with a fresh
+// retry budget, whatever its current status.
+func (s *Service) Redeliver(ctx context.Context, id, deliveryID string) (*domainWebhook.Delivery, error) {
+	d, err := s.Delivery(ctx, id, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now().UTC()
+	d.Status = domainWebhook.DeliveryPending
+	d.Tries = 0
+	d.NextAttemptAt = now
+	d.UpdatedAt = now
+	if err := s.deliveries.Update(ctx, d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Publish queues a delivery of e for every subscription of its tenant that
+// wants it. It makes Service an event.Publisher for the outbox relay, which
+// retries an event Publish failed on; the deliveries queued by the failed
+// attempt are not queued again.
+func (s *Service) Publish(ctx context.Context, e event.Envelope) error {
+	ctx = tenant.WithID(ctx, e.Tenant)
+	subs, err := s.subs.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	body, err := e.Body()
+	if err != nil {
+		return domain.Internal("failed to encode webhook payload", err)
+	}
+
+	now := s.now().UTC()
+	for _, sub := range subs {
+		if !sub.Wants(string(e.Type)) {
+			continue
+		}
+		err := s.deliveries.Create(ctx, &domainWebhook.Delivery{
+			Tenant:         e.Tenant,
+			SubscriptionID: sub.ID,
+			EventID:        e.ID,
+			EventType:      string(e.Type),
+			Payload:        body,
+			Status:         domainWebhook.DeliveryPending,
+			NextAttemptAt:  now,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
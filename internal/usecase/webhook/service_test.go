@@ -0,0 +1,62 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rohitashk/golang-rest-api/internal/adapters/memory"
+	"github.com/rohitashk/golang-rest-api/internal/domain/event"
+	"github.com/rohitashk/golang-rest-api/internal/domain/tenant"
+	domainWebhook "github.com/rohitashk/golang-rest-api/internal/domain/webhook"
+)
+
+// flakyDeliveries fails to queue the next delivery for one subscription.
+type flakyDeliveries struct {
+	*memory.WebhookDeliveryRepository
+	failSubscription string
+}
+
+func (r *flakyDeliveries) Create(ctx context.Context, d *domainWebhook.Delivery) error {
+	if d.SubscriptionID == r.failSubscription {
+		r.failSubscription = ""
+		return errors.New("write failed")
+	}
+	return r.WebhookDeliveryRepository.Create(ctx, d)
+}
+
+func TestPublishRetryQueuesEachDeliveryOnce(t *testing.T) {
+	deliveries := &flakyDeliveries{WebhookDeliveryRepository: memory.NewWebhookDeliveryRepository()}
+	svc := NewService(memory.NewWebhookRepository(), deliveries)
+	ctx := tenant.WithID(context.Background(), "acme")
+	var subs []*domainWebhook.Subscription
+	for _, url := range []string{"https://a.example.com/hooks", "https://b.example.com/hooks", "https://c.example.com/hooks"} {
+		sub, err := svc.Create(ctx, CreateInput{URL: url, Secret: testSecret})
+		if err != nil {
+			t.Fatal(err)
+		}
+		subs = append(subs, sub)
+	}
+
+	// The relay retries an event whose deliveries were partly queued.
+	e := event.Envelope{ID: "evt-1", Tenant: "acme", Type: event.TypeEmployeeCreated, Payload: []byte(`{"id":"1"}`)}
+	deliveries.failSubscription = subs[1].ID
+	if err := svc.Publish(context.Background(), e); err == nil {
+		t.Fatal("Publish succeeded despite a failed delivery")
+	}
+	for i := 0; i < 2; i++ {
+		if err := svc.Publish(context.Background(), e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, sub := range subs {
+		list, total, err := svc.Deliveries(ctx, sub.ID, 0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if total != 1 || list[0].EventID != "evt-1" {
+			t.Errorf("%s has %d deliveries, want 1 of evt-1", sub.URL, total)
+		}
+	}
+}
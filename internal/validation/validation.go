@@ -0,0 +1,103 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+
+	"github.com/rohitashk/golang-rest-api/internal/domain"
+)
+
+// New returns a validator that reports fields by their JSON names.
+func New() *validator.Validate {
+	v := validator.New()
+	UseJSONNames(v)
+	return v
+}
+
+func UseJSONNames(v *validator.Validate) {
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		switch name {
+		case "-":
+			return ""
+		case "":
+			return f.Name
+		}
+		return name
+	})
+}
+
+// Error converts validator output into a domain validation error carrying
+// per-field details. Other errors are returned unchanged.
+func Error(err error) error {
+	fields, ok := Fields(err)
+	if !ok {
+		return err
+	}
+	return domain.InvalidFields("request validation failed", fields)
+}
+
+func Fields(err error) ([]domain.FieldError, bool) {
+	var ve validator.ValidationErrors
+	if !errors.As(err, &ve) {
+		return nil, false
+	}
+
+	out := make([]domain.FieldError, 0, len(ve))
+	for _, fe := range ve {
+		out = append(out, domain.FieldError{
+			Field:   fe.Field(),
+			Rule:    rule(fe),
+			Message: message(fe),
+		})
+	}
+	return out, true
+}
+
+// rule names the constraint fe failed. Of alternatives like "e164|len=0",
+// which let an update clear a field, it is the first.
+func rule(fe validator.FieldError) string {
+	r, _, _ := strings.Cut(fe.Tag(), "|")
+	return r
+}
+
+func message(fe validator.FieldError) string {
+	isString := fe.Kind() == reflect.String
+
+	switch rule(fe) {
+	case "required":
+		return fmt.Sprintf("%s is required", fe.Field())
+	case "email":
+		return fmt.Sprintf("%s must be a valid email address", fe.Field())
+	case "e164":
+		return fmt.Sprintf("%s must be a phone number in international format, e.g. +14155550100", fe.Field())
+	case "datetime":
+		return fmt.Sprintf("%s must be a date like 2006-01-02", fe.Field())
+	case "http_url":
+		return fmt.Sprintf("%s must be an absolute http or https URL", fe.Field())
+	case "oneof":
+		return fmt.Sprintf("%s must be one of: %s", fe.Field(), strings.Join(strings.Fields(fe.Param()), ", "))
+	case "min":
+		if isString && fe.Param() == "1" {
+			return fmt.Sprintf("%s must not be empty", fe.Field())
+		}
+		if isString {
+			return fmt.Sprintf("%s must be at least %s characters long", fe.Field(), fe.Param())
+		}
+		return fmt.Sprintf("%s must be at least %s", fe.Field(), fe.Param())
+	case "max":
+		if isString {
+			return fmt.Sprintf("%s must be at most %s characters long", fe.Field(), fe.Param())
+		}
+		return fmt.Sprintf("%s must be at most %s", fe.Field(), fe.Param())
+	case "gte":
+		return fmt.Sprintf("%s must be greater than or equal to %s", fe.Field(), fe.Param())
+	case "lte":
+		return fmt.Sprintf("%s must be less than or equal to %s", fe.Field(), fe.Param())
+	}
+	return fmt.Sprintf("%s failed the %q rule", fe.Field(), fe.Tag())
+}